@@ -7,6 +7,8 @@ require (
 	github.com/cucumber/godog v0.9.0
 	github.com/cucumber/messages-go/v10 v10.0.3
 	github.com/google/go-cmp v0.5.5
+	github.com/google/go-containerregistry v0.4.1-0.20210128200529-19c2b639fab1
+	github.com/googleapis/gnostic v0.5.3
 	github.com/h2non/gock v1.0.9
 	github.com/jenkins-x/go-scm v1.8.1
 	github.com/mitchellh/go-homedir v1.1.0
@@ -19,9 +21,11 @@ require (
 	github.com/pkg/errors v0.9.1
 	github.com/spf13/afero v1.6.0
 	github.com/spf13/cobra v1.1.3
+	github.com/spf13/pflag v1.0.5
 	github.com/tektoncd/pipeline v0.22.0
 	github.com/tektoncd/triggers v0.12.1
 	github.com/zalando/go-keyring v0.1.1
+	golang.org/x/oauth2 v0.0.0-20210413134643-5e61552d6c78
 	gopkg.in/AlecAivazis/survey.v1 v1.8.8
 	k8s.io/api v0.21.0
 	k8s.io/apimachinery v0.21.0
@@ -29,6 +33,8 @@ require (
 	k8s.io/klog v1.0.0
 	k8s.io/kubectl v0.21.0
 	knative.dev/pkg v0.0.0-20210428141353-878c85083565
+	sigs.k8s.io/kustomize/api v0.8.8
+	sigs.k8s.io/kustomize/kyaml v0.10.17
 	sigs.k8s.io/yaml v1.2.0
 )
 
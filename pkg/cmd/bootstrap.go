@@ -1,29 +1,51 @@
 package cmd
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/zalando/go-keyring"
 
 	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/mkmik/multierror"
 	"github.com/openshift/odo/pkg/log"
+	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
 	ktemplates "k8s.io/kubectl/pkg/util/templates"
+	sigsyaml "sigs.k8s.io/yaml"
 
 	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
 	"github.com/redhat-developer/kam/pkg/cmd/ui"
 	"github.com/redhat-developer/kam/pkg/cmd/utility"
+	"github.com/redhat-developer/kam/pkg/cmd/version"
 	"github.com/redhat-developer/kam/pkg/pipelines"
 	"github.com/redhat-developer/kam/pkg/pipelines/accesstoken"
 	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
+	"github.com/redhat-developer/kam/pkg/pipelines/clientconfig"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+	"github.com/redhat-developer/kam/pkg/pipelines/git"
 	"github.com/redhat-developer/kam/pkg/pipelines/imagerepo"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/namespaces"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
+	"github.com/redhat-developer/kam/pkg/pipelines/tekton"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
 )
 
 const (
@@ -34,17 +56,34 @@ const (
 	gitopsRepoURLFlag      = "gitops-repo-url"
 	serviceRepoURLFlag     = "service-repo-url"
 	gitHostAccessTokenFlag = "git-host-access-token"
+	configFlag             = "config"
 	imageRepoFlag          = "image-repo"
 	gitopsOperatorName     = "OpenShift GitOps Operator"
 	pipelinesOperatorName  = "OpenShift Pipelines Operator"
+	tektonAPIGroup         = "tekton.dev"
+	defaultConcurrency     = 3
+	defaultPushRetries     = 3
+
+	dependencyCheckOutputText = "text"
+	dependencyCheckOutputJSON = "json"
+
+	outputFormatText = "text"
+	outputFormatJSON = "json"
 )
 
 type drivers []string
 
+// buildArgKey matches the names of build args.
+var buildArgKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// quayRobotAccount matches the names of Quay.io robot accounts.
+var quayRobotAccount = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*\+[a-z][a-z0-9_]*$`)
+
 var (
 	supportedDrivers = drivers{
 		"github",
 		"gitlab",
+		"azure",
 	}
 )
 
@@ -70,7 +109,39 @@ var (
 // BootstrapParameters encapsulates the parameters for the kam pipelines init command.
 type BootstrapParameters struct {
 	*pipelines.BootstrapOptions
-	Interactive bool
+	Interactive   bool
+	PrintDefaults bool
+	ExplainLayout bool
+	DryRun        bool
+	CheckOnly     bool
+	Preflight     bool
+	Concurrency   int
+	ConfigFile    string
+	// SkipChecks skips the cluster dependency checks, e.g. when the
+	// operators are being installed at the same time as the bootstrap.
+	SkipChecks bool
+	// GitProxy is the proxy that the Git host API requests are sent through,
+	// instead of the proxy from the environment.
+	GitProxy string
+	// gitProxyURL is the parsed GitProxy, or nil to use the proxy from the
+	// environment.
+	gitProxyURL *url.URL
+	// ClusterProxy is the proxy that the cluster API requests are sent
+	// through, instead of the proxy from the environment.
+	ClusterProxy string
+	// DependencyCheckOutput is the format that the dependency check results
+	// are reported in.
+	DependencyCheckOutput string
+	// OutputFormat is the format that the outcome of the bootstrap is
+	// reported in.
+	OutputFormat string
+}
+
+// bootstrapDefaults is the set of default values that bootstrap uses when
+// nothing is overridden on the command line.
+type bootstrapDefaults struct {
+	*pipelines.BootstrapOptions
+	WebhookSecretLength int `json:"webhook-secret-length"`
 }
 
 // NewBootstrapParameters bootsraps a Bootstrap Parameters instance.
@@ -84,20 +155,61 @@ func NewBootstrapParameters() *BootstrapParameters {
 // If the prefix provided doesn't have a "-" then one is added, this makes the
 // generated environment names nicer to read.
 func (io *BootstrapParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	if io.PrintDefaults {
+		return nil
+	}
+	if io.ConfigFile != "" {
+		if err := loadBootstrapConfig(io.ConfigFile, cmd.Flags(), ioutils.NewFilesystem()); err != nil {
+			return err
+		}
+	}
+	// The conflict is reported before the cluster is checked for --check-only.
+	if io.SkipChecks && (io.CheckOnly || io.Preflight) {
+		return errors.New("--skip-checks cannot be used with --check-only or --preflight")
+	}
+	if cmd.Flags().Changed("existing-cluster-role") && strings.TrimSpace(io.ExistingClusterRole) == "" {
+		return errors.New("--existing-cluster-role must not be empty")
+	}
+	if err := readAccessTokenFile(io, ioutils.NewFilesystem()); err != nil {
+		return err
+	}
+	if err := setProxies(io); err != nil {
+		return err
+	}
+	io.KamVersion = version.Version
+	io.Quiet = io.OutputFormat == outputFormatJSON
+	if io.ExplainLayout {
+		return completeExplainLayout(io)
+	}
+	if io.Preflight {
+		return completePreflight(io)
+	}
 	client, err := utility.NewClient()
 	if err != nil {
 		return err
 	}
+	if io.CheckOnly {
+		return checkDependencies(io, client)
+	}
 
-	if io.PrivateRepoDriver != "" {
-		host, err := accesstoken.HostFromURL(io.GitOpsRepoURL)
-		if err != nil {
-			return err
+	if io.Resume {
+		return resumeMode(io)
+	}
+	drivers, err := driverMappings(io.BootstrapOptions, ioutils.NewFilesystem())
+	if err != nil {
+		return err
+	}
+	config.SetDriverMappings(drivers)
+	if io.SecretBackend == pipelines.SecretBackendSOPS {
+		if _, err := exec.LookPath("sops"); err != nil {
+			return fmt.Errorf("the sops binary is required to encrypt secrets with --secret-backend %s: %w", pipelines.SecretBackendSOPS, err)
 		}
-		identifier := factory.NewDriverIdentifier(factory.Mapping(host, io.PrivateRepoDriver))
-		factory.DefaultIdentifier = identifier
 	}
-	if err := checkBootstrapDependencies(io, client, log.NewStatus(os.Stdout)); err != nil {
+	if io.SkipChecks {
+		if !io.Quiet {
+			log.Warning("Skipping the cluster dependency checks")
+		}
+	} else if err := checkDependencies(io, client); err != nil {
 		return err
 	}
 
@@ -109,9 +221,151 @@ func (io *BootstrapParameters) Complete(name string, cmd *cobra.Command, args []
 	return nonInteractiveMode(io, client)
 }
 
+// loadBootstrapConfig sets the flags from the options in a YAML config file,
+// in the same format as --print-defaults.
+//
+// The options are set as if they were passed on the command line, so
+// interactive mode doesn't prompt for them, and flags that were passed on the
+// command line take precedence over the config file.
+func loadBootstrapConfig(filename string, flags *pflag.FlagSet, fs afero.Fs) error {
+	data, err := afero.ReadFile(fs, filename)
+	if err != nil {
+		return fmt.Errorf("failed to read the config file %q: %w", filename, err)
+	}
+	options := map[string]interface{}{}
+	if err := sigsyaml.Unmarshal(data, &options); err != nil {
+		return fmt.Errorf("failed to parse the config file %q: %w", filename, err)
+	}
+	for name, value := range options {
+		// This is reported by --print-defaults but can't be changed.
+		if name == "webhook-secret-length" {
+			continue
+		}
+		flag := flags.Lookup(name)
+		if flag == nil || name == configFlag {
+			return fmt.Errorf("invalid option %q in the config file %q", name, filename)
+		}
+		// Empty values are treated as missing, so they can still be prompted
+		// for.
+		if flag.Changed || value == nil || value == "" {
+			continue
+		}
+		switch v := value.(type) {
+		case map[string]interface{}:
+			return fmt.Errorf("invalid value for option %q in the config file %q", name, filename)
+		case []interface{}:
+			// Repeatable options are set once for each value.
+			if flag.Value.Type() != "stringArray" {
+				return fmt.Errorf("invalid value for option %q in the config file %q", name, filename)
+			}
+			for _, item := range v {
+				if err := flags.Set(name, fmt.Sprint(item)); err != nil {
+					return fmt.Errorf("invalid value for option %q in the config file %q: %w", name, filename, err)
+				}
+			}
+			continue
+		}
+		if err := flags.Set(name, fmt.Sprint(value)); err != nil {
+			return fmt.Errorf("invalid value for option %q in the config file %q: %w", name, filename, err)
+		}
+	}
+	return nil
+}
+
+// completeExplainLayout completes the parameters needed to explain the layout,
+// this doesn't check the cluster or prompt for anything.
+func completeExplainLayout(io *BootstrapParameters) error {
+	mandatoryFlags := map[string]string{serviceRepoURLFlag: io.ServiceRepoURL, gitopsRepoURLFlag: io.GitOpsRepoURL}
+	if err := checkRequiredFlags(mandatoryFlags, serviceRepoURLFlag, gitopsRepoURLFlag); err != nil {
+		return err
+	}
+	drivers, err := driverMappings(io.BootstrapOptions, ioutils.NewFilesystem())
+	if err != nil {
+		return err
+	}
+	config.SetDriverMappings(drivers)
+	addGitURLSuffixIfNecessary(io)
+	return nil
+}
+
+// completePreflight configures the drivers and finds the access token that
+// the preflight checks use, without validating it, as that's one of the
+// checks.
+func completePreflight(io *BootstrapParameters) error {
+	drivers, err := driverMappings(io.BootstrapOptions, ioutils.NewFilesystem())
+	if err != nil {
+		return err
+	}
+	config.SetDriverMappings(drivers)
+	addGitURLSuffixIfNecessary(io)
+	if err := checkRequiredFlags(map[string]string{serviceRepoURLFlag: io.ServiceRepoURL, gitopsRepoURLFlag: io.GitOpsRepoURL}, serviceRepoURLFlag, gitopsRepoURLFlag); err != nil {
+		return err
+	}
+	if io.GitHostAccessToken == "" {
+		secret, err := accesstoken.GetAccessToken(io.ServiceRepoURL)
+		if err != nil {
+			return fmt.Errorf("unable to use access-token from keyring/env-var: %v, please pass a valid token to --git-host-access-token", err)
+		}
+		io.GitHostAccessToken = secret
+	}
+	return nil
+}
+
+// shouldPrompt returns true if an optional value should be prompted for, this
+// is only if the user wants to be prompted for all values and the flag
+// wasn't set on the command line or in the config file.
+func shouldPrompt(cmd *cobra.Command, flag string, promptForAll bool) bool {
+	return promptForAll && !cmd.Flag(flag).Changed
+}
+
+// driverMappings returns the host to driver mappings loaded from the
+// --driver-map-file, with the --private-repo-driver for the GitOps repository
+// host taking precedence.
+func driverMappings(o *pipelines.BootstrapOptions, fs afero.Fs) (map[string]string, error) {
+	drivers := map[string]string{}
+	if o.DriverMapFile != "" {
+		loaded, err := config.LoadDriverMap(fs, o.DriverMapFile)
+		if err != nil {
+			return nil, err
+		}
+		for host, driver := range loaded {
+			if !supportedDrivers.supported(driver) {
+				return nil, fmt.Errorf("invalid driver type %q for host %q in %s", driver, host, o.DriverMapFile)
+			}
+			drivers[host] = driver
+		}
+	}
+	if o.PrivateRepoDriver != "" {
+		host, err := accesstoken.HostFromURL(o.GitOpsRepoURL)
+		if err != nil {
+			return nil, err
+		}
+		drivers[host] = o.PrivateRepoDriver
+	}
+	return drivers, nil
+}
+
+// isAzureRepo returns true if the repository is identified as an Azure DevOps
+// repository, either by the host or the driver mappings.
+func isAzureRepo(repoURL string) bool {
+	driver, err := scm.GetDriverName(repoURL)
+	return err == nil && driver == "azure"
+}
+
+// isGitLabRepo returns true if the GitOps repository is identified as a GitLab
+// repository, by the --private-repo-driver or the host.
+func isGitLabRepo(o *pipelines.BootstrapOptions) bool {
+	if o.PrivateRepoDriver != "" {
+		return o.PrivateRepoDriver == "gitlab"
+	}
+	driver, err := scm.GetDriverName(o.GitOpsRepoURL)
+	return err == nil && driver == "gitlab"
+}
+
 func addGitURLSuffixIfNecessary(io *BootstrapParameters) {
 	io.GitOpsRepoURL = utility.AddGitSuffixIfNecessary(io.GitOpsRepoURL)
 	io.ServiceRepoURL = utility.AddGitSuffixIfNecessary(io.ServiceRepoURL)
+	io.SecretsRepoURL = utility.AddGitSuffixIfNecessary(io.SecretsRepoURL)
 }
 
 // nonInteractiveMode gets triggered if a flag is passed, checks for mandatory flags.
@@ -127,10 +381,32 @@ func nonInteractiveMode(io *BootstrapParameters, client *utility.Client) error {
 	return nil
 }
 
+// resumeMode loads the previously generated manifest, which also configures
+// any drivers recorded in it, and finds the token to push with.
+func resumeMode(io *BootstrapParameters) error {
+	io.GitOpsRepoURL = utility.AddGitSuffixIfNecessary(io.GitOpsRepoURL)
+	if _, err := pipelines.LoadBootstrapped(io.BootstrapOptions, ioutils.NewFilesystem()); err != nil {
+		return err
+	}
+	if io.GitHostAccessToken == "" {
+		secret, err := accesstoken.GetAccessToken(io.GitOpsRepoURL)
+		if err != nil {
+			return fmt.Errorf("unable to use access-token from keyring/env-var: %v, please pass a valid token to --git-host-access-token", err)
+		}
+		io.GitHostAccessToken = secret
+	}
+	return nil
+}
+
 func checkMandatoryFlags(flags map[string]string) error {
+	return checkRequiredFlags(flags, serviceRepoURLFlag, gitopsRepoURLFlag, gitHostAccessTokenFlag)
+}
+
+// checkRequiredFlags returns an error listing the named flags that have no
+// value in flags.
+func checkRequiredFlags(flags map[string]string, names ...string) error {
 	missingFlags := []string{}
-	mandatoryFlags := []string{serviceRepoURLFlag, gitopsRepoURLFlag, gitHostAccessTokenFlag}
-	for _, flag := range mandatoryFlags {
+	for _, flag := range names {
 		if flags[flag] == "" {
 			missingFlags = append(missingFlags, fmt.Sprintf("%q", flag))
 		}
@@ -156,20 +432,19 @@ func initiateInteractiveMode(io *BootstrapParameters, client *utility.Client, cm
 	io.GitOpsRepoURL = utility.AddGitSuffixIfNecessary(io.GitOpsRepoURL)
 	if !isKnownDriver(io.GitOpsRepoURL) {
 		io.PrivateRepoDriver = ui.SelectPrivateRepoDriver()
-		host, err := accesstoken.HostFromURL(io.GitOpsRepoURL)
+		drivers, err := driverMappings(io.BootstrapOptions, ioutils.NewFilesystem())
 		if err != nil {
 			return fmt.Errorf("failed to parse the gitops url: %w", err)
 		}
-		identifier := factory.NewDriverIdentifier(factory.Mapping(host, io.PrivateRepoDriver))
-		factory.DefaultIdentifier = identifier
+		config.SetDriverMappings(drivers)
 	}
 	if io.ImageRepo != "" {
 		isInternalRegistry, _, err := imagerepo.ValidateImageRepo(io.ImageRepo)
 		if err != nil {
 			return err
 		}
-		if !isInternalRegistry {
-			if !cmd.Flag("dockercfgjson").Changed && promptForAll {
+		if !isInternalRegistry && io.QuayRobotToken == "" && io.GitLabDeployToken == "" {
+			if shouldPrompt(cmd, "dockercfgjson", promptForAll) {
 				log.Progressf("The supplied image repository has been detected as an external repository.")
 				io.DockerConfigJSONFilename = ui.EnterDockercfg()
 			}
@@ -179,17 +454,19 @@ func initiateInteractiveMode(io *BootstrapParameters, client *utility.Client, cm
 			io.ImageRepo = ui.EnterImageRepoInternalRegistry()
 		} else {
 			io.ImageRepo = ui.EnterImageRepoExternalRepository()
-			io.DockerConfigJSONFilename = ui.EnterDockercfg()
+			if !cmd.Flag("dockercfgjson").Changed && io.QuayRobotToken == "" && io.GitLabDeployToken == "" {
+				io.DockerConfigJSONFilename = ui.EnterDockercfg()
+			}
 		}
 	}
-	if promptForAll {
+	if shouldPrompt(cmd, "gitops-webhook-secret", promptForAll) {
 		io.GitOpsWebhookSecret = ui.EnterGitWebhookSecret(io.GitOpsRepoURL)
 	}
 	if io.ServiceRepoURL == "" {
 		io.ServiceRepoURL = ui.EnterServiceRepoURL()
 	}
 	io.ServiceRepoURL = utility.AddGitSuffixIfNecessary(io.ServiceRepoURL)
-	if promptForAll {
+	if shouldPrompt(cmd, "service-webhook-secret", promptForAll) {
 		io.ServiceWebhookSecret = ui.EnterGitWebhookSecret(io.ServiceRepoURL)
 	}
 	secret, err := accesstoken.GetAccessToken(io.ServiceRepoURL)
@@ -198,7 +475,7 @@ func initiateInteractiveMode(io *BootstrapParameters, client *utility.Client, cm
 	}
 	if secret == "" { // We must prompt for the token
 		if io.GitHostAccessToken == "" {
-			io.GitHostAccessToken = ui.EnterGitHostAccessToken(io.ServiceRepoURL)
+			io.GitHostAccessToken = ui.EnterGitHostAccessToken(io.ServiceRepoURL, io.gitProxyURL)
 		}
 		if !cmd.Flag("save-token-keyring").Changed {
 			io.SaveTokenKeyRing = ui.UseKeyringRingSvc()
@@ -207,7 +484,7 @@ func initiateInteractiveMode(io *BootstrapParameters, client *utility.Client, cm
 	} else {
 		io.GitHostAccessToken = secret
 	}
-	if !cmd.Flag("push-to-git").Changed && promptForAll {
+	if shouldPrompt(cmd, "push-to-git", promptForAll) {
 		io.PushToGit = ui.SelectOptionPushToGit()
 	}
 	if io.Prefix == "" && promptForAll {
@@ -225,8 +502,8 @@ func initiateInteractiveMode(io *BootstrapParameters, client *utility.Client, cm
 	appFs := ioutils.NewFilesystem()
 	io.OutputPath, io.Overwrite = ui.VerifyOutputPath(appFs, io.OutputPath, io.Overwrite, outputPathOverridden, promptForAll)
 	if !io.Overwrite {
-		if ui.PathExists(appFs, filepath.Join(io.OutputPath, "..", "secrets")) {
-			return fmt.Errorf("the secrets folder located as a sibling of the output folder %s already exists. Delete or rename the secrets folder and try again", io.OutputPath)
+		if ui.PathExists(appFs, filepath.Join(io.GitOpsPath(), "..", "secrets")) {
+			return fmt.Errorf("the secrets folder located as a sibling of the output folder %s already exists. Delete or rename the secrets folder and try again", io.GitOpsPath())
 		}
 		if io.PushToGit && ui.PathExists(appFs, filepath.Join(io.OutputPath, ".git")) {
 			return fmt.Errorf("the .git folder in output path %s already exists. Delete or rename the .git folder and try again", io.OutputPath)
@@ -244,9 +521,74 @@ func repoFromURL(raw string) (string, error) {
 	return strings.TrimSuffix(parts[len(parts)-1], ".git"), nil
 }
 
+// readAccessTokenFile sets the access token from the contents of the
+// --git-host-access-token-file, if any, with the surrounding whitespace
+// trimmed.
+//
+// The token is read before anything else is completed, so that it's used in
+// the same way as a token passed with --git-host-access-token.
+func readAccessTokenFile(io *BootstrapParameters, fs afero.Fs) error {
+	if io.GitHostAccessTokenFile == "" {
+		return nil
+	}
+	if io.GitHostAccessToken != "" {
+		return errors.New("--git-host-access-token cannot be used with --git-host-access-token-file")
+	}
+	data, err := afero.ReadFile(fs, io.GitHostAccessTokenFile)
+	if err != nil {
+		return fmt.Errorf("failed to read the access token from %q: %w", io.GitHostAccessTokenFile, err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return fmt.Errorf("the access token file %q is empty", io.GitHostAccessTokenFile)
+	}
+	io.GitHostAccessToken = token
+	return nil
+}
+
+// setProxies sends the Git host and cluster API requests through the
+// --git-proxy and --cluster-proxy, if any, otherwise the proxy from the
+// HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables is used.
+func setProxies(io *BootstrapParameters) error {
+	gitProxy, err := parseProxy("git-proxy", io.GitProxy)
+	if err != nil {
+		return err
+	}
+	clusterProxy, err := parseProxy("cluster-proxy", io.ClusterProxy)
+	if err != nil {
+		return err
+	}
+	io.gitProxyURL = gitProxy
+	if clusterProxy != nil {
+		clientconfig.SetProxy(clusterProxy)
+	}
+	return nil
+}
+
+// parseProxy parses the value of a proxy flag, which must be an http, https or
+// socks5 URL with a host, an empty value returns no proxy.
+func parseProxy(flag, value string) (*url.URL, error) {
+	if value == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s %q: %w", flag, value, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid --%s %q: the scheme must be one of http, https, socks5", flag, value)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid --%s %q: no host", flag, value)
+	}
+	return u, nil
+}
+
 func setAccessToken(io *BootstrapParameters) error {
 	if io.GitHostAccessToken != "" {
-		err := ui.ValidateAccessToken(io.GitHostAccessToken, io.ServiceRepoURL)
+		err := ui.ValidateAccessToken(io.GitHostAccessToken, io.ServiceRepoURL, io.gitProxyURL)
 		if err != nil {
 			return fmt.Errorf("Access token validation failed: %v", err)
 		}
@@ -267,34 +609,421 @@ func setAccessToken(io *BootstrapParameters) error {
 	return nil
 }
 
-func checkBootstrapDependencies(io *BootstrapParameters, client *utility.Client, spinner utility.Status) error {
-	missingDeps := []string{}
-	log.Progressf("\nChecking dependencies\n")
+// printLayout prints the paths from pipelines.BootstrapLayout as a tree of
+// the output folder, and a tree of the secrets folder alongside it.
+func printLayout(w io.Writer, outputPath string, paths []string) {
+	secretsPrefix := "../secrets/"
+	gitops, secrets := []string{}, []string{}
+	for _, p := range paths {
+		if strings.HasPrefix(p, secretsPrefix) {
+			secrets = append(secrets, strings.TrimPrefix(p, secretsPrefix))
+			continue
+		}
+		gitops = append(gitops, p)
+	}
+	printTree(w, outputPath, gitops)
+	printTree(w, filepath.Join(outputPath, "..", "secrets"), secrets)
+}
+
+// printTree prints the sorted slash-separated paths indented by depth, with
+// each folder printed once.
+func printTree(w io.Writer, root string, paths []string) {
+	fmt.Fprintf(w, "%s/\n", filepath.ToSlash(root))
+	previous := []string{}
+	for _, p := range paths {
+		parts := strings.Split(p, "/")
+		common := 0
+		for common < len(previous)-1 && common < len(parts)-1 && previous[common] == parts[common] {
+			common++
+		}
+		for i := common; i < len(parts); i++ {
+			name := parts[i]
+			if i < len(parts)-1 {
+				name += "/"
+			}
+			fmt.Fprintf(w, "%s%s\n", strings.Repeat("  ", i+1), name)
+		}
+		previous = parts
+	}
+}
+
+// dependencyCheck is a check that an operator that bootstrap depends on is
+// installed in the cluster.
+type dependencyCheck struct {
+	name    string
+	status  string
+	warning string
+	check   func() error
+}
+
+// dependencyCheckResults are the outcomes of the dependency checks, the errors
+// are in the same order as the checks.
+type dependencyCheckResults struct {
+	checks      []dependencyCheck
+	errs        []error
+	versions    []string
+	versionsErr error
+}
+
+// dependencyCheckReport is the outcome of the dependency checks in the format
+// written by --dependency-check-output json.
+type dependencyCheckReport struct {
+	Dependencies []dependencyReport `json:"dependencies"`
+	Tekton       tektonReport       `json:"tekton"`
+	Satisfied    bool               `json:"satisfied"`
+}
+
+type dependencyReport struct {
+	Name  string `json:"name"`
+	Found bool   `json:"found"`
+	Error string `json:"error,omitempty"`
+}
+
+type tektonReport struct {
+	APIVersion     string   `json:"apiVersion"`
+	ServedVersions []string `json:"servedVersions"`
+	Compatible     bool     `json:"compatible"`
+	Error          string   `json:"error,omitempty"`
+}
+
+func runDependencyChecks(io *BootstrapParameters, client *utility.Client) dependencyCheckResults {
+	checks := []dependencyCheck{
+		{
+			name:    gitopsOperatorName,
+			status:  "Checking if Argo CD is installed with the default configuration",
+			warning: "Please install OpenShift GitOps Operator from OperatorHub",
+			check:   func() error { return client.CheckIfArgoCDExists(io.ArgoCDNamespace) },
+		},
+		{
+			name:    pipelinesOperatorName,
+			status:  "Checking if OpenShift Pipelines Operator is installed with the default configuration",
+			warning: "Please install OpenShift Pipelines Operator from OperatorHub",
+			check:   func() error { return client.CheckIfPipelinesExists(pipelinesOperatorNS) },
+		},
+	}
+	// The checks are run concurrently, and the results reported in order so
+	// that the spinner output is the same regardless of which finishes first.
+	var versions []string
+	funcs := []func() error{}
+	for _, c := range checks {
+		funcs = append(funcs, c.check)
+	}
+	funcs = append(funcs, func() error {
+		var err error
+		versions, err = client.ServedVersions(tektonAPIGroup)
+		return err
+	})
+	results := runConcurrently(io.Concurrency, funcs...)
+	return dependencyCheckResults{
+		checks:      checks,
+		errs:        results[:len(checks)],
+		versions:    versions,
+		versionsErr: results[len(checks)],
+	}
+}
 
-	spinner.Start("Checking if Argo CD is installed with the default configuration", false)
-	if err := client.CheckIfArgoCDExists(argocd.ArgoCDNamespace); err != nil {
-		warnIfNotFound(spinner, "Please install OpenShift GitOps Operator from OperatorHub", err)
-		if !apierrors.IsNotFound(err) {
-			return fmt.Errorf("failed to check for OpenShift GitOps Operator: %w", err)
+// writeDependencyChecks runs the dependency checks and writes the outcome as
+// JSON, it returns an error if the dependencies are not satisfied.
+func writeDependencyChecks(w io.Writer, params *BootstrapParameters, client *utility.Client) error {
+	report := newDependencyCheckReport(params, runDependencyChecks(params, client))
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the dependency checks: %w", err)
+	}
+	fmt.Fprintln(w, string(b))
+	if !report.Satisfied {
+		return errors.New("failed to satisfy the required dependencies")
+	}
+	return nil
+}
+
+// newDependencyCheckReport summarises the results of the dependency checks.
+func newDependencyCheckReport(params *BootstrapParameters, results dependencyCheckResults) dependencyCheckReport {
+	report := dependencyCheckReport{Satisfied: true, Tekton: tektonReport{APIVersion: tektonAPIVersion(params)}}
+	for i, c := range results.checks {
+		d := dependencyReport{Name: c.name, Found: results.errs[i] == nil}
+		if err := results.errs[i]; err != nil {
+			report.Satisfied = false
+			if !apierrors.IsNotFound(err) {
+				d.Error = err.Error()
+			}
 		}
-		missingDeps = append(missingDeps, gitopsOperatorName)
+		report.Dependencies = append(report.Dependencies, d)
 	}
+	report.Tekton.ServedVersions = append([]string{}, results.versions...)
+	if results.versionsErr != nil {
+		report.Tekton.Error = results.versionsErr.Error()
+	} else if err := checkTektonVersions(results.versions, report.Tekton.APIVersion); err != nil {
+		report.Tekton.Error = err.Error()
+	} else {
+		report.Tekton.Compatible = true
+	}
+	report.Satisfied = report.Satisfied && report.Tekton.Compatible
+	return report
+}
+
+// preflightCheck is a check, other than the cluster dependencies, that
+// bootstrapping with the options would succeed.
+type preflightCheck struct {
+	name string
+	// skip is the reason that the check doesn't apply, if it doesn't.
+	skip  string
+	check func() error
+}
+
+type preflightReport struct {
+	Checks       []preflightCheckReport `json:"checks"`
+	Dependencies dependencyCheckReport  `json:"dependencies"`
+	Satisfied    bool                   `json:"satisfied"`
+}
 
-	spinner.Start("Checking if OpenShift Pipelines Operator is installed with the default configuration", false)
-	if err := client.CheckIfPipelinesExists(pipelinesOperatorNS); err != nil {
-		warnIfNotFound(spinner, "Please install OpenShift Pipelines Operator from OperatorHub", err)
-		if !apierrors.IsNotFound(err) {
-			return fmt.Errorf("failed to check for OpenShift Pipelines Operator: %w", err)
+type preflightCheckReport struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Skipped string `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// preflightChecks returns the checks that the access token can read and
+// manage the webhooks of the repositories, and that the image repository can
+// be pushed to.
+//
+// The GitOps repository doesn't need to exist with --push-to-git, as it's
+// created.
+func preflightChecks(io *BootstrapParameters, appFs afero.Fs) []preflightCheck {
+	checks := []preflightCheck{}
+	for _, repoURL := range []string{io.ServiceRepoURL, io.GitOpsRepoURL} {
+		repoURL := repoURL
+		skip := ""
+		if repoURL == io.GitOpsRepoURL && io.PushToGit {
+			skip = "the repository is created with --push-to-git"
+		}
+		checks = append(checks,
+			preflightCheck{
+				name:  "Access token can read " + repoURL,
+				skip:  skip,
+				check: func() error { return ui.ValidateAccessToken(io.GitHostAccessToken, repoURL, io.gitProxyURL) },
+			},
+			preflightCheck{
+				name:  "Access token can manage the webhooks of " + repoURL,
+				skip:  skip,
+				check: func() error { return checkWebhookAccess(repoURL, io.GitHostAccessToken, io.gitProxyURL) },
+			})
+	}
+	return append(checks, preflightCheck{
+		name:  "Docker config can push to the image repository",
+		check: func() error { return pipelines.ValidateImageRepoAuth(io.BootstrapOptions, appFs) },
+	})
+}
+
+// checkWebhookAccess returns an error if the token can't list the webhooks of
+// the repository, which requires the same access as creating them.
+func checkWebhookAccess(repoURL, token string, proxyURL *url.URL) error {
+	repo, err := git.NewRepositoryWithProxy(repoURL, token, proxyURL)
+	if err != nil {
+		return err
+	}
+	if _, err := repo.ListWebhooks(""); err != nil {
+		return fmt.Errorf("failed to list the webhooks: %w", err)
+	}
+	return nil
+}
+
+// runPreflight runs the checks and the dependency checks, and writes a single
+// report in the --dependency-check-output format, it returns an error if any
+// of the checks failed.
+func runPreflight(w io.Writer, params *BootstrapParameters, checks []preflightCheck, client *utility.Client) error {
+	funcs := []func() error{}
+	for _, c := range checks {
+		if c.skip != "" {
+			funcs = append(funcs, func() error { return nil })
+			continue
+		}
+		funcs = append(funcs, c.check)
+	}
+	errs := runConcurrently(params.Concurrency, funcs...)
+	report := preflightReport{Dependencies: newDependencyCheckReport(params, runDependencyChecks(params, client))}
+	report.Satisfied = report.Dependencies.Satisfied
+	for i, c := range checks {
+		r := preflightCheckReport{Name: c.name, Skipped: c.skip, Passed: c.skip == "" && errs[i] == nil}
+		if errs[i] != nil {
+			r.Error = errs[i].Error()
+			report.Satisfied = false
+		}
+		report.Checks = append(report.Checks, r)
+	}
+
+	if params.DependencyCheckOutput == dependencyCheckOutputJSON {
+		b, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal the preflight checks: %w", err)
+		}
+		fmt.Fprintln(w, string(b))
+	} else {
+		writePreflightReport(w, report)
+	}
+	if !report.Satisfied {
+		return errors.New("the preflight checks failed")
+	}
+	return nil
+}
+
+// writePreflightReport writes the report as a line per check.
+func writePreflightReport(w io.Writer, report preflightReport) {
+	line := func(status, name, detail string) {
+		if detail != "" {
+			name += ": " + detail
+		}
+		fmt.Fprintf(w, "  [%s] %s\n", status, name)
+	}
+	fmt.Fprintln(w, "Preflight checks")
+	for _, c := range report.Checks {
+		switch {
+		case c.Skipped != "":
+			line("SKIP", c.Name, c.Skipped)
+		case c.Passed:
+			line("PASS", c.Name, "")
+		default:
+			line("FAIL", c.Name, c.Error)
+		}
+	}
+	for _, d := range report.Dependencies.Dependencies {
+		switch {
+		case d.Found:
+			line("PASS", d.Name+" is installed", "")
+		case d.Error != "":
+			line("FAIL", d.Name+" is installed", d.Error)
+		default:
+			line("FAIL", d.Name+" is installed", "not found")
+		}
+	}
+	t := report.Dependencies.Tekton
+	name := fmt.Sprintf("Tekton Pipelines serves %s/%s", tektonAPIGroup, t.APIVersion)
+	if t.Compatible {
+		line("PASS", name, "")
+	} else {
+		line("FAIL", name, t.Error)
+	}
+}
+
+// checkDependencies runs the dependency checks, reporting the results in the
+// --dependency-check-output format.
+func checkDependencies(io *BootstrapParameters, client *utility.Client) error {
+	if io.DependencyCheckOutput == dependencyCheckOutputJSON {
+		return writeDependencyChecks(os.Stdout, io, client)
+	}
+	// The summary is the only output to stdout with --output-format json.
+	if io.Quiet {
+		return checkBootstrapDependencies(io, client, log.NewStatus(os.Stderr))
+	}
+	return checkBootstrapDependencies(io, client, log.NewStatus(os.Stdout))
+}
+
+func checkBootstrapDependencies(io *BootstrapParameters, client *utility.Client, spinner utility.Status) error {
+	if !io.Quiet {
+		log.Progressf("\nChecking dependencies\n")
+	}
+
+	results := runDependencyChecks(io, client)
+	missingDeps := []string{}
+	errs := []error{}
+	for i, c := range results.checks {
+		spinner.Start(c.status, false)
+		if err := results.errs[i]; err != nil {
+			warnIfNotFound(spinner, c.warning, err)
+			if !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("failed to check for %s: %w", c.name, err))
+				continue
+			}
+			missingDeps = append(missingDeps, c.name)
 		}
-		missingDeps = append(missingDeps, pipelinesOperatorName)
 	}
-	spinner.End(true)
 	if len(missingDeps) > 0 {
-		return fmt.Errorf("failed to satisfy the required dependencies: %s", strings.Join(missingDeps, ", "))
+		spinner.End(true)
+		errs = append(errs, fmt.Errorf("failed to satisfy the required dependencies: %s", strings.Join(missingDeps, ", ")))
+	}
+	if len(errs) > 0 {
+		return joinErrors(errs)
+	}
+
+	spinner.Start("Checking if the installed Tekton Pipelines version is compatible", false)
+	if err := results.versionsErr; err != nil {
+		spinner.End(false)
+		return fmt.Errorf("failed to check the Tekton Pipelines version: %w", err)
+	}
+	apiVersion := tektonAPIVersion(io)
+	if err := checkTektonVersions(results.versions, apiVersion); err != nil {
+		spinner.WarningStatus(tektonVersionWarning(results.versions, apiVersion))
+		spinner.End(false)
+		return err
 	}
+	spinner.End(true)
 	return nil
 }
 
+// tektonAPIVersion returns the tekton.dev API version that the generated
+// resources use.
+func tektonAPIVersion(io *BootstrapParameters) string {
+	if io.TektonAPIVersion == "" {
+		return tekton.V1Beta1
+	}
+	return io.TektonAPIVersion
+}
+
+// runConcurrently calls each of the funcs with at most limit running at once,
+// and returns their errors in the same order as the funcs.
+func runConcurrently(limit int, funcs ...func() error) []error {
+	if limit < 1 {
+		limit = 1
+	}
+	errs := make([]error, len(funcs))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i, f := range funcs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, f func() error) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = f()
+		}(i, f)
+	}
+	wg.Wait()
+	return errs
+}
+
+func joinErrors(errs []error) error {
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return multierror.Join(errs)
+}
+
+// checkTektonVersions returns an error if the Tekton API version that the
+// generated resources use isn't one of the versions served by the cluster.
+func checkTektonVersions(served []string, apiVersion string) error {
+	if len(served) == 0 {
+		return fmt.Errorf("the %s API is not served by the cluster, the generated resources require %s/%s", tektonAPIGroup, tektonAPIGroup, apiVersion)
+	}
+	for _, v := range served {
+		if v == apiVersion {
+			return nil
+		}
+	}
+	return fmt.Errorf("the generated resources use %s/%s which is not served by the installed Tekton Pipelines (served versions: %s)", tektonAPIGroup, apiVersion, strings.Join(served, ", "))
+}
+
+// tektonVersionWarning suggests a served API version that resources can be
+// generated for, or installing a compatible version of the operator.
+func tektonVersionWarning(served []string, apiVersion string) string {
+	for _, v := range served {
+		if v != apiVersion && tekton.IsSupportedAPIVersion(v) {
+			return fmt.Sprintf("Please rerun with --tekton-api-version %s", v)
+		}
+	}
+	return "Please install a version of the OpenShift Pipelines Operator that serves " + tektonAPIGroup + "/" + apiVersion
+}
+
 func warnIfNotFound(spinner utility.Status, warningMsg string, err error) {
 	if apierrors.IsNotFound(err) {
 		spinner.WarningStatus(warningMsg)
@@ -304,13 +1033,49 @@ func warnIfNotFound(spinner utility.Status, warningMsg string, err error) {
 
 // Validate validates the parameters of the BootstrapParameters.
 func (io *BootstrapParameters) Validate() error {
+	if io.PrintDefaults {
+		return nil
+	}
+	if io.CheckOnly {
+		if io.Resume || io.ExplainLayout {
+			return errors.New("--check-only cannot be used with --resume or --explain-layout")
+		}
+		if io.Preflight {
+			return errors.New("--check-only cannot be used with --preflight")
+		}
+		return nil
+	}
+	if io.Preflight && (io.Resume || io.ExplainLayout) {
+		return errors.New("--preflight cannot be used with --resume or --explain-layout")
+	}
+	if io.Resume && io.Overwrite {
+		return errors.New("--resume cannot be used with --overwrite")
+	}
+	if io.Resume && io.ExplainLayout {
+		return errors.New("--explain-layout cannot be used with --resume")
+	}
 	gr, err := url.Parse(io.GitOpsRepoURL)
 	if err != nil {
 		return fmt.Errorf("failed to parse url %s: %w", io.GitOpsRepoURL, err)
 	}
 
-	// TODO: this may not work with GitLab as the repo can have more path elements.
-	if len(utility.RemoveEmptyStrings(strings.Split(gr.Path, "/"))) != 2 {
+	components := utility.RemoveEmptyStrings(strings.Split(gr.Path, "/"))
+	switch {
+	case isAzureRepo(io.GitOpsRepoURL):
+		if _, err := scm.NewRepository(io.GitOpsRepoURL); err != nil {
+			return fmt.Errorf("repo must be org/project/_git/repo: %s", strings.TrimSuffix(gr.Path, ".git"))
+		}
+		// go-scm has no Azure DevOps driver, so kam can't create the
+		// repository or open pull requests on Azure DevOps.
+		if io.PushToGit || io.Hub {
+			return errors.New("--push-to-git and --hub are not supported for Azure DevOps repositories, push the generated resources to the repository with git")
+		}
+	case isGitLabRepo(io.BootstrapOptions):
+		// GitLab projects can be in subgroups e.g. group/subgroup/repo.
+		if len(components) < 2 {
+			return fmt.Errorf("repo must be group/[subgroups/]repo: %s", strings.TrimSuffix(gr.Path, ".git"))
+		}
+	case len(components) != 2:
 		return fmt.Errorf("repo must be org/repo: %s", strings.Trim(gr.Path, ".git"))
 	}
 
@@ -319,32 +1084,387 @@ func (io *BootstrapParameters) Validate() error {
 			return fmt.Errorf("invalid driver type: %q", io.PrivateRepoDriver)
 		}
 	}
+	if io.SecretsRepoURL != "" {
+		if _, err := url.Parse(io.SecretsRepoURL); err != nil {
+			return fmt.Errorf("failed to parse url %s: %w", io.SecretsRepoURL, err)
+		}
+		if io.SecretsRepoURL == io.GitOpsRepoURL {
+			return errors.New("--secrets-repo-url must be a different repository to --gitops-repo-url")
+		}
+	}
+	if err := ui.ValidateSecret(io.GitOpsWebhookSecret); err != nil {
+		return fmt.Errorf("invalid --gitops-webhook-secret: %w", err)
+	}
+	if err := ui.ValidateSecret(io.ServiceWebhookSecret); err != nil {
+		return fmt.Errorf("invalid --service-webhook-secret: %w", err)
+	}
 	if io.SaveTokenKeyRing && io.GitHostAccessToken == "" {
 		return errors.New("--git-host-access-token is required if --save-token-keyring is enabled")
 	}
+	if io.InternalRegistryProject != "" {
+		if io.ImageRepo != "" {
+			return errors.New("--internal-registry-project cannot be used with --image-repo")
+		}
+		if err := ui.ValidateName(io.InternalRegistryProject); err != nil {
+			return fmt.Errorf("invalid internal registry project: %w", err)
+		}
+	}
+	if io.TektonAPIVersion != "" && !tekton.IsSupportedAPIVersion(io.TektonAPIVersion) {
+		return fmt.Errorf("invalid Tekton API version: %q, must be one of %s", io.TektonAPIVersion, strings.Join(tekton.SupportedAPIVersions, ", "))
+	}
+	switch io.DependencyCheckOutput {
+	case "", dependencyCheckOutputText, dependencyCheckOutputJSON:
+	default:
+		return fmt.Errorf("invalid --dependency-check-output %q, must be one of %s, %s", io.DependencyCheckOutput, dependencyCheckOutputText, dependencyCheckOutputJSON)
+	}
+	switch io.OutputFormat {
+	case "", outputFormatText:
+	case outputFormatJSON:
+		if io.Interactive {
+			return errors.New("--output-format json cannot be used with --interactive")
+		}
+		if io.DependencyCheckOutput == dependencyCheckOutputJSON {
+			return errors.New("--output-format json cannot be used with --dependency-check-output json, the summary is the only JSON written")
+		}
+	default:
+		return fmt.Errorf("invalid --output-format %q, must be one of %s, %s", io.OutputFormat, outputFormatText, outputFormatJSON)
+	}
+	if io.ExistingClusterRole != "" && io.NamespacedInstall {
+		return errors.New("--existing-cluster-role cannot be used with --namespaced-install, the pipeline service account is bound to a Role")
+	}
+	if io.UseProjectRequests && io.NamespacedInstall {
+		return errors.New("--use-project-requests cannot be used with --namespaced-install, no namespaces are generated")
+	}
+	if io.RepoSubpath != "" && !config.IsValidRepoSubpath(io.RepoSubpath) {
+		return fmt.Errorf("invalid --repo-subpath %q: must be a relative path within the repository e.g. platform/gitops", io.RepoSubpath)
+	}
+	if !io.WithImageUpdater && (io.ImageUpdateStrategy != "" || io.ImageWriteBackMethod != "") {
+		return errors.New("--image-update-strategy and --image-write-back-method require --with-image-updater")
+	}
+	if io.ImageUpdateStrategy != "" && !config.IsSupportedImageUpdateStrategy(io.ImageUpdateStrategy) {
+		return fmt.Errorf("invalid --image-update-strategy %q, must be one of %s", io.ImageUpdateStrategy, strings.Join(config.ImageUpdateStrategies, ", "))
+	}
+	if io.ImageWriteBackMethod != "" && !config.IsSupportedImageWriteBackMethod(io.ImageWriteBackMethod) {
+		return fmt.Errorf("invalid --image-write-back-method %q, must be one of %s", io.ImageWriteBackMethod, strings.Join(config.ImageWriteBackMethods, ", "))
+	}
+	if io.WithPRPreviews && io.NoAppCI {
+		return errors.New("--with-pr-previews cannot be used with --no-app-ci, the previews deploy the images built by the app-ci pipeline")
+	}
+	if io.ApplyMode != "" && !config.IsSupportedApplyMode(io.ApplyMode) {
+		return fmt.Errorf("invalid --apply-mode %q, must be one of %s", io.ApplyMode, strings.Join(config.ApplyModes, ", "))
+	}
+	if io.BootstrapImage != "" {
+		if err := imagerepo.ValidateImageReference(io.BootstrapImage); err != nil {
+			return fmt.Errorf("invalid --bootstrap-image: %w", err)
+		}
+	}
+	if io.RouteHealthCheckPath != "" && !strings.HasPrefix(io.RouteHealthCheckPath, "/") {
+		return fmt.Errorf("invalid --route-healthcheck-path %q, must start with /", io.RouteHealthCheckPath)
+	}
+	if io.BuildImage != "" {
+		if err := imagerepo.ValidateImageReference(io.BuildImage); err != nil {
+			return fmt.Errorf("invalid --build-image: %w", err)
+		}
+	}
+	for _, arg := range io.BuildArgs {
+		if err := validateBuildArg(arg); err != nil {
+			return err
+		}
+	}
+	if io.PipelineNamePrefix != "" && !config.IsValidPipelineNamePrefix(io.PipelineNamePrefix) {
+		return fmt.Errorf("invalid --pipeline-name-prefix %q: must contain only lowercase letters, digits, periods and dashes, and start with a letter or digit", io.PipelineNamePrefix)
+	}
+	if io.DefaultBranch != "" && !config.IsValidBranch(io.DefaultBranch) {
+		return fmt.Errorf("invalid --default-branch %q: must contain only letters, digits, underscores, periods, dashes and slashes", io.DefaultBranch)
+	}
+	if io.ServiceAccount != "" && !config.IsValidServiceAccountName(io.ServiceAccount) {
+		return fmt.Errorf("invalid --service-account %q: must contain only lowercase letters, digits, periods and dashes, and start and end with a letter or digit", io.ServiceAccount)
+	}
+	if (io.QuayRobotAccount == "") != (io.QuayRobotToken == "") {
+		return errors.New("--quay-robot-account and --quay-robot-token must be provided together")
+	}
+	if io.QuayRobotAccount != "" && !quayRobotAccount.MatchString(io.QuayRobotAccount) {
+		return fmt.Errorf("invalid --quay-robot-account %q: must be of the form <namespace>+<robot> e.g. my-org+ci", io.QuayRobotAccount)
+	}
+	if io.GitLabDeployToken != "" {
+		if io.QuayRobotToken != "" {
+			return errors.New("--gitlab-deploy-token cannot be used with --quay-robot-token")
+		}
+		if _, _, err := imagerepo.ParseGitLabDeployToken(io.GitLabDeployToken); err != nil {
+			return fmt.Errorf("invalid --gitlab-deploy-token: %w", err)
+		}
+	}
+	if io.RouteWildcardPolicy != "" && !eventlisteners.IsSupportedWildcardPolicy(io.RouteWildcardPolicy) {
+		return fmt.Errorf("invalid --route-wildcard-policy %q, must be one of %s", io.RouteWildcardPolicy, strings.Join(eventlisteners.WildcardPolicies, ", "))
+	}
+	if io.ArgoCDNamespace != "" {
+		if errs := k8svalidation.IsDNS1123Label(io.ArgoCDNamespace); len(errs) > 0 {
+			return fmt.Errorf("invalid --argocd-namespace %q: %s", io.ArgoCDNamespace, strings.Join(errs, ", "))
+		}
+	}
+	if io.ArgoCDProject != "" && !config.IsValidArgoCDProject(io.ArgoCDProject) {
+		return fmt.Errorf("invalid --argocd-project %q: must be a DNS-1123 subdomain other than \"default\"", io.ArgoCDProject)
+	}
+	if io.PushRetries < 0 {
+		return fmt.Errorf("invalid --push-retries %d: must not be negative", io.PushRetries)
+	}
+	if io.RouteSubdomain != "" {
+		if errs := k8svalidation.IsDNS1123Subdomain(io.RouteSubdomain); len(errs) > 0 {
+			return fmt.Errorf("invalid --route-subdomain %q: %s", io.RouteSubdomain, strings.Join(errs, ", "))
+		}
+	}
+	if io.Hub && (io.Resume || io.ExplainLayout || io.DryRun || io.ManifestOut != "" || io.WriteChecksums || io.OutputFormat == outputFormatJSON) {
+		return errors.New("--hub cannot be used with --resume, --explain-layout, --dry-run, --manifest-out, --write-checksums or --output-format json, the service is added to the existing manifest of the hub repository")
+	}
+	if io.DryRun && (io.PushToGit || io.Resume || io.ExplainLayout || io.ManifestOut != "" || io.WriteChecksums || io.VerifyKustomize || io.OutputFormat == outputFormatJSON) {
+		return errors.New("--dry-run cannot be used with --push-to-git, --resume, --explain-layout, --manifest-out, --write-checksums, --verify-kustomize or --output-format json, nothing is written")
+	}
+	if io.ManifestOut != "" && (io.PushToGit || io.Resume || io.ExplainLayout || io.WriteChecksums || io.VerifyKustomize) {
+		return errors.New("--manifest-out cannot be used with --push-to-git, --resume, --explain-layout, --write-checksums or --verify-kustomize, only the manifest is written")
+	}
+	if err := validateResources("", io.CPURequest, io.CPULimit, io.MemoryRequest, io.MemoryLimit); err != nil {
+		return err
+	}
+	if err := validateResources("event-listener-", io.EventListenerCPURequest, io.EventListenerCPULimit, io.EventListenerMemoryRequest, io.EventListenerMemoryLimit); err != nil {
+		return err
+	}
+	if io.DryRunTrigger != "" && !eventlisteners.IsSupportedDryRunTrigger(io.DryRunTrigger) {
+		return fmt.Errorf("invalid --dry-run-trigger %q, must be one of %s", io.DryRunTrigger, strings.Join(eventlisteners.DryRunTriggers, ", "))
+	}
+	if io.WebhookInterceptorURL != "" {
+		if _, err := eventlisteners.WebhookInterceptor(io.WebhookInterceptorURL); err != nil {
+			return fmt.Errorf("invalid --webhook-interceptor-url: %w", err)
+		}
+	}
+	if io.PipelineRunTTL != "" {
+		ttl, err := time.ParseDuration(io.PipelineRunTTL)
+		if err != nil {
+			return fmt.Errorf("invalid --pipelinerun-ttl %q: %w", io.PipelineRunTTL, err)
+		}
+		if ttl < time.Second {
+			return fmt.Errorf("invalid --pipelinerun-ttl %q: must be at least 1s", io.PipelineRunTTL)
+		}
+	}
+	if io.SecretBackend != pipelines.SecretBackendSOPS && (io.SOPSAgeRecipients != "" || io.SOPSPGPKey != "") {
+		return errors.New("--sops-age-recipients and --sops-pgp-key require --secret-backend sops")
+	}
+	if io.SecretBackend != pipelines.SecretBackendVault && io.VaultSecretStore != "" {
+		return errors.New("--vault-secret-store requires --secret-backend vault")
+	}
+	switch io.SecretBackend {
+	case pipelines.SecretBackendNone:
+	case pipelines.SecretBackendSOPS:
+		if io.SOPSAgeRecipients == "" && io.SOPSPGPKey == "" {
+			return errors.New("--secret-backend sops requires --sops-age-recipients or --sops-pgp-key")
+		}
+	case pipelines.SecretBackendVault:
+		if io.VaultSecretStore == "" {
+			return errors.New("--secret-backend vault requires --vault-secret-store")
+		}
+		if errs := k8svalidation.IsDNS1123Subdomain(io.VaultSecretStore); len(errs) > 0 {
+			return fmt.Errorf("invalid --vault-secret-store %q: %s", io.VaultSecretStore, strings.Join(errs, ", "))
+		}
+		if io.SecretsRepoURL != "" {
+			return errors.New("--secret-backend vault cannot be used with --secrets-repo-url, the secrets are fetched from Vault")
+		}
+	default:
+		return fmt.Errorf("invalid secret backend: %q", io.SecretBackend)
+	}
+	if err := validateEnvironments(io.BootstrapOptions); err != nil {
+		return err
+	}
+	for _, ns := range pipelines.SecretReflectionNamespaces(io.BootstrapOptions) {
+		if errs := k8svalidation.IsDNS1123Label(ns); len(errs) > 0 {
+			return fmt.Errorf("invalid namespace %q in --secret-reflection-namespaces: %s", ns, strings.Join(errs, ", "))
+		}
+	}
 	io.Prefix = utility.MaybeCompletePrefix(io.Prefix)
 	return nil
 }
 
+// validateEnvironments returns an error if the environment names are
+// repeated, or aren't valid namespace names with the prefix.
+func validateEnvironments(o *pipelines.BootstrapOptions) error {
+	seen := map[string]bool{}
+	for _, env := range o.EnvironmentNames() {
+		if env == namespaces.CICDName {
+			return fmt.Errorf("invalid --environments %q: %q is the CI/CD namespace", o.Environments, env)
+		}
+		if seen[env] {
+			return fmt.Errorf("invalid --environments %q: %q is repeated", o.Environments, env)
+		}
+		seen[env] = true
+		if errs := k8svalidation.IsDNS1123Label(utility.MaybeCompletePrefix(o.Prefix) + env); len(errs) > 0 {
+			return fmt.Errorf("invalid --environments %q: %s", o.Environments, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// validateBuildArg returns an error if the arg isn't KEY=value, or the value
+// can't be single-quoted in the build command.
+func validateBuildArg(arg string) error {
+	parts := strings.SplitN(arg, "=", 2)
+	if len(parts) != 2 || !buildArgKey.MatchString(parts[0]) {
+		return fmt.Errorf("invalid --build-arg %q: must be KEY=value, where the KEY contains only letters, digits and underscores", arg)
+	}
+	if strings.Contains(parts[1], "'") {
+		return fmt.Errorf("invalid --build-arg %q: the value must not contain single quotes", arg)
+	}
+	return nil
+}
+
+// validateResources returns an error if the resource quantities of the
+// flags with the prefix can't be parsed, or a request is greater than its
+// limit.
+func validateResources(prefix, cpuRequest, cpuLimit, memoryRequest, memoryLimit string) error {
+	for _, pair := range []struct {
+		resource       string
+		request, limit string
+	}{
+		{"cpu", cpuRequest, cpuLimit},
+		{"memory", memoryRequest, memoryLimit},
+	} {
+		requestFlag, limitFlag := prefix+pair.resource+"-request", prefix+pair.resource+"-limit"
+		var request, limit resource.Quantity
+		var err error
+		if pair.request != "" {
+			if request, err = resource.ParseQuantity(pair.request); err != nil {
+				return fmt.Errorf("invalid --%s %q: %w", requestFlag, pair.request, err)
+			}
+		}
+		if pair.limit != "" {
+			if limit, err = resource.ParseQuantity(pair.limit); err != nil {
+				return fmt.Errorf("invalid --%s %q: %w", limitFlag, pair.limit, err)
+			}
+		}
+		if pair.request != "" && pair.limit != "" && request.Cmp(limit) > 0 {
+			return fmt.Errorf("--%s %s is greater than --%s %s", requestFlag, pair.request, limitFlag, pair.limit)
+		}
+	}
+	return nil
+}
+
 // Run runs the project Bootstrap command.
 func (io *BootstrapParameters) Run() error {
-	log.Progressf("\nCompleting Bootstrap process\n")
+	if io.PrintDefaults {
+		return yaml.MarshalOutput(os.Stdout, defaultBootstrapValues())
+	}
+	if io.CheckOnly {
+		if io.DependencyCheckOutput != dependencyCheckOutputJSON {
+			log.Success("The bootstrap dependencies are satisfied")
+		}
+		return nil
+	}
 	appFs := ioutils.NewFilesystem()
-	err := pipelines.Bootstrap(io.BootstrapOptions, appFs)
-	if err != nil {
-		return err
+	if io.Preflight {
+		client, err := utility.NewClient()
+		if err != nil {
+			return err
+		}
+		if err := runPreflight(os.Stdout, io, preflightChecks(io, appFs), client); err != nil {
+			return err
+		}
+		if io.DependencyCheckOutput != dependencyCheckOutputJSON {
+			log.Success("The preflight checks passed")
+		}
+		return nil
 	}
-	if io.PushToGit {
-		err = pipelines.BootstrapRepository(io.BootstrapOptions, factory.FromRepoURL, pipelines.NewCmdExecutor(), appFs)
+	if io.ExplainLayout {
+		paths, err := pipelines.BootstrapLayout(io.BootstrapOptions, appFs)
+		if err != nil {
+			return err
+		}
+		printLayout(os.Stdout, io.GitOpsPath(), paths)
+		return nil
+	}
+	if io.DryRun {
+		return pipelines.BootstrapDryRun(io.BootstrapOptions, os.Stdout, appFs)
+	}
+	if io.ManifestOut != "" {
+		if _, err := pipelines.BootstrapManifest(io.BootstrapOptions, appFs); err != nil {
+			return err
+		}
+		if !io.Quiet {
+			log.Successf("Wrote the manifest to %s, generate the resources from it with kam build", io.ManifestOut)
+		}
+		return nil
+	}
+	if io.Hub {
+		return runHub(io, appFs)
+	}
+	if io.Resume {
+		if !io.Quiet {
+			log.Progressf("\nResuming Bootstrap process from %s\n", io.GitOpsPath())
+		}
+	} else {
+		if !io.Quiet {
+			log.Progressf("\nCompleting Bootstrap process\n")
+		}
+		err := pipelines.Bootstrap(io.BootstrapOptions, appFs)
+		if err != nil {
+			return err
+		}
+		err = pipelines.EncryptSecrets(io.BootstrapOptions, pipelines.NewCmdExecutor(), appFs)
+		if err != nil {
+			return err
+		}
+	}
+	if io.PushToGit || io.Resume {
+		err := pipelines.BootstrapRepository(io.BootstrapOptions, git.NewClientFactory(io.gitProxyURL), pipelines.NewCmdExecutor(), appFs)
 		if err != nil {
 			return fmt.Errorf("failed to create the gitops repository: %q: %w", io.GitOpsRepoURL, err)
 		}
-		log.Successf("Created repository")
+		if !io.Quiet {
+			log.Successf("Created repository")
+		}
 	}
-	nextSteps()
+	if io.OutputFormat == outputFormatJSON {
+		return writeBootstrapSummary(os.Stdout, io.BootstrapOptions, appFs)
+	}
+	nextSteps(io.SecretBackend)
 	return nil
 }
 
+// runHub adds the service to the hub GitOps repository, and opens a pull
+// request with the changes if they're pushed.
+func runHub(io *BootstrapParameters, appFs afero.Fs) error {
+	if err := pipelines.BootstrapHub(io.BootstrapOptions, appFs); err != nil {
+		return err
+	}
+	if err := pipelines.EncryptSecrets(io.BootstrapOptions, pipelines.NewCmdExecutor(), appFs); err != nil {
+		return err
+	}
+	if !io.PushToGit {
+		if !io.Quiet {
+			log.Successf("Added the service to the manifest in %s", io.GitOpsPath())
+		}
+		return nil
+	}
+	pr, err := pipelines.PushHubPullRequest(io.BootstrapOptions, git.NewClientFactory(io.gitProxyURL), pipelines.NewCmdExecutor(), appFs)
+	if err != nil {
+		return fmt.Errorf("failed to open a pull request to the hub repository: %q: %w", io.GitOpsRepoURL, err)
+	}
+	if !io.Quiet {
+		log.Successf("Opened pull request %s", pr.Link)
+	}
+	return nil
+}
+
+// writeBootstrapSummary writes the summary of the bootstrapped files as JSON.
+func writeBootstrapSummary(w io.Writer, o *pipelines.BootstrapOptions, appFs afero.Fs) error {
+	summary, err := pipelines.SummarizeBootstrap(o, appFs)
+	if err != nil {
+		return fmt.Errorf("failed to summarize the bootstrap: %w", err)
+	}
+	b, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the bootstrap summary: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
 // NewCmdBootstrap creates the project init command.
 func NewCmdBootstrap(name, fullName string) *cobra.Command {
 	o := NewBootstrapParameters()
@@ -358,28 +1478,125 @@ func NewCmdBootstrap(name, fullName string) *cobra.Command {
 			genericclioptions.GenericRun(o, cmd, args)
 		},
 	}
-	bootstrapCmd.Flags().StringVar(&o.GitOpsRepoURL, "gitops-repo-url", "", "Provide the URL for your GitOps repository e.g. https://github.com/organisation/repository.git")
-	bootstrapCmd.Flags().StringVar(&o.GitOpsWebhookSecret, "gitops-webhook-secret", "", "Provide a secret that we can use to authenticate incoming hooks from your Git hosting service for the GitOps repository. (if not provided, it will be auto-generated)")
-	bootstrapCmd.Flags().StringVar(&o.OutputPath, "output", "./gitops", "Path to write GitOps resources")
-	bootstrapCmd.Flags().StringVarP(&o.Prefix, "prefix", "p", "", "Add a prefix to the environment names(Dev, stage,prod,cicd etc.) to distinguish and identify individual environments")
-	bootstrapCmd.Flags().StringVar(&o.DockerConfigJSONFilename, "dockercfgjson", "~/.docker/config.json", "Filepath to config.json which authenticates the image push to the desired image registry ")
-	bootstrapCmd.Flags().StringVar(&o.ImageRepo, "image-repo", "", "Image repository of the form <registry>/<username>/<repository> or <project>/<app> which is used to push newly built images")
-	bootstrapCmd.Flags().StringVar(&o.GitHostAccessToken, "git-host-access-token", "", "Used to authenticate repository clones. Access token is encrypted and stored on local file system by keyring, will be updated/reused.")
-	bootstrapCmd.Flags().BoolVar(&o.Overwrite, "overwrite", false, "Overwrites previously existing GitOps configuration (if any) on the local filesystem")
-	bootstrapCmd.Flags().StringVar(&o.ServiceRepoURL, "service-repo-url", "", "Provide the URL for your Service repository e.g. https://github.com/organisation/service.git")
-	bootstrapCmd.Flags().StringVar(&o.ServiceWebhookSecret, "service-webhook-secret", "", "Provide a secret that we can use to authenticate incoming hooks from your Git hosting service for the Service repository. (if not provided, it will be auto-generated)")
-	bootstrapCmd.Flags().BoolVar(&o.SaveTokenKeyRing, "save-token-keyring", false, "Explicitly pass this flag to update the git-host-access-token in the keyring on your local machine")
-	bootstrapCmd.Flags().StringVar(&o.PrivateRepoDriver, "private-repo-driver", "", "If your Git repositories are on a custom domain, please indicate which driver to use github or gitlab")
-	bootstrapCmd.Flags().BoolVar(&o.PushToGit, "push-to-git", false, "If true, automatically creates and populates the gitops-repo-url with the generated resources")
-	bootstrapCmd.Flags().BoolVar(&o.Interactive, "interactive", false, "If true, enable prompting for most options if not already specified on the command line")
+	addBootstrapFlags(bootstrapCmd.Flags(), o)
 	return bootstrapCmd
 }
 
-func nextSteps() {
+func addBootstrapFlags(flags *pflag.FlagSet, o *BootstrapParameters) {
+	flags.StringVar(&o.GitOpsRepoURL, "gitops-repo-url", "", "Provide the URL for your GitOps repository e.g. https://github.com/organisation/repository.git")
+	flags.StringVar(&o.GitOpsWebhookSecret, "gitops-webhook-secret", "", "Provide a secret that we can use to authenticate incoming hooks from your Git hosting service for the GitOps repository, of at least 16 characters. (if not provided, it will be auto-generated)")
+	flags.StringVar(&o.OutputPath, "output", "./gitops", "Path to write GitOps resources")
+	flags.StringVarP(&o.Prefix, "prefix", "p", "", "Add a prefix to the environment names(Dev, stage,prod,cicd etc.) to distinguish and identify individual environments")
+	flags.StringVar(&o.Environments, "environments", "", "Comma separated names of the environments to generate e.g. sandbox,integration,prod, the service is bootstrapped in the first, the CI/CD namespace is always generated (defaults to dev,stage)")
+	flags.StringVar(&o.DockerConfigJSONFilename, "dockercfgjson", "~/.docker/config.json", "Filepath to config.json which authenticates the image push to the desired image registry ")
+	flags.StringVar(&o.QuayRobotAccount, "quay-robot-account", "", "The Quay.io robot account that the app-ci pipeline pushes images with e.g. my-org+ci, requires --quay-robot-token")
+	flags.StringVar(&o.QuayRobotToken, "quay-robot-token", "", "The token of the --quay-robot-account, the Docker config that authenticates the image push is generated from it instead of being read from --dockercfgjson")
+	flags.StringVar(&o.GitLabDeployToken, "gitlab-deploy-token", "", "A GitLab deploy token with the write_registry scope as <username>:<token> e.g. gitlab+deploy-token-1:abcdef, the Docker config that authenticates the image push to the GitLab container registry of the --image-repo is generated from it instead of being read from --dockercfgjson")
+	flags.StringVar(&o.BootstrapImage, "bootstrap-image", pipelines.DefaultBootstrapImage, "The image of the bootstrapped service's Deployment, e.g. a mirror in an internal registry for clusters that can't pull from Docker Hub")
+	flags.StringVar(&o.CPURequest, "cpu-request", pipelines.DefaultCPURequest, "The CPU request of the bootstrapped service's container")
+	flags.StringVar(&o.CPULimit, "cpu-limit", pipelines.DefaultCPULimit, "The CPU limit of the bootstrapped service's container")
+	flags.StringVar(&o.MemoryRequest, "memory-request", pipelines.DefaultMemoryRequest, "The memory request of the bootstrapped service's container")
+	flags.StringVar(&o.MemoryLimit, "memory-limit", pipelines.DefaultMemoryLimit, "The memory limit of the bootstrapped service's container")
+	flags.StringVar(&o.RouteHealthCheckPath, "route-healthcheck-path", "", "A path e.g. /healthz that the bootstrapped service's readiness is checked on, the Route only sends traffic to the service once it's ready")
+	flags.StringVar(&o.BuildImage, "build-image", "", "The buildah image that the app-ci pipeline builds images with, e.g. a mirror in an internal registry (defaults to the image of the buildah ClusterTask)")
+	flags.StringArrayVar(&o.BuildArgs, "build-arg", nil, "A KEY=value build arg passed to the app-ci pipeline's image build e.g. HTTP_PROXY=http://proxy.example.com:3128, can be repeated")
+	flags.StringVar(&o.ImageRepo, "image-repo", "", "Image repository of the form <registry>/<username>/<repository> or <project>/<app> which is used to push newly built images")
+	flags.StringVar(&o.GitHostAccessToken, "git-host-access-token", "", "Used to authenticate repository clones. Access token is encrypted and stored on local file system by keyring, will be updated/reused.")
+	flags.StringVar(&o.GitHostAccessTokenFile, "git-host-access-token-file", "", "Path to a file that the --git-host-access-token is read from, e.g. a mounted secret, so that the token isn't visible in the process arguments")
+	flags.BoolVar(&o.Overwrite, "overwrite", false, "Overwrites previously existing GitOps configuration (if any) on the local filesystem")
+	flags.StringVar(&o.ServiceRepoURL, "service-repo-url", "", "Provide the URL for your Service repository e.g. https://github.com/organisation/service.git")
+	flags.StringVar(&o.ServiceWebhookSecret, "service-webhook-secret", "", "Provide a secret that we can use to authenticate incoming hooks from your Git hosting service for the Service repository, of at least 16 characters. (if not provided, it will be auto-generated)")
+	flags.BoolVar(&o.SaveTokenKeyRing, "save-token-keyring", false, "Explicitly pass this flag to update the git-host-access-token in the keyring on your local machine")
+	flags.StringVar(&o.PrivateRepoDriver, "private-repo-driver", "", "If your Git repositories are on a custom domain, please indicate which driver to use github, gitlab or azure")
+	flags.StringVar(&o.GitCloneHost, "git-clone-host", "", "Override the host e.g. https://git.example.com:8443 that the git-host-access-token is used for when cloning Service repositories in pipelines (defaults to the host of the service-repo-url)")
+	flags.StringVar(&o.GitUsername, "git-username", "", fmt.Sprintf("The username that the git-host-access-token is used with when cloning Service repositories in pipelines, e.g. the username of a GitLab deploy token (defaults to %q)", secrets.DefaultBasicAuthUsername))
+	flags.StringVar(&o.DriverMapFile, "driver-map-file", "", "Path to a YAML or JSON file mapping Git hosts to the driver to use (github or gitlab) e.g. github.example.com: github")
+	flags.BoolVar(&o.Hub, "hub", false, "If true, the service is added to the existing pipelines.yaml of a central GitOps repository checked out to --output, rather than bootstrapping a new GitOps repository, with --push-to-git the changes are pushed to a branch and a pull request is opened")
+	flags.BoolVar(&o.PushToGit, "push-to-git", false, "If true, automatically creates and populates the gitops-repo-url with the generated resources")
+	flags.BoolVar(&o.Resume, "resume", false, "If true, skip generating resources when the output folder already contains a valid manifest, and push the existing resources to the gitops-repo-url")
+	flags.IntVar(&o.PushRetries, "push-retries", defaultPushRetries, "The number of times creating the gitops-repo-url, and pushing to it, is retried when the Git host fails with a server error or rate limits the requests, with an exponentially increasing delay between the retries, authentication failures aren't retried")
+	o.PrivateRepo = flags.Bool("private-repo", true, "If true, the gitops-repo-url is created as a private repository with --push-to-git, if false it's created as a public repository, which exposes the configuration of the cluster")
+	flags.StringVar(&o.TektonAPIVersion, "tekton-api-version", tekton.V1Beta1, fmt.Sprintf("The tekton.dev API version of the generated OpenShift Pipelines resources, one of %s", strings.Join(tekton.SupportedAPIVersions, ", ")))
+	flags.StringVar(&o.SecretBackend, "secret-backend", "", "Encrypt the generated secrets with sops, or fetch them from HashiCorp Vault with vault (if not provided, secrets are not encrypted)")
+	flags.StringVar(&o.SOPSAgeRecipients, "sops-age-recipients", "", "Comma separated list of age recipients used to encrypt the generated secrets with --secret-backend sops")
+	flags.StringVar(&o.SOPSPGPKey, "sops-pgp-key", "", "Comma separated list of PGP fingerprints used to encrypt the generated secrets with --secret-backend sops")
+	flags.StringVar(&o.VaultSecretStore, "vault-secret-store", "", "The name of the External Secrets Operator SecretStore that the ExternalSecrets fetch the secrets from with --secret-backend vault")
+	flags.StringVar(&o.SecretReflectionNamespaces, "secret-reflection-namespaces", "", "Comma separated list of namespaces that kubernetes-reflector replicates the generated secrets to, the secrets are annotated to allow and enable the replication")
+	flags.BoolVar(&o.Interactive, "interactive", false, "If true, enable prompting for most options if not already specified on the command line")
+	flags.StringVar(&o.InternalRegistryProject, "internal-registry-project", "", "Project in the internal image registry to push newly built images to if --image-repo is not provided (defaults to the CI/CD namespace)")
+	flags.StringVar(&o.SecretsRepoURL, "secrets-repo-url", "", "Provide the URL for a separate repository that the generated secrets are delivered from, an ArgoCD application is generated to sync it")
+	flags.StringVar(&o.ArgoCDNamespace, "argocd-namespace", argocd.ArgoCDNamespace, "The namespace that Argo CD is installed in, the Argo CD applications are generated in it and its application controller is granted admin in the environments")
+	flags.StringVar(&o.ArgoCDProject, "argocd-project", "", "If set, generate an Argo CD AppProject with this name that is restricted to the GitOps repository, the service repositories and the environments, and generate the applications in it instead of the default project")
+	flags.BoolVar(&o.NamespacedInstall, "namespaced-install", false, "If true, don't generate cluster-scoped resources, the namespaces and Argo CD must already exist and the pipeline service account is granted a Role in the CI/CD namespace instead of a ClusterRole")
+	flags.StringVar(&o.DefaultBranch, "default-branch", "", "The default branch of the repositories, the GitOps repository is pushed to it and only pushes to it in the service repositories trigger the app-ci pipelines (defaults to pushing to main, and triggering on pushes to any branch)")
+	flags.StringVar(&o.ServiceAccount, "service-account", config.DefaultServiceAccountName, "The service account that the EventListener and the PipelineRuns run as, and that is bound to the environments, it is generated in the CI/CD namespace if it isn't the default that OpenShift Pipelines creates")
+	flags.StringVar(&o.ExistingClusterRole, "existing-cluster-role", "", "Bind the pipeline service account to this existing ClusterRole instead of generating one, for clusters where creating ClusterRoles is forbidden")
+	flags.BoolVar(&o.UseProjectRequests, "use-project-requests", false, "If true, generate OpenShift ProjectRequests instead of Namespaces, for clusters where namespaces can only be created through project self-provisioning")
+	flags.BoolVar(&o.PerEnvOverlays, "per-env-overlays", false, "If true, generate a base for each service that the environments share, and an overlay named for each environment e.g. apps/<app>/services/<service>/overlays/dev, which the environment's application uses (defaults to a base and a single overlays folder for the service in each environment)")
+	flags.BoolVar(&o.NoAppCI, "no-app-ci", false, "If true, don't generate the app-ci pipeline, template and image binding, images are built out-of-band and only the GitOps CI dry-run pipeline is generated")
+	flags.StringVar(&o.RepoSubpath, "repo-subpath", "", "Generate the GitOps configuration in this folder within the GitOps repository e.g. platform/gitops, the Argo CD applications use paths within the folder (defaults to the root of the repository)")
+	flags.StringVar(&o.PipelineRunTTL, "pipelinerun-ttl", "", "How long to keep the PipelineRuns created by the CI triggers after they finish e.g. 24h, they are annotated for the Tekton pruner (if not provided, they are not annotated)")
+	flags.StringVar(&o.PipelineNamePrefix, "pipeline-name-prefix", "", "Add this prefix to the names of the generated CI Pipelines, TriggerTemplates and TriggerBindings e.g. team-a-, so that several configurations can share a CI/CD namespace")
+	flags.BoolVar(&o.WithImageUpdater, "with-image-updater", false, "If true, annotate the Argo CD applications for the Argo CD Image Updater, so new tags pushed to the image repository are deployed automatically")
+	flags.StringVar(&o.ImageUpdateStrategy, "image-update-strategy", "", fmt.Sprintf("How the Argo CD Image Updater picks the new image tag with --with-image-updater, one of %s (defaults to latest)", strings.Join(config.ImageUpdateStrategies, ", ")))
+	flags.StringVar(&o.ImageWriteBackMethod, "image-write-back-method", "", fmt.Sprintf("How the Argo CD Image Updater records the new image tag with --with-image-updater, one of %s (defaults to git, which commits to the GitOps repository)", strings.Join(config.ImageWriteBackMethods, ", ")))
+	flags.BoolVar(&o.WithPRPreviews, "with-pr-previews", false, "If true, generate an Argo CD ApplicationSet that deploys a preview of the service to its own namespace for each open pull request")
+	flags.StringVar(&o.ApplyMode, "apply-mode", "", fmt.Sprintf("How Argo CD applies the generated resources, one of %s, server-side adds the ServerSideApply=true sync option to the Argo CD applications (defaults to client-side)", strings.Join(config.ApplyModes, ", ")))
+	o.ArgoCDAutoSync = flags.Bool("argocd-auto-sync", true, "If true, the Argo CD applications sync automatically when the GitOps repository changes, if false they're only synced manually")
+	o.ArgoCDSelfHeal = flags.Bool("argocd-self-heal", true, "If true, the automated syncs of the Argo CD applications also revert changes to the live resources that drift from the GitOps repository")
+	o.ArgoCDPrune = flags.Bool("argocd-prune", true, "If true, the automated syncs of the Argo CD applications delete the resources that are removed from the GitOps repository")
+	flags.BoolVar(&o.ArgoCDCreateNamespace, "argocd-create-namespace", false, "If true, add the CreateNamespace=true sync option to the Argo CD applications, so that Argo CD creates their destination namespaces")
+	flags.StringVar(&o.RouteWildcardPolicy, "route-wildcard-policy", "", fmt.Sprintf("The wildcard policy of the EventListener's Route, one of %s, for clusters with routers that serve wildcard routes (defaults to None)", strings.Join(eventlisteners.WildcardPolicies, ", ")))
+	flags.StringVar(&o.RouteSubdomain, "route-subdomain", "", "The subdomain within the router's domain that the EventListener's Route requests e.g. webhooks, so the webhook endpoint is served by the intended router shard (defaults to a generated host)")
+	flags.BoolVar(&o.WebhookTLS, "webhook-tls", false, "If true, the EventListener's Route terminates TLS at the router with edge termination and redirects insecure requests, so the webhooks are delivered to an HTTPS endpoint")
+	flags.StringVar(&o.EventListenerCPURequest, "event-listener-cpu-request", "", "The CPU request of the EventListener's pod e.g. 250m (defaults to none)")
+	flags.StringVar(&o.EventListenerCPULimit, "event-listener-cpu-limit", "", "The CPU limit of the EventListener's pod e.g. 1 (defaults to none)")
+	flags.StringVar(&o.EventListenerMemoryRequest, "event-listener-memory-request", "", "The memory request of the EventListener's pod e.g. 256Mi (defaults to none)")
+	flags.StringVar(&o.EventListenerMemoryLimit, "event-listener-memory-limit", "", "The memory limit of the EventListener's pod e.g. 512Mi (defaults to none)")
+	flags.StringVar(&o.DryRunTrigger, "dry-run-trigger", "", fmt.Sprintf("The events in the GitOps repository that trigger the CI dry-run, one of %s, defaults to pushes, and merge requests for GitLab repositories", strings.Join(eventlisteners.DryRunTriggers, ", ")))
+	flags.StringVar(&o.WebhookInterceptorURL, "webhook-interceptor-url", "", "Forward every event received by the EventListener to the Service at this URL e.g. http://audit.audit-ns.svc, with a webhook interceptor after the event filters")
+	flags.BoolVar(&o.LabelsFromGit, "labels-from-git", false, "If true, annotate the generated resources with the GitOps repository, the branch and the version of kam that generated them, so resources in the cluster can be traced to the bootstrap that generated them")
+	flags.StringVar(&o.ManifestOut, "manifest-out", "", "Write only the manifest to this file e.g. ./gitops/pipelines.yaml, without the resources or secrets, the resources can be generated from the manifest later with kam build")
+	flags.BoolVar(&o.VerifyKustomize, "verify-kustomize", false, "If true, run a kustomize build over every overlay in the generated resources")
+	flags.BoolVar(&o.WriteChecksums, "write-checksums", false, fmt.Sprintf("If true, write the sha256 checksums of the generated files to %s in the output folder, so changes to the files can be detected with verify-checksums", pipelines.ChecksumsFile))
+	flags.BoolVar(&o.CheckOnly, "check-only", false, "If true, only check that the cluster dependencies are installed and exit, with a non-zero exit code if they're not satisfied, without generating or pushing anything")
+	flags.BoolVar(&o.Preflight, "preflight", false, "If true, check that the access token can read and manage the webhooks of the repositories, that the image repository can be pushed to and that the cluster dependencies are installed, report the results and exit without generating or pushing anything")
+	flags.BoolVar(&o.SkipChecks, "skip-checks", false, "If true, skip the checks that the cluster dependencies are installed, e.g. when the operators are installed at the same time, the resources are generated regardless of the state of the cluster")
+	flags.StringVar(&o.GitProxy, "git-proxy", "", "Send the Git host API requests through the proxy at this URL e.g. http://proxy.example.com:3128, instead of the proxy from the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables")
+	flags.StringVar(&o.ClusterProxy, "cluster-proxy", "", "Send the cluster API requests through the proxy at this URL e.g. http://proxy.example.com:3128, instead of the proxy from the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables")
+	flags.StringVar(&o.DependencyCheckOutput, "dependency-check-output", dependencyCheckOutputText, fmt.Sprintf("The format that the results of the cluster dependency checks are written in, one of %s, %s", dependencyCheckOutputText, dependencyCheckOutputJSON))
+	flags.StringVar(&o.OutputFormat, "output-format", outputFormatText, fmt.Sprintf("The format that the outcome of the bootstrap is written in, one of %s, %s, json writes a summary of the generated environments, services, webhook secrets and secret files instead of the progress", outputFormatText, outputFormatJSON))
+	flags.IntVar(&o.Concurrency, "concurrency", defaultConcurrency, "The number of cluster dependency checks to run at once, values less than 1 run the checks one at a time")
+	flags.StringVar(&o.ConfigFile, configFlag, "", "Path to a YAML file of bootstrap options in the same format as --print-defaults, options passed on the command line take precedence")
+	flags.BoolVar(&o.DryRun, "dry-run", false, "If true, print the files that bootstrap would generate with the other options, and their contents, to stdout without writing anything, the secrets are printed unencrypted")
+	flags.BoolVar(&o.ExplainLayout, "explain-layout", false, "If true, print the files that bootstrap would generate with the other options and exit without generating anything")
+	flags.BoolVar(&o.PrintDefaults, "print-defaults", false, "If true, print the default bootstrap options as YAML and exit")
+}
+
+// defaultBootstrapValues returns the options that bootstrap would use if no
+// flags were provided, along with the other defaults applied when generating
+// resources.
+func defaultBootstrapValues() *bootstrapDefaults {
+	o := NewBootstrapParameters()
+	addBootstrapFlags(pflag.NewFlagSet(BootstrapRecommendedCommandName, pflag.ContinueOnError), o)
+	return &bootstrapDefaults{
+		BootstrapOptions:    o.BootstrapOptions,
+		WebhookSecretLength: pipelines.WebhookSecretLength,
+	}
+}
+
+func nextSteps(secretBackend string) {
 	log.Success("Bootstrapped OpenShift resources successfully\n\n",
 		"Next Steps:\n",
 		"Please refer to https://github.com/redhat-developer/kam/tree/master/docs to get started.\n",
 	)
+	if secretBackend == pipelines.SecretBackendSOPS {
+		return
+	}
+	if secretBackend == pipelines.SecretBackendVault {
+		log.Info(" The generated secrets are fetched from Vault by ExternalSecrets, store the values of the secrets in the secrets folder in Vault at <namespace>/<name>, and delete the secrets folder.\n For more information see: https://github.com/redhat-developer/kam/tree/master/docs/journey/day1#fetching-secrets-from-vault\n")
+		return
+	}
 	log.Info(" WARNING: Generated secrets are not encrypted. Deploying the GitOps configuration without encrypting secrets is insecure and is not recommended.\n For more information on secret management see: https://github.com/redhat-developer/kam/tree/master/docs/journey/day1#secrets\n")
 }
 
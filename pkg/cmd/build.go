@@ -1,7 +1,11 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/openshift/odo/pkg/log"
 	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
@@ -21,6 +25,24 @@ var (
 	buildExample = ktemplates.Examples(`
 	# Build files from pipelines
 	%[1]s 
+
+	# Regenerate only the ArgoCD applications
+	%[1]s --only argocd
+
+	# Regenerate only the EventListener, its Route, and the trigger bindings and templates
+	%[1]s --only eventlistener
+
+	# Check the built resources against the Kubernetes and Tekton schemas
+	%[1]s --validate
+
+	# Record the checksums of the built files
+	%[1]s --write-checksums
+
+	# Build a team's files from a manifest template
+	%[1]s --pipelines-folder ./template --values team-a.yaml --output ./team-a
+
+	# Print the manifest in canonical form without building anything
+	%[1]s --dump-config > pipelines.yaml.new
 	`)
 
 	buildLongDesc  = ktemplates.LongDesc(`Build GitOps pipelines files, generating the ArgoCD applications and OpenShift Pipelines EventListener`)
@@ -31,6 +53,12 @@ var (
 type BuildParameters struct {
 	pipelinesFolderPath string
 	output              string // path to add Gitops resources
+	verifyKustomize     bool
+	only                string
+	values              string
+	validate            bool
+	writeChecksums      bool
+	dumpConfig          bool
 }
 
 // NewBuildParameters bootstraps a BuildParameters instance.
@@ -45,6 +73,15 @@ func (io *BuildParameters) Complete(name string, cmd *cobra.Command, args []stri
 
 // Validate validates the parameters of the BuildParameters.
 func (io *BuildParameters) Validate() error {
+	if io.only != "" && !pipelines.IsSupportedBuildOnly(io.only) {
+		return fmt.Errorf("invalid --only %q, must be one of %s", io.only, strings.Join(pipelines.BuildOnly, ", "))
+	}
+	if io.values != "" && filepath.Clean(io.output) == filepath.Clean(io.pipelinesFolderPath) {
+		return errors.New("--values requires an --output folder other than the --pipelines-folder, the built manifest would overwrite the template")
+	}
+	if io.dumpConfig && (io.only != "" || io.validate || io.writeChecksums || io.verifyKustomize) {
+		return errors.New("--dump-config cannot be used with --only, --validate, --write-checksums or --verify-kustomize, no resources are built")
+	}
 	return nil
 }
 
@@ -53,6 +90,14 @@ func (io *BuildParameters) Run() error {
 	options := pipelines.BuildParameters{
 		PipelinesFolderPath: io.pipelinesFolderPath,
 		OutputPath:          io.output,
+		VerifyKustomize:     io.verifyKustomize,
+		Only:                io.only,
+		ValuesFile:          io.values,
+		Validate:            io.validate,
+		WriteChecksums:      io.writeChecksums,
+	}
+	if io.dumpConfig {
+		return pipelines.DumpManifest(os.Stdout, &options, ioutils.NewFilesystem())
 	}
 	err := pipelines.BuildResources(&options, ioutils.NewFilesystem())
 	if err != nil {
@@ -77,5 +122,11 @@ func NewCmdBuild(name, fullName string) *cobra.Command {
 
 	buildCmd.Flags().StringVar(&o.output, "output", ".", "Folder path to add GitOps resources")
 	buildCmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	buildCmd.Flags().StringVar(&o.only, "only", "", "Only build these resources without touching the other files, argocd regenerates the ArgoCD applications and eventlistener regenerates the EventListener, its Route, and the trigger bindings and templates")
+	buildCmd.Flags().StringVar(&o.values, "values", "", "Path to a YAML file of values to substitute for the ${NAME} placeholders in the manifest, the manifest with the values substituted is written to the output folder")
+	buildCmd.Flags().BoolVar(&o.validate, "validate", false, "If true, validate the resources against the Kubernetes and Tekton schemas without contacting a cluster, and write nothing if any are invalid")
+	buildCmd.Flags().BoolVar(&o.writeChecksums, "write-checksums", false, fmt.Sprintf("If true, update the sha256 checksums of the built files in %s in the output folder", pipelines.ChecksumsFile))
+	buildCmd.Flags().BoolVar(&o.dumpConfig, "dump-config", false, "If true, write the manifest to stdout in canonical form, sorted by name, without building any resources")
+	buildCmd.Flags().BoolVar(&o.verifyKustomize, "verify-kustomize", false, "If true, run a kustomize build over every overlay in the generated resources")
 	return buildCmd
 }
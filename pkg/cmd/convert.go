@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/cmd/utility"
+	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/spf13/cobra"
+
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// ConvertRecommendedCommandName the recommended command name
+	ConvertRecommendedCommandName = "convert"
+)
+
+var (
+	convertExample = ktemplates.Examples(`
+	# Generate a manifest from an existing kustomize repository
+	%[1]s --input ./my-repo --gitops-repo-url https://github.com/org/my-repo.git
+	`)
+
+	convertLongDesc = ktemplates.LongDesc(`Generate a best-effort pipelines.yaml from an existing kustomize repository
+
+The repository is expected to be laid out as environments/{env}/apps/{app}/services/{service},
+configuration that can't be inferred from the repository is reported as warnings.`)
+	convertShortDesc = `Generate a manifest from a kustomize repository`
+)
+
+// ConvertParameters encapsulates the parameters for the kam convert command.
+type ConvertParameters struct {
+	input         string
+	output        string
+	gitopsRepoURL string
+	overwrite     bool
+}
+
+// NewConvertParameters bootstraps a ConvertParameters instance.
+func NewConvertParameters() *ConvertParameters {
+	return &ConvertParameters{}
+}
+
+// Complete completes ConvertParameters after they've been created.
+func (co *ConvertParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	if co.gitopsRepoURL != "" {
+		co.gitopsRepoURL = utility.AddGitSuffixIfNecessary(co.gitopsRepoURL)
+	}
+	return nil
+}
+
+// Validate validates the parameters of the ConvertParameters.
+func (co *ConvertParameters) Validate() error {
+	return nil
+}
+
+// Run runs the convert command.
+func (co *ConvertParameters) Run() error {
+	options := pipelines.ConvertParameters{
+		InputPath:     co.input,
+		OutputPath:    co.output,
+		GitOpsRepoURL: co.gitopsRepoURL,
+		Overwrite:     co.overwrite,
+	}
+	warnings, err := pipelines.Convert(&options, ioutils.NewFilesystem())
+	if err != nil {
+		return err
+	}
+	for _, w := range warnings {
+		log.Warningf("%s", w)
+	}
+	log.Success("Converted successfully, please review the generated manifest.")
+	return nil
+}
+
+// NewCmdConvert creates the convert command.
+func NewCmdConvert(name, fullName string) *cobra.Command {
+	o := NewConvertParameters()
+	convertCmd := &cobra.Command{
+		Use:     name,
+		Short:   convertShortDesc,
+		Long:    convertLongDesc,
+		Example: fmt.Sprintf(convertExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	convertCmd.Flags().StringVar(&o.input, "input", ".", "Folder path of the existing kustomize repository")
+	convertCmd.Flags().StringVar(&o.output, "output", ".", "Folder path to write the generated pipelines.yaml")
+	convertCmd.Flags().StringVar(&o.gitopsRepoURL, "gitops-repo-url", "", "The URL of the existing repository, written to the manifest as the GitOps repository")
+	convertCmd.Flags().BoolVar(&o.overwrite, "overwrite", false, "Overwrite an existing pipelines.yaml")
+	return convertCmd
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/cmd/ui"
+	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/spf13/cobra"
+
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// DeleteRecommendedCommandName the recommended command name
+	DeleteRecommendedCommandName = "delete"
+)
+
+var (
+	deleteExample = ktemplates.Examples(`
+	# Delete the GitOps configuration bootstrapped into ./gitops, and ./secrets
+	%[1]s --output ./gitops
+
+	# Delete without confirming
+	%[1]s --output ./gitops --force
+	`)
+
+	deleteLongDesc = ktemplates.LongDesc(`Delete the local GitOps configuration that was bootstrapped
+
+The output folder and the secrets folder that is a sibling of it are deleted, after confirming.  The output folder must contain a pipelines.yaml, so that folders that weren't bootstrapped aren't deleted.  Nothing is deleted from the cluster or the Git hosting service.`)
+	deleteShortDesc = `Delete the bootstrapped GitOps configuration`
+)
+
+// DeleteParameters encapsulates the parameters for the kam delete command.
+type DeleteParameters struct {
+	output string
+	force  bool
+}
+
+// NewDeleteParameters bootstraps a DeleteParameters instance.
+func NewDeleteParameters() *DeleteParameters {
+	return &DeleteParameters{}
+}
+
+// Complete completes DeleteParameters after they've been created.
+func (dp *DeleteParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the DeleteParameters.
+func (dp *DeleteParameters) Validate() error {
+	if dp.output == "" {
+		return errors.New("--output must not be empty")
+	}
+	return nil
+}
+
+// Run runs the delete command.
+func (dp *DeleteParameters) Run() error {
+	appFs := ioutils.NewFilesystem()
+	paths, err := pipelines.BootstrappedPaths(appFs, dp.output)
+	if err != nil {
+		return err
+	}
+	if !dp.force && !ui.SelectOptionDelete(paths) {
+		log.Info("Nothing was deleted")
+		return nil
+	}
+	deleted, err := pipelines.DeleteBootstrapped(appFs, dp.output)
+	if err != nil {
+		return err
+	}
+	for _, p := range deleted {
+		log.Successf("Deleted %s", p)
+	}
+	return nil
+}
+
+// NewCmdDelete creates the delete command.
+func NewCmdDelete(name, fullName string) *cobra.Command {
+	o := NewDeleteParameters()
+	deleteCmd := &cobra.Command{
+		Use:     name,
+		Short:   deleteShortDesc,
+		Long:    deleteLongDesc,
+		Example: fmt.Sprintf(deleteExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	deleteCmd.Flags().StringVar(&o.output, "output", ".", "Path to the bootstrapped GitOps configuration, the folder that contains the pipelines.yaml")
+	deleteCmd.Flags().BoolVar(&o.force, "force", false, "If true, the folders are deleted without confirming")
+	return deleteCmd
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/openshift/odo/pkg/log"
 	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
 	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
 	"github.com/spf13/cobra"
 
@@ -21,6 +22,9 @@ var (
 	addEnvExample = ktemplates.Examples(`
 	# Add a new environment to GitOps
 	%[1]s 
+
+	# Add an environment that is deployed to another cluster
+	%[1]s --env-name prod --cluster https://api.prod.example.com:6443
 	`)
 
 	addEnvLongDesc  = ktemplates.LongDesc(`Add a new environment to the GitOps repository`)
@@ -32,6 +36,7 @@ type AddEnvParameters struct {
 	envName         string
 	pipelinesFolder string
 	cluster         string
+	manualSync      bool
 }
 
 // NewAddEnvParameters bootstraps a AddEnvParameters instance.
@@ -49,6 +54,9 @@ func (eo *AddEnvParameters) Complete(name string, cmd *cobra.Command, args []str
 
 // Validate validates the parameters of the EnvParameters.
 func (eo *AddEnvParameters) Validate() error {
+	if eo.cluster != "" && !config.IsValidClusterURL(eo.cluster) {
+		return fmt.Errorf("invalid --cluster %q, must be the URL of the cluster's API server e.g. https://api.cluster.example.com:6443", eo.cluster)
+	}
 	return nil
 }
 
@@ -58,6 +66,7 @@ func (eo *AddEnvParameters) Run() error {
 		EnvName:             eo.envName,
 		PipelinesFolderPath: eo.pipelinesFolder,
 		Cluster:             eo.cluster,
+		ManualSync:          eo.manualSync,
 	}
 	err := pipelines.AddEnv(&options, ioutils.NewFilesystem())
 	if err != nil {
@@ -84,6 +93,7 @@ func NewCmdAddEnv(name, fullName string) *cobra.Command {
 	addEnvCmd.Flags().StringVar(&o.envName, "env-name", "", "Name of the environment/namespace")
 	_ = addEnvCmd.MarkFlagRequired("env-name")
 	addEnvCmd.Flags().StringVar(&o.pipelinesFolder, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
-	addEnvCmd.Flags().StringVar(&o.cluster, "cluster", "", "Deployment cluster e.g. https://kubernetes.local.svc")
+	addEnvCmd.Flags().StringVar(&o.cluster, "cluster", "", "The URL of the cluster's API server that the environment is deployed to e.g. https://api.cluster.example.com:6443 (defaults to the cluster that Argo CD runs in)")
+	addEnvCmd.Flags().BoolVar(&o.manualSync, "manual-sync", false, "If true, the Argo CD applications for the environment are only synced manually")
 	return addEnvCmd
 }
@@ -51,3 +51,25 @@ func flag(k, v string) keyValuePair {
 		value: v,
 	}
 }
+
+func TestValidateAddEnvCluster(t *testing.T) {
+	validateTests := []struct {
+		cluster string
+		wantErr string
+	}{
+		{"", ""},
+		{"https://api.cluster.example.com:6443", ""},
+		{"testing.cluster", `invalid --cluster "testing.cluster", must be the URL of the cluster's API server e.g. https://api.cluster.example.com:6443`},
+	}
+	for _, tt := range validateTests {
+		t.Run(tt.cluster, func(rt *testing.T) {
+			err := (&AddEnvParameters{cluster: tt.cluster}).Validate()
+			if tt.wantErr == "" && err != nil {
+				rt.Fatalf("got error %s, want none", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				rt.Fatalf("got error %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
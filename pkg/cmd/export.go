@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/spf13/cobra"
+
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// ExportRecommendedCommandName the recommended command name
+	ExportRecommendedCommandName = "export"
+)
+
+var (
+	exportExample = ktemplates.Examples(`
+	# Export the resources built from the manifest in the current folder
+	%[1]s
+
+	# Apply the resources without Argo CD
+	%[1]s --pipelines-folder ./gitops | kubectl apply -f -
+
+	# Write the resources to a file
+	%[1]s --output-file resources.yaml
+	`)
+
+	exportLongDesc = ktemplates.LongDesc(`Export the resources built from the manifest as a single YAML stream
+
+Each resource is a separate YAML document, preceded by a comment with the path that build writes it to, sorted by the path.  The kustomizations aren't exported, so the stream can be applied with kubectl apply.  Nothing is written to the GitOps tree.`)
+	exportShortDesc = `Export the built resources as a single YAML stream`
+)
+
+// ExportParameters encapsulates the parameters for the kam export command.
+type ExportParameters struct {
+	pipelinesFolderPath string
+	outputFile          string
+}
+
+// NewExportParameters bootstraps an ExportParameters instance.
+func NewExportParameters() *ExportParameters {
+	return &ExportParameters{}
+}
+
+// Complete completes ExportParameters after they've been created.
+func (ep *ExportParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the ExportParameters.
+func (ep *ExportParameters) Validate() error {
+	return nil
+}
+
+// Run runs the export command.
+func (ep *ExportParameters) Run() error {
+	appFs := ioutils.NewFilesystem()
+	var out io.Writer = os.Stdout
+	if ep.outputFile != "" {
+		f, err := appFs.Create(ep.outputFile)
+		if err != nil {
+			return fmt.Errorf("failed to create the output file %q: %w", ep.outputFile, err)
+		}
+		defer f.Close()
+		out = f
+	}
+	return pipelines.ExportResources(out, &pipelines.BuildParameters{PipelinesFolderPath: ep.pipelinesFolderPath}, appFs)
+}
+
+// NewCmdExport creates the export command.
+func NewCmdExport(name, fullName string) *cobra.Command {
+	o := NewExportParameters()
+	exportCmd := &cobra.Command{
+		Use:     name,
+		Short:   exportShortDesc,
+		Long:    exportLongDesc,
+		Example: fmt.Sprintf(exportExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+	exportCmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	exportCmd.Flags().StringVar(&o.outputFile, "output-file", "", "Write the resources to this file instead of stdout")
+	return exportCmd
+}
@@ -4,6 +4,7 @@ import (
 	"log"
 
 	"github.com/redhat-developer/kam/pkg/cmd/environment"
+	"github.com/redhat-developer/kam/pkg/cmd/manifest"
 	"github.com/redhat-developer/kam/pkg/cmd/service"
 	"github.com/redhat-developer/kam/pkg/cmd/utility"
 	"github.com/redhat-developer/kam/pkg/cmd/version"
@@ -33,6 +34,13 @@ func MakeRootCmd() *cobra.Command {
 		version.NewCmd(version.RecommendedCommandName, utility.GetFullName(fullName, version.RecommendedCommandName)),
 		webhook.NewCmdWebhook(webhook.RecommendedCommandName, utility.GetFullName(fullName, webhook.RecommendedCommandName)),
 		NewCmdBuild(BuildRecommendedCommandName, utility.GetFullName(fullName, BuildRecommendedCommandName)),
+		NewCmdConvert(ConvertRecommendedCommandName, utility.GetFullName(fullName, ConvertRecommendedCommandName)),
+		NewCmdExport(ExportRecommendedCommandName, utility.GetFullName(fullName, ExportRecommendedCommandName)),
+		NewCmdNamespaces(NamespacesRecommendedCommandName, utility.GetFullName(fullName, NamespacesRecommendedCommandName)),
+		manifest.NewCmd(manifest.RecommendedCommandName, utility.GetFullName(fullName, manifest.RecommendedCommandName)),
+		NewCmdVerify(VerifyRecommendedCommandName, utility.GetFullName(fullName, VerifyRecommendedCommandName)),
+		NewCmdVerifyChecksums(VerifyChecksumsRecommendedCommandName, utility.GetFullName(fullName, VerifyChecksumsRecommendedCommandName)),
+		NewCmdDelete(DeleteRecommendedCommandName, utility.GetFullName(fullName, DeleteRecommendedCommandName)),
 		completionCmd,
 	)
 	return rootCmd
@@ -0,0 +1,32 @@
+package manifest
+
+import (
+	"fmt"
+
+	"github.com/redhat-developer/kam/pkg/cmd/utility"
+	"github.com/spf13/cobra"
+)
+
+// RecommendedCommandName is the recommended manifest command name.
+const RecommendedCommandName = "manifest"
+
+// NewCmd creates a new manifest command
+func NewCmd(name, fullName string) *cobra.Command {
+
+	validateCmd := newCmdValidate(validateRecommendedCommandName, utility.GetFullName(fullName, validateRecommendedCommandName))
+
+	var cmd = &cobra.Command{
+		Use:   name,
+		Short: "Manage the GitOps manifest",
+		Long:  "Manage the pipelines.yaml manifest that the GitOps repository is generated from",
+		Example: fmt.Sprintf("%s\n%s\n\n  See sub-commands individually for more examples",
+			fullName, validateRecommendedCommandName),
+		Run: func(cmd *cobra.Command, args []string) {
+		},
+	}
+
+	cmd.AddCommand(validateCmd)
+
+	cmd.Annotations = map[string]string{"command": "main"}
+	return cmd
+}
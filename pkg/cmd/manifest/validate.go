@@ -0,0 +1,76 @@
+package manifest
+
+import (
+	"fmt"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/spf13/cobra"
+
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const validateRecommendedCommandName = "validate"
+
+var (
+	validateExample = ktemplates.Examples(`
+	# Validate the manifest in the current folder
+	%[1]s
+
+	# Validate the manifest in another folder
+	%[1]s --pipelines-folder ./gitops
+	`)
+
+	validateLongDesc = ktemplates.LongDesc(`Validate the manifest without building the GitOps tree
+
+The manifest is checked for invalid and duplicate names and missing fields, the TriggerBindings that the services reference must resolve, and their webhook secrets must be in the CI/CD namespace.  All of the problems are printed, and the command exits non-zero if there are any.`)
+	validateShortDesc = `Validate the manifest`
+)
+
+// ValidateParameters encapsulates the parameters for the kam manifest validate
+// command.
+type ValidateParameters struct {
+	pipelinesFolderPath string
+}
+
+// NewValidateParameters bootstraps a ValidateParameters instance.
+func NewValidateParameters() *ValidateParameters {
+	return &ValidateParameters{}
+}
+
+// Complete completes ValidateParameters after they've been created.
+func (vp *ValidateParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the ValidateParameters.
+func (vp *ValidateParameters) Validate() error {
+	return nil
+}
+
+// Run runs the manifest validate command.
+func (vp *ValidateParameters) Run() error {
+	if err := pipelines.ValidateManifest(ioutils.NewFilesystem(), vp.pipelinesFolderPath); err != nil {
+		return err
+	}
+	log.Success("The manifest is valid.")
+	return nil
+}
+
+func newCmdValidate(name, fullName string) *cobra.Command {
+	o := NewValidateParameters()
+	cmd := &cobra.Command{
+		Use:     name,
+		Short:   validateShortDesc,
+		Long:    validateLongDesc,
+		Example: fmt.Sprintf(validateExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	return cmd
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/cmd/utility"
+	"github.com/redhat-developer/kam/pkg/pipelines/namespaces"
+	"github.com/spf13/cobra"
+
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// NamespacesRecommendedCommandName the recommended command name
+	NamespacesRecommendedCommandName = "namespaces"
+
+	namespacesOutputText = "text"
+	namespacesOutputJSON = "json"
+)
+
+var (
+	namespacesExample = ktemplates.Examples(`
+	# Print the namespaces that bootstrap generates with the prefix
+	%[1]s --prefix tst
+
+	# Print the namespaces as JSON
+	%[1]s --prefix tst --output json
+
+	# Print the namespaces for other environments
+	%[1]s --prefix tst --environments sandbox,integration
+	`)
+
+	namespacesLongDesc = ktemplates.LongDesc(`Print the names of the namespaces that bootstrap generates with the prefix
+
+The prefix is completed in the same way as bootstrap, e.g. "tst" generates "tst-dev".`)
+	namespacesShortDesc = `Print the namespace names for a prefix`
+)
+
+// NamespacesParameters encapsulates the parameters for the kam namespaces
+// command.
+type NamespacesParameters struct {
+	prefix       string
+	environments string
+	output       string
+}
+
+// NewNamespacesParameters bootstraps a NamespacesParameters instance.
+func NewNamespacesParameters() *NamespacesParameters {
+	return &NamespacesParameters{}
+}
+
+// Complete completes NamespacesParameters after they've been created.
+func (np *NamespacesParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	np.prefix = utility.MaybeCompletePrefix(np.prefix)
+	return nil
+}
+
+// Validate validates the parameters of the NamespacesParameters.
+func (np *NamespacesParameters) Validate() error {
+	if np.output != namespacesOutputText && np.output != namespacesOutputJSON {
+		return fmt.Errorf("invalid output format: %q, must be one of %s, %s", np.output, namespacesOutputText, namespacesOutputJSON)
+	}
+	return nil
+}
+
+// Run runs the namespaces command.
+func (np *NamespacesParameters) Run() error {
+	return printNamespaces(os.Stdout, namespaces.EnvironmentNamesWithPrefix(np.prefix, namespaces.SplitEnvironments(np.environments)), np.output)
+}
+
+// printNamespaces writes the namespace names keyed by the environment that
+// they are for, ordered by environment.
+func printNamespaces(out io.Writer, names map[string]string, format string) error {
+	if format == namespacesOutputJSON {
+		b, err := json.MarshalIndent(names, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal the namespaces: %w", err)
+		}
+		_, err = fmt.Fprintf(out, "%s\n", b)
+		return err
+	}
+	envs := []string{}
+	for env := range names {
+		envs = append(envs, env)
+	}
+	sort.Strings(envs)
+	w := tabwriter.NewWriter(out, 5, 2, 3, ' ', tabwriter.TabIndent)
+	fmt.Fprintln(w, "ENVIRONMENT\tNAMESPACE")
+	for _, env := range envs {
+		fmt.Fprintf(w, "%s\t%s\n", env, names[env])
+	}
+	return w.Flush()
+}
+
+// NewCmdNamespaces creates the namespaces command.
+func NewCmdNamespaces(name, fullName string) *cobra.Command {
+	o := NewNamespacesParameters()
+	namespacesCmd := &cobra.Command{
+		Use:     name,
+		Short:   namespacesShortDesc,
+		Long:    namespacesLongDesc,
+		Example: fmt.Sprintf(namespacesExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	namespacesCmd.Flags().StringVarP(&o.prefix, "prefix", "p", "", "The prefix that is added to the environment names, in the same way as bootstrap")
+	namespacesCmd.Flags().StringVar(&o.environments, "environments", "", "Comma separated names of the environments, in the same way as bootstrap (defaults to dev,stage)")
+	namespacesCmd.Flags().StringVar(&o.output, "output", namespacesOutputText, fmt.Sprintf("The output format, one of %s, %s", namespacesOutputText, namespacesOutputJSON))
+	return namespacesCmd
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+)
+
+func TestPrintNamespaces(t *testing.T) {
+	names := map[string]string{"stage": "tst-stage", "cicd": "tst-cicd", "dev": "tst-dev"}
+	outputTests := []struct {
+		format string
+		want   string
+	}{
+		{namespacesOutputText, "ENVIRONMENT   NAMESPACE\ncicd          tst-cicd\ndev           tst-dev\nstage         tst-stage\n"},
+		{namespacesOutputJSON, "{\n  \"cicd\": \"tst-cicd\",\n  \"dev\": \"tst-dev\",\n  \"stage\": \"tst-stage\"\n}\n"},
+	}
+	for _, tt := range outputTests {
+		t.Run(tt.format, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := printNamespaces(&b, names, tt.format); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, b.String()); diff != "" {
+				t.Fatalf("namespaces output didn't match:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNamespacesCompletesPrefix(t *testing.T) {
+	o := &NamespacesParameters{prefix: "tst", output: namespacesOutputText}
+	if err := o.Complete(NamespacesRecommendedCommandName, &cobra.Command{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if o.prefix != "tst-" {
+		t.Fatalf("got prefix %q, want %q", o.prefix, "tst-")
+	}
+}
+
+func TestValidateNamespacesOutput(t *testing.T) {
+	o := &NamespacesParameters{output: "yaml"}
+	assertError(t, o.Validate(), `invalid output format: "yaml", must be one of text, json`)
+}
@@ -70,11 +70,13 @@ func newCmdAdd(name, fullName string) *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&o.GitRepoURL, "git-repo-url", "", "Service repository URL e.g. https://github.com/organisation/repository - only needed when you need to rebuild the source image for the environment")
+	cmd.Flags().StringVar(&o.GitRepoURL, "service-repo-url", "", "Service repository URL, the same as --git-repo-url")
 	cmd.Flags().StringVar(&o.WebhookSecret, "webhook-secret", "", "Source Git repository webhook secret (if not provided, it will be auto-generated)")
 	cmd.Flags().StringVar(&o.AppName, "app-name", "", "Name of the application where the service will be added")
 	cmd.Flags().StringVar(&o.ServiceName, "service-name", "", "Name of the service to be added")
 	cmd.Flags().StringVar(&o.EnvName, "env-name", "", "Name of the environment where the service will be added")
 	cmd.Flags().StringVar(&o.ImageRepo, "image-repo", "", "Image registry of the form <registry>/<username>/<image name> or <project>/<app> which is used to push newly built images")
+	cmd.Flags().BoolVar(&o.Overwrite, "overwrite", false, "If true, replace a service with the same name in the application")
 	cmd.Flags().StringVar(&o.PipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
 
 	// required flags
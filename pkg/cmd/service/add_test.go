@@ -39,6 +39,16 @@ func TestCompleteAddOptions(t *testing.T) {
 	}
 }
 
+func TestAddCommandServiceRepoURL(t *testing.T) {
+	cmd := newCmdAdd("add", "kam service")
+	if err := cmd.Flags().Set("service-repo-url", "https://github.com/test/org"); err != nil {
+		t.Fatal(err)
+	}
+	if got := cmd.Flags().Lookup("git-repo-url").Value.String(); got != "https://github.com/test/org" {
+		t.Fatalf("got git-repo-url %q, want %q", got, "https://github.com/test/org")
+	}
+}
+
 func TestAddCommandWithMissingParams(t *testing.T) {
 	cmdTests := []struct {
 		desc    string
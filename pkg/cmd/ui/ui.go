@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"net/url"
 	"path/filepath"
 	"strings"
 
@@ -128,14 +129,15 @@ func EnterGitWebhookSecret(repoURL string) string {
 }
 
 // EnterGitHostAccessToken , it becomes necessary to add the personal access
-// token to access upstream git hosts.
-func EnterGitHostAccessToken(serviceRepo string) string {
+// token to access upstream git hosts, the token is checked through the proxy
+// at proxyURL, if any.
+func EnterGitHostAccessToken(serviceRepo string, proxyURL *url.URL) string {
 	var accessToken string
 	prompt := &survey.Password{
 		Message: fmt.Sprintf("Please provide a token used to authenticate requests to %q", serviceRepo),
 		Help:    "Tokens are required to authenticate to git provider various operations on git repository (e.g. enable automated creation/push to git-repo).",
 	}
-	err := survey.AskOne(prompt, &accessToken, makeAccessTokenCheck(serviceRepo))
+	err := survey.AskOne(prompt, &accessToken, makeAccessTokenCheck(serviceRepo, proxyURL))
 	handleError(err)
 	return accessToken
 }
@@ -204,13 +206,27 @@ func SelectOptionOverwrite(currentPath string) bool {
 	return overwrite == "yes"
 }
 
+// SelectOptionDelete asks users to confirm that the bootstrapped folders
+// should be deleted.
+func SelectOptionDelete(paths []string) bool {
+	var confirm string
+	prompt := &survey.Select{
+		Message: "Do you want to delete the bootstrapped folders?",
+		Help:    "Delete: " + strings.Join(paths, ", "),
+		Options: []string{"yes", "no"},
+		Default: "no",
+	}
+	handleError(survey.AskOne(prompt, &confirm, nil))
+	return confirm == "yes"
+}
+
 // SelectPrivateRepoDriver lets users choose the driver for their git hosting
 // service.
 func SelectPrivateRepoDriver() string {
 	var driver string
 	prompt := &survey.Select{
 		Message: "Please select which driver to use for your Git host",
-		Options: []string{"github", "gitlab"},
+		Options: []string{"github", "gitlab", "azure"},
 	}
 
 	err := survey.AskOne(prompt, &driver, survey.Required)
@@ -29,9 +29,9 @@ func makeSecretValidator() survey.Validator {
 	}
 }
 
-func makeAccessTokenCheck(serviceRepo string) survey.Validator {
+func makeAccessTokenCheck(serviceRepo string, proxyURL *url.URL) survey.Validator {
 	return func(input interface{}) error {
-		return ValidateAccessToken(input, serviceRepo)
+		return ValidateAccessToken(input, serviceRepo, proxyURL)
 	}
 }
 
@@ -72,6 +72,12 @@ func ValidateName(name string) error {
 	return nil
 }
 
+// ValidateSecret validates that a webhook secret that wasn't entered at the
+// prompt is at least the minimum length, an empty secret is auto-generated.
+func ValidateSecret(secret string) error {
+	return validateSecretLength(secret)
+}
+
 func validateSecretLength(input interface{}) error {
 	if s, ok := input.(string); ok {
 		err := checkSecretLength(s)
@@ -83,10 +89,11 @@ func validateSecretLength(input interface{}) error {
 	return nil
 }
 
-// ValidateAccessToken validates if the access token is correct for a particular service repo
-func ValidateAccessToken(input interface{}, serviceRepo string) error {
+// ValidateAccessToken validates if the access token is correct for a particular service repo,
+// the requests are sent through the proxy at proxyURL, if any.
+func ValidateAccessToken(input interface{}, serviceRepo string, proxyURL *url.URL) error {
 	if s, ok := input.(string); ok {
-		repo, err := git.NewRepository(serviceRepo, s)
+		repo, err := git.NewRepositoryWithProxy(serviceRepo, s, proxyURL)
 		if err != nil {
 			return fmt.Errorf("%w. %s", err, "Check that the --private-repo-driver option is provided.")
 		}
@@ -55,7 +55,7 @@ func TestValidateSecretLength(t *testing.T) {
 
 func TestAccessToken(t *testing.T) {
 	mockurl := "https://github.com/example/test.git"
-	validator := makeAccessTokenCheck(mockurl)
+	validator := makeAccessTokenCheck(mockurl, nil)
 	cmdTests := []struct {
 		desc     string
 		argument string
@@ -78,7 +78,7 @@ func TestAccessToken(t *testing.T) {
 
 func TestAccessTokenForEnterpriseGitLab(t *testing.T) {
 	mockurl := "https://gitlab.cee.redhat.com/example/test.git"
-	validator := makeAccessTokenCheck(mockurl)
+	validator := makeAccessTokenCheck(mockurl, nil)
 	cmdTests := []struct {
 		desc     string
 		argument string
@@ -103,6 +103,25 @@ func (c *Client) CheckIfPipelinesExists(ns string) error {
 	return nil
 }
 
+// ServedVersions returns the versions of the API group that are served by the
+// cluster, this is empty if the group is not served.
+func (c *Client) ServedVersions(group string) ([]string, error) {
+	groups, err := c.KubeClient.Discovery().ServerGroups()
+	if err != nil {
+		return nil, err
+	}
+	versions := []string{}
+	for _, g := range groups.Groups {
+		if g.Name != group {
+			continue
+		}
+		for _, v := range g.Versions {
+			versions = append(versions, v.Version)
+		}
+	}
+	return versions, nil
+}
+
 // GetFullName generates a command's full name based on its parent's full name and its own name
 func GetFullName(parentName, name string) string {
 	return parentName + " " + name
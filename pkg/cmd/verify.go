@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/spf13/cobra"
+
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// VerifyRecommendedCommandName the recommended command name
+	VerifyRecommendedCommandName = "verify"
+)
+
+var (
+	verifyExample = ktemplates.Examples(`
+	# Verify that the GitOps tree in the current folder matches its manifest
+	%[1]s
+
+	# Verify the GitOps tree in another folder
+	%[1]s --pipelines-folder ./gitops
+	`)
+
+	verifyLongDesc = ktemplates.LongDesc(`Verify that the generated files in a GitOps tree match the files that build generates from its manifest
+
+The files that differ, are missing, or are extra are printed, and the command exits non-zero if there are any.  The tree isn't modified.`)
+	verifyShortDesc = `Verify the GitOps tree matches its manifest`
+)
+
+// VerifyParameters encapsulates the parameters for the kam verify command.
+type VerifyParameters struct {
+	pipelinesFolderPath string
+}
+
+// NewVerifyParameters bootstraps a VerifyParameters instance.
+func NewVerifyParameters() *VerifyParameters {
+	return &VerifyParameters{}
+}
+
+// Complete completes VerifyParameters after they've been created.
+func (vp *VerifyParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the VerifyParameters.
+func (vp *VerifyParameters) Validate() error {
+	return nil
+}
+
+// Run runs the verify command.
+func (vp *VerifyParameters) Run() error {
+	drift, err := pipelines.VerifyTree(ioutils.NewFilesystem(), vp.pipelinesFolderPath)
+	if err != nil {
+		return err
+	}
+	if drift.Empty() {
+		log.Success("The GitOps tree matches the manifest.")
+		return nil
+	}
+	printDrift(os.Stdout, drift)
+	return errors.New("the GitOps tree has drifted from the manifest, run build to regenerate it")
+}
+
+// printDrift writes each of the files that have drifted, with how they
+// differ.
+func printDrift(out io.Writer, drift *pipelines.Drift) {
+	for _, f := range drift.Changed {
+		fmt.Fprintf(out, "changed: %s\n", f)
+	}
+	for _, f := range drift.Missing {
+		fmt.Fprintf(out, "missing: %s\n", f)
+	}
+	for _, f := range drift.Extra {
+		fmt.Fprintf(out, "extra: %s\n", f)
+	}
+}
+
+// NewCmdVerify creates the verify command.
+func NewCmdVerify(name, fullName string) *cobra.Command {
+	o := NewVerifyParameters()
+	verifyCmd := &cobra.Command{
+		Use:     name,
+		Short:   verifyShortDesc,
+		Long:    verifyLongDesc,
+		Example: fmt.Sprintf(verifyExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	verifyCmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path of the GitOps tree, eg. /test where manifest exists at /test/pipelines.yaml")
+	return verifyCmd
+}
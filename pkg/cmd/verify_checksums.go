@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/spf13/cobra"
+
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// VerifyChecksumsRecommendedCommandName the recommended command name
+	VerifyChecksumsRecommendedCommandName = "verify-checksums"
+)
+
+var (
+	verifyChecksumsExample = ktemplates.Examples(`
+	# Verify the files in the GitOps tree in the current folder against its checksums
+	%[1]s
+
+	# Verify the GitOps tree in another folder
+	%[1]s --pipelines-folder ./gitops
+	`)
+
+	verifyChecksumsLongDesc = ktemplates.LongDesc(`Verify the files in a GitOps tree against the checksums that were written with --write-checksums
+
+The files that have changed or are missing since the checksums were written are printed, and the command exits non-zero if there are any.  Files without checksums aren't verified.`)
+	verifyChecksumsShortDesc = `Verify the GitOps tree matches its checksums`
+)
+
+// VerifyChecksumsParameters encapsulates the parameters for the kam
+// verify-checksums command.
+type VerifyChecksumsParameters struct {
+	pipelinesFolderPath string
+}
+
+// NewVerifyChecksumsParameters bootstraps a VerifyChecksumsParameters
+// instance.
+func NewVerifyChecksumsParameters() *VerifyChecksumsParameters {
+	return &VerifyChecksumsParameters{}
+}
+
+// Complete completes VerifyChecksumsParameters after they've been created.
+func (vp *VerifyChecksumsParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the VerifyChecksumsParameters.
+func (vp *VerifyChecksumsParameters) Validate() error {
+	return nil
+}
+
+// Run runs the verify-checksums command.
+func (vp *VerifyChecksumsParameters) Run() error {
+	drift, err := pipelines.VerifyChecksums(ioutils.NewFilesystem(), vp.pipelinesFolderPath)
+	if err != nil {
+		return err
+	}
+	if drift.Empty() {
+		log.Success("The GitOps tree matches the checksums.")
+		return nil
+	}
+	printDrift(os.Stdout, drift)
+	return errors.New("the GitOps tree doesn't match the checksums, the files have been modified since they were generated")
+}
+
+// NewCmdVerifyChecksums creates the verify-checksums command.
+func NewCmdVerifyChecksums(name, fullName string) *cobra.Command {
+	o := NewVerifyChecksumsParameters()
+	verifyCmd := &cobra.Command{
+		Use:     name,
+		Short:   verifyChecksumsShortDesc,
+		Long:    verifyChecksumsLongDesc,
+		Example: fmt.Sprintf(verifyChecksumsExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	verifyCmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", fmt.Sprintf("Folder path of the GitOps tree, eg. /test where the checksums exist at /test/%s", pipelines.ChecksumsFile))
+	return verifyCmd
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines"
+)
+
+func TestPrintDrift(t *testing.T) {
+	drift := &pipelines.Drift{
+		Changed: []string{"config/argocd/tst-dev-env-app.yaml"},
+		Missing: []string{"config/argocd/tst-stage-env-app.yaml"},
+		Extra:   []string{"config/argocd/tst-prod-env-app.yaml"},
+	}
+	var b bytes.Buffer
+	printDrift(&b, drift)
+
+	want := "changed: config/argocd/tst-dev-env-app.yaml\nmissing: config/argocd/tst-stage-env-app.yaml\nextra: config/argocd/tst-prod-env-app.yaml\n"
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Fatalf("drift output didn't match:\n%s", diff)
+	}
+}
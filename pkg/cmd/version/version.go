@@ -1,25 +1,161 @@
 package version
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"text/tabwriter"
 
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
 	"github.com/spf13/cobra"
+
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
 )
 
 // RecommendedCommandName is the recommended command name.
 const RecommendedCommandName = "version"
 
+const (
+	outputText = "text"
+	outputJSON = "json"
+
+	tektonPipelineModule = "github.com/tektoncd/pipeline"
+	tektonTriggersModule = "github.com/tektoncd/triggers"
+
+	// The Argo CD types are copied into pkg/pipelines/argocd/v1alpha1 rather
+	// than imported, so the API version is reported instead of a module.
+	argoCDAPIVersion = "argoproj.io/v1alpha1"
+
+	unknownVersion = "unknown"
+)
+
 // Version is populated by the versioning information at compile time.  See the VERSION marco in Makefile.
 var Version string
 
+// Commit is the git commit that kam was built from, populated at compile time.
+var Commit string
+
+// BuildDate is the date that kam was built, populated at compile time.
+var BuildDate string
+
+var (
+	versionExample = ktemplates.Examples(`
+	# Print the version information
+	%[1]s
+
+	# Print the version information as JSON, e.g. to attach to an issue
+	%[1]s --output json
+	`)
+
+	versionLongDesc = ktemplates.LongDesc(`Print the version information
+
+The version, git commit and build date of kam, and the versions of the Tekton Pipelines and Triggers modules, and the Argo CD API, that the generated resources are built against.`)
+)
+
+// Info is the version information of the kam build.
+type Info struct {
+	Version        string `json:"version"`
+	Commit         string `json:"commit"`
+	BuildDate      string `json:"buildDate"`
+	TektonPipeline string `json:"tektonPipeline"`
+	TektonTriggers string `json:"tektonTriggers"`
+	ArgoCD         string `json:"argoCD"`
+}
+
+// Parameters encapsulates the parameters for the kam version command.
+type Parameters struct {
+	output string
+}
+
+// Complete completes Parameters after they've been created.
+func (p *Parameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the Parameters.
+func (p *Parameters) Validate() error {
+	if p.output != outputText && p.output != outputJSON {
+		return fmt.Errorf("invalid output format: %q, must be one of %s, %s", p.output, outputText, outputJSON)
+	}
+	return nil
+}
+
+// Run runs the version command.
+func (p *Parameters) Run() error {
+	return printInfo(os.Stdout, buildInfo(debug.ReadBuildInfo()), p.output)
+}
+
+// buildInfo returns the version information from the values populated at
+// compile time, and the module versions from the build information, if
+// any.
+func buildInfo(bi *debug.BuildInfo, ok bool) Info {
+	info := Info{
+		Version:        valueOrUnknown(Version),
+		Commit:         valueOrUnknown(Commit),
+		BuildDate:      valueOrUnknown(BuildDate),
+		TektonPipeline: unknownVersion,
+		TektonTriggers: unknownVersion,
+		ArgoCD:         argoCDAPIVersion,
+	}
+	if !ok {
+		return info
+	}
+	for _, dep := range bi.Deps {
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		switch dep.Path {
+		case tektonPipelineModule:
+			info.TektonPipeline = dep.Version
+		case tektonTriggersModule:
+			info.TektonTriggers = dep.Version
+		}
+	}
+	return info
+}
+
+// printInfo writes the version information, the first line of the text format
+// is the same as earlier versions of kam.
+func printInfo(out io.Writer, info Info, format string) error {
+	if format == outputJSON {
+		b, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal the version information: %w", err)
+		}
+		_, err = fmt.Fprintf(out, "%s\n", b)
+		return err
+	}
+	fmt.Fprintf(out, "kam version %s\n", info.Version)
+	w := tabwriter.NewWriter(out, 5, 2, 3, ' ', tabwriter.TabIndent)
+	fmt.Fprintf(w, "Commit:\t%s\n", info.Commit)
+	fmt.Fprintf(w, "Build date:\t%s\n", info.BuildDate)
+	fmt.Fprintf(w, "Tekton Pipelines:\t%s\n", info.TektonPipeline)
+	fmt.Fprintf(w, "Tekton Triggers:\t%s\n", info.TektonTriggers)
+	fmt.Fprintf(w, "Argo CD API:\t%s\n", info.ArgoCD)
+	return w.Flush()
+}
+
+func valueOrUnknown(s string) string {
+	if s == "" {
+		return unknownVersion
+	}
+	return s
+}
+
 // NewCmd creates a new command
 func NewCmd(name, fullName string) *cobra.Command {
-	return &cobra.Command{
-		Use:   name,
-		Short: "Print the version information",
-		Long:  "Print the version information",
+	o := &Parameters{}
+	versionCmd := &cobra.Command{
+		Use:     name,
+		Short:   "Print the version information",
+		Long:    versionLongDesc,
+		Example: fmt.Sprintf(versionExample, fullName),
 		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Printf("kam version %s\n", Version)
+			genericclioptions.GenericRun(o, cmd, args)
 		},
 	}
+	versionCmd.Flags().StringVar(&o.output, "output", outputText, fmt.Sprintf("The output format, one of %s, %s", outputText, outputJSON))
+	return versionCmd
 }
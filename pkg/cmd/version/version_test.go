@@ -0,0 +1,70 @@
+package version
+
+import (
+	"bytes"
+	"runtime/debug"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBuildInfo(t *testing.T) {
+	defer func(v, c, d string) { Version, Commit, BuildDate = v, c, d }(Version, Commit, BuildDate)
+	Version, Commit, BuildDate = "v0.0.40-1-gabcdef0", "abcdef0", ""
+
+	bi := &debug.BuildInfo{
+		Deps: []*debug.Module{
+			{Path: "github.com/spf13/cobra", Version: "v1.1.3"},
+			{Path: tektonPipelineModule, Version: "v0.22.0"},
+			{Path: tektonTriggersModule, Version: "v0.12.1", Replace: &debug.Module{Path: tektonTriggersModule, Version: "v0.12.2"}},
+		},
+	}
+	want := Info{
+		Version:        "v0.0.40-1-gabcdef0",
+		Commit:         "abcdef0",
+		BuildDate:      "unknown",
+		TektonPipeline: "v0.22.0",
+		TektonTriggers: "v0.12.2",
+		ArgoCD:         "argoproj.io/v1alpha1",
+	}
+	if diff := cmp.Diff(want, buildInfo(bi, true)); diff != "" {
+		t.Fatalf("version information didn't match:\n%s", diff)
+	}
+}
+
+func TestPrintInfo(t *testing.T) {
+	info := Info{
+		Version:        "v0.0.40",
+		Commit:         "abcdef0",
+		BuildDate:      "2021-06-01T10:00:00Z",
+		TektonPipeline: "v0.22.0",
+		TektonTriggers: "v0.12.1",
+		ArgoCD:         "argoproj.io/v1alpha1",
+	}
+	outputTests := []struct {
+		format string
+		want   string
+	}{
+		{outputText, "kam version v0.0.40\nCommit:             abcdef0\nBuild date:         2021-06-01T10:00:00Z\nTekton Pipelines:   v0.22.0\nTekton Triggers:    v0.12.1\nArgo CD API:        argoproj.io/v1alpha1\n"},
+		{outputJSON, "{\n  \"version\": \"v0.0.40\",\n  \"commit\": \"abcdef0\",\n  \"buildDate\": \"2021-06-01T10:00:00Z\",\n  \"tektonPipeline\": \"v0.22.0\",\n  \"tektonTriggers\": \"v0.12.1\",\n  \"argoCD\": \"argoproj.io/v1alpha1\"\n}\n"},
+	}
+	for _, tt := range outputTests {
+		t.Run(tt.format, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := printInfo(&b, info, tt.format); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, b.String()); diff != "" {
+				t.Fatalf("version output didn't match:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateOutput(t *testing.T) {
+	err := (&Parameters{output: "yaml"}).Validate()
+	want := `invalid output format: "yaml", must be one of text, json`
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v, want %s", err, want)
+	}
+}
@@ -18,7 +18,10 @@ const createRecommendedCommandName = "create"
 
 var (
 	createExample = ktemplates.Examples(`	# Create a new Git repository webhook 
-	%[1]s`)
+	%[1]s
+
+	# Create the webhook for the GitOps repository after pushing it, delivering events to the EventListener Route's host
+	%[1]s --repo-type gitops --webhook-url https://gitops-webhook-event-listener-route-cicd.apps.example.com`)
 )
 
 type createOptions struct {
@@ -27,7 +30,7 @@ type createOptions struct {
 
 // Run contains the logic for the kam command
 func (o *createOptions) Run() error {
-	id, err := backend.Create(o.accessToken, o.pipelinesFolderPath, o.getAppServiceNames(), o.isCICD)
+	id, err := backend.Create(o.accessToken, o.pipelinesFolderPath, o.getAppServiceNames(), o.isCICD, o.events, o.webhookURL)
 
 	if err != nil {
 		return fmt.Errorf("unable to create webhook: %v", err)
@@ -61,6 +64,7 @@ func newCmdCreate(name, fullName string) *cobra.Command {
 	}
 
 	o.setFlags(command)
+	command.Flags().StringSliceVar(&o.events, "events", nil, "Comma separated webhook events to deliver e.g. push,release, the events are validated for the Git host: GitHub supports push, pull_request, issue_comment and release, GitLab supports push, merge_request, tag_push, note and release (defaults to push and pull_request or merge_request, which the EventListener triggers handle)")
 	return command
 }
 
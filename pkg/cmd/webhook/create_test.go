@@ -77,6 +77,58 @@ func TestValidateForCreate(t *testing.T) {
 	}
 }
 
+func TestValidateForCreateWithRepoType(t *testing.T) {
+	testcases := []struct {
+		options *createOptions
+		errMsg  string
+	}{
+		{
+			&createOptions{options{repoType: "gitops"}},
+			"",
+		},
+		{
+			&createOptions{options{repoType: "gitops", serviceName: "foo", envName: "gau"}},
+			"Only one of 'cicd' or 'env-name/service-name' can be specified",
+		},
+		{
+			&createOptions{options{repoType: "service", serviceName: "foo", envName: "gau"}},
+			"",
+		},
+		{
+			&createOptions{options{repoType: "service"}},
+			"One of 'cicd' or 'env-name/service-name' must be specified",
+		},
+		{
+			&createOptions{options{repoType: "service", isCICD: true}},
+			"--repo-type service can't be used with --cicd",
+		},
+		{
+			&createOptions{options{repoType: "config"}},
+			`invalid --repo-type "config", must be one of gitops, service`,
+		},
+		{
+			&createOptions{options{repoType: "gitops", webhookURL: "https://listener.apps.example.com"}},
+			"",
+		},
+		{
+			&createOptions{options{repoType: "gitops", webhookURL: "listener.apps.example.com"}},
+			`invalid --webhook-url "listener.apps.example.com", must be an http or https URL`,
+		},
+	}
+
+	for i, tt := range testcases {
+		t.Run(fmt.Sprintf("Test %d", i), func(t *testing.T) {
+			if err := tt.options.Complete("create", &cobra.Command{}, nil); err != nil {
+				t.Fatal(err)
+			}
+			err := tt.options.Validate()
+			if !matchError(t, tt.errMsg, err) {
+				t.Errorf("Validate() failed to match error: got %v, want %s", err, tt.errMsg)
+			}
+		})
+	}
+}
+
 func executeCommand(cmd *cobra.Command, flags ...keyValuePair) (output string, err error) {
 	buf := new(bytes.Buffer)
 	cmd.SetOutput(buf)
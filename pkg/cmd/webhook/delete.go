@@ -26,7 +26,7 @@ type deleteOptions struct {
 
 // Run contains the logic for the kam command
 func (o *deleteOptions) Run() error {
-	ids, err := backend.Delete(o.accessToken, o.pipelinesFolderPath, o.getAppServiceNames(), o.isCICD)
+	ids, err := backend.Delete(o.accessToken, o.pipelinesFolderPath, o.getAppServiceNames(), o.isCICD, o.webhookURL)
 
 	if len(ids) > 0 {
 		if log.IsJSON() {
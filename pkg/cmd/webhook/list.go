@@ -26,7 +26,7 @@ type listOptions struct {
 
 // Run contains the logic for the kam command
 func (o *listOptions) Run() error {
-	ids, err := backend.List(o.accessToken, o.pipelinesFolderPath, o.getAppServiceNames(), o.isCICD)
+	ids, err := backend.List(o.accessToken, o.pipelinesFolderPath, o.getAppServiceNames(), o.isCICD, o.webhookURL)
 	if err != nil {
 		return fmt.Errorf("unable to a get list of webhook IDs: %v", err)
 	}
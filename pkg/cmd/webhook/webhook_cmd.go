@@ -2,23 +2,34 @@ package webhook
 
 import (
 	"fmt"
+	"net/url"
 
 	"github.com/spf13/cobra"
 
 	backend "github.com/redhat-developer/kam/pkg/pipelines/webhook"
 )
 
+const (
+	repoTypeGitOps  = "gitops"
+	repoTypeService = "service"
+)
+
 type options struct {
 	accessToken         string
 	envName             string
 	isCICD              bool
 	pipelinesFolderPath string
 	serviceName         string
+	events              []string
+	repoType            string
+	webhookURL          string
 }
 
 // Complete completes createOptions after they've been created
 func (o *options) Complete(name string, cmd *cobra.Command, args []string) (err error) {
-
+	if o.repoType == repoTypeGitOps {
+		o.isCICD = true
+	}
 	return nil
 
 }
@@ -26,6 +37,23 @@ func (o *options) Complete(name string, cmd *cobra.Command, args []string) (err
 // Validate validates the createOptions based on completed values
 func (o *options) Validate() (err error) {
 
+	switch o.repoType {
+	case "", repoTypeGitOps:
+	case repoTypeService:
+		if o.isCICD {
+			return fmt.Errorf("--repo-type %s can't be used with --cicd", repoTypeService)
+		}
+	default:
+		return fmt.Errorf("invalid --repo-type %q, must be one of %s, %s", o.repoType, repoTypeGitOps, repoTypeService)
+	}
+
+	if o.webhookURL != "" {
+		u, err := url.Parse(o.webhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid --webhook-url %q, must be an http or https URL", o.webhookURL)
+		}
+	}
+
 	if o.isCICD {
 		if o.serviceName != "" || o.envName != "" {
 			return fmt.Errorf("Only one of 'cicd' or 'env-name/service-name' can be specified")
@@ -49,6 +77,10 @@ func (o *options) setFlags(command *cobra.Command) {
 
 	// cicd option
 	command.Flags().BoolVar(&o.isCICD, "cicd", false, "Provide this flag if the target Git repository is a CI/CD configuration repository")
+	command.Flags().StringVar(&o.repoType, "repo-type", "", fmt.Sprintf("The type of the target Git repository, one of %s, %s, %s is the same as --cicd, and %s requires --env-name and --service-name", repoTypeGitOps, repoTypeService, repoTypeGitOps, repoTypeService))
+
+	// listener option
+	command.Flags().StringVar(&o.webhookURL, "webhook-url", "", "The URL that the webhook delivers events to, e.g. the host of the generated EventListener Route, if not provided, the URL of the EventListener Route is read from the cluster")
 
 	// service option
 	command.Flags().StringVar(&o.serviceName, "service-name", "", "Provide service name if the target Git repository is a service's source repository.")
@@ -1,8 +1,12 @@
 package argocd
 
 import (
+	"fmt"
+	"net/url"
+	"path"
 	"path/filepath"
 	"sort"
+	"strings"
 
 	// This is a hack because ArgoCD doesn't support a compatible (code-wise)
 	// version of k8s in common with kam.
@@ -10,13 +14,25 @@ import (
 	argoappv1 "github.com/redhat-developer/kam/pkg/pipelines/argocd/v1alpha1"
 	"github.com/redhat-developer/kam/pkg/pipelines/roles"
 	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	"github.com/redhat-developer/kam/pkg/pipelines/openapi"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
 )
 
-const appLabel = "app.kubernetes.io/name"
+const (
+	appLabel = "app.kubernetes.io/name"
+
+	imageUpdaterPrefix          = "argocd-image-updater.argoproj.io/"
+	defaultImageUpdateStrategy  = "latest"
+	defaultImageWriteBackMethod = "git"
+
+	serverSideApplyOption = "ServerSideApply"
+	createNamespaceOption = "CreateNamespace"
+)
 
 var (
 	applicationTypeMeta = meta.TypeMeta(
@@ -24,6 +40,25 @@ var (
 		"argoproj.io/v1alpha1",
 	)
 
+	applicationSetTypeMeta = meta.TypeMeta(
+		"ApplicationSet",
+		"argoproj.io/v1alpha1",
+	)
+
+	appProjectTypeMeta = meta.TypeMeta(
+		"AppProject",
+		"argoproj.io/v1alpha1",
+	)
+
+	// The cluster-scoped resources that are generated, which the AppProject
+	// must allow.
+	clusterResourceWhitelist = []metav1.GroupKind{
+		{Group: "", Kind: "Namespace"},
+		{Group: "project.openshift.io", Kind: "ProjectRequest"},
+		{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"},
+		{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"},
+	}
+
 	syncPolicy = &argoappv1.SyncPolicy{
 		Automated: &argoappv1.SyncPolicyAutomated{
 			Prune:    true,
@@ -83,12 +118,12 @@ func Build(argoNS, repoURL string, m *config.Manifest) (res.Resources, error) {
 	}
 
 	files := make(res.Resources)
-	eb := &argocdBuilder{repoURL: repoURL, files: files, argoCDConfig: argoCDConfig, argoNS: argoNS}
+	eb := &argocdBuilder{repoURL: repoURL, files: files, argoCDConfig: argoCDConfig, argoNS: argoNS, repoSubpath: m.RepoSubpath, perEnvOverlays: m.UsePerEnvOverlays(), project: projectName(argoCDConfig)}
 	err := m.Walk(eb)
 	if err != nil {
 		return nil, err
 	}
-	err = argoCDConfigResources(m.Config, m.GitOpsURL, eb.files)
+	err = argoCDConfigResources(m.Config, m.GitOpsURL, m.RepoSubpath, eb.files)
 	if err != nil {
 		return nil, err
 	}
@@ -96,10 +131,13 @@ func Build(argoNS, repoURL string, m *config.Manifest) (res.Resources, error) {
 }
 
 type argocdBuilder struct {
-	repoURL      string
-	argoCDConfig *config.ArgoCDConfig
-	files        res.Resources
-	argoNS       string
+	repoURL        string
+	argoCDConfig   *config.ArgoCDConfig
+	files          res.Resources
+	argoNS         string
+	repoSubpath    string
+	perEnvOverlays bool
+	project        string
 }
 
 func (b *argocdBuilder) Application(env *config.Environment, app *config.Application) error {
@@ -107,11 +145,26 @@ func (b *argocdBuilder) Application(env *config.Environment, app *config.Applica
 	argoFiles := res.Resources{}
 	filename := filepath.ToSlash(filepath.Join(basePath, env.Name+"-"+app.Name+"-app.yaml"))
 
-	argoFiles[filename] = makeApplication(app, env.Name+"-"+app.Name, b.argoNS,
-		defaultProject,
+	argoApp := withSyncPolicy(makeApplication(app, env.Name+"-"+app.Name, b.appNamespace(env),
+		b.project,
 		env.Name,
 		clusterForEnv(env),
-		makeAppSource(env, app, b.repoURL))
+		makeAppSource(env, app, b.repoURL, b.repoSubpath)), b.argoCDConfig, env.IsAutoSync(), applyModeSyncOptions(b.argoCDConfig, appSyncOptions(env, app)))
+	if b.argoCDConfig.ImageUpdater != nil {
+		argoApp.Annotations = imageUpdaterAnnotations(b.argoCDConfig.ImageUpdater, app)
+	}
+	argoApp.Spec.IgnoreDifferences = appIgnoreDifferences(env, app)
+	argoFiles[filename] = argoApp
+	for _, svc := range app.Services {
+		if svc.PRPreviews == nil {
+			continue
+		}
+		appSet, err := makePRPreviewsApplicationSet(env, app, svc, b.argoCDConfig, b.argoNS, b.project, b.repoURL, path.Join(b.repoSubpath, filepath.ToSlash(config.PathForServiceBase(app, env, svc.Name, b.perEnvOverlays))))
+		if err != nil {
+			return err
+		}
+		argoFiles[filepath.ToSlash(filepath.Join(basePath, appSet.Name+".yaml"))] = appSet
+	}
 	b.files = res.Merge(argoFiles, b.files)
 	return nil
 }
@@ -121,46 +174,72 @@ func (b *argocdBuilder) Environment(env *config.Environment) error {
 	argoFiles := res.Resources{}
 	filename := filepath.ToSlash(filepath.Join(basePath, env.Name+"-env-app.yaml"))
 
-	argoFiles[filename] = makeApplication(
+	envApp := withSyncPolicy(makeApplication(
 		nil,
-		env.Name+"-env", b.argoNS,
-		defaultProject,
+		env.Name+"-env", b.appNamespace(env),
+		b.project,
 		env.Name,
 		clusterForEnv(env),
-		makeEnvSource(env, b.repoURL))
+		makeEnvSource(env, b.repoURL, b.repoSubpath)), b.argoCDConfig, env.IsAutoSync(), applyModeSyncOptions(b.argoCDConfig, env.SyncOptions))
+	envApp.Spec.IgnoreDifferences = makeIgnoreDifferences(env.IgnoreDifferences)
+	argoFiles[filename] = envApp
 	b.files = res.Merge(argoFiles, b.files)
 	return nil
 }
 
-func argoCDConfigResources(cfg *config.Config, repoURL string, files res.Resources) error {
+// appNamespace returns the namespace that the applications for the
+// environment are created in.
+func (b *argocdBuilder) appNamespace(env *config.Environment) string {
+	if env.ArgoCDAppNamespace != "" {
+		return env.ArgoCDAppNamespace
+	}
+	return b.argoNS
+}
+
+func argoCDConfigResources(cfg *config.Config, repoURL, repoSubpath string, files res.Resources) error {
 	if cfg.ArgoCD.Namespace == "" {
 		return nil
 	}
 	basePath := filepath.ToSlash(filepath.Join(config.PathForArgoCD()))
 	filename := filepath.ToSlash(filepath.Join(basePath, "kustomization.yaml"))
+	options := applyModeSyncOptions(cfg.ArgoCD, nil)
+	project := projectName(cfg.ArgoCD)
 	files[filepath.ToSlash(filepath.Join(basePath, "argo-app.yaml"))] =
-		ignoreDifferences(makeApplication(nil, "argo-app", cfg.ArgoCD.Namespace,
-			defaultProject, cfg.ArgoCD.Namespace, defaultServer,
-			&argoappv1.ApplicationSource{RepoURL: repoURL, Path: basePath}))
+		ignoreDifferences(withSyncPolicy(makeApplication(nil, "argo-app", cfg.ArgoCD.Namespace,
+			project, cfg.ArgoCD.Namespace, defaultServer,
+			&argoappv1.ApplicationSource{RepoURL: repoURL, Path: path.Join(repoSubpath, basePath)}), cfg.ArgoCD, true, options))
 	if cfg.Pipelines != nil {
-		files[filepath.ToSlash(filepath.Join(basePath, "cicd-app.yaml"))] = ignoreDifferences(
-			makeApplication(nil, "cicd-app", cfg.ArgoCD.Namespace, defaultProject, cfg.Pipelines.Name, defaultServer,
-				&argoappv1.ApplicationSource{RepoURL: repoURL, Path: filepath.ToSlash(filepath.Join(config.PathForPipelines(cfg.Pipelines), "overlays"))}))
+		files[filepath.ToSlash(filepath.Join(basePath, "cicd-app.yaml"))] = ignoreDifferences(withSyncPolicy(
+			makeApplication(nil, "cicd-app", cfg.ArgoCD.Namespace, project, cfg.Pipelines.Name, defaultServer,
+				&argoappv1.ApplicationSource{RepoURL: repoURL, Path: path.Join(repoSubpath, config.PathForPipelines(cfg.Pipelines), "overlays")}), cfg.ArgoCD, true, options))
+		if cfg.SecretsRepo != nil {
+			files[filepath.ToSlash(filepath.Join(basePath, "secrets-app.yaml"))] = withSyncPolicy(makeApplication(nil, "secrets-app", cfg.ArgoCD.Namespace, project, cfg.Pipelines.Name, defaultServer,
+				&argoappv1.ApplicationSource{RepoURL: cfg.SecretsRepo.URL, Path: cfg.SecretsRepo.Path, TargetRevision: cfg.SecretsRepo.TargetRevision}), cfg.ArgoCD, true, options)
+		}
+	}
+	if cfg.ArgoCD.Project != "" {
+		files[filepath.ToSlash(filepath.Join(basePath, "appproject.yaml"))] = makeAppProject(cfg.ArgoCD, files)
 	}
 	resourceNames := []string{}
 	for k := range files {
 		resourceNames = append(resourceNames, filepath.Base(k))
 	}
 	sort.Strings(resourceNames)
-	files[filename] = &res.Kustomization{Resources: resourceNames}
+	// The schema lets patches to the Applications merge their lists.
+	schema, err := openapi.Schema(nil, openapi.Application)
+	if err != nil {
+		return err
+	}
+	files[filepath.ToSlash(filepath.Join(basePath, openapi.Filename))] = schema
+	files[filename] = &res.Kustomization{Resources: resourceNames, OpenAPI: &res.OpenAPI{Path: openapi.Filename}}
 	return nil
 }
 
-func makeAppSource(env *config.Environment, app *config.Application, repoURL string) *argoappv1.ApplicationSource {
+func makeAppSource(env *config.Environment, app *config.Application, repoURL, repoSubpath string) *argoappv1.ApplicationSource {
 	if app.ConfigRepo == nil {
 		return &argoappv1.ApplicationSource{
 			RepoURL: repoURL,
-			Path:    filepath.ToSlash(filepath.Join(config.PathForApplication(env, app), "overlays")),
+			Path:    path.Join(repoSubpath, config.PathForApplication(env, app), "overlays"),
 		}
 	}
 	return &argoappv1.ApplicationSource{
@@ -170,15 +249,152 @@ func makeAppSource(env *config.Environment, app *config.Application, repoURL str
 	}
 }
 
-func makeEnvSource(env *config.Environment, repoURL string) *argoappv1.ApplicationSource {
+// imageUpdaterAnnotations returns the Argo CD Image Updater annotations for
+// the services in the application that configure an image update, the service
+// name is used as the alias for the image.
+func imageUpdaterAnnotations(cfg *config.ImageUpdaterConfig, app *config.Application) map[string]string {
+	strategy := cfg.UpdateStrategy
+	if strategy == "" {
+		strategy = defaultImageUpdateStrategy
+	}
+	writeBack := cfg.WriteBackMethod
+	if writeBack == "" {
+		writeBack = defaultImageWriteBackMethod
+	}
+	annotations := map[string]string{}
+	images := []string{}
+	for _, svc := range app.Services {
+		if svc.ImageUpdate == nil {
+			continue
+		}
+		images = append(images, svc.Name+"="+svc.ImageUpdate.Repository)
+		annotations[imageUpdaterPrefix+svc.Name+".update-strategy"] = strategy
+		if svc.ImageUpdate.ImageName != "" {
+			annotations[imageUpdaterPrefix+svc.Name+".kustomize.image-name"] = svc.ImageUpdate.ImageName
+		}
+	}
+	if len(images) == 0 {
+		return nil
+	}
+	annotations[imageUpdaterPrefix+"image-list"] = strings.Join(images, ",")
+	annotations[imageUpdaterPrefix+"write-back-method"] = writeBack
+	return annotations
+}
+
+func makeEnvSource(env *config.Environment, repoURL, repoSubpath string) *argoappv1.ApplicationSource {
 	envPath := filepath.ToSlash(filepath.Join(config.PathForEnvironment(env), "env"))
-	envBasePath := filepath.ToSlash(filepath.Join(envPath, "overlays"))
+	envBasePath := path.Join(repoSubpath, envPath, "overlays")
 	return &argoappv1.ApplicationSource{
 		RepoURL: repoURL,
 		Path:    envBasePath,
 	}
 }
 
+// appSyncOptions returns the sync options for the environment followed by
+// those for the services in the application, without duplicates.
+func appSyncOptions(env *config.Environment, app *config.Application) []string {
+	seen := map[string]bool{}
+	options := []string{}
+	add := func(opts []string) {
+		for _, o := range opts {
+			if !seen[o] {
+				seen[o] = true
+				options = append(options, o)
+			}
+		}
+	}
+	add(env.SyncOptions)
+	for _, svc := range app.Services {
+		add(svc.SyncOptions)
+	}
+	return options
+}
+
+// appIgnoreDifferences returns the ignored differences for the environment
+// followed by those for the services in the application.
+func appIgnoreDifferences(env *config.Environment, app *config.Application) []argoappv1.ResourceIgnoreDifferences {
+	differences := append([]*config.IgnoreDifference{}, env.IgnoreDifferences...)
+	for _, svc := range app.Services {
+		differences = append(differences, svc.IgnoreDifferences...)
+	}
+	return makeIgnoreDifferences(differences)
+}
+
+func makeIgnoreDifferences(differences []*config.IgnoreDifference) []argoappv1.ResourceIgnoreDifferences {
+	if len(differences) == 0 {
+		return nil
+	}
+	ignored := []argoappv1.ResourceIgnoreDifferences{}
+	for _, d := range differences {
+		ignored = append(ignored, argoappv1.ResourceIgnoreDifferences{
+			Group:        d.Group,
+			Kind:         d.Kind,
+			Name:         d.Name,
+			JSONPointers: d.JSONPointers,
+		})
+	}
+	return ignored
+}
+
+// applyModeSyncOptions returns the options with ServerSideApply=true added if
+// Argo CD applies the resources with server-side apply, unless the options
+// already set ServerSideApply.
+func applyModeSyncOptions(cfg *config.ArgoCDConfig, options []string) []string {
+	if !cfg.IsServerSideApply() {
+		return options
+	}
+	return addSyncOption(options, serverSideApplyOption)
+}
+
+// addSyncOption returns a copy of the options with name=true added, unless the
+// options already set the option.
+func addSyncOption(options []string, name string) []string {
+	for _, o := range options {
+		if strings.HasPrefix(o, name+"=") {
+			return options
+		}
+	}
+	return append(append([]string{}, options...), name+"=true")
+}
+
+// automatedSyncPolicy returns the automated sync policy from the Argo CD
+// configuration, or nil if autoSync is false or the configuration disables
+// automated syncing, the default policy is shared between applications.
+func automatedSyncPolicy(cfg *config.ArgoCDConfig, autoSync bool) *argoappv1.SyncPolicyAutomated {
+	if !autoSync || !cfg.IsAutoSync() {
+		return nil
+	}
+	if cfg.IsPrune() && cfg.IsSelfHeal() {
+		return syncPolicy.Automated
+	}
+	return &argoappv1.SyncPolicyAutomated{Prune: cfg.IsPrune(), SelfHeal: cfg.IsSelfHeal()}
+}
+
+// withSyncPolicy returns the application with a copy of its sync policy that
+// has the sync options added, and the automated sync policy from the Argo CD
+// configuration, which is removed if autoSync is false, the policy is shared
+// between applications.
+func withSyncPolicy(app *argoappv1.Application, cfg *config.ArgoCDConfig, autoSync bool, options []string) *argoappv1.Application {
+	if cfg != nil && cfg.CreateNamespace {
+		options = addSyncOption(options, createNamespaceOption)
+	}
+	automated := automatedSyncPolicy(cfg, autoSync)
+	if automated == syncPolicy.Automated && len(options) == 0 {
+		return app
+	}
+	policy := *app.Spec.SyncPolicy
+	policy.Automated = automated
+	if len(options) > 0 {
+		policy.SyncOptions = append(argoappv1.SyncOptions{}, options...)
+	}
+	if policy.Automated == nil && len(policy.SyncOptions) == 0 {
+		app.Spec.SyncPolicy = nil
+		return app
+	}
+	app.Spec.SyncPolicy = &policy
+	return app
+}
+
 func ignoreDifferences(app *argoappv1.Application) *argoappv1.Application {
 	app.Spec.IgnoreDifferences = ignoreDifferencesFields
 	return app
@@ -208,6 +424,160 @@ func makeApplication(app *config.Application, appName, argoNS, project, ns, serv
 	}
 }
 
+// projectName returns the project that the applications are created in.
+func projectName(cfg *config.ArgoCDConfig) string {
+	if cfg.Project != "" {
+		return cfg.Project
+	}
+	return defaultProject
+}
+
+// makeAppProject creates the AppProject for the applications in the files,
+// it only allows their source repositories, destinations and namespaces, and
+// the cluster-scoped resources that are generated.
+//
+// The namespaces of the pull request previews match any pull request number.
+func makeAppProject(cfg *config.ArgoCDConfig, files res.Resources) *argoappv1.AppProject {
+	repos := map[string]bool{}
+	destinations := map[argoappv1.ApplicationDestination]bool{}
+	namespaces := map[string]bool{}
+	addSpec := func(ns string, spec argoappv1.ApplicationSpec) {
+		repos[spec.Source.RepoURL] = true
+		destinations[spec.Destination] = true
+		if ns != cfg.Namespace {
+			namespaces[ns] = true
+		}
+	}
+	for _, v := range files {
+		switch r := v.(type) {
+		case *argoappv1.Application:
+			addSpec(r.Namespace, r.Spec)
+		case *argoappv1.ApplicationSet:
+			spec := r.Spec.Template.Spec
+			spec.Destination.Namespace = strings.ReplaceAll(spec.Destination.Namespace, "{{number}}", "*")
+			addSpec(r.Namespace, spec)
+		}
+	}
+	project := &argoappv1.AppProject{
+		TypeMeta:   appProjectTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(cfg.Namespace, cfg.Project)),
+		Spec: argoappv1.AppProjectSpec{
+			Description:              "The applications generated by kam",
+			SourceRepos:              sortedKeys(repos),
+			ClusterResourceWhitelist: clusterResourceWhitelist,
+			SourceNamespaces:         sortedKeys(namespaces),
+		},
+	}
+	for d := range destinations {
+		project.Spec.Destinations = append(project.Spec.Destinations, d)
+	}
+	sort.Slice(project.Spec.Destinations, func(i, j int) bool {
+		a, b := project.Spec.Destinations[i], project.Spec.Destinations[j]
+		if a.Server != b.Server {
+			return a.Server < b.Server
+		}
+		return a.Namespace < b.Namespace
+	})
+	return project
+}
+
+func sortedKeys(m map[string]bool) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// makePRPreviewsApplicationSet creates an ApplicationSet that deploys the
+// service's base for each open pull request to its source repository, into a
+// namespace named for the pull request, with the image built for the pull
+// request's head commit, the basePath is the path to the service's base in
+// the repository.
+//
+// The overlays set the namespace of the environment, so the previews are
+// deployed from the base, whose resources don't have a namespace.
+//
+// The app-ci pipeline tags the images with the branch and commit, so the
+// branch names of the pull requests must be valid in image tags.
+func makePRPreviewsApplicationSet(env *config.Environment, app *config.Application, svc *config.Service, cfg *config.ArgoCDConfig, argoNS, project, repoURL, basePath string) (*argoappv1.ApplicationSet, error) {
+	generator, err := pullRequestGenerator(svc)
+	if err != nil {
+		return nil, err
+	}
+	name := env.Name + "-" + app.Name + "-" + svc.Name + "-previews"
+	image := svc.PRPreviews.ImageName + "=" + svc.PRPreviews.Repository + ":{{branch}}-{{head_sha}}"
+	return &argoappv1.ApplicationSet{
+		TypeMeta: applicationSetTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(argoNS, name),
+			meta.AddLabels(map[string]string{appLabel: app.Name}),
+		),
+		Spec: argoappv1.ApplicationSetSpec{
+			Generators: []argoappv1.ApplicationSetGenerator{{PullRequest: generator}},
+			Template: argoappv1.ApplicationSetTemplate{
+				ApplicationSetTemplateMeta: argoappv1.ApplicationSetTemplateMeta{
+					Name:   env.Name + "-" + app.Name + "-" + svc.Name + "-pr-{{number}}",
+					Labels: map[string]string{appLabel: app.Name},
+				},
+				Spec: argoappv1.ApplicationSpec{
+					Project: project,
+					Destination: argoappv1.ApplicationDestination{
+						Namespace: env.Name + "-" + svc.Name + "-pr-{{number}}",
+						Server:    clusterForEnv(env),
+					},
+					Source: argoappv1.ApplicationSource{
+						RepoURL:   repoURL,
+						Path:      basePath,
+						Kustomize: &argoappv1.ApplicationSourceKustomize{Images: argoappv1.KustomizeImages{argoappv1.KustomizeImage(image)}},
+					},
+					SyncPolicy: &argoappv1.SyncPolicy{
+						Automated:   automatedSyncPolicy(cfg, true),
+						SyncOptions: argoappv1.SyncOptions{createNamespaceOption + "=true"},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// pullRequestGenerator returns the generator for the pull requests to the
+// service's source repository, for the repository's Git host.
+func pullRequestGenerator(svc *config.Service) (*argoappv1.PullRequestGenerator, error) {
+	driver, err := scm.GetDriverName(svc.SourceURL)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(svc.SourceURL)
+	if err != nil {
+		return nil, err
+	}
+	repoPath := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	tokenRef := &argoappv1.SecretRef{SecretName: svc.PRPreviews.TokenSecret, Key: "token"}
+	switch driver {
+	case "github":
+		parts := strings.Split(repoPath, "/")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("failed to find the owner and repository in %q", svc.SourceURL)
+		}
+		generator := &argoappv1.PullRequestGeneratorGithub{Owner: parts[0], Repo: parts[1], TokenRef: tokenRef}
+		if u.Host != "github.com" {
+			generator.API = "https://" + u.Host + "/api/v3/"
+		}
+		return &argoappv1.PullRequestGenerator{Github: generator}, nil
+	case "gitlab":
+		generator := &argoappv1.PullRequestGeneratorGitLab{Project: repoPath, TokenRef: tokenRef, PullRequestState: "opened"}
+		if u.Host != "gitlab.com" {
+			generator.API = "https://" + u.Host + "/"
+		}
+		return &argoappv1.PullRequestGenerator{GitLab: generator}, nil
+	}
+	return nil, fmt.Errorf("pull requests can't be previewed for %s repositories", driver)
+}
+
 func clusterForEnv(env *config.Environment) string {
 	if env.Cluster != "" {
 		return env.Cluster
@@ -215,8 +585,18 @@ func clusterForEnv(env *config.Environment) string {
 	return defaultServer
 }
 
-// MakeApplicationControllerAdmin returns a rolebinding with argocd application controller as an admin in the given namespace
-func MakeApplicationControllerAdmin(ns string) *rbacv1.RoleBinding {
-	argocdSA := roles.CreateServiceAccount(meta.NamespacedName(ArgoCDNamespace, argoCDSAName))
+// Namespace returns the namespace that Argo CD is installed in for the
+// manifest, or the default ArgoCDNamespace if it's not configured.
+func Namespace(m *config.Manifest) string {
+	if cfg := m.GetArgoCDConfig(); cfg != nil && cfg.Namespace != "" {
+		return cfg.Namespace
+	}
+	return ArgoCDNamespace
+}
+
+// MakeApplicationControllerAdmin returns a rolebinding with the argocd
+// application controller in argoNS as an admin in the given namespace.
+func MakeApplicationControllerAdmin(argoNS, ns string) *rbacv1.RoleBinding {
+	argocdSA := roles.CreateServiceAccount(meta.NamespacedName(argoNS, argoCDSAName))
 	return roles.CreateRoleBinding(meta.NamespacedName(ns, argocdAdminBindingName), argocdSA, "ClusterRole", "admin")
 }
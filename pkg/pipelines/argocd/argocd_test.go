@@ -5,8 +5,10 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/jenkins-x/go-scm/scm/factory"
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	"github.com/redhat-developer/kam/pkg/pipelines/openapi"
 
 	// This is a hack because ArgoCD doesn't support a compatible (code-wise)
 	// version of k8s in common with kam
@@ -103,7 +105,9 @@ func TestBuildCreatesArgoCD(t *testing.T) {
 				"test-dev-env-app.yaml",
 				"test-dev-http-api-app.yaml",
 			},
+			OpenAPI: &res.OpenAPI{Path: openapi.Filename},
 		},
+		"config/argocd/openapi.json": applicationSchema(t),
 	}
 
 	if diff := cmp.Diff(want, files); diff != "" {
@@ -132,7 +136,7 @@ func TestBuildCreatesArgoCDWithMultipleApps(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	if len(files) != 6 {
+	if len(files) != 7 {
 		t.Fatalf("got %d files, want 7\n", len(files))
 	}
 	want := &res.Kustomization{
@@ -143,12 +147,505 @@ func TestBuildCreatesArgoCDWithMultipleApps(t *testing.T) {
 			"test-production-env-app.yaml",
 			"test-production-http-api-app.yaml",
 		},
+		OpenAPI: &res.OpenAPI{Path: openapi.Filename},
 	}
 	if diff := cmp.Diff(want, files["config/argocd/kustomization.yaml"]); diff != "" {
 		t.Fatalf("files didn't match: %s\n", diff)
 	}
 }
 
+func TestBuildWithSecretsRepo(t *testing.T) {
+	m := &config.Manifest{
+		Config: &config.Config{
+			ArgoCD:      &config.ArgoCDConfig{Namespace: ArgoCDNamespace},
+			Pipelines:   &config.PipelinesConfig{Name: "tst-cicd"},
+			SecretsRepo: &config.Repository{URL: "https://github.com/rhd-example-gitops/secrets.git", Path: "."},
+		},
+	}
+
+	files, err := Build(ArgoCDNamespace, testRepoURL, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &argoappv1.Application{
+		TypeMeta:   applicationTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ArgoCDNamespace, "secrets-app")),
+		Spec: argoappv1.ApplicationSpec{
+			Source: argoappv1.ApplicationSource{
+				RepoURL: "https://github.com/rhd-example-gitops/secrets.git",
+				Path:    ".",
+			},
+			Destination: argoappv1.ApplicationDestination{
+				Server:    defaultServer,
+				Namespace: "tst-cicd",
+			},
+			Project:    defaultProject,
+			SyncPolicy: syncPolicy,
+		},
+	}
+	if diff := cmp.Diff(want, files["config/argocd/secrets-app.yaml"]); diff != "" {
+		t.Fatalf("secrets application didn't match: %s\n", diff)
+	}
+	wantKustomization := &res.Kustomization{
+		Resources: []string{"argo-app.yaml", "cicd-app.yaml", "secrets-app.yaml"},
+		OpenAPI:   &res.OpenAPI{Path: openapi.Filename},
+	}
+	if diff := cmp.Diff(wantKustomization, files["config/argocd/kustomization.yaml"]); diff != "" {
+		t.Fatalf("kustomization didn't match: %s\n", diff)
+	}
+}
+
+func TestBuildWithRepoSubpath(t *testing.T) {
+	m := &config.Manifest{
+		RepoSubpath:  "platform/gitops",
+		Environments: []*config.Environment{testEnv, {Name: "prod", Apps: []*config.Application{configRepoApp}}},
+		Config: &config.Config{
+			ArgoCD:    &config.ArgoCDConfig{Namespace: ArgoCDNamespace},
+			Pipelines: &config.PipelinesConfig{Name: "cicd"},
+		},
+	}
+
+	files, err := Build(ArgoCDNamespace, testRepoURL, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"config/argocd/argo-app.yaml":              "platform/gitops/config/argocd",
+		"config/argocd/cicd-app.yaml":              "platform/gitops/config/cicd/overlays",
+		"config/argocd/test-dev-env-app.yaml":      "platform/gitops/" + testEnvBasePath,
+		"config/argocd/test-dev-http-api-app.yaml": "platform/gitops/environments/test-dev/apps/http-api/overlays",
+		"config/argocd/prod-env-app.yaml":          "platform/gitops/environments/prod/env/overlays",
+		"config/argocd/prod-prod-api-app.yaml":     "deploys",
+	}
+	for k, wantPath := range want {
+		app := files[k].(*argoappv1.Application)
+		if app.Spec.Source.Path != wantPath {
+			t.Errorf("%s got source path %q, want %q", k, app.Spec.Source.Path, wantPath)
+		}
+	}
+}
+
+func TestBuildWithSyncOptions(t *testing.T) {
+	env := &config.Environment{
+		Name:        "test-dev",
+		SyncOptions: []string{"CreateNamespace=true"},
+		Apps: []*config.Application{
+			{
+				Name: "http-api",
+				Services: []*config.Service{
+					{Name: "http-svc", SyncOptions: []string{"ServerSideApply=true", "CreateNamespace=true"}},
+					{Name: "worker", SyncOptions: []string{"Replace=true"}},
+				},
+			},
+		},
+	}
+	m := &config.Manifest{
+		Environments: []*config.Environment{env, {Name: "test-stage", Apps: []*config.Application{testApp}}},
+		Config: &config.Config{
+			ArgoCD: &config.ArgoCDConfig{Namespace: ArgoCDNamespace},
+		},
+	}
+
+	files, err := Build(ArgoCDNamespace, testRepoURL, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]argoappv1.SyncOptions{
+		"config/argocd/test-dev-env-app.yaml":        {"CreateNamespace=true"},
+		"config/argocd/test-dev-http-api-app.yaml":   {"CreateNamespace=true", "ServerSideApply=true", "Replace=true"},
+		"config/argocd/test-stage-env-app.yaml":      nil,
+		"config/argocd/test-stage-http-api-app.yaml": nil,
+	}
+	for k, options := range want {
+		app := files[k].(*argoappv1.Application)
+		if diff := cmp.Diff(options, app.Spec.SyncPolicy.SyncOptions); diff != "" {
+			t.Errorf("%s sync options didn't match:\n%s", k, diff)
+		}
+	}
+	if syncPolicy.SyncOptions != nil {
+		t.Fatalf("the shared sync policy was modified: %#v", syncPolicy)
+	}
+}
+
+func TestBuildWithIgnoreDifferences(t *testing.T) {
+	replicas := &config.IgnoreDifference{Group: "apps", Kind: "Deployment", JSONPointers: []string{"/spec/replicas"}}
+	caBundle := &config.IgnoreDifference{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration", Name: "injector", JSONPointers: []string{"/webhooks/0/clientConfig/caBundle"}}
+	env := &config.Environment{
+		Name:              "test-dev",
+		IgnoreDifferences: []*config.IgnoreDifference{replicas},
+		Apps: []*config.Application{
+			{
+				Name: "http-api",
+				Services: []*config.Service{
+					{Name: "http-svc", IgnoreDifferences: []*config.IgnoreDifference{caBundle}},
+					{Name: "worker"},
+				},
+			},
+		},
+	}
+	m := &config.Manifest{
+		Environments: []*config.Environment{env, {Name: "test-stage", Apps: []*config.Application{testApp}}},
+		Config: &config.Config{
+			ArgoCD: &config.ArgoCDConfig{Namespace: ArgoCDNamespace},
+		},
+	}
+
+	files, err := Build(ArgoCDNamespace, testRepoURL, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantReplicas := argoappv1.ResourceIgnoreDifferences{Group: "apps", Kind: "Deployment", JSONPointers: []string{"/spec/replicas"}}
+	wantCABundle := argoappv1.ResourceIgnoreDifferences{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration", Name: "injector", JSONPointers: []string{"/webhooks/0/clientConfig/caBundle"}}
+	want := map[string][]argoappv1.ResourceIgnoreDifferences{
+		"config/argocd/test-dev-env-app.yaml":        {wantReplicas},
+		"config/argocd/test-dev-http-api-app.yaml":   {wantReplicas, wantCABundle},
+		"config/argocd/test-stage-env-app.yaml":      nil,
+		"config/argocd/test-stage-http-api-app.yaml": nil,
+	}
+	for k, differences := range want {
+		app := files[k].(*argoappv1.Application)
+		if diff := cmp.Diff(differences, app.Spec.IgnoreDifferences); diff != "" {
+			t.Errorf("%s ignored differences didn't match:\n%s", k, diff)
+		}
+	}
+	if l := len(env.IgnoreDifferences); l != 1 {
+		t.Fatalf("the environment's ignored differences were modified: got %d", l)
+	}
+}
+
+func TestBuildWithServerSideApply(t *testing.T) {
+	m := &config.Manifest{
+		Environments: []*config.Environment{
+			{Name: "test-dev", SyncOptions: []string{"CreateNamespace=true"}, Apps: []*config.Application{testApp}},
+			{Name: "test-stage", SyncOptions: []string{"ServerSideApply=false"}},
+		},
+		Config: &config.Config{
+			ArgoCD:      &config.ArgoCDConfig{Namespace: ArgoCDNamespace, ApplyMode: config.ApplyModeServerSide},
+			Pipelines:   &config.PipelinesConfig{Name: "tst-cicd"},
+			SecretsRepo: &config.Repository{URL: "https://github.com/org/secrets.git", Path: "."},
+		},
+	}
+
+	files, err := Build(ArgoCDNamespace, testRepoURL, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]argoappv1.SyncOptions{
+		"config/argocd/test-dev-env-app.yaml":      {"CreateNamespace=true", "ServerSideApply=true"},
+		"config/argocd/test-dev-http-api-app.yaml": {"CreateNamespace=true", "ServerSideApply=true"},
+		"config/argocd/test-stage-env-app.yaml":    {"ServerSideApply=false"},
+		"config/argocd/argo-app.yaml":              {"ServerSideApply=true"},
+		"config/argocd/cicd-app.yaml":              {"ServerSideApply=true"},
+		"config/argocd/secrets-app.yaml":           {"ServerSideApply=true"},
+	}
+	for k, options := range want {
+		app := files[k].(*argoappv1.Application)
+		if diff := cmp.Diff(options, app.Spec.SyncPolicy.SyncOptions); diff != "" {
+			t.Errorf("%s sync options didn't match:\n%s", k, diff)
+		}
+		if app.Spec.SyncPolicy.Automated == nil {
+			t.Errorf("%s is not synced automatically", k)
+		}
+	}
+	if syncPolicy.SyncOptions != nil {
+		t.Fatalf("the shared sync policy was modified: %#v", syncPolicy)
+	}
+}
+
+func TestBuildWithManualSync(t *testing.T) {
+	autoSync := false
+	m := &config.Manifest{
+		Environments: []*config.Environment{
+			{Name: "test-prod", AutoSync: &autoSync, Apps: []*config.Application{testApp}},
+			{Name: "test-stage", AutoSync: &autoSync, SyncOptions: []string{"CreateNamespace=true"}},
+			{Name: "test-dev", Apps: []*config.Application{testApp}},
+		},
+		Config: &config.Config{
+			ArgoCD: &config.ArgoCDConfig{Namespace: ArgoCDNamespace},
+		},
+	}
+
+	files, err := Build(ArgoCDNamespace, testRepoURL, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]*argoappv1.SyncPolicy{
+		"config/argocd/test-prod-env-app.yaml":      nil,
+		"config/argocd/test-prod-http-api-app.yaml": nil,
+		"config/argocd/test-stage-env-app.yaml":     {SyncOptions: argoappv1.SyncOptions{"CreateNamespace=true"}},
+		"config/argocd/test-dev-env-app.yaml":       syncPolicy,
+		"config/argocd/test-dev-http-api-app.yaml":  syncPolicy,
+	}
+	for k, policy := range want {
+		app := files[k].(*argoappv1.Application)
+		if diff := cmp.Diff(policy, app.Spec.SyncPolicy); diff != "" {
+			t.Errorf("%s sync policy didn't match:\n%s", k, diff)
+		}
+	}
+	if syncPolicy.Automated == nil {
+		t.Fatalf("the shared sync policy was modified: %#v", syncPolicy)
+	}
+}
+
+func TestBuildWithSyncPolicy(t *testing.T) {
+	disabled := false
+	syncTests := []struct {
+		name   string
+		argoCD *config.ArgoCDConfig
+		want   *argoappv1.SyncPolicy
+	}{
+		{"default", &config.ArgoCDConfig{Namespace: ArgoCDNamespace}, syncPolicy},
+		{"manual sync", &config.ArgoCDConfig{Namespace: ArgoCDNamespace, AutoSync: &disabled}, nil},
+		{"no self heal", &config.ArgoCDConfig{Namespace: ArgoCDNamespace, SelfHeal: &disabled},
+			&argoappv1.SyncPolicy{Automated: &argoappv1.SyncPolicyAutomated{Prune: true}}},
+		{"create namespace", &config.ArgoCDConfig{Namespace: ArgoCDNamespace, AutoSync: &disabled, CreateNamespace: true},
+			&argoappv1.SyncPolicy{SyncOptions: argoappv1.SyncOptions{"CreateNamespace=true"}}},
+	}
+	for _, tt := range syncTests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &config.Manifest{
+				Environments: []*config.Environment{{Name: "test-dev", Apps: []*config.Application{testApp}}},
+				Config:       &config.Config{ArgoCD: tt.argoCD},
+			}
+			files, err := Build(ArgoCDNamespace, testRepoURL, m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, k := range []string{"config/argocd/argo-app.yaml", "config/argocd/test-dev-env-app.yaml", "config/argocd/test-dev-http-api-app.yaml"} {
+				app := files[k].(*argoappv1.Application)
+				if diff := cmp.Diff(tt.want, app.Spec.SyncPolicy); diff != "" {
+					t.Errorf("%s sync policy didn't match:\n%s", k, diff)
+				}
+			}
+		})
+	}
+	if !syncPolicy.Automated.Prune || !syncPolicy.Automated.SelfHeal {
+		t.Fatalf("the shared sync policy was modified: %#v", syncPolicy)
+	}
+}
+
+func TestBuildWithAppNamespace(t *testing.T) {
+	m := &config.Manifest{
+		Environments: []*config.Environment{
+			{Name: "test-dev", ArgoCDAppNamespace: "team-a", Apps: []*config.Application{testApp}},
+			{Name: "test-stage"},
+		},
+		Config: &config.Config{
+			ArgoCD: &config.ArgoCDConfig{Namespace: ArgoCDNamespace, ApplicationNamespaces: []string{"team-*"}},
+		},
+	}
+
+	files, err := Build(ArgoCDNamespace, testRepoURL, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][2]string{
+		"config/argocd/test-dev-env-app.yaml":      {"team-a", "test-dev"},
+		"config/argocd/test-dev-http-api-app.yaml": {"team-a", "test-dev"},
+		"config/argocd/test-stage-env-app.yaml":    {ArgoCDNamespace, "test-stage"},
+		"config/argocd/argo-app.yaml":              {ArgoCDNamespace, ArgoCDNamespace},
+	}
+	for k, namespaces := range want {
+		app := files[k].(*argoappv1.Application)
+		if got := [2]string{app.Namespace, app.Spec.Destination.Namespace}; got != namespaces {
+			t.Errorf("%s got namespace and destination %v, want %v", k, got, namespaces)
+		}
+	}
+}
+
+func TestBuildWithProject(t *testing.T) {
+	m := &config.Manifest{
+		GitOpsURL: testRepoURL,
+		Environments: []*config.Environment{
+			{Name: "test-dev", ArgoCDAppNamespace: "team-a", Apps: []*config.Application{testApp}},
+			{Name: "test-stage", Cluster: "https://api.stage.example.com:6443"},
+		},
+		Config: &config.Config{
+			ArgoCD:    &config.ArgoCDConfig{Namespace: ArgoCDNamespace, ApplicationNamespaces: []string{"team-*"}, Project: "kam"},
+			Pipelines: &config.PipelinesConfig{Name: "cicd"},
+		},
+	}
+
+	files, err := Build(ArgoCDNamespace, testRepoURL, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for k, v := range files {
+		if app, ok := v.(*argoappv1.Application); ok && app.Spec.Project != "kam" {
+			t.Errorf("%s got project %q, want kam", k, app.Spec.Project)
+		}
+	}
+	want := &argoappv1.AppProject{
+		TypeMeta:   appProjectTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ArgoCDNamespace, "kam")),
+		Spec: argoappv1.AppProjectSpec{
+			Description: "The applications generated by kam",
+			SourceRepos: []string{testRepoURL},
+			Destinations: []argoappv1.ApplicationDestination{
+				{Server: "https://api.stage.example.com:6443", Namespace: "test-stage"},
+				{Server: defaultServer, Namespace: "cicd"},
+				{Server: defaultServer, Namespace: ArgoCDNamespace},
+				{Server: defaultServer, Namespace: "test-dev"},
+			},
+			ClusterResourceWhitelist: clusterResourceWhitelist,
+			SourceNamespaces:         []string{"team-a"},
+		},
+	}
+	if diff := cmp.Diff(want, files["config/argocd/appproject.yaml"]); diff != "" {
+		t.Fatalf("AppProject didn't match:\n%s", diff)
+	}
+	k := files["config/argocd/kustomization.yaml"].(*res.Kustomization)
+	if k.Resources[0] != "appproject.yaml" {
+		t.Fatalf("kustomization doesn't include the AppProject: %v", k.Resources)
+	}
+}
+
+func TestBuildWithImageUpdater(t *testing.T) {
+	env := &config.Environment{
+		Name: "test-dev",
+		Apps: []*config.Application{
+			{
+				Name: "http-api",
+				Services: []*config.Service{
+					{Name: "http-svc", ImageUpdate: &config.ImageUpdate{Repository: "quay.io/org/http-svc", ImageName: "nginxinc/nginx-unprivileged"}},
+					{Name: "worker", ImageUpdate: &config.ImageUpdate{Repository: "quay.io/org/worker"}},
+					{Name: "cache"},
+				},
+			},
+		},
+	}
+	m := &config.Manifest{
+		Environments: []*config.Environment{env, {Name: "test-stage", Apps: []*config.Application{testApp}}},
+		Config: &config.Config{
+			ArgoCD: &config.ArgoCDConfig{
+				Namespace:    ArgoCDNamespace,
+				ImageUpdater: &config.ImageUpdaterConfig{UpdateStrategy: "semver"},
+			},
+		},
+	}
+
+	files, err := Build(ArgoCDNamespace, testRepoURL, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]map[string]string{
+		"config/argocd/test-dev-http-api-app.yaml": {
+			"argocd-image-updater.argoproj.io/image-list":                    "http-svc=quay.io/org/http-svc,worker=quay.io/org/worker",
+			"argocd-image-updater.argoproj.io/http-svc.update-strategy":      "semver",
+			"argocd-image-updater.argoproj.io/http-svc.kustomize.image-name": "nginxinc/nginx-unprivileged",
+			"argocd-image-updater.argoproj.io/worker.update-strategy":        "semver",
+			"argocd-image-updater.argoproj.io/write-back-method":             "git",
+		},
+		"config/argocd/test-dev-env-app.yaml":        nil,
+		"config/argocd/test-stage-http-api-app.yaml": nil,
+	}
+	for k, annotations := range want {
+		app := files[k].(*argoappv1.Application)
+		if diff := cmp.Diff(annotations, app.Annotations); diff != "" {
+			t.Errorf("%s annotations didn't match:\n%s", k, diff)
+		}
+	}
+}
+
+func TestBuildWithPRPreviews(t *testing.T) {
+	env := &config.Environment{
+		Name: "test-dev",
+		Apps: []*config.Application{
+			{
+				Name: "http-api",
+				Services: []*config.Service{
+					{
+						Name:       "http-svc",
+						SourceURL:  "https://github.com/org/http-svc.git",
+						PRPreviews: &config.PRPreviews{Repository: "quay.io/org/http-svc", ImageName: "nginxinc/nginx-unprivileged", TokenSecret: "preview-token"},
+					},
+					{
+						Name:       "worker",
+						SourceURL:  "https://gitlab.example.com/org/team/worker.git",
+						PRPreviews: &config.PRPreviews{Repository: "quay.io/org/worker", ImageName: "worker", TokenSecret: "preview-token"},
+					},
+					{Name: "cache"},
+				},
+			},
+		},
+	}
+	m := &config.Manifest{
+		Environments: []*config.Environment{env},
+		Config: &config.Config{
+			ArgoCD: &config.ArgoCDConfig{Namespace: ArgoCDNamespace},
+		},
+	}
+	defer func(id factory.HostDriverIdentifier) {
+		factory.DefaultIdentifier = id
+	}(factory.DefaultIdentifier)
+	config.SetDriverMappings(map[string]string{"gitlab.example.com": "gitlab"})
+
+	files, err := Build(ArgoCDNamespace, testRepoURL, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokenRef := &argoappv1.SecretRef{SecretName: "preview-token", Key: "token"}
+	wantGenerators := map[string]*argoappv1.PullRequestGenerator{
+		"config/argocd/test-dev-http-api-http-svc-previews.yaml": {
+			Github: &argoappv1.PullRequestGeneratorGithub{Owner: "org", Repo: "http-svc", TokenRef: tokenRef},
+		},
+		"config/argocd/test-dev-http-api-worker-previews.yaml": {
+			GitLab: &argoappv1.PullRequestGeneratorGitLab{Project: "org/team/worker", API: "https://gitlab.example.com/", TokenRef: tokenRef, PullRequestState: "opened"},
+		},
+	}
+	for k, generator := range wantGenerators {
+		appSet, ok := files[k].(*argoappv1.ApplicationSet)
+		if !ok {
+			t.Fatalf("no ApplicationSet generated at %s", k)
+		}
+		if diff := cmp.Diff(generator, appSet.Spec.Generators[0].PullRequest); diff != "" {
+			t.Errorf("%s generator didn't match:\n%s", k, diff)
+		}
+	}
+
+	appSet := files["config/argocd/test-dev-http-api-http-svc-previews.yaml"].(*argoappv1.ApplicationSet)
+	want := argoappv1.ApplicationSetTemplate{
+		ApplicationSetTemplateMeta: argoappv1.ApplicationSetTemplateMeta{
+			Name:   "test-dev-http-api-http-svc-pr-{{number}}",
+			Labels: map[string]string{appLabel: "http-api"},
+		},
+		Spec: argoappv1.ApplicationSpec{
+			Project: defaultProject,
+			Destination: argoappv1.ApplicationDestination{
+				Namespace: "test-dev-http-svc-pr-{{number}}",
+				Server:    defaultServer,
+			},
+			Source: argoappv1.ApplicationSource{
+				RepoURL: testRepoURL,
+				Path:    filepath.ToSlash(filepath.Join(config.PathForApplication(env, env.Apps[0]), "services/http-svc/base")),
+				Kustomize: &argoappv1.ApplicationSourceKustomize{
+					Images: argoappv1.KustomizeImages{"nginxinc/nginx-unprivileged=quay.io/org/http-svc:{{branch}}-{{head_sha}}"},
+				},
+			},
+			SyncPolicy: &argoappv1.SyncPolicy{
+				Automated:   syncPolicy.Automated,
+				SyncOptions: argoappv1.SyncOptions{"CreateNamespace=true"},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, appSet.Spec.Template); diff != "" {
+		t.Fatalf("template didn't match:\n%s", diff)
+	}
+	if _, ok := files["config/argocd/test-dev-http-api-cache-previews.yaml"]; ok {
+		t.Fatal("ApplicationSet generated for a service without previews")
+	}
+}
+
 func TestBuildWithNoRepoURL(t *testing.T) {
 	m := &config.Manifest{
 		Environments: []*config.Environment{
@@ -213,7 +710,7 @@ func TestBuildWithRepoConfig(t *testing.T) {
 			TypeMeta:   applicationTypeMeta,
 			ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ArgoCDNamespace, "test-production-env")),
 			Spec: argoappv1.ApplicationSpec{
-				Source: *makeEnvSource(prodEnv, testRepoURL),
+				Source: *makeEnvSource(prodEnv, testRepoURL, ""),
 				Destination: argoappv1.ApplicationDestination{
 					Server:    defaultServer,
 					Namespace: "test-production",
@@ -231,7 +728,7 @@ func TestBuildWithRepoConfig(t *testing.T) {
 				}),
 			),
 			Spec: argoappv1.ApplicationSpec{
-				Source: *makeAppSource(prodEnv, prodEnv.Apps[0], testRepoURL),
+				Source: *makeAppSource(prodEnv, prodEnv.Apps[0], testRepoURL, ""),
 				Destination: argoappv1.ApplicationDestination{
 					Server:    defaultServer,
 					Namespace: "test-production",
@@ -247,7 +744,9 @@ func TestBuildWithRepoConfig(t *testing.T) {
 				"test-production-env-app.yaml",
 				"test-production-prod-api-app.yaml",
 			},
+			OpenAPI: &res.OpenAPI{Path: openapi.Filename},
 		},
+		"config/argocd/openapi.json": applicationSchema(t),
 	}
 
 	if diff := cmp.Diff(want, files); diff != "" {
@@ -285,7 +784,7 @@ func TestBuildAddsClusterToApp(t *testing.T) {
 				meta.NamespacedName(ArgoCDNamespace, "test-dev-env"),
 			),
 			Spec: argoappv1.ApplicationSpec{
-				Source: *makeEnvSource(testEnv, testRepoURL),
+				Source: *makeEnvSource(testEnv, testRepoURL, ""),
 				Destination: argoappv1.ApplicationDestination{
 					Server:    "not.real.cluster",
 					Namespace: "test-dev",
@@ -303,7 +802,7 @@ func TestBuildAddsClusterToApp(t *testing.T) {
 				}),
 			),
 			Spec: argoappv1.ApplicationSpec{
-				Source: *makeAppSource(testEnv, testEnv.Apps[0], testRepoURL),
+				Source: *makeAppSource(testEnv, testEnv.Apps[0], testRepoURL, ""),
 				Destination: argoappv1.ApplicationDestination{
 					Server:    "not.real.cluster",
 					Namespace: "test-dev",
@@ -319,7 +818,9 @@ func TestBuildAddsClusterToApp(t *testing.T) {
 				"test-dev-env-app.yaml",
 				"test-dev-http-api-app.yaml",
 			},
+			OpenAPI: &res.OpenAPI{Path: openapi.Filename},
 		},
+		"config/argocd/openapi.json": applicationSchema(t),
 	}
 
 	if diff := cmp.Diff(want, files); diff != "" {
@@ -357,3 +858,31 @@ func fakeArgoApplication() *argoappv1.Application {
 		},
 	}
 }
+
+func applicationSchema(t *testing.T) map[string]interface{} {
+	t.Helper()
+	schema, err := openapi.Schema(nil, openapi.Application)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+func TestNamespace(t *testing.T) {
+	tests := []struct {
+		name string
+		m    *config.Manifest
+		want string
+	}{
+		{"no config", &config.Manifest{}, ArgoCDNamespace},
+		{"no namespace", &config.Manifest{Config: &config.Config{ArgoCD: &config.ArgoCDConfig{}}}, ArgoCDNamespace},
+		{"namespace", &config.Manifest{Config: &config.Config{ArgoCD: &config.ArgoCDConfig{Namespace: "argocd"}}}, "argocd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Namespace(tt.m); got != tt.want {
+				t.Fatalf("Namespace() got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package argocd
+
+// This is a subset of the ArgoCD ApplicationSet types from v1alpha1, with the
+// pull request generator.
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ApplicationSet is a set of Application resources
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:path=applicationsets,shortName=appset;appsets
+type ApplicationSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata" protobuf:"bytes,1,opt,name=metadata"`
+	Spec              ApplicationSetSpec `json:"spec"`
+}
+
+// ApplicationSetSpec represents a class of application set state.
+type ApplicationSetSpec struct {
+	Generators []ApplicationSetGenerator `json:"generators"`
+	Template   ApplicationSetTemplate    `json:"template"`
+}
+
+// ApplicationSetTemplate represents argocd ApplicationSpec
+type ApplicationSetTemplate struct {
+	ApplicationSetTemplateMeta `json:"metadata"`
+	Spec                       ApplicationSpec `json:"spec"`
+}
+
+// ApplicationSetTemplateMeta represents the Argo CD application fields that may
+// be used for Applications generated from the ApplicationSet (based on metav1.ObjectMeta)
+type ApplicationSetTemplateMeta struct {
+	Name        string            `json:"name,omitempty"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Finalizers  []string          `json:"finalizers,omitempty"`
+}
+
+// ApplicationSetGenerator represents a generator at the top level of an ApplicationSet.
+type ApplicationSetGenerator struct {
+	PullRequest *PullRequestGenerator `json:"pullRequest,omitempty"`
+}
+
+// PullRequestGenerator defines a generator that scrapes a PullRequest API to find candidate pull requests.
+type PullRequestGenerator struct {
+	// Which provider to use and config for it.
+	Github *PullRequestGeneratorGithub `json:"github,omitempty"`
+	GitLab *PullRequestGeneratorGitLab `json:"gitlab,omitempty"`
+	// Standard parameters.
+	RequeueAfterSeconds *int64 `json:"requeueAfterSeconds,omitempty"`
+}
+
+// PullRequestGeneratorGithub defines a connection info specific to GitHub.
+type PullRequestGeneratorGithub struct {
+	// GitHub org or user to scan. Required.
+	Owner string `json:"owner"`
+	// GitHub repo name to scan. Required.
+	Repo string `json:"repo"`
+	// The GitHub API URL to talk to. If blank, use https://api.github.com/.
+	API string `json:"api,omitempty"`
+	// Authentication token reference.
+	TokenRef *SecretRef `json:"tokenRef,omitempty"`
+	// Labels is used to filter the PRs that you want to target
+	Labels []string `json:"labels,omitempty"`
+}
+
+// PullRequestGeneratorGitLab defines connection info specific to GitLab.
+type PullRequestGeneratorGitLab struct {
+	// GitLab project to scan. Required.
+	Project string `json:"project"`
+	// The GitLab API URL to talk to. If blank, uses https://gitlab.com/.
+	API string `json:"api,omitempty"`
+	// Authentication token reference.
+	TokenRef *SecretRef `json:"tokenRef,omitempty"`
+	// Labels is used to filter the MRs that you want to target
+	Labels []string `json:"labels,omitempty"`
+	// PullRequestState is an additional MRs filter to get only those with a certain state. Default: "" (all states)
+	PullRequestState string `json:"pullRequestState,omitempty"`
+}
+
+// SecretRef struct for a reference to a secret key.
+type SecretRef struct {
+	SecretName string `json:"secretName"`
+	Key        string `json:"key"`
+}
@@ -1,17 +1,25 @@
 package pipelines
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/url"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/openshift/odo/pkg/log"
 	"github.com/spf13/afero"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	v1rbac "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -25,6 +33,7 @@ import (
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
 	"github.com/redhat-developer/kam/pkg/pipelines/namespaces"
+	"github.com/redhat-developer/kam/pkg/pipelines/openapi"
 	"github.com/redhat-developer/kam/pkg/pipelines/pipelines"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
 	"github.com/redhat-developer/kam/pkg/pipelines/roles"
@@ -32,6 +41,7 @@ import (
 	"github.com/redhat-developer/kam/pkg/pipelines/scm"
 	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
 	"github.com/redhat-developer/kam/pkg/pipelines/tasks"
+	"github.com/redhat-developer/kam/pkg/pipelines/tekton"
 	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
 	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
 )
@@ -55,35 +65,145 @@ const (
 	routePath             = "08-routes/gitops-webhook-event-listener.yaml"
 
 	dockerSecretName = "regcred"
+	// quayRegistry is the registry that Quay.io robot accounts authenticate
+	// with.
+	quayRegistry = "quay.io"
 
 	authTokenSecretName = "git-host-access-token"
 	basicAuthTokenName  = "git-host-basic-auth-token"
+	// prPreviewsTokenSecretName is the secret in the Argo CD namespace that
+	// the pull requests to preview are listed with.
+	prPreviewsTokenSecretName = "pr-previews-access-token"
 
-	saName              = "pipeline"
-	roleBindingName     = "pipelines-service-role-binding"
-	webhookSecretLength = 20
+	roleBindingName = "pipelines-service-role-binding"
+
+	// WebhookSecretLength is the length of the generated webhook secrets.
+	WebhookSecretLength = 20
 
 	pipelinesFile     = "pipelines.yaml"
-	bootstrapImage    = "nginxinc/nginx-unprivileged:latest"
 	appCITemplateName = "app-ci-template"
 	version           = 1
+
+	// DefaultBootstrapImage is the image of the bootstrapped service's
+	// Deployment if no image is provided.
+	DefaultBootstrapImage = "nginxinc/nginx-unprivileged:latest"
+
+	// The default compute resources of the bootstrapped service's container,
+	// so that the Deployment is admitted in namespaces with quotas.
+	DefaultCPURequest    = "100m"
+	DefaultCPULimit      = "500m"
+	DefaultMemoryRequest = "128Mi"
+	DefaultMemoryLimit   = "256Mi"
+
+	gitOpsRepoAnnotation         = "kam.redhat-developer/gitops-repo"
+	branchAnnotation             = "kam.redhat-developer/branch"
+	generatedByVersionAnnotation = "kam.redhat-developer/generated-by-version"
+
+	// maxServiceNameLength is the longest service name that is valid in the
+	// manifest.
+	maxServiceNameLength = 47
 )
 
+// invalidNameChars matches the runs of characters that are not valid in a
+// DNS-1035 label.
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
 // BootstrapOptions is a struct that provides the optional flags
 type BootstrapOptions struct {
-	GitOpsRepoURL            string // This is where the pipelines and configuration are.
-	GitOpsWebhookSecret      string // This is the secret for authenticating hooks from your GitOps repo.
-	Prefix                   string
-	DockerConfigJSONFilename string
-	ImageRepo                string // This is where built images are pushed to.
-	OutputPath               string // Where to write the bootstrapped files to?
-	GitHostAccessToken       string // The auth token to use to access repositories.
-	Overwrite                bool   // This allows to overwrite if there is an existing gitops repository
-	ServiceRepoURL           string // This is the full URL to your GitHub repository for your app source.
-	SaveTokenKeyRing         bool   // If true, the access-token will be saved in the keyring
-	ServiceWebhookSecret     string // This is the secret for authenticating hooks from your app source.
-	PrivateRepoDriver        string // Records the type of the GitOpsRepoURL driver if not a well-known host.
-	PushToGit                bool   // If true, gitops repository is pushed to remote git repository.
+	GitOpsRepoURL              string   `json:"gitops-repo-url"`       // This is where the pipelines and configuration are.
+	GitOpsWebhookSecret        string   `json:"gitops-webhook-secret"` // This is the secret for authenticating hooks from your GitOps repo.
+	Prefix                     string   `json:"prefix"`
+	Environments               string   `json:"environments"` // Comma separated names of the environments, the first is the dev environment that the service is bootstrapped in, defaults to namespaces.DefaultEnvironments.
+	DockerConfigJSONFilename   string   `json:"dockercfgjson"`
+	QuayRobotAccount           string   `json:"quay-robot-account"`            // The Quay.io robot account that images are pushed with, e.g. my-org+ci.
+	QuayRobotToken             string   `json:"quay-robot-token"`              // The token of the QuayRobotAccount, if set it's used instead of the Docker config.
+	GitLabDeployToken          string   `json:"gitlab-deploy-token"`           // A GitLab deploy token as <username>:<token> for a GitLab container registry, if set it's used instead of the Docker config.
+	ImageRepo                  string   `json:"image-repo"`                    // This is where built images are pushed to.
+	OutputPath                 string   `json:"output"`                        // Where to write the bootstrapped files to?
+	GitHostAccessToken         string   `json:"git-host-access-token"`         // The auth token to use to access repositories.
+	GitHostAccessTokenFile     string   `json:"git-host-access-token-file"`    // A file that the GitHostAccessToken is read from, so that it isn't passed as an argument.
+	Overwrite                  bool     `json:"overwrite"`                     // This allows to overwrite if there is an existing gitops repository
+	ServiceRepoURL             string   `json:"service-repo-url"`              // This is the full URL to your GitHub repository for your app source.
+	SaveTokenKeyRing           bool     `json:"save-token-keyring"`            // If true, the access-token will be saved in the keyring
+	ServiceWebhookSecret       string   `json:"service-webhook-secret"`        // This is the secret for authenticating hooks from your app source.
+	PrivateRepoDriver          string   `json:"private-repo-driver"`           // Records the type of the GitOpsRepoURL driver if not a well-known host.
+	GitCloneHost               string   `json:"git-clone-host"`                // Overrides the host that the basic-auth secret is used for when cloning.
+	GitUsername                string   `json:"git-username"`                  // The username of the basic-auth secret that is used when cloning, e.g. of a GitLab deploy token, defaults to secrets.DefaultBasicAuthUsername.
+	DriverMapFile              string   `json:"driver-map-file"`               // A YAML or JSON file of host to driver mappings for hosts that are not well-known.
+	PushToGit                  bool     `json:"push-to-git"`                   // If true, gitops repository is pushed to remote git repository.
+	Resume                     bool     `json:"resume"`                        // If true, skip generation and push the previously generated resources.
+	PushRetries                int      `json:"push-retries"`                  // How many times creating the repository, and pushing to it, is retried after a server error or rate limiting.
+	PrivateRepo                *bool    `json:"private-repo"`                  // If false, the repository is created as a public repository, defaults to true.
+	Hub                        bool     `json:"hub"`                           // If true, the service is added to the existing manifest of a hub GitOps repository checked out to the OutputPath.
+	TektonAPIVersion           string   `json:"tekton-api-version"`            // The tekton.dev API version of the generated resources, defaults to v1beta1.
+	SecretBackend              string   `json:"secret-backend"`                // How the generated secrets are encrypted, if at all.
+	SOPSAgeRecipients          string   `json:"sops-age-recipients"`           // Comma separated age recipients to encrypt secrets with sops.
+	SOPSPGPKey                 string   `json:"sops-pgp-key"`                  // Comma separated PGP fingerprints to encrypt secrets with sops.
+	VaultSecretStore           string   `json:"vault-secret-store"`            // The SecretStore that the ExternalSecrets fetch the secrets from with the vault SecretBackend.
+	SecretReflectionNamespaces string   `json:"secret-reflection-namespaces"`  // Comma separated namespaces that kubernetes-reflector replicates the generated secrets to.
+	InternalRegistryProject    string   `json:"internal-registry-project"`     // The project in the internal registry that images are pushed to if no ImageRepo is provided.
+	VerifyKustomize            bool     `json:"verify-kustomize"`              // If true, kustomize build every overlay in the generated tree.
+	SecretsRepoURL             string   `json:"secrets-repo-url"`              // This is where the generated secrets are delivered from, if not the GitOps repository.
+	NamespacedInstall          bool     `json:"namespaced-install"`            // If true, no cluster-scoped resources are generated.
+	ArgoCDNamespace            string   `json:"argocd-namespace"`              // The namespace that Argo CD is installed in, defaults to argocd.ArgoCDNamespace.
+	ArgoCDProject              string   `json:"argocd-project"`                // If set, an Argo CD AppProject with this name is generated for the applications.
+	NoAppCI                    bool     `json:"no-app-ci"`                     // If true, no app-ci pipeline is generated, images are built out-of-band.
+	PipelineRunTTL             string   `json:"pipelinerun-ttl"`               // How long finished PipelineRuns from the CI triggers are kept before they are pruned, e.g. 24h.
+	RepoSubpath                string   `json:"repo-subpath"`                  // The folder within the GitOps repository that the configuration is generated in.
+	UseProjectRequests         bool     `json:"use-project-requests"`          // If true, OpenShift ProjectRequests are generated instead of Namespaces.
+	WebhookInterceptorURL      string   `json:"webhook-interceptor-url"`       // The URL of a Service that the webhook events are also forwarded to.
+	DryRunTrigger              string   `json:"dry-run-trigger"`               // The events in the GitOps repository that trigger the CI dry-run, see eventlisteners.DryRunEvents.
+	PerEnvOverlays             bool     `json:"per-env-overlays"`              // If true, services have an overlay named for each environment.
+	ExistingClusterRole        string   `json:"existing-cluster-role"`         // The name of an existing ClusterRole to bind the pipeline service account to, instead of generating one.
+	DefaultBranch              string   `json:"default-branch"`                // The branch that the GitOps repository is pushed to, and whose pushes to the service repositories trigger the app-ci pipelines.
+	ServiceAccount             string   `json:"service-account"`               // The service account that the EventListener and PipelineRuns run as, defaults to config.DefaultServiceAccountName.
+	PipelineNamePrefix         string   `json:"pipeline-name-prefix"`          // Prefixed to the names of the generated CI Pipelines, TriggerTemplates and TriggerBindings.
+	WithImageUpdater           bool     `json:"with-image-updater"`            // If true, the applications are annotated for the Argo CD Image Updater to promote new image tags.
+	ImageUpdateStrategy        string   `json:"image-update-strategy"`         // How the Argo CD Image Updater picks the new image tag, defaults to latest.
+	ImageWriteBackMethod       string   `json:"image-write-back-method"`       // How the Argo CD Image Updater records the new image tag, defaults to git.
+	WithPRPreviews             bool     `json:"with-pr-previews"`              // If true, an Argo CD ApplicationSet deploys a preview of the service for each open pull request.
+	BootstrapImage             string   `json:"bootstrap-image"`               // The image of the bootstrapped service's Deployment, defaults to DefaultBootstrapImage.
+	CPURequest                 string   `json:"cpu-request"`                   // The CPU request of the bootstrapped service's container, defaults to DefaultCPURequest.
+	CPULimit                   string   `json:"cpu-limit"`                     // The CPU limit of the bootstrapped service's container, defaults to DefaultCPULimit.
+	MemoryRequest              string   `json:"memory-request"`                // The memory request of the bootstrapped service's container, defaults to DefaultMemoryRequest.
+	MemoryLimit                string   `json:"memory-limit"`                  // The memory limit of the bootstrapped service's container, defaults to DefaultMemoryLimit.
+	RouteHealthCheckPath       string   `json:"route-healthcheck-path"`        // If set, the path that the bootstrapped service's readiness is checked on before the Route sends it traffic.
+	BuildImage                 string   `json:"build-image"`                   // The image that the app-ci pipeline builds with, defaults to the image of the buildah ClusterTask.
+	BuildArgs                  []string `json:"build-arg"`                     // KEY=value args passed to the app-ci pipeline's image build.
+	ApplyMode                  string   `json:"apply-mode"`                    // How Argo CD applies the generated resources, defaults to client-side.
+	ArgoCDAutoSync             *bool    `json:"argocd-auto-sync"`              // If false, the Argo CD applications are only synced manually, defaults to true.
+	ArgoCDSelfHeal             *bool    `json:"argocd-self-heal"`              // If false, the automated syncs don't revert drift from Git, defaults to true.
+	ArgoCDPrune                *bool    `json:"argocd-prune"`                  // If false, the automated syncs don't delete resources removed from Git, defaults to true.
+	ArgoCDCreateNamespace      bool     `json:"argocd-create-namespace"`       // If true, the CreateNamespace=true sync option is added to the Argo CD applications.
+	LabelsFromGit              bool     `json:"labels-from-git"`               // If true, the generated resources are annotated with the GitOps repository, branch and kam version.
+	RouteWildcardPolicy        string   `json:"route-wildcard-policy"`         // The wildcard policy of the EventListener's Route, defaults to None.
+	RouteSubdomain             string   `json:"route-subdomain"`               // The subdomain within the router's domain that the EventListener's Route requests.
+	WebhookTLS                 bool     `json:"webhook-tls"`                   // If true, the EventListener's Route terminates TLS at the router, and redirects insecure requests.
+	WriteChecksums             bool     `json:"write-checksums"`               // If true, the sha256 checksums of the generated files are written to the ChecksumsFile.
+	EventListenerCPURequest    string   `json:"event-listener-cpu-request"`    // The CPU request of the EventListener's pod, e.g. 250m.
+	EventListenerCPULimit      string   `json:"event-listener-cpu-limit"`      // The CPU limit of the EventListener's pod.
+	EventListenerMemoryRequest string   `json:"event-listener-memory-request"` // The memory request of the EventListener's pod, e.g. 256Mi.
+	EventListenerMemoryLimit   string   `json:"event-listener-memory-limit"`   // The memory limit of the EventListener's pod.
+	ManifestOut                string   `json:"manifest-out"`                  // If set, only the manifest is written to this file, the resources are generated from it with kam build.
+	KamVersion                 string   `json:"-"`                             // The version of kam that the generated resources are annotated with.
+	Quiet                      bool     `json:"-"`                             // If true, the progress of the bootstrap isn't logged.
+}
+
+// GitOpsPath returns the local folder that the GitOps configuration is written
+// to, this is the RepoSubpath within the OutputPath.
+func (o *BootstrapOptions) GitOpsPath() string {
+	return filepath.Join(o.OutputPath, filepath.FromSlash(o.RepoSubpath))
+}
+
+// EnvironmentNames returns the names of the environments without the prefix,
+// the first is the dev environment that the service is bootstrapped in.
+func (o *BootstrapOptions) EnvironmentNames() []string {
+	return namespaces.SplitEnvironments(o.Environments)
+}
+
+// DevEnvironment returns the name of the dev environment with the prefix.
+func (o *BootstrapOptions) DevEnvironment() string {
+	return o.Prefix + o.EnvironmentNames()[0]
 }
 
 // PolicyRules to be bound to service account
@@ -115,55 +235,243 @@ var (
 			Verbs:     []string{"get", "create", "patch"},
 		},
 	}
+
+	// NamespacedRules are bound to the service account in the CI/CD namespace
+	// when no cluster-scoped resources can be created.
+	NamespacedRules = []v1rbac.PolicyRule{
+		{
+			APIGroups: []string{""},
+			Resources: []string{"services"},
+			Verbs:     []string{"patch", "get", "create"},
+		},
+		{
+			APIGroups: []string{"rbac.authorization.k8s.io"},
+			Resources: []string{"roles"},
+			Verbs:     []string{"bind", "patch", "get"},
+		},
+		{
+			APIGroups: []string{"rbac.authorization.k8s.io"},
+			Resources: []string{"rolebindings"},
+			Verbs:     []string{"get", "create", "patch"},
+		},
+		{
+			APIGroups: []string{"apps"},
+			Resources: []string{"deployments"},
+			Verbs:     []string{"get", "create", "patch"},
+		},
+		{
+			APIGroups: []string{"argoproj.io"},
+			Resources: []string{"applications"},
+			Verbs:     []string{"get", "create", "patch"},
+		},
+	}
 )
 
 // Bootstrap is the entry-point from the CLI for bootstrapping the GitOps
 // configuration.
 func Bootstrap(o *BootstrapOptions, appFs afero.Fs) error {
-	err := checkPipelinesFileExists(appFs, o.OutputPath, o.Overwrite, o.PushToGit)
+	_, err := BootstrapToFs(o, appFs)
+	return err
+}
+
+// BootstrapToFs is the same as Bootstrap, but also returns the resources that
+// were written to appFs, this allows the generated resources to be inspected
+// when bootstrapping into an in-memory filesystem.
+//
+// The keys are the paths relative to the GitOpsPath, and the secrets are in a
+// sibling of the GitOpsPath, e.g. "../secrets/gitops-webhook-secret.yaml".
+func BootstrapToFs(o *BootstrapOptions, appFs afero.Fs) (res.Resources, error) {
+	err := checkPipelinesFileExists(appFs, o.GitOpsPath(), o.Overwrite, o.PushToGit)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = maybeMakeHookSecrets(o)
+	bootstrapped, otherResources, err := generateBootstrap(o, appFs)
 	if err != nil {
+		return nil, err
+	}
+	filenames, err := yaml.WriteResources(appFs, o.GitOpsPath(), bootstrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write resources: %w", err)
+	}
+	_, err = yaml.WriteResources(appFs, filepath.Join(o.GitOpsPath(), ".."), otherResources)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write resources: %w", err)
+	}
+	if o.WriteChecksums {
+		if err := WriteChecksums(appFs, o.GitOpsPath(), filenames); err != nil {
+			return nil, fmt.Errorf("failed to write checksums: %w", err)
+		}
+	}
+	if o.VerifyKustomize {
+		if err := VerifyKustomize(appFs, o.GitOpsPath()); err != nil {
+			return nil, fmt.Errorf("failed to verify resources: %w", err)
+		}
+		if !o.Quiet {
+			log.Successf("Verified the generated kustomizations")
+		}
+	}
+
+	written := res.Resources{}
+	for k, v := range bootstrapped {
+		written[filepath.ToSlash(k)] = v
+	}
+	for k, v := range otherResources {
+		written[filepath.ToSlash(filepath.Join("..", k))] = v
+	}
+	return written, nil
+}
+
+// BootstrapDryRun generates the same resources as Bootstrap from the options,
+// and prints them to out, rather than writing them.
+//
+// Nothing is written to appFs, and the output and secrets folders don't need
+// to be empty, the progress isn't logged so that only the resources are
+// printed.
+func BootstrapDryRun(o *BootstrapOptions, out io.Writer, appFs afero.Fs) error {
+	quiet := *o
+	quiet.Quiet = true
+	bootstrapped, otherResources, err := generateBootstrap(&quiet, appFs)
+	if err != nil {
+		return err
+	}
+	if err := yaml.PrintResources(out, o.GitOpsPath(), bootstrapped); err != nil {
 		return err
 	}
+	return yaml.PrintResources(out, filepath.Join(o.GitOpsPath(), ".."), otherResources)
+}
 
+// generateBootstrap generates the resources for the GitOps repository, and
+// the secrets that are written to a sibling of it.
+func generateBootstrap(o *BootstrapOptions, appFs afero.Fs) (res.Resources, res.Resources, error) {
+	if err := maybeMakeHookSecrets(o); err != nil {
+		return nil, nil, err
+	}
 	bootstrapped, otherResources, err := bootstrapResources(o, appFs)
 	if err != nil {
-		return fmt.Errorf("failed to bootstrap resources: %v", err)
+		return nil, nil, fmt.Errorf("failed to bootstrap resources: %v", err)
+	}
+	if namespaces := SecretReflectionNamespaces(o); len(namespaces) > 0 {
+		annotations := secrets.ReflectionAnnotations(namespaces)
+		for k, v := range otherResources {
+			otherResources[k] = meta.AnnotateObject(v, annotations)
+		}
+	}
+	if o.SecretBackend == SecretBackendVault {
+		bootstrapped, err = addExternalSecrets(bootstrapped, otherResources, o.VaultSecretStore)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
 	m := bootstrapped[pipelinesFile].(*config.Manifest)
 	built, err := buildResources(appFs, m)
 	if err != nil {
-		return fmt.Errorf("failed to build resources: %v", err)
+		return nil, nil, fmt.Errorf("failed to build resources: %v", err)
 	}
 
 	bootstrapped = res.Merge(built, bootstrapped)
-	log.Successf("Created dev, stage and CICD environments")
-	_, err = yaml.WriteResources(appFs, o.OutputPath, bootstrapped)
+	if o.LabelsFromGit {
+		annotateFromGit(o, bootstrapped)
+	}
+	if !o.Quiet {
+		log.Successf("Created %s and CICD environments", strings.Join(o.EnvironmentNames(), ", "))
+	}
+	return bootstrapped, otherResources, nil
+}
+
+// BootstrapManifest writes only the manifest that Bootstrap would generate
+// from the options to the ManifestOut file.
+//
+// No resources or secrets are written, the resources are generated from the
+// manifest later with kam build, so the manifest can be reviewed and edited
+// first.
+func BootstrapManifest(o *BootstrapOptions, appFs afero.Fs) (*config.Manifest, error) {
+	filename, err := homedir.Expand(o.ManifestOut)
 	if err != nil {
-		return fmt.Errorf("failed to write resources: %w", err)
+		return nil, fmt.Errorf("failed to resolve the path to the manifest: %w", err)
+	}
+	if exists, _ := ioutils.IsExisting(appFs, filename); exists && !o.Overwrite {
+		return nil, fmt.Errorf("%s already exists. If you want to replace it, please rerun with --overwrite", o.ManifestOut)
+	}
+	if err := maybeMakeHookSecrets(o); err != nil {
+		return nil, err
 	}
-	_, err = yaml.WriteResources(appFs, filepath.Join(o.OutputPath, ".."), otherResources)
+	quiet := *o
+	quiet.Quiet = true
+	bootstrapped, _, err := bootstrapResources(&quiet, appFs)
 	if err != nil {
-		return fmt.Errorf("failed to write resources: %w", err)
+		return nil, fmt.Errorf("failed to bootstrap the manifest: %v", err)
 	}
+	m := bootstrapped[pipelinesFile].(*config.Manifest)
+	if err := yaml.MarshalItemToFile(appFs, filename, m); err != nil {
+		return nil, fmt.Errorf("failed to write the manifest: %w", err)
+	}
+	return m, nil
+}
 
-	return nil
+// SecretReflectionNamespaces returns the namespaces that the generated secrets
+// are replicated to by kubernetes-reflector.
+func SecretReflectionNamespaces(o *BootstrapOptions) []string {
+	namespaces := []string{}
+	for _, ns := range strings.Split(o.SecretReflectionNamespaces, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
+// annotateFromGit annotates the generated resources with the GitOps repository
+// and branch that they are pushed to, and the version of kam that generated
+// them, so that resources in the cluster can be traced to the bootstrap that
+// generated them.
+//
+// The secrets are not annotated, as they may be encrypted after they're
+// generated.
+func annotateFromGit(o *BootstrapOptions, r res.Resources) {
+	kamVersion := o.KamVersion
+	if kamVersion == "" {
+		kamVersion = "unknown"
+	}
+	annotations := map[string]string{
+		gitOpsRepoAnnotation:         o.GitOpsRepoURL,
+		branchAnnotation:             gitBranch(o),
+		generatedByVersionAnnotation: kamVersion,
+	}
+	for k, v := range r {
+		r[k] = meta.AnnotateObject(v, annotations)
+	}
+}
+
+// LoadBootstrapped loads and validates the manifest from a previous
+// bootstrap into the GitOpsPath, this is used to resume a bootstrap that failed
+// after the resources were generated.
+//
+// If no GitOpsRepoURL is set, the URL is taken from the manifest.
+func LoadBootstrapped(o *BootstrapOptions, appFs afero.Fs) (*config.Manifest, error) {
+	m, err := config.LoadManifest(appFs, o.GitOpsPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid manifest to resume from in %q: %w", o.GitOpsPath(), err)
+	}
+	if o.GitOpsRepoURL == "" {
+		o.GitOpsRepoURL = m.GitOpsURL
+	}
+	if o.GitOpsRepoURL != m.GitOpsURL {
+		return nil, fmt.Errorf("the GitOps repository %q does not match the manifest in %q: %s", o.GitOpsRepoURL, o.GitOpsPath(), m.GitOpsURL)
+	}
+	return m, nil
 }
 
 func maybeMakeHookSecrets(o *BootstrapOptions) error {
 	if o.GitOpsWebhookSecret == "" {
-		gitopsSecret, err := secrets.GenerateString(webhookSecretLength)
+		gitopsSecret, err := secrets.GenerateString(WebhookSecretLength)
 		if err != nil {
 			return fmt.Errorf("failed to generate GitOps webhook secret: %v", err)
 		}
 		o.GitOpsWebhookSecret = gitopsSecret
 	}
 	if o.ServiceWebhookSecret == "" {
-		appSecret, err := secrets.GenerateString(webhookSecretLength)
+		appSecret, err := secrets.GenerateString(WebhookSecretLength)
 		if err != nil {
 			return fmt.Errorf("failed to generate application webhook secret: %v", err)
 		}
@@ -172,8 +480,34 @@ func maybeMakeHookSecrets(o *BootstrapOptions) error {
 	return nil
 }
 
+// logOptions logs the options that the resources are bootstrapped with.
+func logOptions(o *BootstrapOptions, imageRepo string, isInternalRegistry bool) {
+	log.Success("Options used:")
+	log.Progressf("  Service repository: %s", o.ServiceRepoURL)
+	log.Progressf("  GitOps repository: %s", o.GitOpsRepoURL)
+	if o.SecretsRepoURL != "" {
+		log.Progressf("  Secrets repository: %s", o.SecretsRepoURL)
+	}
+	log.Progressf("  Image repository: %s", imageRepo)
+	if !isInternalRegistry {
+		if o.QuayRobotToken != "" {
+			log.Progressf("  Quay.io robot account: %s", o.QuayRobotAccount)
+		} else if username, _, err := imagerepo.ParseGitLabDeployToken(o.GitLabDeployToken); err == nil {
+			log.Progressf("  GitLab deploy token: %s", username)
+		} else {
+			log.Progressf("  Path to config.json: %s", o.DockerConfigJSONFilename)
+		}
+	}
+	log.Progressf("  Output folder: %s", o.OutputPath)
+	if o.RepoSubpath != "" {
+		log.Progressf("  Repository subpath: %s", o.RepoSubpath)
+	}
+	log.Progressf("  Overwrite output folder: %s", strconv.FormatBool(o.Overwrite))
+	log.Progressf("")
+}
+
 func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, res.Resources, error) {
-	ns := namespaces.NamesWithPrefix(o.Prefix)
+	ns := namespaces.EnvironmentNamesWithPrefix(o.Prefix, o.EnvironmentNames())
 	appRepo, err := scm.NewRepository(o.ServiceRepoURL)
 	if err != nil {
 		return nil, nil, err
@@ -182,25 +516,15 @@ func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, res
 	if err != nil {
 		return nil, nil, fmt.Errorf("invalid app repo URL: %v", err)
 	}
-	// No image repo was supplied so create the default OS internal image registry
-	if o.ImageRepo == "" {
-		o.ImageRepo = ns["cicd"] + "/" + repoName
-	}
+	o.ImageRepo = imageRepoOrDefault(o, ns["cicd"], repoName)
 	isInternalRegistry, imageRepo, err := imagerepo.ValidateImageRepo(o.ImageRepo)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	log.Success("Options used:")
-	log.Progressf("  Service repository: %s", o.ServiceRepoURL)
-	log.Progressf("  GitOps repository: %s", o.GitOpsRepoURL)
-	log.Progressf("  Image repository: %s", imageRepo)
-	if !isInternalRegistry {
-		log.Progressf("  Path to config.json: %s", o.DockerConfigJSONFilename)
+	if !o.Quiet {
+		logOptions(o, imageRepo, isInternalRegistry)
 	}
-	log.Progressf("  Output folder: %s", o.OutputPath)
-	log.Progressf("  Overwrite output folder: %s", strconv.FormatBool(o.Overwrite))
-	log.Progressf("")
 
 	gitOpsRepo, err := scm.NewRepository(o.GitOpsRepoURL)
 	if err != nil {
@@ -213,30 +537,22 @@ func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, res
 	}
 	appName := repoToAppName(repoName)
 	serviceName := repoName
-	secretName := secrets.MakeServiceWebhookSecretName(ns["dev"], serviceName)
-	envs, configEnv, err := bootstrapEnvironments(appRepo, o.Prefix, secretName, ns)
+	secretName := secrets.MakeServiceWebhookSecretName(o.DevEnvironment(), serviceName)
+	m, err := bootstrapManifest(o, appFs, appRepo, gitOpsRepo, secretName, ns)
 	if err != nil {
 		return nil, nil, err
 	}
-	if o.PrivateRepoDriver != "" {
-		host, err := scm.HostnameFromURL(o.GitOpsRepoURL)
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to get hostname from URL %q: %w", o.GitOpsRepoURL, err)
-		}
-		configEnv.Git = &config.GitConfig{Drivers: map[string]string{host: o.PrivateRepoDriver}}
-	}
-	m := createManifest(gitOpsRepo.URL(), configEnv, envs...)
 
-	devEnv := m.GetEnvironment(ns["dev"])
+	devEnv := m.GetEnvironment(o.DevEnvironment())
 	if devEnv == nil {
 		return nil, nil, errors.New("unable to bootstrap without dev environment")
 	}
 
-	app := m.GetApplication(ns["dev"], appName)
+	app := m.GetApplication(o.DevEnvironment(), appName)
 	if app == nil {
 		return nil, nil, errors.New("unable to bootstrap without application")
 	}
-	svcFiles, err := bootstrapServiceDeployment(devEnv, app)
+	svcFiles, err := bootstrapServiceDeployment(devEnv, app, m.UsePerEnvOverlays(), bootstrapImage(o), bootstrapContainerResources(o), o.RouteHealthCheckPath)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create bootstrap service: %w", err)
 	}
@@ -254,6 +570,25 @@ func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, res
 	}
 	secretFilename := filepath.ToSlash(filepath.Join("secrets", secretName+".yaml"))
 	otherResources[secretFilename] = opaqueSecret
+	if o.WithImageUpdater {
+		devEnv.Apps[0].Services[0].ImageUpdate = &config.ImageUpdate{Repository: imageRepo, ImageName: imagerepo.ImageName(bootstrapImage(o))}
+	}
+	if o.WithPRPreviews {
+		devEnv.Apps[0].Services[0].PRPreviews = &config.PRPreviews{Repository: imageRepo, ImageName: imagerepo.ImageName(bootstrapImage(o)), TokenSecret: prPreviewsTokenSecretName}
+		tokenSecret, err := secrets.CreateUnsealedSecret(meta.NamespacedName(m.GetArgoCDConfig().Namespace, prPreviewsTokenSecretName), o.GitHostAccessToken, "token")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate Secret: %w", err)
+		}
+		otherResources[filepath.ToSlash(filepath.Join("secrets", prPreviewsTokenSecretName+".yaml"))] = tokenSecret
+	}
+	if o.NoAppCI {
+		// Images are built out-of-band, so there's nothing to bind the image
+		// repository to.
+		devEnv.Pipelines = nil
+		bootstrapped[pipelinesFile] = m
+		bootstrapped = res.Merge(svcFiles, bootstrapped)
+		return bootstrapped, otherResources, nil
+	}
 	bindingName, imageRepoBindingFilename, svcImageBinding := createSvcImageBinding(cfg, devEnv, appName, serviceName, imageRepo, !isInternalRegistry)
 	bootstrapped = res.Merge(svcImageBinding, bootstrapped)
 
@@ -264,8 +599,8 @@ func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, res
 	}
 	if isInternalRegistry {
 		filenames, resources, err := imagerepo.CreateInternalRegistryResources(
-			cfg, roles.CreateServiceAccount(meta.NamespacedName(cfg.Name, saName)),
-			imageRepo, o.GitOpsRepoURL)
+			cfg, roles.CreateServiceAccount(meta.NamespacedName(cfg.Name, serviceAccountName(o))),
+			imageRepo, o.GitOpsRepoURL, m.UseProjectRequests(), existingNamespaces(m, imageRepo)...)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to get resources for internal image repository: %v", err)
 		}
@@ -288,14 +623,145 @@ func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, res
 	return bootstrapped, otherResources, nil
 }
 
-func bootstrapServiceDeployment(dev *config.Environment, app *config.Application) (res.Resources, error) {
+// imageRepoOrDefault returns the image repository to push to, if none was
+// provided, this is a repository in the OpenShift internal image registry.
+func imageRepoOrDefault(o *BootstrapOptions, cicdNS, repoName string) string {
+	if o.ImageRepo != "" {
+		return o.ImageRepo
+	}
+	project := cicdNS
+	if o.InternalRegistryProject != "" {
+		project = o.InternalRegistryProject
+	}
+	return project + "/" + repoName
+}
+
+// bootstrapManifest creates the manifest for the bootstrapped environments.
+func bootstrapManifest(o *BootstrapOptions, appFs afero.Fs, appRepo, gitOpsRepo scm.Repository, secretName string, ns map[string]string) (*config.Manifest, error) {
+	envs, configEnv, err := bootstrapEnvironments(appRepo, o.Prefix, o.PipelineNamePrefix, secretName, argoCDNamespace(o), o.EnvironmentNames(), ns)
+	if err != nil {
+		return nil, err
+	}
+	drivers := map[string]string{}
+	if o.DriverMapFile != "" {
+		drivers, err = config.LoadDriverMap(appFs, o.DriverMapFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if o.PrivateRepoDriver != "" {
+		host, err := scm.HostnameFromURL(o.GitOpsRepoURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get hostname from URL %q: %w", o.GitOpsRepoURL, err)
+		}
+		drivers[host] = o.PrivateRepoDriver
+	}
+	if len(drivers) > 0 {
+		configEnv.Git = &config.GitConfig{Drivers: drivers}
+	}
+	if o.SecretsRepoURL != "" {
+		configEnv.SecretsRepo = &config.Repository{URL: o.SecretsRepoURL, Path: "."}
+	}
+	configEnv.NamespacedInstall = o.NamespacedInstall
+	configEnv.UseProjectRequests = o.UseProjectRequests
+	configEnv.PerEnvOverlays = o.PerEnvOverlays
+	configEnv.Pipelines.DisableAppCI = o.NoAppCI
+	configEnv.Pipelines.WebhookInterceptorURL = o.WebhookInterceptorURL
+	configEnv.Pipelines.EventListenerResources = eventListenerResources(o)
+	configEnv.Pipelines.DryRunTrigger = o.DryRunTrigger
+	configEnv.Pipelines.DefaultBranch = o.DefaultBranch
+	configEnv.Pipelines.TektonAPIVersion = o.TektonAPIVersion
+	configEnv.Pipelines.PipelineRunTTL = o.PipelineRunTTL
+	configEnv.Pipelines.RouteWildcardPolicy = o.RouteWildcardPolicy
+	configEnv.Pipelines.RouteSubdomain = o.RouteSubdomain
+	configEnv.Pipelines.WebhookTLS = o.WebhookTLS
+	if sa := serviceAccountName(o); sa != config.DefaultServiceAccountName {
+		configEnv.Pipelines.ServiceAccountName = sa
+	}
+	if o.WithImageUpdater {
+		configEnv.ArgoCD.ImageUpdater = &config.ImageUpdaterConfig{
+			UpdateStrategy:  o.ImageUpdateStrategy,
+			WriteBackMethod: o.ImageWriteBackMethod,
+		}
+	}
+	configEnv.ArgoCD.ApplyMode = o.ApplyMode
+	configEnv.ArgoCD.Project = o.ArgoCDProject
+	configEnv.ArgoCD.AutoSync = disabledOrNil(o.ArgoCDAutoSync)
+	configEnv.ArgoCD.SelfHeal = disabledOrNil(o.ArgoCDSelfHeal)
+	configEnv.ArgoCD.Prune = disabledOrNil(o.ArgoCDPrune)
+	configEnv.ArgoCD.CreateNamespace = o.ArgoCDCreateNamespace
+	m := createManifest(gitOpsRepo.URL(), configEnv, envs...)
+	m.RepoSubpath = o.RepoSubpath
+	return m, nil
+}
+
+// disabledOrNil returns the option if it's set to false, so that only the
+// Argo CD sync settings that change the defaults are written to the manifest.
+func disabledOrNil(b *bool) *bool {
+	if b != nil && !*b {
+		return b
+	}
+	return nil
+}
+
+// bootstrapContainerResources returns the compute resources of the
+// bootstrapped service's container, with the defaults for those that aren't
+// set.
+func bootstrapContainerResources(o *BootstrapOptions) *config.Resources {
+	orDefault := func(v, def string) string {
+		if v == "" {
+			return def
+		}
+		return v
+	}
+	return &config.Resources{
+		CPURequest:    orDefault(o.CPURequest, DefaultCPURequest),
+		CPULimit:      orDefault(o.CPULimit, DefaultCPULimit),
+		MemoryRequest: orDefault(o.MemoryRequest, DefaultMemoryRequest),
+		MemoryLimit:   orDefault(o.MemoryLimit, DefaultMemoryLimit),
+	}
+}
+
+// argoCDNamespace returns the namespace that Argo CD is installed in.
+func argoCDNamespace(o *BootstrapOptions) string {
+	if o.ArgoCDNamespace == "" {
+		return argocd.ArgoCDNamespace
+	}
+	return o.ArgoCDNamespace
+}
+
+// bootstrapImage returns the image of the bootstrapped service's Deployment.
+func bootstrapImage(o *BootstrapOptions) string {
+	if o.BootstrapImage == "" {
+		return DefaultBootstrapImage
+	}
+	return o.BootstrapImage
+}
+
+// bootstrapServiceDeployment creates the Deployment, Service and Route of the
+// bootstrapped service.
+//
+// The OpenShift router only sends traffic to the ready endpoints of the
+// Service, if healthCheckPath is set the container's readiness is checked on
+// it.
+//
+// The resources don't have a namespace, the service's overlays set the
+// namespace of the environment, and the pull request previews are deployed
+// from the base into their own namespaces.
+func bootstrapServiceDeployment(dev *config.Environment, app *config.Application, perEnvOverlays bool, image string, containerResources *config.Resources, healthCheckPath string) (res.Resources, error) {
 	svc := dev.Apps[0].Services[0]
-	svcBase := filepath.Join(config.PathForService(app, dev, svc.Name), "base", "config")
+	requirements, err := containerResources.Requirements()
+	if err != nil {
+		return nil, err
+	}
+	svcBase := filepath.Join(config.PathForServiceBase(app, dev, svc.Name, perEnvOverlays), "config")
 	resources := res.Resources{}
-	// TODO: This should change if we add Namespace to Environment.
-	// We'd need to create the resources in the namespace _of_ the Environment.
-	resources[filepath.Join(svcBase, "100-deployment.yaml")] = deployment.Create(app.Name, dev.Name, svc.Name, bootstrapImage, deployment.ContainerPort(8080))
-	containerSvc := createBootstrapService(app.Name, dev.Name, svc.Name)
+	opts := []deployment.PodSpecFunc{deployment.ContainerPort(8080), deployment.Resources(requirements)}
+	if healthCheckPath != "" {
+		opts = append(opts, deployment.ReadinessProbe(healthCheckPath, 8080))
+	}
+	resources[filepath.Join(svcBase, "100-deployment.yaml")] = deployment.Create(app.Name, "", svc.Name, image, opts...)
+	containerSvc := createBootstrapService(app.Name, svc.Name)
 	resources[filepath.Join(svcBase, "200-service.yaml")] = containerSvc
 	r, err := routes.NewFromService(containerSvc)
 	if err != nil {
@@ -311,35 +777,52 @@ func bootstrapServiceDeployment(dev *config.Environment, app *config.Application
 	return resources, nil
 }
 
-func bootstrapEnvironments(repo scm.Repository, prefix, secretName string, ns map[string]string) ([]*config.Environment, *config.Config, error) {
+// bootstrapEnvironments returns an environment for each of the envNames, the
+// service is bootstrapped in the first, which is the dev environment, and the
+// configuration of the cicd namespace.
+func bootstrapEnvironments(repo scm.Repository, prefix, pipelineNamePrefix, secretName, argoNS string, envNames []string, ns map[string]string) ([]*config.Environment, *config.Config, error) {
 	envs := []*config.Environment{}
-	var pipelinesConfig *config.PipelinesConfig
-	for _, k := range []string{"cicd", "dev", "stage"} {
-		v := ns[k]
-		if k == "cicd" {
-			pipelinesConfig = &config.PipelinesConfig{Name: prefix + "cicd"}
-		} else {
-			env := &config.Environment{Name: v}
-			if k == "dev" {
-				svc, err := serviceFromRepo(repo.URL(), secretName, ns["cicd"])
-				if err != nil {
-					return nil, nil, err
-				}
-				app, err := applicationFromRepo(repo.URL(), svc)
-				if err != nil {
-					return nil, nil, err
-				}
-				app.Services = []*config.Service{svc}
-				env.Apps = []*config.Application{app}
-				env.Pipelines = defaultPipelines(repo)
+	pipelinesConfig := &config.PipelinesConfig{Name: prefix + namespaces.CICDName, PipelineNamePrefix: pipelineNamePrefix}
+	for i, k := range envNames {
+		env := &config.Environment{Name: ns[k]}
+		if i == 0 {
+			svc, err := serviceFromRepo(repo.URL(), secretName, ns[namespaces.CICDName])
+			if err != nil {
+				return nil, nil, err
 			}
-			envs = append(envs, env)
+			app, err := applicationFromRepo(repo.URL(), svc)
+			if err != nil {
+				return nil, nil, err
+			}
+			app.Services = []*config.Service{svc}
+			env.Apps = []*config.Application{app}
+			env.Pipelines = defaultPipelines(repo, pipelineNamePrefix)
 		}
+		envs = append(envs, env)
 	}
-	cfg := &config.Config{Pipelines: pipelinesConfig, ArgoCD: &config.ArgoCDConfig{Namespace: argocd.ArgoCDNamespace}}
+	cfg := &config.Config{Pipelines: pipelinesConfig, ArgoCD: &config.ArgoCDConfig{Namespace: argoNS}}
 	return envs, cfg, nil
 }
 
+func environmentNames(m *config.Manifest) []string {
+	names := []string{}
+	for _, env := range m.Environments {
+		names = append(names, env.Name)
+	}
+	return names
+}
+
+// existingNamespaces returns the namespaces that don't need to be created
+// for pushing images to the internal registry, in a namespaced install the
+// image project must already exist.
+func existingNamespaces(m *config.Manifest, imageRepo string) []string {
+	names := environmentNames(m)
+	if m.IsNamespacedInstall() {
+		names = append(names, strings.Split(imageRepo, "/")[1])
+	}
+	return names
+}
+
 func serviceFromRepo(repoURL, secretName, secretNS string) (*config.Service, error) {
 	repo, err := repoFromURL(repoURL)
 	if err != nil {
@@ -368,13 +851,32 @@ func applicationFromRepo(repoURL string, service *config.Service) (*config.Appli
 	}, nil
 }
 
+// repoFromURL returns the name of the repository in the URL, sanitized so
+// that it can be used as the name of a service.
 func repoFromURL(raw string) (string, error) {
 	u, err := url.Parse(raw)
 	if err != nil {
 		return "", err
 	}
 	parts := strings.Split(u.Path, "/")
-	return strings.TrimSuffix(parts[len(parts)-1], ".git"), nil
+	return sanitizeName(strings.TrimSuffix(parts[len(parts)-1], ".git")), nil
+}
+
+// sanitizeName converts a repository name into a valid DNS-1035 label that
+// fits within the service name length limit, e.g. "My_App.v2" is converted to
+// "my-app-v2".
+//
+// Names that don't start with a letter are prefixed with "repo-".
+func sanitizeName(name string) string {
+	name = invalidNameChars.ReplaceAllString(strings.ToLower(name), "-")
+	name = strings.Trim(name, "-")
+	if name == "" || !unicode.IsLetter(rune(name[0])) {
+		name = "repo-" + name
+	}
+	if len(name) > maxServiceNameLength {
+		name = name[:maxServiceNameLength]
+	}
+	return strings.TrimRight(name, "-")
 }
 
 func orgRepoFromURL(raw string) (string, error) {
@@ -382,15 +884,25 @@ func orgRepoFromURL(raw string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	// GitLab projects are identified by the full path of their namespace, as
+	// they can be in subgroups e.g. group/subgroup/repo.
+	if driver, err := scm.GetDriverName(raw); err == nil && driver == "gitlab" {
+		return strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git"), nil
+	}
 	parts := strings.Split(u.Path, "/")
+	// Azure DevOps repositories are identified by the project and name, the
+	// path is /org/project/_git/repo.
+	if len(parts) > 3 && parts[len(parts)-2] == "_git" {
+		parts = append(parts[:len(parts)-2:len(parts)-2], parts[len(parts)-1])
+	}
 	orgRepo := strings.Join(parts[len(parts)-2:], "/")
 	return strings.TrimSuffix(orgRepo, ".git"), nil
 }
 
-func createBootstrapService(appName, ns, name string) *corev1.Service {
+func createBootstrapService(appName, name string) *corev1.Service {
 	svc := &corev1.Service{
 		TypeMeta:   meta.TypeMeta("Service", "v1"),
-		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, name)),
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName("", name)),
 		Spec: corev1.ServiceSpec{
 			Ports: []corev1.ServicePort{
 				{
@@ -411,14 +923,14 @@ func createBootstrapService(appName, ns, name string) *corev1.Service {
 }
 
 func repoToAppName(repoName string) string {
-	return "app-" + repoName
+	return "app-" + sanitizeName(repoName)
 }
 
-func defaultPipelines(r scm.Repository) *config.Pipelines {
+func defaultPipelines(r scm.Repository, pipelineNamePrefix string) *config.Pipelines {
 	return &config.Pipelines{
 		Integration: &config.TemplateBinding{
-			Template: appCITemplateName,
-			Bindings: []string{r.PushBindingName()},
+			Template: pipelineNamePrefix + appCITemplateName,
+			Bindings: []string{pipelineNamePrefix + r.PushBindingName()},
 		},
 	}
 }
@@ -472,20 +984,43 @@ func createInitialFiles(fs afero.Fs, repo scm.Repository, o *BootstrapOptions) (
 	prefixedResources := addPrefixToResources(pipelinesPath(manifest.Config), resources)
 	initialFiles = res.Merge(prefixedResources, initialFiles)
 
+	cicdKustomizations, err := getCICDKustomization(files)
+	if err != nil {
+		return nil, nil, err
+	}
 	pipelinesConfigKustomizations := addPrefixToResources(
-		config.PathForPipelines(manifest.Config.Pipelines),
-		getCICDKustomization(files))
+		config.PathForPipelines(manifest.Config.Pipelines), cicdKustomizations)
 	initialFiles = res.Merge(pipelinesConfigKustomizations, initialFiles)
 
 	return initialFiles, otherResources, nil
 }
 
-// createDockerSecret creates a secret that allows pushing images to upstream repositories.
-func createDockerSecret(fs afero.Fs, dockerConfigJSONFilename, secretNS string) (*corev1.Secret, error) {
-	if dockerConfigJSONFilename == "" {
+// createDockerSecret creates the secret that authenticates the image push,
+// from the Quay.io robot account if there is a QuayRobotToken, the GitLab
+// deploy token if there is a GitLabDeployToken, or the Docker config file.
+func createDockerSecret(fs afero.Fs, o *BootstrapOptions, secretNS string) (*corev1.Secret, error) {
+	if o.GitLabDeployToken != "" {
+		username, token, err := imagerepo.ParseGitLabDeployToken(o.GitLabDeployToken)
+		if err != nil {
+			return nil, err
+		}
+		config, err := secrets.DockerConfigJSON(imagerepo.RegistryHost(o.ImageRepo), username, token)
+		if err != nil {
+			return nil, err
+		}
+		return secrets.CreateUnsealedDockerConfigSecret(meta.NamespacedName(secretNS, dockerSecretName), bytes.NewReader(config))
+	}
+	if o.QuayRobotToken != "" {
+		config, err := secrets.DockerConfigJSON(quayRegistry, o.QuayRobotAccount, o.QuayRobotToken)
+		if err != nil {
+			return nil, err
+		}
+		return secrets.CreateUnsealedDockerConfigSecret(meta.NamespacedName(secretNS, dockerSecretName), bytes.NewReader(config))
+	}
+	if o.DockerConfigJSONFilename == "" {
 		return nil, errors.New("failed to generate path to file: --dockerconfigjson flag is not provided")
 	}
-	authJSONPath, err := homedir.Expand(dockerConfigJSONFilename)
+	authJSONPath, err := homedir.Expand(o.DockerConfigJSONFilename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate path to file: %v", err)
 	}
@@ -502,6 +1037,215 @@ func createDockerSecret(fs afero.Fs, dockerConfigJSONFilename, secretNS string)
 	return dockerSecret, nil
 }
 
+// hasDockerConfig returns true if a secret is generated to authenticate the
+// image push.
+func hasDockerConfig(o *BootstrapOptions) bool {
+	return o.DockerConfigJSONFilename != "" || o.QuayRobotToken != "" || o.GitLabDeployToken != ""
+}
+
+// buildOptions returns the options for the app-ci pipeline's image build.
+func buildOptions(o *BootstrapOptions) []pipelines.BuildOption {
+	opts := []pipelines.BuildOption{}
+	if o.BuildImage != "" {
+		opts = append(opts, pipelines.BuilderImage(o.BuildImage))
+	}
+	if len(o.BuildArgs) > 0 {
+		opts = append(opts, pipelines.BuildArgs(o.BuildArgs...))
+	}
+	return opts
+}
+
+// gitBranch returns the branch that the GitOps repository is pushed to.
+func gitBranch(o *BootstrapOptions) string {
+	if o.DefaultBranch != "" {
+		return o.DefaultBranch
+	}
+	return defaultBranch
+}
+
+// serviceAccountName returns the name of the service account that the
+// generated CI resources run as.
+func serviceAccountName(o *BootstrapOptions) string {
+	if o.ServiceAccount != "" {
+		return o.ServiceAccount
+	}
+	return config.DefaultServiceAccountName
+}
+
+// clusterRoleName returns the name of the ClusterRole that the pipeline service
+// account is bound to.
+func clusterRoleName(o *BootstrapOptions) string {
+	if o.ExistingClusterRole != "" {
+		return o.ExistingClusterRole
+	}
+	return roles.ClusterRoleName
+}
+
+// validateDockerConfigRegistry returns an error if the registry of an external
+// image repository has no entry in the Docker config, as pushing the images
+// would fail to authenticate.
+func validateDockerConfigRegistry(dockerSecret *corev1.Secret, imageRepo string) error {
+	isInternalRegistry, imageRepo, err := imagerepo.ValidateImageRepo(imageRepo)
+	if err != nil || isInternalRegistry {
+		return err
+	}
+	var dockerConfig struct {
+		Auths       map[string]json.RawMessage `json:"auths"`
+		CredHelpers map[string]string          `json:"credHelpers"`
+	}
+	if err := json.Unmarshal(dockerSecret.Data[corev1.DockerConfigJsonKey], &dockerConfig); err != nil {
+		return fmt.Errorf("failed to parse Docker config: %w", err)
+	}
+	host := strings.Split(imageRepo, "/")[0]
+	registries := []string{}
+	for k := range dockerConfig.Auths {
+		registries = append(registries, k)
+	}
+	for k := range dockerConfig.CredHelpers {
+		registries = append(registries, k)
+	}
+	for _, r := range registries {
+		if registryHost(r) == registryHost(host) {
+			return nil
+		}
+	}
+	configured := "none"
+	if len(registries) > 0 {
+		configured = strings.Join(uniqueSorted(registries), ", ")
+	}
+	return fmt.Errorf("the Docker config has no credentials for the image repository registry %s, the configured registries are: %s", host, configured)
+}
+
+// ValidateImageRepoAuth returns an error if the Docker config can't be read,
+// or has no credentials for the registry of an external image repository.
+//
+// The internal registry is authenticated with the pipeline service account,
+// so no Docker config is needed.
+func ValidateImageRepoAuth(o *BootstrapOptions, fs afero.Fs) error {
+	if o.ImageRepo == "" {
+		return nil
+	}
+	isInternalRegistry, _, err := imagerepo.ValidateImageRepo(o.ImageRepo)
+	if err != nil || isInternalRegistry {
+		return err
+	}
+	dockerSecret, err := createDockerSecret(fs, o, "")
+	if err != nil {
+		return err
+	}
+	return validateDockerConfigRegistry(dockerSecret, o.ImageRepo)
+}
+
+// registryHost returns the host of a registry in a Docker config, the Docker
+// Hub registry can be configured with several names.
+func registryHost(registry string) string {
+	host := strings.ToLower(registry)
+	host = strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://")
+	host = strings.Split(host, "/")[0]
+	switch host {
+	case "index.docker.io", "registry-1.docker.io":
+		return "docker.io"
+	}
+	return host
+}
+
+// addPipelineRunTTL annotates the PipelineRuns in the TriggerTemplates at the
+// paths so that the Tekton pruner deletes them once they have finished for
+// the ttl.
+func addPipelineRunTTL(outputs res.Resources, ttl string, paths ...string) error {
+	d, err := time.ParseDuration(ttl)
+	if err != nil {
+		return fmt.Errorf("invalid PipelineRun TTL %q: %w", ttl, err)
+	}
+	for _, p := range paths {
+		t, ok := outputs[p].(triggersv1.TriggerTemplate)
+		if !ok {
+			continue
+		}
+		outputs[p], err = triggers.WithPipelineRunTTL(t, d)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addPipelineNamePrefix prefixes the names of the TriggerTemplates at the paths
+// and the Pipelines that they reference.
+func addPipelineNamePrefix(outputs res.Resources, prefix string, paths ...string) error {
+	for _, p := range paths {
+		t, ok := outputs[p].(triggersv1.TriggerTemplate)
+		if !ok {
+			continue
+		}
+		var err error
+		outputs[p], err = triggers.WithPipelineNamePrefix(t, prefix)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateConfig returns the pipelines configuration that the TriggerTemplates
+// and the EventListener's Route are generated from, it's recorded in the
+// manifest so that they can be rebuilt.
+func templateConfig(o *BootstrapOptions) *config.PipelinesConfig {
+	return &config.PipelinesConfig{
+		DisableAppCI:        o.NoAppCI,
+		PipelineNamePrefix:  o.PipelineNamePrefix,
+		TektonAPIVersion:    o.TektonAPIVersion,
+		PipelineRunTTL:      o.PipelineRunTTL,
+		RouteWildcardPolicy: o.RouteWildcardPolicy,
+		RouteSubdomain:      o.RouteSubdomain,
+		WebhookTLS:          o.WebhookTLS,
+	}
+}
+
+// createTriggerTemplates creates the TriggerTemplates for the CI dry-run, and
+// the app-ci builds unless they're disabled, keyed by their paths relative to
+// the CI/CD base.
+func createTriggerTemplates(ns, saName string, cfg *config.PipelinesConfig) (res.Resources, error) {
+	templates := res.Resources{}
+	// PipelineResources are not available in tekton.dev/v1 so the CI dry-run
+	// clones the GitOps repository into a workspace.
+	if cfg.TektonAPIVersion == tekton.V1 {
+		templates[pushTemplatePath] = triggers.CreateCIDryRunWorkspaceTemplate(ns, saName)
+	} else {
+		templates[pushTemplatePath] = triggers.CreateCIDryRunTemplate(ns, saName)
+	}
+	if !cfg.DisableAppCI {
+		templates[appCIPushTemplatePath] = triggers.CreateDevCIBuildPRTemplate(ns, saName)
+	}
+	if cfg.PipelineNamePrefix != "" {
+		if err := addPipelineNamePrefix(templates, cfg.PipelineNamePrefix, pushTemplatePath, appCIPushTemplatePath); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.PipelineRunTTL != "" {
+		if err := addPipelineRunTTL(templates, cfg.PipelineRunTTL, pushTemplatePath, appCIPushTemplatePath); err != nil {
+			return nil, err
+		}
+	}
+	return tekton.ConvertResources(templates, cfg.TektonAPIVersion)
+}
+
+// createEventListenerRoute creates the Route that exposes the EventListener
+// for the webhooks.
+func createEventListenerRoute(ns string, cfg *config.PipelinesConfig) (interface{}, error) {
+	routeOptions := []eventlisteners.RouteOption{}
+	if cfg.RouteWildcardPolicy != "" {
+		routeOptions = append(routeOptions, eventlisteners.WithWildcardPolicy(cfg.RouteWildcardPolicy))
+	}
+	if cfg.RouteSubdomain != "" {
+		routeOptions = append(routeOptions, eventlisteners.WithSubdomain(cfg.RouteSubdomain))
+	}
+	if cfg.WebhookTLS {
+		routeOptions = append(routeOptions, eventlisteners.WithEdgeTLS())
+	}
+	return eventlisteners.GenerateRoute(ns, routeOptions...)
+}
+
 // createCICDResources creates resources for OpenShift pipelines.
 func createCICDResources(fs afero.Fs, repo scm.Repository, pipelineConfig *config.PipelinesConfig, o *BootstrapOptions) (res.Resources, res.Resources, error) {
 	cicdNamespace := pipelineConfig.Name
@@ -515,19 +1259,37 @@ func createCICDResources(fs afero.Fs, repo scm.Repository, pipelineConfig *confi
 	}
 	unEncSecretPath := filepath.Join("secrets", "gitops-webhook-secret.yaml")
 	otherOutputs[unEncSecretPath] = githubSecret
-	outputs[namespacesPath] = namespaces.Create(cicdNamespace, o.GitOpsRepoURL)
-	outputs[rolesPath] = roles.CreateClusterRole(meta.NamespacedName("", roles.ClusterRoleName), Rules)
+	if o.NamespacedInstall {
+		outputs[rolesPath] = roles.CreateRole(meta.NamespacedName(cicdNamespace, roles.RoleName), NamespacedRules)
+	} else {
+		outputs[namespacesPath] = namespaces.Generate(cicdNamespace, o.GitOpsRepoURL, o.UseProjectRequests)
+		if o.ExistingClusterRole == "" {
+			outputs[rolesPath] = roles.CreateClusterRole(meta.NamespacedName("", roles.ClusterRoleName), Rules)
+		}
+	}
 
+	saName := serviceAccountName(o)
 	sa := roles.CreateServiceAccount(meta.NamespacedName(cicdNamespace, saName))
+	if saName != config.DefaultServiceAccountName {
+		// OpenShift Pipelines only creates the default service account.
+		outputs[serviceAccountPath] = sa
+	}
 
-	if o.DockerConfigJSONFilename != "" {
-		dockerUnencryptedSecret, err := createDockerSecret(fs, o.DockerConfigJSONFilename, cicdNamespace)
+	if hasDockerConfig(o) {
+		dockerUnencryptedSecret, err := createDockerSecret(fs, o, cicdNamespace)
 		if err != nil {
 			return nil, nil, err
 		}
+		if o.ImageRepo != "" {
+			if err := validateDockerConfigRegistry(dockerUnencryptedSecret, o.ImageRepo); err != nil {
+				return nil, nil, err
+			}
+		}
 		if dockerUnencryptedSecret != nil {
 			otherOutputs[filepath.Join("secrets", "docker-config.yaml")] = dockerUnencryptedSecret
-			log.Success("Authentication tokens for docker config not sealed in secrets")
+			if !o.Quiet {
+				log.Success("Authentication tokens for docker config not sealed in secrets")
+			}
 		}
 		outputs[serviceAccountPath] = roles.AddSecretToSA(sa, dockerSecretName)
 	}
@@ -539,32 +1301,111 @@ func createCICDResources(fs afero.Fs, repo scm.Repository, pipelineConfig *confi
 		}
 	}
 
-	outputs[argocdAdminRolePath] = argocd.MakeApplicationControllerAdmin(cicdNamespace)
+	outputs[argocdAdminRolePath] = argocd.MakeApplicationControllerAdmin(argoCDNamespace(o), cicdNamespace)
 
-	outputs[rolebindingsPath] = roles.CreateClusterRoleBinding(meta.NamespacedName("", roleBindingName), sa, "ClusterRole", roles.ClusterRoleName)
+	if o.NamespacedInstall {
+		outputs[rolebindingsPath] = roles.CreateRoleBinding(meta.NamespacedName(cicdNamespace, roleBindingName), sa, "Role", roles.RoleName)
+	} else {
+		outputs[rolebindingsPath] = roles.CreateClusterRoleBinding(meta.NamespacedName("", roleBindingName), sa, "ClusterRole", clusterRoleName(o))
+	}
 	script, err := dryrun.MakeScript("kubectl", cicdNamespace)
 	if err != nil {
 		return nil, otherOutputs, err
 	}
-	outputs[gitopsTasksPath] = tasks.CreateDeployFromSourceTask(cicdNamespace, script)
-	outputs[commitStatusTaskPath] = tasks.CreateCommitStatusTask(cicdNamespace)
-	outputs[ciPipelinesPath] = pipelines.CreateCIPipeline(meta.NamespacedName(cicdNamespace, "ci-dryrun-from-push-pipeline"), cicdNamespace)
-	outputs[appCiPipelinesPath] = pipelines.CreateAppCIPipeline(meta.NamespacedName(cicdNamespace, "app-ci-pipeline"))
-	pushBinding, pushBindingName := repo.CreatePushBinding(cicdNamespace)
-	outputs[filepath.ToSlash(filepath.Join("05-bindings", pushBindingName+".yaml"))] = pushBinding
-	outputs[pushTemplatePath] = triggers.CreateCIDryRunTemplate(cicdNamespace, saName)
-	outputs[appCIPushTemplatePath] = triggers.CreateDevCIBuildPRTemplate(cicdNamespace, saName)
-	outputs[eventListenerPath] = eventlisteners.Generate(repo, cicdNamespace, saName, eventlisteners.GitOpsWebhookSecret)
-	log.Success("OpenShift Pipelines resources created")
-	route, err := eventlisteners.GenerateRoute(cicdNamespace)
+	commitStatusTask, err := createCommitStatusTask(repo, cicdNamespace)
+	if err != nil {
+		return nil, otherOutputs, err
+	}
+	outputs[commitStatusTaskPath] = commitStatusTask
+	if !o.NoAppCI {
+		outputs[appCiPipelinesPath] = pipelines.CreateAppCIPipeline(meta.NamespacedName(cicdNamespace, o.PipelineNamePrefix+"app-ci-pipeline"), buildOptions(o)...)
+	}
+	// PipelineResources are not available in tekton.dev/v1 so the CI dry-run
+	// clones the GitOps repository into a workspace.
+	if o.TektonAPIVersion == tekton.V1 {
+		outputs[gitopsTasksPath] = tasks.CreateDeployFromSourceWorkspaceTask(cicdNamespace, script, o.RepoSubpath)
+		outputs[ciPipelinesPath] = pipelines.CreateCIWorkspacePipeline(meta.NamespacedName(cicdNamespace, o.PipelineNamePrefix+"ci-dryrun-from-push-pipeline"), cicdNamespace)
+	} else {
+		outputs[gitopsTasksPath] = tasks.CreateDeployFromSourceTask(cicdNamespace, script, o.RepoSubpath)
+		outputs[ciPipelinesPath] = pipelines.CreateCIPipeline(meta.NamespacedName(cicdNamespace, o.PipelineNamePrefix+"ci-dryrun-from-push-pipeline"), cicdNamespace)
+	}
+	outputs = res.Merge(gitOpsRepoBindings(repo, cicdNamespace, o.PipelineNamePrefix, o.DryRunTrigger), outputs)
+	templates, err := createTriggerTemplates(cicdNamespace, saName, templateConfig(o))
+	if err != nil {
+		return nil, nil, err
+	}
+	outputs = res.Merge(templates, outputs)
+	interceptors := []*triggersv1.EventInterceptor{}
+	if o.WebhookInterceptorURL != "" {
+		interceptor, err := eventlisteners.WebhookInterceptor(o.WebhookInterceptorURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		interceptors = append(interceptors, interceptor)
+	}
+	eventListener := eventlisteners.Generate(repo, cicdNamespace, saName, eventlisteners.GitOpsWebhookSecret, o.PipelineNamePrefix, o.DryRunTrigger, interceptors...)
+	if elResources := eventListenerResources(o); elResources != nil {
+		resources, err := elResources.Requirements()
+		if err != nil {
+			return nil, nil, err
+		}
+		eventlisteners.SetResources(&eventListener, resources)
+	}
+	outputs[eventListenerPath] = eventListener
+	outputs, err = tekton.ConvertResources(outputs, o.TektonAPIVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !o.Quiet {
+		log.Success("OpenShift Pipelines resources created")
+	}
+	route, err := createEventListenerRoute(cicdNamespace, templateConfig(o))
 	if err != nil {
 		return nil, nil, err
 	}
 	outputs[routePath] = route
-	log.Success("Openshift Route for EventListener created")
+	if !o.Quiet {
+		log.Success("Openshift Route for EventListener created")
+	}
 	return outputs, otherOutputs, nil
 }
 
+// createCommitStatusTask creates the task that sets the commit statuses, with
+// the API of the GitHub Enterprise Server or Azure DevOps organization if the
+// repository is hosted on one.
+func createCommitStatusTask(repo scm.Repository, ns string) (*pipelinev1.Task, error) {
+	azureURL, err := scm.AzureDevOpsAPIURL(repo.URL())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the API URL of %q: %w", repo.URL(), err)
+	}
+	if azureURL != "" {
+		return tasks.CreateAzureCommitStatusTask(ns, azureURL), nil
+	}
+	apiURL, err := scm.GitHubEnterpriseAPIURL(repo.URL())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the API URL of %q: %w", repo.URL(), err)
+	}
+	if apiURL != "" {
+		return tasks.CreateEnterpriseCommitStatusTask(ns, apiURL), nil
+	}
+	return tasks.CreateCommitStatusTask(ns), nil
+}
+
+// eventListenerResources returns the EventListener's compute resources from
+// the options, or nil if none are set.
+func eventListenerResources(o *BootstrapOptions) *config.Resources {
+	r := &config.Resources{
+		CPURequest:    o.EventListenerCPURequest,
+		CPULimit:      o.EventListenerCPULimit,
+		MemoryRequest: o.EventListenerMemoryRequest,
+		MemoryLimit:   o.EventListenerMemoryLimit,
+	}
+	if *r == (config.Resources{}) {
+		return nil
+	}
+	return r
+}
+
 func createManifest(gitOpsRepoURL string, configEnv *config.Config, envs ...*config.Environment) *config.Manifest {
 	return &config.Manifest{
 		GitOpsURL:    gitOpsRepoURL,
@@ -574,15 +1415,35 @@ func createManifest(gitOpsRepoURL string, configEnv *config.Config, envs ...*con
 	}
 }
 
-func getCICDKustomization(files []string) res.Resources {
+// cicdBuiltinKinds are the definitions of the built-in kinds in the CI/CD
+// configuration, which are included in the OpenAPI schema of its overlays.
+var cicdBuiltinKinds = []string{
+	"io.k8s.api.core.v1.Namespace",
+	"io.k8s.api.core.v1.ServiceAccount",
+	"io.k8s.api.rbac.v1.ClusterRole",
+	"io.k8s.api.rbac.v1.ClusterRoleBinding",
+	"io.k8s.api.rbac.v1.Role",
+	"io.k8s.api.rbac.v1.RoleBinding",
+}
+
+// getCICDKustomization returns the kustomizations of the CI/CD configuration,
+// the overlays use an OpenAPI schema so that patches to the EventListener are
+// merged.
+func getCICDKustomization(files []string) (res.Resources, error) {
+	schema, err := openapi.Schema(cicdBuiltinKinds, openapi.EventListener)
+	if err != nil {
+		return nil, err
+	}
 	return res.Resources{
 		"overlays/kustomization.yaml": res.Kustomization{
-			Bases: []string{"../base"},
+			Bases:   []string{"../base"},
+			OpenAPI: &res.OpenAPI{Path: openapi.Filename},
 		},
+		"overlays/" + openapi.Filename: schema,
 		"base/kustomization.yaml": res.Kustomization{
 			Resources: files,
 		},
-	}
+	}, nil
 }
 
 func pipelinesPath(m *config.Config) string {
@@ -616,12 +1477,16 @@ func generateSecrets(outputs res.Resources, otherOutputs res.Resources, sa *core
 	outputs[serviceAccountPath] = roles.AddSecretToSA(sa, tokenSecret.Name)
 
 	// basic auth token is used by Tekton pipelines to access private repositories
-	secretTargetHost, err := repoURL(o.ServiceRepoURL)
+	secretTargetHost, err := cloneHost(o.GitCloneHost, o.ServiceRepoURL)
 	if err != nil {
 		return fmt.Errorf("failed to parse the Service Repo URL %q: %w", o.ServiceRepoURL, err)
 	}
+	username := o.GitUsername
+	if username == "" {
+		username = secrets.DefaultBasicAuthUsername
+	}
 	basicAuthSecret := secrets.CreateUnsealedBasicAuthSecret(meta.NamespacedName(
-		ns, basicAuthTokenName), o.GitHostAccessToken, meta.AddAnnotations(map[string]string{
+		ns, basicAuthTokenName), username, o.GitHostAccessToken, meta.AddAnnotations(map[string]string{
 		"tekton.dev/git-0": secretTargetHost,
 	}))
 	otherOutputs[filepath.Join("secrets", basicAuthTokenName+".yaml")] = basicAuthSecret
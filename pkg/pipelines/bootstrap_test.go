@@ -1,24 +1,44 @@
 package pipelines
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jenkins-x/go-scm/scm/factory"
+	projectv1 "github.com/openshift/api/project/v1"
 	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
+	argoappv1 "github.com/redhat-developer/kam/pkg/pipelines/argocd/v1alpha1"
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/deployment"
 	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	"github.com/redhat-developer/kam/pkg/pipelines/openapi"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
 	"github.com/redhat-developer/kam/pkg/pipelines/roles"
 	"github.com/redhat-developer/kam/pkg/pipelines/routes"
 	"github.com/redhat-developer/kam/pkg/pipelines/scm"
 	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
+	"github.com/redhat-developer/kam/pkg/pipelines/tasks"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	"github.com/redhat-developer/kam/test"
+	"github.com/spf13/afero"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	v1rbac "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/api/validation"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/kustomize/api/krusty"
 )
 
 const (
@@ -54,7 +74,7 @@ func TestBootstrapManifest(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	svc := createBootstrapService("app-http-api", "tst-dev", "http-api")
+	svc := createBootstrapService("app-http-api", "http-api")
 	route, err := routes.NewFromService(svc)
 	if err != nil {
 		t.Fatal(err)
@@ -71,8 +91,12 @@ func TestBootstrapManifest(t *testing.T) {
 
 	want := res.Resources{
 		"environments/tst-dev/apps/app-http-api/services/http-api/base/config/100-deployment.yaml": deployment.Create(
-			"app-http-api", "tst-dev", "http-api", bootstrapImage,
-			deployment.ContainerPort(8080)),
+			"app-http-api", "", "http-api", DefaultBootstrapImage,
+			deployment.ContainerPort(8080),
+			deployment.Resources(corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("128Mi")},
+				Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("256Mi")},
+			})),
 		"environments/tst-dev/apps/app-http-api/services/http-api/base/config/200-service.yaml": svc,
 		"environments/tst-dev/apps/app-http-api/services/http-api/base/config/300-route.yaml":   route,
 		"environments/tst-dev/apps/app-http-api/services/http-api/base/config/kustomization.yaml": &res.Kustomization{
@@ -152,6 +176,1227 @@ func TestBootstrapManifest(t *testing.T) {
 	}
 }
 
+func TestBootstrapManifestWithInternalRegistryProject(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:                  "tst-",
+		GitOpsRepoURL:           testGitOpsRepo,
+		InternalRegistryProject: "tst-dev",
+		GitOpsWebhookSecret:     "123",
+		ServiceRepoURL:          testSvcRepo,
+		ServiceWebhookSecret:    "456",
+	}
+	r, _, err := bootstrapResources(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	if params.ImageRepo != "tst-dev/http-api" {
+		t.Fatalf("got image repo %q, want %q", params.ImageRepo, "tst-dev/http-api")
+	}
+	k := r["config/tst-cicd/base/kustomization.yaml"].(res.Kustomization)
+	for _, v := range k.Resources {
+		if v == "01-namespaces/tst-dev-environment.yaml" {
+			t.Fatalf("namespace for the dev environment should not be created in the CI/CD configuration")
+		}
+	}
+	wantBinding := "02-rolebindings/internal-registry-tst-dev-binding.yaml"
+	if _, ok := r["config/tst-cicd/base/"+wantBinding]; !ok {
+		t.Fatalf("internal registry role binding %s not found", wantBinding)
+	}
+}
+
+func TestBootstrapManifestWithDriverMapFile(t *testing.T) {
+	defer func(id factory.HostDriverIdentifier) {
+		factory.DefaultIdentifier = id
+	}(factory.DefaultIdentifier)
+	config.SetDriverMappings(map[string]string{"scm.example.com": "github"})
+	fakeFs := ioutils.NewMemoryFilesystem()
+	err := afero.WriteFile(fakeFs, "/drivers.yaml", []byte("gitlab.example.com: gitlab\n"), 0644)
+	fatalIfError(t, err)
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        "https://scm.example.com/my-org/gitops.git",
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		ServiceRepoURL:       "https://scm.example.com/my-org/http-api.git",
+		ServiceWebhookSecret: "456",
+		PrivateRepoDriver:    "github",
+		DriverMapFile:        "/drivers.yaml",
+	}
+	r, _, err := bootstrapResources(params, fakeFs)
+	fatalIfError(t, err)
+
+	m := r["pipelines.yaml"].(*config.Manifest)
+	want := &config.GitConfig{Drivers: map[string]string{"gitlab.example.com": "gitlab", "scm.example.com": "github"}}
+	if diff := cmp.Diff(want, m.Config.Git); diff != "" {
+		t.Fatalf("git config mismatch:\n%s", diff)
+	}
+}
+
+func TestBootstrapWithGitHubEnterprise(t *testing.T) {
+	defer func(id factory.HostDriverIdentifier) {
+		factory.DefaultIdentifier = id
+	}(factory.DefaultIdentifier)
+	config.SetDriverMappings(map[string]string{"ghe.example.com": "github"})
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        "https://ghe.example.com/my-org/gitops.git",
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       "https://ghe.example.com/my-org/http-api.git",
+		ServiceWebhookSecret: "456",
+		PrivateRepoDriver:    "github",
+	}
+	r, _, err := bootstrapResources(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	task := r["config/tst-cicd/base/03-tasks/set-commit-status-task.yaml"].(*pipelinev1.Task)
+	if diff := cmp.Diff(tasks.CreateEnterpriseCommitStatusTask("tst-cicd", "https://ghe.example.com/api/v3"), task); diff != "" {
+		t.Fatalf("commit status task mismatch:\n%s", diff)
+	}
+}
+
+func TestBootstrapWithAzureDevOps(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        "https://dev.azure.com/my-org/my-project/_git/gitops.git",
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       "https://dev.azure.com/my-org/my-project/_git/http-api.git",
+		ServiceWebhookSecret: "456",
+	}
+	r, _, err := bootstrapResources(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	task := r["config/tst-cicd/base/03-tasks/set-commit-status-task.yaml"].(*pipelinev1.Task)
+	if diff := cmp.Diff(tasks.CreateAzureCommitStatusTask("tst-cicd", "https://dev.azure.com/my-org"), task); diff != "" {
+		t.Fatalf("commit status task mismatch:\n%s", diff)
+	}
+	m := r["pipelines.yaml"].(*config.Manifest)
+	svc := m.GetEnvironment("tst-dev").Apps[0].Services[0]
+	if svc.Name != "http-api" {
+		t.Fatalf("got service %q, want http-api", svc.Name)
+	}
+}
+
+func TestBootstrapManifestWithSecretsRepo(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		SecretsRepoURL:       "https://github.com/my-org/secrets.git",
+	}
+	r, _, err := bootstrapResources(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	m := r["pipelines.yaml"].(*config.Manifest)
+	want := &config.Repository{URL: "https://github.com/my-org/secrets.git", Path: "."}
+	if diff := cmp.Diff(want, m.Config.SecretsRepo); diff != "" {
+		t.Fatalf("secrets repo mismatch:\n%s", diff)
+	}
+}
+
+func TestBootstrapManifestWithRouteOptions(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		RouteWildcardPolicy:  "Subdomain",
+		RouteSubdomain:       "webhooks",
+	}
+	r, _, err := bootstrapResources(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	route := r[filepath.Join("config/tst-cicd/base", routePath)].(map[string]interface{})
+	spec := route["spec"].(map[string]interface{})
+	if spec["wildcardPolicy"] != "Subdomain" || spec["subdomain"] != "webhooks" {
+		t.Fatalf("route spec didn't have the wildcard policy and subdomain: %#v", spec)
+	}
+}
+
+func TestBootstrapManifestWithWebhookTLS(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		WebhookTLS:           true,
+	}
+	r, _, err := bootstrapResources(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	route := r[filepath.Join("config/tst-cicd/base", routePath)].(map[string]interface{})
+	tls := route["spec"].(map[string]interface{})["tls"].(map[string]interface{})
+	if tls["termination"] != "edge" || tls["insecureEdgeTerminationPolicy"] != "Redirect" {
+		t.Fatalf("route wasn't edge terminated: %#v", tls)
+	}
+}
+
+func TestBootstrapManifestWithNamespacedInstall(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		NamespacedInstall:    true,
+	}
+	r, _, err := bootstrapResources(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	for k, v := range r {
+		switch v.(type) {
+		case *corev1.Namespace, *v1rbac.ClusterRole, *v1rbac.ClusterRoleBinding:
+			t.Errorf("cluster-scoped resource generated in %s", k)
+		}
+	}
+	sa := roles.CreateServiceAccount(meta.NamespacedName("tst-cicd", config.DefaultServiceAccountName))
+	want := res.Resources{
+		"config/tst-cicd/base/02-rolebindings/pipeline-service-role.yaml":        roles.CreateRole(meta.NamespacedName("tst-cicd", roles.RoleName), NamespacedRules),
+		"config/tst-cicd/base/02-rolebindings/pipeline-service-rolebinding.yaml": roles.CreateRoleBinding(meta.NamespacedName("tst-cicd", roleBindingName), sa, "Role", roles.RoleName),
+	}
+	if diff := cmp.Diff(want, r, cmpopts.IgnoreMapEntries(func(k string, v interface{}) bool {
+		_, ok := want[k]
+		return !ok
+	})); diff != "" {
+		t.Fatalf("bootstrapped resources:\n%s", diff)
+	}
+	if !r["pipelines.yaml"].(*config.Manifest).IsNamespacedInstall() {
+		t.Fatal("namespaced install not recorded in the manifest")
+	}
+}
+
+func TestBootstrapManifestWithNoAppCI(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		NoAppCI:              true,
+	}
+	r, _, err := bootstrapResources(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	for _, k := range []string{
+		"config/tst-cicd/base/04-pipelines/app-ci-pipeline.yaml",
+		"config/tst-cicd/base/06-templates/app-ci-build-from-push-template.yaml",
+		"config/tst-cicd/base/05-bindings/tst-dev-app-http-api-http-api-binding.yaml",
+	} {
+		if _, ok := r[k]; ok {
+			t.Errorf("%s should not be generated", k)
+		}
+	}
+	k := r["config/tst-cicd/base/kustomization.yaml"].(res.Kustomization)
+	for _, name := range k.Resources {
+		if strings.Contains(name, "app-ci") || strings.HasSuffix(name, "-http-api-binding.yaml") {
+			t.Errorf("kustomization references %s", name)
+		}
+	}
+	if _, ok := r["config/tst-cicd/base/04-pipelines/ci-dryrun-from-push-pipeline.yaml"]; !ok {
+		t.Error("the ci dry-run pipeline was not generated")
+	}
+	m := r["pipelines.yaml"].(*config.Manifest)
+	if !m.GetPipelinesConfig().DisableAppCI {
+		t.Fatal("disabled app-ci not recorded in the manifest")
+	}
+	built, err := buildEventListenerResources(testGitOpsRepo, m)
+	fatalIfError(t, err)
+	el := built["config/tst-cicd/base/07-eventlisteners/cicd-event-listener.yaml"].(*triggersv1.EventListener)
+	for _, trigger := range el.Spec.Triggers {
+		if trigger.Name != "ci-dryrun-from-push" {
+			t.Errorf("unexpected trigger %s in the EventListener", trigger.Name)
+		}
+	}
+}
+
+func TestBootstrapWithPipelineRunTTL(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		PipelineRunTTL:       "24h",
+	}
+	r, _, err := bootstrapResources(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	for _, k := range []string{
+		"config/tst-cicd/base/06-templates/ci-dryrun-from-push-template.yaml",
+		"config/tst-cicd/base/06-templates/app-ci-build-from-push-template.yaml",
+	} {
+		template := r[k].(triggersv1.TriggerTemplate)
+		var pr map[string]interface{}
+		fatalIfError(t, json.Unmarshal(template.Spec.ResourceTemplates[0].Raw, &pr))
+		annotations := pr["metadata"].(map[string]interface{})["annotations"]
+		want := map[string]interface{}{triggers.PipelineRunTTLAnnotation: "86400"}
+		if diff := cmp.Diff(want, annotations); diff != "" {
+			t.Errorf("%s annotations didn't match:\n%s", k, diff)
+		}
+	}
+}
+
+func TestBootstrapWithWebhookInterceptor(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	params := &BootstrapOptions{
+		Prefix:                "tst-",
+		GitOpsRepoURL:         testGitOpsRepo,
+		ImageRepo:             "image/repo",
+		GitOpsWebhookSecret:   "123",
+		GitHostAccessToken:    "test-token",
+		ServiceRepoURL:        testSvcRepo,
+		ServiceWebhookSecret:  "456",
+		OutputPath:            "/out",
+		WebhookInterceptorURL: "http://audit.audit-ns.svc",
+	}
+	r, err := BootstrapToFs(params, fakeFs)
+	fatalIfError(t, err)
+
+	m := r[pipelinesFile].(*config.Manifest)
+	if u := m.GetPipelinesConfig().WebhookInterceptorURL; u != params.WebhookInterceptorURL {
+		t.Fatalf("got webhook interceptor URL %q in the manifest, want %q", u, params.WebhookInterceptorURL)
+	}
+	el := r["config/tst-cicd/base/07-eventlisteners/cicd-event-listener.yaml"].(*triggersv1.EventListener)
+	want := &triggersv1.WebhookInterceptor{
+		ObjectRef: &corev1.ObjectReference{APIVersion: "v1", Kind: "Service", Name: "audit", Namespace: "audit-ns"},
+	}
+	for _, trigger := range el.Spec.Triggers {
+		last := trigger.Interceptors[len(trigger.Interceptors)-1]
+		if diff := cmp.Diff(want, last.Webhook); diff != "" {
+			t.Errorf("trigger %s webhook interceptor didn't match:\n%s", trigger.Name, diff)
+		}
+	}
+}
+
+func TestBootstrapWithLabelsFromGit(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/out",
+		LabelsFromGit:        true,
+		KamVersion:           "v0.0.30",
+	}
+	r, err := BootstrapToFs(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	want := map[string]string{
+		"kam.redhat-developer/gitops-repo":          testGitOpsRepo,
+		"kam.redhat-developer/branch":               "main",
+		"kam.redhat-developer/generated-by-version": "v0.0.30",
+	}
+	annotations := map[string]map[string]string{
+		"config/argocd/argo-app.yaml":                                     r["config/argocd/argo-app.yaml"].(*argoappv1.Application).Annotations,
+		"config/tst-cicd/base/03-tasks/deploy-from-source-task.yaml":      r["config/tst-cicd/base/03-tasks/deploy-from-source-task.yaml"].(pipelinev1.Task).Annotations,
+		"config/tst-cicd/base/07-eventlisteners/cicd-event-listener.yaml": r["config/tst-cicd/base/07-eventlisteners/cicd-event-listener.yaml"].(*triggersv1.EventListener).Annotations,
+		"environments/tst-dev/env/base/tst-dev-environment.yaml":          r["environments/tst-dev/env/base/tst-dev-environment.yaml"].(*corev1.Namespace).Annotations,
+	}
+	route := r["config/tst-cicd/base/08-routes/gitops-webhook-event-listener.yaml"].(map[string]interface{})
+	routeAnnotations := map[string]string{}
+	for k, v := range route["metadata"].(map[string]interface{})["annotations"].(map[string]interface{}) {
+		routeAnnotations[k] = v.(string)
+	}
+	annotations["config/tst-cicd/base/08-routes/gitops-webhook-event-listener.yaml"] = routeAnnotations
+	for k, got := range annotations {
+		for name, value := range want {
+			if got[name] != value {
+				t.Errorf("%s got annotation %s %q, want %q", k, name, got[name], value)
+			}
+		}
+	}
+	if secret := r["../secrets/gitops-webhook-secret.yaml"].(*corev1.Secret); secret.Annotations != nil {
+		t.Errorf("secret was annotated: %v", secret.Annotations)
+	}
+}
+
+func TestBootstrapWithServerSideApply(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/out",
+		ApplyMode:            config.ApplyModeServerSide,
+	}
+	r, err := BootstrapToFs(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	m := r[pipelinesFile].(*config.Manifest)
+	if mode := m.GetArgoCDConfig().ApplyMode; mode != config.ApplyModeServerSide {
+		t.Fatalf("got apply mode %q in the manifest, want %q", mode, config.ApplyModeServerSide)
+	}
+	for _, k := range []string{"config/argocd/cicd-app.yaml", "config/argocd/tst-dev-env-app.yaml", "config/argocd/tst-dev-app-http-api-app.yaml"} {
+		app := r[k].(*argoappv1.Application)
+		if diff := cmp.Diff(argoappv1.SyncOptions{"ServerSideApply=true"}, app.Spec.SyncPolicy.SyncOptions); diff != "" {
+			t.Errorf("%s sync options didn't match:\n%s", k, diff)
+		}
+	}
+}
+
+func TestBootstrapWithImageUpdater(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:                   "tst-",
+		GitOpsRepoURL:            testGitOpsRepo,
+		ImageRepo:                "quay.io/my-org/http-api",
+		DockerConfigJSONFilename: "/config.json",
+		GitOpsWebhookSecret:      "123",
+		GitHostAccessToken:       "test-token",
+		ServiceRepoURL:           testSvcRepo,
+		ServiceWebhookSecret:     "456",
+		OutputPath:               "/out",
+		WithImageUpdater:         true,
+		ImageUpdateStrategy:      "digest",
+	}
+	fakeFs := ioutils.NewMemoryFilesystem()
+	fatalIfError(t, afero.WriteFile(fakeFs, "/config.json", []byte(`{"auths":{"quay.io":{}}}`), 0644))
+	r, err := BootstrapToFs(params, fakeFs)
+	fatalIfError(t, err)
+
+	m := r[pipelinesFile].(*config.Manifest)
+	if diff := cmp.Diff(&config.ImageUpdaterConfig{UpdateStrategy: "digest"}, m.GetArgoCDConfig().ImageUpdater); diff != "" {
+		t.Fatalf("image updater configuration didn't match:\n%s", diff)
+	}
+	app := r["config/argocd/tst-dev-app-http-api-app.yaml"].(*argoappv1.Application)
+	want := map[string]string{
+		"argocd-image-updater.argoproj.io/image-list":                    "http-api=quay.io/my-org/http-api",
+		"argocd-image-updater.argoproj.io/http-api.update-strategy":      "digest",
+		"argocd-image-updater.argoproj.io/http-api.kustomize.image-name": "nginxinc/nginx-unprivileged",
+		"argocd-image-updater.argoproj.io/write-back-method":             "git",
+	}
+	if diff := cmp.Diff(want, app.Annotations); diff != "" {
+		t.Fatalf("application annotations didn't match:\n%s", diff)
+	}
+}
+
+func TestBootstrapWithPRPreviews(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "quay.io/my-org/http-api",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/out",
+		WithPRPreviews:       true,
+	}
+	r, err := BootstrapToFs(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	appSet := r["config/argocd/tst-dev-app-http-api-http-api-previews.yaml"].(*argoappv1.ApplicationSet)
+	want := &argoappv1.PullRequestGenerator{
+		Github: &argoappv1.PullRequestGeneratorGithub{
+			Owner:    "my-org",
+			Repo:     "http-api",
+			TokenRef: &argoappv1.SecretRef{SecretName: prPreviewsTokenSecretName, Key: "token"},
+		},
+	}
+	if diff := cmp.Diff(want, appSet.Spec.Generators[0].PullRequest); diff != "" {
+		t.Fatalf("pull request generator didn't match:\n%s", diff)
+	}
+	secret := r["../secrets/pr-previews-access-token.yaml"].(*corev1.Secret)
+	if secret.Namespace != argocd.ArgoCDNamespace {
+		t.Fatalf("got token secret in namespace %q, want %q", secret.Namespace, argocd.ArgoCDNamespace)
+	}
+	if token := string(secret.Data["token"]); token != "test-token" {
+		t.Fatalf("got token %q, want test-token", token)
+	}
+}
+
+// The previews are deployed into the namespace of the ApplicationSet's
+// destination, so the source must build without a namespace.
+func TestBootstrapWithPRPreviewsBuildsWithoutNamespace(t *testing.T) {
+	for _, perEnvOverlays := range []bool{false, true} {
+		t.Run(fmt.Sprintf("per-env overlays %v", perEnvOverlays), func(t *testing.T) {
+			fakeFs := ioutils.NewMemoryFilesystem()
+			params := &BootstrapOptions{
+				Prefix:               "tst-",
+				GitOpsRepoURL:        testGitOpsRepo,
+				ImageRepo:            "quay.io/my-org/http-api",
+				GitOpsWebhookSecret:  "123",
+				GitHostAccessToken:   "test-token",
+				ServiceRepoURL:       testSvcRepo,
+				ServiceWebhookSecret: "456",
+				OutputPath:           "/out",
+				WithPRPreviews:       true,
+				PerEnvOverlays:       perEnvOverlays,
+			}
+			r, err := BootstrapToFs(params, fakeFs)
+			fatalIfError(t, err)
+
+			appSet := r["config/argocd/tst-dev-app-http-api-http-api-previews.yaml"].(*argoappv1.ApplicationSet)
+			kfs, err := copyToKustomizeFs(fakeFs, params.GitOpsPath())
+			fatalIfError(t, err)
+			m, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(kfs, "/"+appSet.Spec.Template.Spec.Source.Path)
+			fatalIfError(t, err)
+			if m.Size() != 3 {
+				t.Fatalf("got %d resources, want the Deployment, Service and Route", m.Size())
+			}
+			for _, r := range m.Resources() {
+				if ns := r.GetNamespace(); ns != "" {
+					t.Errorf("got %s namespace %q, want the preview's namespace", r.GetKind(), ns)
+				}
+			}
+		})
+	}
+}
+
+func TestBootstrapWithEventListenerResources(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:                     "tst-",
+		GitOpsRepoURL:              testGitOpsRepo,
+		ImageRepo:                  "image/repo",
+		GitOpsWebhookSecret:        "123",
+		GitHostAccessToken:         "test-token",
+		ServiceRepoURL:             testSvcRepo,
+		ServiceWebhookSecret:       "456",
+		OutputPath:                 "/out",
+		EventListenerCPURequest:    "250m",
+		EventListenerMemoryRequest: "256Mi",
+		EventListenerMemoryLimit:   "512Mi",
+	}
+	fakeFs := ioutils.NewMemoryFilesystem()
+	r, err := BootstrapToFs(params, fakeFs)
+	fatalIfError(t, err)
+
+	want := &config.Resources{CPURequest: "250m", MemoryRequest: "256Mi", MemoryLimit: "512Mi"}
+	m := r[pipelinesFile].(*config.Manifest)
+	if diff := cmp.Diff(want, m.GetPipelinesConfig().EventListenerResources); diff != "" {
+		t.Fatalf("event listener resources configuration didn't match:\n%s", diff)
+	}
+	el := r["config/tst-cicd/base/07-eventlisteners/cicd-event-listener.yaml"].(*triggersv1.EventListener)
+	requirements := el.Spec.Resources.KubernetesResource.Template.Spec.Containers[0].Resources
+	if cpu := requirements.Requests[corev1.ResourceCPU]; cpu.String() != "250m" {
+		t.Fatalf("got CPU request %s, want 250m", cpu.String())
+	}
+	if memory := requirements.Limits[corev1.ResourceMemory]; memory.String() != "512Mi" {
+		t.Fatalf("got memory limit %s, want 512Mi", memory.String())
+	}
+
+	// The build regenerates the same EventListener from the manifest.
+	built, err := buildEventListenerResources(testGitOpsRepo, m)
+	fatalIfError(t, err)
+	rebuilt := built["config/tst-cicd/base/07-eventlisteners/cicd-event-listener.yaml"].(*triggersv1.EventListener)
+	if diff := cmp.Diff(el.Spec.Resources, rebuilt.Spec.Resources); diff != "" {
+		t.Fatalf("built event listener resources didn't match:\n%s", diff)
+	}
+}
+
+func TestBootstrapManifestOnly(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:             "tst-",
+		GitOpsRepoURL:      testGitOpsRepo,
+		ImageRepo:          "image/repo",
+		GitHostAccessToken: "test-token",
+		ServiceRepoURL:     testSvcRepo,
+		OutputPath:         "/out",
+		ManifestOut:        "/gitops/pipelines.yaml",
+		Quiet:              true,
+	}
+	fakeFs := ioutils.NewMemoryFilesystem()
+	m, err := BootstrapManifest(params, fakeFs)
+	fatalIfError(t, err)
+
+	loaded, err := config.LoadManifest(fakeFs, "/gitops")
+	fatalIfError(t, err)
+	if diff := cmp.Diff(m, loaded); diff != "" {
+		t.Fatalf("written manifest didn't match:\n%s", diff)
+	}
+	for _, path := range []string{"/out", "/gitops/config", "/gitops/environments", "/secrets"} {
+		if exists, _ := ioutils.IsExisting(fakeFs, path); exists {
+			t.Fatalf("%s was written with the manifest", path)
+		}
+	}
+
+	err = BuildResources(&BuildParameters{PipelinesFolderPath: "/gitops", OutputPath: "/gitops"}, fakeFs)
+	fatalIfError(t, err)
+	if exists, _ := ioutils.IsExisting(fakeFs, "/gitops/config/argocd/tst-dev-app-http-api-app.yaml"); !exists {
+		t.Fatal("the resources were not built from the manifest")
+	}
+
+	_, err = BootstrapManifest(params, fakeFs)
+	test.AssertErrorMatch(t, "/gitops/pipelines.yaml already exists", err)
+}
+
+func TestBootstrapWithContainerResources(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/out",
+		CPURequest:           "250m",
+		MemoryLimit:          "1Gi",
+	}
+	r, err := BootstrapToFs(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	d := r["environments/tst-dev/apps/app-http-api/services/http-api/base/config/100-deployment.yaml"].(*appsv1.Deployment)
+	want := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m"), corev1.ResourceMemory: resource.MustParse(DefaultMemoryRequest)},
+		Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(DefaultCPULimit), corev1.ResourceMemory: resource.MustParse("1Gi")},
+	}
+	if diff := cmp.Diff(want, d.Spec.Template.Spec.Containers[0].Resources); diff != "" {
+		t.Fatalf("container resources didn't match:\n%s", diff)
+	}
+}
+
+func TestBootstrapDryRun(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	fatalIfError(t, fakeFs.MkdirAll("/secrets", 0755))
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/out",
+	}
+	var b bytes.Buffer
+	fatalIfError(t, BootstrapDryRun(params, &b, fakeFs))
+
+	for _, want := range []string{"---\n# /out/pipelines.yaml\n", "---\n# /out/config/tst-cicd/base/07-eventlisteners/cicd-event-listener.yaml\n", "---\n# /secrets/gitops-webhook-secret.yaml\n"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("the dry-run output doesn't contain %q", want)
+		}
+	}
+	exists, err := afero.Exists(fakeFs, "/out")
+	fatalIfError(t, err)
+	if exists {
+		t.Fatal("the dry-run wrote the resources")
+	}
+}
+
+func TestBootstrapWithDryRunTrigger(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/out",
+		DryRunTrigger:        eventlisteners.DryRunTriggerAll,
+	}
+	r, err := BootstrapToFs(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	if _, ok := r["config/tst-cicd/base/05-bindings/github-pull-request-binding.yaml"]; !ok {
+		t.Fatal("no pull request binding was generated")
+	}
+	el := r["config/tst-cicd/base/07-eventlisteners/cicd-event-listener.yaml"].(*triggersv1.EventListener)
+	got := []string{}
+	for _, trigger := range el.Spec.Triggers {
+		got = append(got, trigger.Name)
+	}
+	want := []string{"ci-dryrun-from-push", "ci-dryrun-from-merge-request", "app-ci-build-from-push-http-api"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("dry-run triggers didn't match:\n%s", diff)
+	}
+	m := r["pipelines.yaml"].(*config.Manifest)
+	if trigger := m.GetPipelinesConfig().DryRunTrigger; trigger != eventlisteners.DryRunTriggerAll {
+		t.Fatalf("got dry-run trigger %q in the manifest, want all", trigger)
+	}
+}
+
+func TestBootstrapWithSecretReflectionNamespaces(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:                     "tst-",
+		GitOpsRepoURL:              testGitOpsRepo,
+		GitOpsWebhookSecret:        "123",
+		GitHostAccessToken:         "test-token",
+		ServiceRepoURL:             testSvcRepo,
+		ServiceWebhookSecret:       "456",
+		OutputPath:                 "/out",
+		SecretReflectionNamespaces: "tst-dev, tst-stage",
+	}
+	r, err := BootstrapToFs(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	want := secrets.ReflectionAnnotations([]string{"tst-dev", "tst-stage"})
+	for filename, v := range r {
+		if !strings.HasPrefix(filename, "../secrets/") {
+			continue
+		}
+		annotations := v.(*corev1.Secret).Annotations
+		for k, value := range want {
+			if annotations[k] != value {
+				t.Errorf("%s got annotation %s=%q, want %q", filename, k, annotations[k], value)
+			}
+		}
+	}
+	if annotations := r["config/argocd/argo-app.yaml"].(*argoappv1.Application).Annotations; len(annotations) != 0 {
+		t.Fatalf("a resource other than the secrets was annotated: %v", annotations)
+	}
+}
+
+func TestBootstrapWithVaultSecretBackend(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/out",
+		SecretBackend:        SecretBackendVault,
+		VaultSecretStore:     "vault-backend",
+	}
+	r, err := BootstrapToFs(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	secret := r["../secrets/gitops-webhook-secret.yaml"].(*corev1.Secret)
+	want := secrets.CreateExternalSecret(secret, "vault-backend")
+	if diff := cmp.Diff(want, r["config/tst-cicd/base/09-externalsecrets/gitops-webhook-secret.yaml"]); diff != "" {
+		t.Fatalf("ExternalSecret didn't match:\n%s", diff)
+	}
+	k := r["config/tst-cicd/base/kustomization.yaml"].(res.Kustomization)
+	for _, f := range []string{"09-externalsecrets/git-host-access-token.yaml", "09-externalsecrets/git-host-basic-auth-token.yaml", "09-externalsecrets/gitops-webhook-secret.yaml", "09-externalsecrets/webhook-secret-tst-dev-http-api.yaml"} {
+		if !hasResource(k.Resources, f) {
+			t.Errorf("kustomization doesn't include %s: %v", f, k.Resources)
+		}
+	}
+}
+
+func hasResource(resources []string, f string) bool {
+	for _, r := range resources {
+		if r == f {
+			return true
+		}
+	}
+	return false
+}
+
+func TestBootstrapWithArgoCDNamespace(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/out",
+		ArgoCDNamespace:      "argocd",
+	}
+	r, err := BootstrapToFs(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	for _, filename := range []string{"config/argocd/argo-app.yaml", "config/argocd/tst-dev-app-http-api-app.yaml"} {
+		if ns := r[filename].(*argoappv1.Application).Namespace; ns != "argocd" {
+			t.Errorf("%s got namespace %q, want argocd", filename, ns)
+		}
+	}
+	for _, filename := range []string{"config/tst-cicd/base/02-rolebindings/argocd-admin.yaml", "environments/tst-dev/env/base/argocd-admin.yaml"} {
+		if ns := r[filename].(*v1rbac.RoleBinding).Subjects[0].Namespace; ns != "argocd" {
+			t.Errorf("%s got subject namespace %q, want argocd", filename, ns)
+		}
+	}
+}
+
+func TestBootstrapWithArgoCDProject(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/out",
+		ArgoCDProject:        "my-team",
+	}
+	r, err := BootstrapToFs(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	project, ok := r["config/argocd/appproject.yaml"].(*argoappv1.AppProject)
+	if !ok {
+		t.Fatal("no AppProject generated")
+	}
+	if project.Name != "my-team" {
+		t.Errorf("got AppProject %q, want my-team", project.Name)
+	}
+	for _, filename := range []string{"config/argocd/argo-app.yaml", "config/argocd/cicd-app.yaml", "config/argocd/tst-dev-app-http-api-app.yaml"} {
+		if p := r[filename].(*argoappv1.Application).Spec.Project; p != "my-team" {
+			t.Errorf("%s got project %q, want my-team", filename, p)
+		}
+	}
+	k := r["config/argocd/kustomization.yaml"].(*res.Kustomization)
+	if !hasResource(k.Resources, "appproject.yaml") {
+		t.Errorf("appproject.yaml not in the kustomization resources: %v", k.Resources)
+	}
+}
+
+func TestBootstrapWithSyncPolicy(t *testing.T) {
+	enabled, disabled := true, false
+	params := &BootstrapOptions{
+		Prefix:                "tst-",
+		GitOpsRepoURL:         testGitOpsRepo,
+		GitOpsWebhookSecret:   "123",
+		GitHostAccessToken:    "test-token",
+		ServiceRepoURL:        testSvcRepo,
+		ServiceWebhookSecret:  "456",
+		OutputPath:            "/out",
+		ArgoCDAutoSync:        &enabled,
+		ArgoCDSelfHeal:        &disabled,
+		ArgoCDCreateNamespace: true,
+	}
+	r, err := BootstrapToFs(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	m := r[pipelinesFile].(*config.Manifest)
+	want := &config.ArgoCDConfig{Namespace: "openshift-gitops", SelfHeal: &disabled, CreateNamespace: true}
+	if diff := cmp.Diff(want, m.Config.ArgoCD); diff != "" {
+		t.Errorf("manifest Argo CD config didn't match:\n%s", diff)
+	}
+	wantPolicy := &argoappv1.SyncPolicy{
+		Automated:   &argoappv1.SyncPolicyAutomated{Prune: true},
+		SyncOptions: argoappv1.SyncOptions{"CreateNamespace=true"},
+	}
+	for _, filename := range []string{"config/argocd/argo-app.yaml", "config/argocd/tst-dev-env-app.yaml", "config/argocd/tst-dev-app-http-api-app.yaml"} {
+		if diff := cmp.Diff(wantPolicy, r[filename].(*argoappv1.Application).Spec.SyncPolicy); diff != "" {
+			t.Errorf("%s sync policy didn't match:\n%s", filename, diff)
+		}
+	}
+}
+
+func TestBootstrapWithEnvironments(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/out",
+		Environments:         "sandbox,integration",
+	}
+	r, err := BootstrapToFs(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	m := r[pipelinesFile].(*config.Manifest)
+	if diff := cmp.Diff([]string{"tst-integration", "tst-sandbox"}, environmentNames(m)); diff != "" {
+		t.Errorf("environments didn't match:\n%s", diff)
+	}
+	if name := m.GetPipelinesConfig().Name; name != "tst-cicd" {
+		t.Errorf("got CI/CD namespace %q, want tst-cicd", name)
+	}
+	if app := m.GetApplication("tst-sandbox", "app-http-api"); app == nil {
+		t.Error("the service wasn't bootstrapped in the first environment")
+	}
+	for _, filename := range []string{"config/argocd/tst-sandbox-app-http-api-app.yaml", "config/argocd/tst-integration-env-app.yaml", "environments/tst-integration/env/base/tst-integration-environment.yaml"} {
+		if _, ok := r[filename]; !ok {
+			t.Errorf("%s wasn't generated", filename)
+		}
+	}
+	if _, ok := r["config/argocd/tst-dev-env-app.yaml"]; ok {
+		t.Error("the default dev environment was generated")
+	}
+}
+
+func TestBootstrapWithBootstrapImage(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/out",
+		WithImageUpdater:     true,
+		BootstrapImage:       "registry.example.com:5000/mirror/nginx-unprivileged:1.21",
+	}
+	r, err := BootstrapToFs(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	d := r["environments/tst-dev/apps/app-http-api/services/http-api/base/config/100-deployment.yaml"].(*appsv1.Deployment)
+	if image := d.Spec.Template.Spec.Containers[0].Image; image != params.BootstrapImage {
+		t.Fatalf("got deployment image %q, want %q", image, params.BootstrapImage)
+	}
+	app := r["config/argocd/tst-dev-app-http-api-app.yaml"].(*argoappv1.Application)
+	if name := app.Annotations["argocd-image-updater.argoproj.io/http-api.kustomize.image-name"]; name != "registry.example.com:5000/mirror/nginx-unprivileged" {
+		t.Fatalf("got image updater image name %q", name)
+	}
+}
+
+func TestBootstrapWithRouteHealthCheckPath(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/out",
+		RouteHealthCheckPath: "/healthz",
+	}
+	r, err := BootstrapToFs(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	d := r["environments/tst-dev/apps/app-http-api/services/http-api/base/config/100-deployment.yaml"].(*appsv1.Deployment)
+	want := &corev1.Probe{
+		Handler: corev1.Handler{
+			HTTPGet: &corev1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt(8080)},
+		},
+	}
+	if diff := cmp.Diff(want, d.Spec.Template.Spec.Containers[0].ReadinessProbe); diff != "" {
+		t.Fatalf("readiness probe didn't match:\n%s", diff)
+	}
+}
+
+func TestBootstrapWithPipelineNamePrefix(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/out",
+		PipelineNamePrefix:   "team-a-",
+	}
+	r, err := BootstrapToFs(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	m := r[pipelinesFile].(*config.Manifest)
+	if p := m.GetPipelineNamePrefix(); p != params.PipelineNamePrefix {
+		t.Fatalf("got pipeline name prefix %q in the manifest, want %q", p, params.PipelineNamePrefix)
+	}
+	want := &config.TemplateBinding{
+		Template: "team-a-app-ci-template",
+		Bindings: []string{"team-a-github-push-binding"},
+	}
+	if diff := cmp.Diff(want, m.GetEnvironment("tst-dev").Pipelines.Integration); diff != "" {
+		t.Fatalf("environment pipelines didn't match:\n%s", diff)
+	}
+	for path, name := range map[string]string{
+		"config/tst-cicd/base/" + appCiPipelinesPath: "team-a-app-ci-pipeline",
+		"config/tst-cicd/base/" + ciPipelinesPath:    "team-a-ci-dryrun-from-push-pipeline",
+	} {
+		if got := r[path].(*pipelinev1.Pipeline).Name; got != name {
+			t.Errorf("got pipeline name %q for %s, want %q", got, path, name)
+		}
+	}
+	for path, name := range map[string]string{
+		"config/tst-cicd/base/" + appCIPushTemplatePath: "team-a-app-ci-template",
+		"config/tst-cicd/base/" + pushTemplatePath:      "team-a-ci-dryrun-from-push-template",
+	} {
+		tt := r[path].(triggersv1.TriggerTemplate)
+		if tt.Name != name {
+			t.Errorf("got template name %q for %s, want %q", tt.Name, path, name)
+		}
+		pr := &pipelinev1.PipelineRun{}
+		fatalIfError(t, json.Unmarshal(tt.Spec.ResourceTemplates[0].Raw, pr))
+		if !strings.HasPrefix(pr.Spec.PipelineRef.Name, params.PipelineNamePrefix) {
+			t.Errorf("template %s references unprefixed pipeline %q", path, pr.Spec.PipelineRef.Name)
+		}
+	}
+	binding := r["config/tst-cicd/base/05-bindings/team-a-github-push-binding.yaml"].(triggersv1.TriggerBinding)
+	if binding.Name != "team-a-github-push-binding" {
+		t.Fatalf("got binding name %q, want team-a-github-push-binding", binding.Name)
+	}
+	el := r["config/tst-cicd/base/"+eventListenerPath].(*triggersv1.EventListener)
+	for _, trigger := range el.Spec.Triggers {
+		if !strings.HasPrefix(*trigger.Template.Ref, params.PipelineNamePrefix) {
+			t.Errorf("trigger %s references unprefixed template %q", trigger.Name, *trigger.Template.Ref)
+		}
+		for _, b := range trigger.Bindings {
+			if b.Ref == "github-push-binding" {
+				t.Errorf("trigger %s references unprefixed push binding", trigger.Name)
+			}
+		}
+	}
+}
+
+func TestBootstrapWithServiceAccount(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/out",
+		ServiceAccount:       "ci-bot",
+	}
+	r, err := BootstrapToFs(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	m := r[pipelinesFile].(*config.Manifest)
+	if sa := m.GetServiceAccountName(); sa != params.ServiceAccount {
+		t.Fatalf("got service account %q in the manifest, want %q", sa, params.ServiceAccount)
+	}
+	sa := r["config/tst-cicd/base/"+serviceAccountPath].(*corev1.ServiceAccount)
+	if sa.Name != params.ServiceAccount || sa.Namespace != "tst-cicd" {
+		t.Fatalf("got service account %s/%s, want tst-cicd/%s", sa.Namespace, sa.Name, params.ServiceAccount)
+	}
+	wantSubjects := []v1rbac.Subject{{Kind: "ServiceAccount", Name: params.ServiceAccount, Namespace: "tst-cicd"}}
+	for _, path := range []string{"config/tst-cicd/base/" + rolebindingsPath, "environments/tst-dev/env/base/tst-dev-rolebinding.yaml"} {
+		var subjects []v1rbac.Subject
+		switch rb := r[path].(type) {
+		case *v1rbac.ClusterRoleBinding:
+			subjects = rb.Subjects
+		case *v1rbac.RoleBinding:
+			subjects = rb.Subjects
+		default:
+			t.Fatalf("got %T for %s, want a role binding", r[path], path)
+		}
+		if diff := cmp.Diff(wantSubjects, subjects); diff != "" {
+			t.Errorf("role binding subjects for %s didn't match:\n%s", path, diff)
+		}
+	}
+	for _, path := range []string{appCIPushTemplatePath, pushTemplatePath} {
+		tt := r["config/tst-cicd/base/"+path].(triggersv1.TriggerTemplate)
+		pr := &pipelinev1.PipelineRun{}
+		fatalIfError(t, json.Unmarshal(tt.Spec.ResourceTemplates[0].Raw, pr))
+		if pr.Spec.ServiceAccountName != params.ServiceAccount {
+			t.Errorf("got service account %q in template %s, want %q", pr.Spec.ServiceAccountName, path, params.ServiceAccount)
+		}
+	}
+	el := r["config/tst-cicd/base/"+eventListenerPath].(*triggersv1.EventListener)
+	if el.Spec.ServiceAccountName != params.ServiceAccount {
+		t.Fatalf("got EventListener service account %q, want %q", el.Spec.ServiceAccountName, params.ServiceAccount)
+	}
+}
+
+func TestBootstrapRecordsTemplateAndRouteOptions(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/out",
+		PipelineNamePrefix:   "team-a-",
+		TektonAPIVersion:     "v1",
+		PipelineRunTTL:       "24h",
+		RouteWildcardPolicy:  "Subdomain",
+		RouteSubdomain:       "webhooks",
+		WebhookTLS:           true,
+	}
+	r, err := BootstrapToFs(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	m := r[pipelinesFile].(*config.Manifest)
+	rebuilt, err := buildEventListenerOnly(m)
+	fatalIfError(t, err)
+	for _, p := range []string{pushTemplatePath, appCIPushTemplatePath, routePath} {
+		path := "config/tst-cicd/base/" + p
+		if r[path] == nil {
+			t.Fatalf("%s wasn't bootstrapped", path)
+		}
+		if diff := cmp.Diff(r[path], rebuilt[path]); diff != "" {
+			t.Errorf("rebuilt %s didn't match the bootstrapped one:\n%s", path, diff)
+		}
+	}
+}
+
+func TestBootstrapWithDefaultBranch(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/out",
+		DefaultBranch:        "master",
+	}
+	r, err := BootstrapToFs(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	m := r[pipelinesFile].(*config.Manifest)
+	if b := m.GetDefaultBranch(); b != params.DefaultBranch {
+		t.Fatalf("got default branch %q in the manifest, want %q", b, params.DefaultBranch)
+	}
+	el := r["config/tst-cicd/base/"+eventListenerPath].(*triggersv1.EventListener)
+	for _, trigger := range el.Spec.Triggers {
+		if trigger.Name != "app-ci-build-from-push-http-api" {
+			continue
+		}
+		if filter := trigger.Interceptors[1].CEL.Filter; !strings.HasSuffix(filter, " && (body.ref == 'refs/heads/master')") {
+			t.Fatalf("got filter %q, want pushes to master", filter)
+		}
+		return
+	}
+	t.Fatal("no app-ci trigger for the service")
+}
+
+func TestBootstrapWithPerEnvOverlays(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/out",
+		PerEnvOverlays:       true,
+	}
+	r, err := BootstrapToFs(params, fakeFs)
+	fatalIfError(t, err)
+
+	if m := r[pipelinesFile].(*config.Manifest); !m.UsePerEnvOverlays() {
+		t.Fatal("per-environment overlays not recorded in the manifest")
+	}
+	svcPath := "apps/app-http-api/services/http-api"
+	want := res.Resources{
+		"environments/tst-dev/apps/app-http-api/base/kustomization.yaml": &res.Kustomization{Bases: []string{"../../../../../" + svcPath + "/overlays/tst-dev"}},
+		svcPath + "/overlays/tst-dev/kustomization.yaml":                 &res.Kustomization{Bases: []string{"../../base"}, Namespace: "tst-dev"},
+		svcPath + "/base/kustomization.yaml":                             &res.Kustomization{Bases: []string{"./config"}},
+	}
+	for k, v := range want {
+		if diff := cmp.Diff(v, r[k]); diff != "" {
+			t.Errorf("%s didn't match:\n%s", k, diff)
+		}
+	}
+	d, ok := r[svcPath+"/base/config/100-deployment.yaml"].(*appsv1.Deployment)
+	if !ok {
+		t.Fatal("the service deployment was not generated in the shared base")
+	}
+	if d.Namespace != "" {
+		t.Fatalf("the deployment in the shared base has namespace %q", d.Namespace)
+	}
+
+	kfs, err := copyToKustomizeFs(fakeFs, params.GitOpsPath())
+	fatalIfError(t, err)
+	m, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(kfs, "/environments/tst-dev/apps/app-http-api")
+	fatalIfError(t, err)
+	if m.Size() != 3 {
+		t.Fatalf("got %d resources, want the Deployment, Service and Route", m.Size())
+	}
+	for _, r := range m.Resources() {
+		if ns := r.GetNamespace(); ns != "tst-dev" {
+			t.Errorf("got %s namespace %q, want %q", r.GetKind(), ns, "tst-dev")
+		}
+	}
+}
+
+func TestBootstrapToFs(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	outputPath := filepath.Join("/", "out", "gitops")
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           outputPath,
+	}
+	r, err := BootstrapToFs(params, fakeFs)
+	fatalIfError(t, err)
+
+	if m := r["pipelines.yaml"].(*config.Manifest); m.GitOpsURL != testGitOpsRepo {
+		t.Errorf("got GitOps URL %q in the manifest, want %q", m.GitOpsURL, testGitOpsRepo)
+	}
+	if _, ok := r["../secrets/gitops-webhook-secret.yaml"]; !ok {
+		t.Error("the GitOps webhook secret was not returned")
+	}
+	for k := range r {
+		exists, err := afero.Exists(fakeFs, filepath.Join(outputPath, k))
+		fatalIfError(t, err)
+		if !exists {
+			t.Errorf("%s was returned but not written", k)
+		}
+	}
+}
+
+func TestBootstrapWithRepoSubpath(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/repo",
+		RepoSubpath:          "platform/gitops",
+	}
+	r, err := BootstrapToFs(params, fakeFs)
+	fatalIfError(t, err)
+
+	for _, f := range []string{"/repo/platform/gitops/pipelines.yaml", "/repo/platform/secrets/gitops-webhook-secret.yaml"} {
+		exists, err := afero.Exists(fakeFs, f)
+		fatalIfError(t, err)
+		if !exists {
+			t.Errorf("%s was not written", f)
+		}
+	}
+	if m := r["pipelines.yaml"].(*config.Manifest); m.RepoSubpath != "platform/gitops" {
+		t.Errorf("got repo subpath %q in the manifest, want %q", m.RepoSubpath, "platform/gitops")
+	}
+	app := r["config/argocd/tst-dev-env-app.yaml"].(*argoappv1.Application)
+	if want := "platform/gitops/environments/tst-dev/env/overlays"; app.Spec.Source.Path != want {
+		t.Errorf("got ArgoCD source path %q, want %q", app.Spec.Source.Path, want)
+	}
+	task := r["config/tst-cicd/base/"+gitopsTasksPath].(pipelinev1.Task)
+	if want := "/workspace/source/platform/gitops"; task.Spec.Steps[0].WorkingDir != want {
+		t.Errorf("got CI dry-run working dir %q, want %q", task.Spec.Steps[0].WorkingDir, want)
+	}
+}
+
+func TestBootstrapWithProjectRequests(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/gitops",
+		UseProjectRequests:   true,
+	}
+	r, err := BootstrapToFs(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	for _, k := range []string{
+		"config/tst-cicd/base/01-namespaces/cicd-environment.yaml",
+		"environments/tst-dev/env/base/tst-dev-environment.yaml",
+		"environments/tst-stage/env/base/tst-stage-environment.yaml",
+	} {
+		if _, ok := r[k].(*projectv1.ProjectRequest); !ok {
+			t.Errorf("%s is a %T, want a ProjectRequest", k, r[k])
+		}
+	}
+	if !r["pipelines.yaml"].(*config.Manifest).UseProjectRequests() {
+		t.Fatal("project requests not recorded in the manifest")
+	}
+}
+
 func TestBootstrapCreatesRepository(t *testing.T) {
 	params := &BootstrapOptions{
 		Prefix:               "tst-",
@@ -167,11 +1412,55 @@ func TestBootstrapCreatesRepository(t *testing.T) {
 }
 
 func TestOrgRepoFromURL(t *testing.T) {
-	want := "my-org/gitops"
-	got, err := orgRepoFromURL(testGitOpsRepo)
-	fatalIfError(t, err)
-	if got != want {
-		t.Fatalf("orgRepFromURL(%s) got %s, want %s", testGitOpsRepo, got, want)
+	urlTests := []struct {
+		url  string
+		want string
+	}{
+		{testGitOpsRepo, "my-org/gitops"},
+		{"https://dev.azure.com/my-org/my-project/_git/gitops", "my-project/gitops"},
+		{"https://gitlab.com/my-group/gitops.git", "my-group/gitops"},
+		{"https://gitlab.com/my-group/my-subgroup/gitops.git", "my-group/my-subgroup/gitops"},
+		{"https://gitlab.com/my-group/my-subgroup/my-team/gitops.git", "my-group/my-subgroup/my-team/gitops"},
+	}
+	for _, tt := range urlTests {
+		got, err := orgRepoFromURL(tt.url)
+		fatalIfError(t, err)
+		if got != tt.want {
+			t.Fatalf("orgRepFromURL(%s) got %s, want %s", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestRepoFromURL(t *testing.T) {
+	urlTests := []struct {
+		url  string
+		want string
+	}{
+		{"https://github.com/my-org/http-api.git", "http-api"},
+		{"https://github.com/my-org/My_App.v2.git", "my-app-v2"},
+		{"https://github.com/my-org/--Service__Name--", "service-name"},
+		{"https://github.com/my-org/2048.git", "repo-2048"},
+		{"https://github.com/my-org/___.git", "repo"},
+		{"https://gitlab.com/group/sub/a-very-long-repository-name-that-is-over-the-service-name-limit.git", "a-very-long-repository-name-that-is-over-the-se"},
+		{"https://github.com/my-org/a-long-repository-name-with-a-dash-at-the-limit-.git", "a-long-repository-name-with-a-dash-at-the-limit"},
+	}
+	for _, tt := range urlTests {
+		t.Run(tt.url, func(t *testing.T) {
+			got, err := repoFromURL(tt.url)
+			fatalIfError(t, err)
+			if got != tt.want {
+				t.Fatalf("repoFromURL(%q) got %q, want %q", tt.url, got, tt.want)
+			}
+			if errs := validation.NameIsDNS1035Label(got, false); len(errs) > 0 {
+				t.Fatalf("repoFromURL(%q) got invalid name %q: %v", tt.url, got, errs)
+			}
+		})
+	}
+}
+
+func TestRepoToAppName(t *testing.T) {
+	if got := repoToAppName("My_App.v2"); got != "app-my-app-v2" {
+		t.Fatalf("repoToAppName() got %q, want %q", got, "app-my-app-v2")
 	}
 }
 
@@ -218,6 +1507,40 @@ func TestOverwriteFlag(t *testing.T) {
 	}
 }
 
+func TestLoadBootstrapped(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/gitops",
+	}
+	_, err := LoadBootstrapped(params, fakeFs)
+	if err == nil {
+		t.Fatal("expected an error loading a missing manifest")
+	}
+
+	err = Bootstrap(params, fakeFs)
+	fatalIfError(t, err)
+
+	resume := &BootstrapOptions{OutputPath: "/gitops"}
+	m, err := LoadBootstrapped(resume, fakeFs)
+	fatalIfError(t, err)
+	if m.GitOpsURL != testGitOpsRepo || resume.GitOpsRepoURL != testGitOpsRepo {
+		t.Fatalf("got GitOps URL %q, want %q", resume.GitOpsRepoURL, testGitOpsRepo)
+	}
+
+	resume = &BootstrapOptions{OutputPath: "/gitops", GitOpsRepoURL: "https://github.com/my-org/other.git"}
+	_, err = LoadBootstrapped(resume, fakeFs)
+	want := `the GitOps repository "https://github.com/my-org/other.git" does not match the manifest in "/gitops": ` + testGitOpsRepo
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v, want %q", err, want)
+	}
+}
+
 func TestOverwriteFlagExistingGitDirectory(t *testing.T) {
 	fakeFs := ioutils.NewMemoryFilesystem()
 	params := &BootstrapOptions{
@@ -280,23 +1603,196 @@ func TestInitialFiles(t *testing.T) {
 	files := getResourceFiles(resources)
 
 	want = res.Merge(addPrefixToResources("config/tst-cicd/base", resources), want)
-	want = res.Merge(addPrefixToResources("config/tst-cicd", getCICDKustomization(files)), want)
+	cicdKustomizations, err := getCICDKustomization(files)
+	fatalIfError(t, err)
+	want = res.Merge(addPrefixToResources("config/tst-cicd", cicdKustomizations), want)
 
 	if diff := cmp.Diff(want, got); diff != "" {
 		t.Fatalf("outputs didn't match: %s\n", diff)
 	}
 }
 
+func TestCreateCICDResourcesWithExistingClusterRole(t *testing.T) {
+	o := BootstrapOptions{Prefix: "tst-", GitOpsWebhookSecret: "123", ExistingClusterRole: "pipeline-runner"}
+	repo, err := scm.NewRepository("https://github.com/foo/test-repo")
+	assertNoError(t, err)
+
+	resources, _, err := createCICDResources(ioutils.NewMemoryFilesystem(), repo, testpipelineConfig, &o)
+	assertNoError(t, err)
+
+	if r, ok := resources[rolesPath]; ok {
+		t.Fatalf("ClusterRole generated with an existing cluster role: %#v", r)
+	}
+	binding := resources[rolebindingsPath].(*v1rbac.ClusterRoleBinding)
+	want := v1rbac.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "pipeline-runner"}
+	if diff := cmp.Diff(want, binding.RoleRef); diff != "" {
+		t.Fatalf("binding role didn't match:\n%s", diff)
+	}
+}
+
+func TestCreateCICDResourcesWithDockerConfigRegistries(t *testing.T) {
+	tests := []struct {
+		name      string
+		imageRepo string
+		config    string
+		wantErr   string
+	}{
+		{"matching registry", "quay.io/my-org/http-api", `{"auths":{"quay.io":{"auth":"dGVzdA=="}}}`, ""},
+		{"matching credential helper", "quay.io/my-org/http-api", `{"credHelpers":{"quay.io":"secretservice"}}`, ""},
+		{"docker hub index", "docker.io/my-org/http-api", `{"auths":{"https://index.docker.io/v1/":{}}}`, ""},
+		{"internal registry", "tst-cicd/http-api", `{"auths":{}}`, ""},
+		{"mismatched registry", "quay.io/my-org/http-api", `{"auths":{"https://index.docker.io/v1/":{},"ghcr.io":{}}}`,
+			"no credentials for the image repository registry quay.io, the configured registries are: ghcr.io, https://index.docker.io/v1/"},
+		{"no registries", "quay.io/my-org/http-api", `{"auths":{}}`,
+			"no credentials for the image repository registry quay.io, the configured registries are: none"},
+		{"invalid config", "quay.io/my-org/http-api", `{`, "failed to parse Docker config"},
+	}
+	repo, err := scm.NewRepository("https://github.com/foo/test-repo")
+	assertNoError(t, err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(rt *testing.T) {
+			fakeFs := ioutils.NewMemoryFilesystem()
+			fatalIfError(rt, afero.WriteFile(fakeFs, "/config.json", []byte(tt.config), 0644))
+			o := BootstrapOptions{Prefix: "tst-", GitOpsWebhookSecret: "123", ImageRepo: tt.imageRepo, DockerConfigJSONFilename: "/config.json"}
+
+			_, _, err := createCICDResources(fakeFs, repo, testpipelineConfig, &o)
+			if tt.wantErr == "" {
+				assertNoError(rt, err)
+				return
+			}
+			test.AssertErrorMatch(rt, tt.wantErr, err)
+		})
+	}
+}
+
+func TestCreateCICDResourcesWithQuayRobot(t *testing.T) {
+	repo, err := scm.NewRepository("https://github.com/foo/test-repo")
+	assertNoError(t, err)
+	o := BootstrapOptions{Prefix: "tst-", GitOpsWebhookSecret: "123", ImageRepo: "quay.io/my-org/http-api",
+		DockerConfigJSONFilename: "/missing.json", QuayRobotAccount: "my-org+ci", QuayRobotToken: "robot-token"}
+
+	_, otherResources, err := createCICDResources(ioutils.NewMemoryFilesystem(), repo, testpipelineConfig, &o)
+	fatalIfError(t, err)
+
+	secret := otherResources[filepath.Join("secrets", "docker-config.yaml")].(*corev1.Secret)
+	want := `{"auths":{"quay.io":{"auth":"bXktb3JnK2NpOnJvYm90LXRva2Vu"}}}`
+	if diff := cmp.Diff(want, string(secret.Data[corev1.DockerConfigJsonKey])); diff != "" {
+		t.Fatalf("docker config didn't match:\n%s", diff)
+	}
+}
+
+func TestCreateCICDResourcesWithGitLabDeployToken(t *testing.T) {
+	repo, err := scm.NewRepository("https://gitlab.com/foo/test-repo")
+	assertNoError(t, err)
+	o := BootstrapOptions{Prefix: "tst-", GitOpsWebhookSecret: "123", ImageRepo: "registry.gitlab.com/foo/subgroup/http-api",
+		GitLabDeployToken: "gitlab+deploy-token-1:abcdef"}
+
+	_, otherResources, err := createCICDResources(ioutils.NewMemoryFilesystem(), repo, testpipelineConfig, &o)
+	fatalIfError(t, err)
+
+	secret := otherResources[filepath.Join("secrets", "docker-config.yaml")].(*corev1.Secret)
+	want := `{"auths":{"registry.gitlab.com":{"auth":"Z2l0bGFiK2RlcGxveS10b2tlbi0xOmFiY2RlZg=="}}}`
+	if diff := cmp.Diff(want, string(secret.Data[corev1.DockerConfigJsonKey])); diff != "" {
+		t.Fatalf("docker config didn't match:\n%s", diff)
+	}
+	fatalIfError(t, ValidateImageRepoAuth(&o, ioutils.NewMemoryFilesystem()))
+}
+
+func TestValidateImageRepoAuthWithQuayRobot(t *testing.T) {
+	o := &BootstrapOptions{ImageRepo: "ghcr.io/my-org/http-api", QuayRobotAccount: "my-org+ci", QuayRobotToken: "robot-token"}
+	err := ValidateImageRepoAuth(o, ioutils.NewMemoryFilesystem())
+	test.AssertErrorMatch(t, "no credentials for the image repository registry ghcr.io, the configured registries are: quay.io", err)
+}
+
+func TestValidateImageRepoAuth(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	fatalIfError(t, afero.WriteFile(fakeFs, "/config.json", []byte(`{"auths":{"quay.io":{"auth":"dGVzdA=="}}}`), 0644))
+	tests := []struct {
+		name       string
+		imageRepo  string
+		dockerJSON string
+		wantErr    string
+	}{
+		{"default internal registry", "", "", ""},
+		{"internal registry", "tst-cicd/http-api", "", ""},
+		{"external registry", "quay.io/my-org/http-api", "/config.json", ""},
+		{"external registry without credentials", "ghcr.io/my-org/http-api", "/config.json",
+			"no credentials for the image repository registry ghcr.io, the configured registries are: quay.io"},
+		{"external registry without config", "quay.io/my-org/http-api", "", "--dockerconfigjson flag is not provided"},
+		{"missing config", "quay.io/my-org/http-api", "/missing.json", "failed to read Docker config"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(rt *testing.T) {
+			err := ValidateImageRepoAuth(&BootstrapOptions{ImageRepo: tt.imageRepo, DockerConfigJSONFilename: tt.dockerJSON}, fakeFs)
+			if tt.wantErr == "" {
+				assertNoError(rt, err)
+				return
+			}
+			test.AssertErrorMatch(rt, tt.wantErr, err)
+		})
+	}
+}
+
+func TestCreateCICDResourcesWithBuildOptions(t *testing.T) {
+	o := BootstrapOptions{Prefix: "tst-", GitOpsWebhookSecret: "123",
+		BuildImage: "registry.example.com/mirror/buildah:v1", BuildArgs: []string{"HTTP_PROXY=http://proxy.example.com:3128"}}
+	repo, err := scm.NewRepository("https://github.com/foo/test-repo")
+	assertNoError(t, err)
+
+	resources, _, err := createCICDResources(ioutils.NewMemoryFilesystem(), repo, testpipelineConfig, &o)
+	assertNoError(t, err)
+
+	build := resources[appCiPipelinesPath].(*pipelinev1.Pipeline).Spec.Tasks[2]
+	params := map[string]string{}
+	for _, p := range build.Params {
+		params[p.Name] = p.Value.StringVal
+	}
+	if params["BUILDER_IMAGE"] != o.BuildImage {
+		t.Errorf("got BUILDER_IMAGE %q, want %q", params["BUILDER_IMAGE"], o.BuildImage)
+	}
+	if !strings.HasSuffix(params["BUILD_EXTRA_ARGS"], " --build-arg='HTTP_PROXY=http://proxy.example.com:3128'") {
+		t.Errorf("BUILD_EXTRA_ARGS %q doesn't pass the build arg", params["BUILD_EXTRA_ARGS"])
+	}
+}
+
+func TestCreateCICDResourcesWithTektonV1(t *testing.T) {
+	o := BootstrapOptions{Prefix: "tst-", GitOpsWebhookSecret: "123", TektonAPIVersion: "v1"}
+	repo, err := scm.NewRepository("https://github.com/foo/test-repo")
+	assertNoError(t, err)
+
+	resources, _, err := createCICDResources(ioutils.NewMemoryFilesystem(), repo, testpipelineConfig, &o)
+	assertNoError(t, err)
+
+	for _, path := range []string{gitopsTasksPath, ciPipelinesPath, appCiPipelinesPath, commitStatusTaskPath} {
+		obj, ok := resources[path].(map[string]interface{})
+		if !ok {
+			t.Fatalf("%s was not converted", path)
+		}
+		if v := obj["apiVersion"]; v != "tekton.dev/v1" {
+			t.Errorf("%s got apiVersion %v, want tekton.dev/v1", path, v)
+		}
+	}
+	ciPipeline := resources[ciPipelinesPath].(map[string]interface{})
+	if _, ok := ciPipeline["spec"].(map[string]interface{})["resources"]; ok {
+		t.Fatal("CI pipeline uses PipelineResources")
+	}
+}
+
 func TestGetCICDKustomization(t *testing.T) {
+	schema, err := openapi.Schema(cicdBuiltinKinds, openapi.EventListener)
+	fatalIfError(t, err)
 	want := res.Resources{
 		"overlays/kustomization.yaml": res.Kustomization{
-			Bases: []string{"../base"},
+			Bases:   []string{"../base"},
+			OpenAPI: &res.OpenAPI{Path: openapi.Filename},
 		},
+		"overlays/openapi.json": schema,
 		"base/kustomization.yaml": res.Kustomization{
 			Resources: []string{"resource1", "resource2"},
 		},
 	}
-	got := getCICDKustomization([]string{"resource1", "resource2"})
+	got, err := getCICDKustomization([]string{"resource1", "resource2"})
+	fatalIfError(t, err)
 	if diff := cmp.Diff(want, got); diff != "" {
 		t.Fatalf("getCICDKustomization was not correct: %s\n", diff)
 	}
@@ -325,7 +1821,54 @@ func TestGenerateSecrets(t *testing.T) {
 	if diff := cmp.Diff(wantSA, outputs[serviceAccountPath]); diff != "" {
 		t.Fatalf("generatedSecrets failed to update the ServiceAccount:\n%s", diff)
 	}
+	basicAuth := otherOutputs[filepath.Join("secrets", basicAuthTokenName+".yaml")].(*corev1.Secret)
+	if h := basicAuth.Annotations["tekton.dev/git-0"]; h != "https://gl.example.com" {
+		t.Fatalf("got git host annotation %q, want %q", h, "https://gl.example.com")
+	}
+	if u := basicAuth.StringData["username"]; u != secrets.DefaultBasicAuthUsername {
+		t.Fatalf("got username %q, want %q", u, secrets.DefaultBasicAuthUsername)
+	}
+}
+
+func TestGenerateSecretsWithGitCloneHost(t *testing.T) {
+	outputs := res.Resources{}
+	otherOutputs := res.Resources{}
+	sa := roles.CreateServiceAccount(meta.NamespacedName("test-ns", "test-sa"))
+	o := &BootstrapOptions{
+		GitHostAccessToken: "abc123",
+		ServiceRepoURL:     "https://gl.example.com/my-org/my-project.git",
+		GitCloneHost:       "gl-clone.example.com:8443",
+	}
+
+	err := generateSecrets(outputs, otherOutputs, sa, "test-ns", o)
+	fatalIfError(t, err)
+
+	basicAuth := otherOutputs[filepath.Join("secrets", basicAuthTokenName+".yaml")].(*corev1.Secret)
+	if h := basicAuth.Annotations["tekton.dev/git-0"]; h != "https://gl-clone.example.com:8443" {
+		t.Fatalf("got git host annotation %q, want %q", h, "https://gl-clone.example.com:8443")
+	}
+}
+
+func TestGenerateSecretsWithGitUsername(t *testing.T) {
+	outputs := res.Resources{}
+	otherOutputs := res.Resources{}
+	sa := roles.CreateServiceAccount(meta.NamespacedName("test-ns", "test-sa"))
+	o := &BootstrapOptions{
+		GitHostAccessToken: "abc123",
+		ServiceRepoURL:     "https://gitlab.com/my-org/my-project.git",
+		GitUsername:        "gitlab+deploy-token-1",
+	}
+
+	err := generateSecrets(outputs, otherOutputs, sa, "test-ns", o)
+	fatalIfError(t, err)
+
+	basicAuth := otherOutputs[filepath.Join("secrets", basicAuthTokenName+".yaml")].(*corev1.Secret)
+	want := map[string]string{"username": "gitlab+deploy-token-1", "password": "abc123"}
+	if diff := cmp.Diff(want, basicAuth.StringData); diff != "" {
+		t.Fatalf("generateSecrets failed to set the username:\n%s", diff)
+	}
 }
+
 func TestAddPrefixToResources(t *testing.T) {
 	files := map[string]interface{}{
 		"base/kustomization.yaml": map[string]interface{}{
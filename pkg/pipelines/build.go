@@ -1,33 +1,178 @@
 package pipelines
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
 	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/environments"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
 	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
 	"github.com/spf13/afero"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+const (
+	// BuildOnlyArgoCD restricts BuildResources to the Argo CD applications.
+	BuildOnlyArgoCD = "argocd"
+
+	// BuildOnlyEventListener restricts BuildResources to the EventListener and
+	// the bindings for the GitOps repository's CI dry-run.
+	BuildOnlyEventListener = "eventlistener"
 )
 
+// BuildOnly is the list of resources that BuildResources can be restricted
+// to.
+var BuildOnly = []string{BuildOnlyArgoCD, BuildOnlyEventListener}
+
+// IsSupportedBuildOnly returns true if BuildResources can be restricted to
+// the resources.
+func IsSupportedBuildOnly(s string) bool {
+	for _, v := range BuildOnly {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 // BuildParameters is a struct that provides flags for the BuildResources
 // command.
 type BuildParameters struct {
 	PipelinesFolderPath string
 	OutputPath          string
+	VerifyKustomize     bool
+	Only                string // If set, only these resources are built e.g. BuildOnlyArgoCD.
+	ValuesFile          string // If set, the manifest is a template and these values are substituted into it.
+	Validate            bool   // If true, the resources are validated against the Kubernetes and Tekton schemas before they're written.
+	WriteChecksums      bool   // If true, the checksums of the written files are recorded in the ChecksumsFile.
 }
 
 // BuildResources builds all resources from a pipelines.
+//
+// If a ValuesFile is provided, the manifest is a template, and the manifest
+// with the values substituted is written to the OutputPath along with the
+// resources.
+//
+// If Validate is set, the resources are checked against their schemas, and
+// nothing is written if any of them are invalid.
+//
+// If WriteChecksums is set, the checksums of the written files are updated in
+// the ChecksumsFile, the checksums of the files that weren't built are kept.
 func BuildResources(o *BuildParameters, appFs afero.Fs) error {
-	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	m, err := loadBuildManifest(o, appFs)
 	if err != nil {
 		return err
 	}
-	resources, err := buildResources(appFs, m)
+	var resources res.Resources
+	switch o.Only {
+	case BuildOnlyArgoCD:
+		resources, err = buildArgoCDResources(m)
+	case BuildOnlyEventListener:
+		resources, err = buildEventListenerOnly(m)
+	default:
+		resources, err = buildWithGitOpsRepoBindings(appFs, m)
+	}
+	if err != nil {
+		return err
+	}
+	if o.Only != BuildOnlyArgoCD {
+		if err := addBindingsToCICDKustomization(appFs, o.OutputPath, m, resources); err != nil {
+			return err
+		}
+	}
+	if o.ValuesFile != "" {
+		resources[pipelinesFile] = m
+	}
+	if o.Validate {
+		if err := ValidateSchemas(resources); err != nil {
+			return fmt.Errorf("failed to validate the resources: %w", err)
+		}
+	}
+	filenames, err := yaml.WriteResources(appFs, o.OutputPath, resources)
 	if err != nil {
 		return err
 	}
-	_, err = yaml.WriteResources(appFs, o.OutputPath, resources)
-	return err
+	if o.WriteChecksums {
+		if err := WriteChecksums(appFs, o.OutputPath, filenames); err != nil {
+			return fmt.Errorf("failed to write checksums: %w", err)
+		}
+	}
+	if o.VerifyKustomize {
+		return VerifyKustomize(appFs, o.OutputPath)
+	}
+	return nil
+}
+
+// DumpManifest writes the manifest in the PipelinesFolderPath to w as
+// canonical YAML, without building any resources.
+//
+// The environments, applications and services are sorted by name, and the
+// fields are written in alphabetical order, so that two manifests that
+// describe the same GitOps tree are written identically.  If a ValuesFile is
+// provided, the values are substituted before the manifest is written.
+func DumpManifest(w io.Writer, o *BuildParameters, appFs afero.Fs) error {
+	m, err := loadBuildManifest(o, appFs)
+	if err != nil {
+		return err
+	}
+	sort.Slice(m.Environments, func(i, j int) bool {
+		return m.Environments[i].Name < m.Environments[j].Name
+	})
+	for _, env := range m.Environments {
+		sort.Slice(env.Apps, func(i, j int) bool {
+			return env.Apps[i].Name < env.Apps[j].Name
+		})
+		for _, app := range env.Apps {
+			sort.Slice(app.Services, func(i, j int) bool {
+				return app.Services[i].Name < app.Services[j].Name
+			})
+		}
+	}
+	return yaml.MarshalOutput(w, m)
+}
+
+// ExportResources builds the resources from the manifest in the
+// PipelinesFolderPath, and writes them to w as a single YAML stream, sorted by
+// the path that BuildResources would write each of them to.
+//
+// The kustomizations, and the OpenAPI schema they reference, are only used to
+// build the tree with kustomize, so they're left out, and the stream can be
+// applied directly with kubectl apply -f -.
+func ExportResources(w io.Writer, o *BuildParameters, appFs afero.Fs) error {
+	m, err := loadBuildManifest(o, appFs)
+	if err != nil {
+		return err
+	}
+	resources, err := buildWithGitOpsRepoBindings(appFs, m)
+	if err != nil {
+		return err
+	}
+	exported := res.Resources{}
+	for k, v := range resources {
+		if _, ok := v.(*res.Kustomization); ok || filepath.Ext(k) != ".yaml" {
+			continue
+		}
+		exported[k] = v
+	}
+	return yaml.PrintResources(w, "", exported)
+}
+
+func loadBuildManifest(o *BuildParameters, appFs afero.Fs) (*config.Manifest, error) {
+	if o.ValuesFile == "" {
+		return config.LoadManifest(appFs, o.PipelinesFolderPath)
+	}
+	values, err := config.LoadValues(appFs, o.ValuesFile)
+	if err != nil {
+		return nil, err
+	}
+	return config.LoadManifestTemplate(appFs, o.PipelinesFolderPath, values)
 }
 
 func buildResources(fs afero.Fs, m *config.Manifest) (res.Resources, error) {
@@ -39,7 +184,7 @@ func buildResources(fs afero.Fs, m *config.Manifest) (res.Resources, error) {
 		appLinks = environments.AppsToEnvironments
 	}
 
-	envs, err := environments.Build(fs, m, saName, appLinks)
+	envs, err := environments.Build(fs, m, m.GetServiceAccountName(), appLinks)
 	if err != nil {
 		return nil, err
 	}
@@ -51,10 +196,149 @@ func buildResources(fs afero.Fs, m *config.Manifest) (res.Resources, error) {
 	}
 
 	resources = res.Merge(elFiles, resources)
-	argoApps, err := argocd.Build(argocd.ArgoCDNamespace, m.GitOpsURL, m)
+	argoApps, err := argocd.Build(argocd.Namespace(m), m.GitOpsURL, m)
 	if err != nil {
 		return nil, err
 	}
 	resources = res.Merge(argoApps, resources)
+	resources[Kustomize] = rootKustomization(m, appLinks)
 	return resources, nil
 }
+
+// buildArgoCDResources builds only the Argo CD applications, leaving the rest
+// of the resources untouched.
+func buildArgoCDResources(m *config.Manifest) (res.Resources, error) {
+	if m.GetArgoCDConfig() == nil {
+		return nil, errors.New("the manifest has no Argo CD configuration to build")
+	}
+	return argocd.Build(argocd.Namespace(m), m.GitOpsURL, m)
+}
+
+// buildEventListenerOnly builds only the EventListener, its Route, and the
+// bindings and templates for its triggers, leaving the rest of the resources
+// untouched.
+func buildEventListenerOnly(m *config.Manifest) (res.Resources, error) {
+	cfg := m.GetPipelinesConfig()
+	if cfg == nil {
+		return nil, errors.New("the manifest has no pipelines configuration to build")
+	}
+	if m.GitOpsURL == "" {
+		return nil, errors.New("the manifest has no gitops_url to build the EventListener for")
+	}
+	files, err := buildEventListenerResources(m.GitOpsURL, m)
+	if err != nil {
+		return nil, err
+	}
+	bindings, err := buildGitOpsRepoBindings(m)
+	if err != nil {
+		return nil, err
+	}
+	templates, err := createTriggerTemplates(cfg.Name, m.GetServiceAccountName(), cfg)
+	if err != nil {
+		return nil, err
+	}
+	route, err := createEventListenerRoute(cfg.Name, cfg)
+	if err != nil {
+		return nil, err
+	}
+	templates[routePath] = route
+	return res.Merge(addPrefixToResources(pipelinesPath(m.Config), templates), res.Merge(bindings, files)), nil
+}
+
+// buildWithGitOpsRepoBindings builds all the resources, and the bindings for
+// the GitOps repository's CI dry-run that the EventListener's triggers
+// reference.
+//
+// The bindings aren't built by buildResources, because the folder they're in
+// also has the image repository bindings that are only generated when a
+// service is added.
+func buildWithGitOpsRepoBindings(appFs afero.Fs, m *config.Manifest) (res.Resources, error) {
+	resources, err := buildResources(appFs, m)
+	if err != nil {
+		return nil, err
+	}
+	bindings, err := buildGitOpsRepoBindings(m)
+	if err != nil {
+		return nil, err
+	}
+	return res.Merge(bindings, resources), nil
+}
+
+// buildGitOpsRepoBindings builds the bindings for the GitOps repository's CI
+// dry-run, if the manifest has a pipelines configuration and a gitops_url.
+func buildGitOpsRepoBindings(m *config.Manifest) (res.Resources, error) {
+	cfg := m.GetPipelinesConfig()
+	if cfg == nil || m.GitOpsURL == "" {
+		return res.Resources{}, nil
+	}
+	repo, err := scm.NewRepository(m.GitOpsURL)
+	if err != nil {
+		return nil, err
+	}
+	return addPrefixToResources(pipelinesPath(m.Config), gitOpsRepoBindings(repo, cfg.Name, cfg.PipelineNamePrefix, cfg.DryRunTrigger)), nil
+}
+
+// addBindingsToCICDKustomization lists the bindings for the GitOps
+// repository's CI dry-run in the base kustomization of the CI/CD configuration
+// in outputPath.
+//
+// The base kustomization lists its resources explicitly, so the bindings for
+// a repository or dry-run trigger that weren't bootstrapped wouldn't be
+// applied with the EventListener that references them.  If there's no base
+// kustomization, the resources are left untouched.
+func addBindingsToCICDKustomization(appFs afero.Fs, outputPath string, m *config.Manifest, resources res.Resources) error {
+	cfg := m.GetPipelinesConfig()
+	if cfg == nil {
+		return nil
+	}
+	kustomizePath := filepath.Join(pipelinesPath(m.Config), Kustomize)
+	filename := filepath.Join(outputPath, kustomizePath)
+	data, err := afero.ReadFile(appFs, filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	var k res.Kustomization
+	if err := sigsyaml.Unmarshal(data, &k); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	bindingsPath := filepath.Join(pipelinesPath(m.Config), "05-bindings")
+	for path := range resources {
+		if filepath.Dir(path) == bindingsPath {
+			k.AddResources(filepath.ToSlash(filepath.Join("05-bindings", filepath.Base(path))))
+		}
+	}
+	resources[kustomizePath] = k
+	return nil
+}
+
+// rootKustomization creates a kustomization at the root of the GitOps
+// repository that aggregates all the environments and the CI/CD and ArgoCD
+// configuration, so that the whole tree can be rendered with a single
+// kustomize build.
+func rootKustomization(m *config.Manifest, appLinks environments.AppLinks) *res.Kustomization {
+	bases := []string{}
+	if cfg := m.GetPipelinesConfig(); cfg != nil {
+		bases = append(bases, filepath.ToSlash(filepath.Join(config.PathForPipelines(cfg), "overlays")))
+	}
+	if m.GetArgoCDConfig() != nil {
+		bases = append(bases, filepath.ToSlash(config.PathForArgoCD()))
+	}
+	for _, env := range m.Environments {
+		bases = append(bases, filepath.ToSlash(filepath.Join(config.PathForEnvironment(env), "env", "overlays")))
+		// When apps aren't linked from the environments, they must be
+		// referenced individually.
+		if appLinks == environments.EnvironmentsToApps {
+			continue
+		}
+		for _, app := range env.Apps {
+			if app.ConfigRepo != nil {
+				continue
+			}
+			bases = append(bases, filepath.ToSlash(filepath.Join(config.PathForApplication(env, app), "overlays")))
+		}
+	}
+	return &res.Kustomization{Bases: bases}
+}
@@ -0,0 +1,485 @@
+package pipelines
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/environments"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestRootKustomization(t *testing.T) {
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{Name: "tst-cicd"},
+			ArgoCD:    &config.ArgoCDConfig{Namespace: "argocd"},
+		},
+		Environments: []*config.Environment{
+			{
+				Name: "tst-dev",
+				Apps: []*config.Application{
+					{Name: "app-taxi", Services: []*config.Service{{Name: "taxi"}}},
+					{Name: "app-remote", ConfigRepo: &config.Repository{URL: "https://github.com/org/config.git", Path: "deploy"}},
+				},
+			},
+			{Name: "tst-stage"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		appLinks environments.AppLinks
+		want     *res.Kustomization
+	}{
+		{"apps linked to environments", environments.AppsToEnvironments, &res.Kustomization{
+			Bases: []string{
+				"config/tst-cicd/overlays",
+				"config/argocd",
+				"environments/tst-dev/env/overlays",
+				"environments/tst-dev/apps/app-taxi/overlays",
+				"environments/tst-stage/env/overlays",
+			},
+		}},
+		{"environments linked to apps", environments.EnvironmentsToApps, &res.Kustomization{
+			Bases: []string{
+				"config/tst-cicd/overlays",
+				"config/argocd",
+				"environments/tst-dev/env/overlays",
+				"environments/tst-stage/env/overlays",
+			},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(rt *testing.T) {
+			if diff := cmp.Diff(tt.want, rootKustomization(m, tt.appLinks)); diff != "" {
+				rt.Fatalf("root kustomization didn't match:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBuildResourcesOnlyArgoCD(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	m := &config.Manifest{
+		GitOpsURL: testGitOpsRepo,
+		Config: &config.Config{
+			ArgoCD: &config.ArgoCDConfig{Namespace: "argocd"},
+		},
+		Environments: []*config.Environment{
+			{
+				Name: "tst-dev",
+				Apps: []*config.Application{
+					{Name: "app-taxi", Services: []*config.Service{{Name: "taxi"}}},
+				},
+			},
+		},
+	}
+	_, err := yaml.WriteResources(fakeFs, "/gitops", res.Resources{pipelinesFile: m})
+	fatalIfError(t, err)
+
+	err = BuildResources(&BuildParameters{PipelinesFolderPath: "/gitops", OutputPath: "/gitops", Only: BuildOnlyArgoCD}, fakeFs)
+	fatalIfError(t, err)
+
+	files := []string{}
+	err = afero.Walk(fakeFs, "/gitops", func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		files = append(files, p)
+		return nil
+	})
+	fatalIfError(t, err)
+	want := []string{
+		"/gitops/config/argocd/argo-app.yaml",
+		"/gitops/config/argocd/kustomization.yaml",
+		"/gitops/config/argocd/openapi.json",
+		"/gitops/config/argocd/tst-dev-app-taxi-app.yaml",
+		"/gitops/config/argocd/tst-dev-env-app.yaml",
+		"/gitops/pipelines.yaml",
+	}
+	if diff := cmp.Diff(want, files); diff != "" {
+		t.Fatalf("built files didn't match:\n%s", diff)
+	}
+}
+
+func TestBuildResourcesOnlyArgoCDWithoutArgoCD(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	m := &config.Manifest{GitOpsURL: testGitOpsRepo, Environments: []*config.Environment{{Name: "tst-dev"}}}
+	_, err := yaml.WriteResources(fakeFs, "/gitops", res.Resources{pipelinesFile: m})
+	fatalIfError(t, err)
+
+	err = BuildResources(&BuildParameters{PipelinesFolderPath: "/gitops", OutputPath: "/gitops", Only: BuildOnlyArgoCD}, fakeFs)
+	test.AssertErrorMatch(t, "no Argo CD configuration", err)
+}
+
+func TestBuildResourcesOnlyEventListener(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	m := &config.Manifest{
+		GitOpsURL: testGitOpsRepo,
+		Config: &config.Config{
+			ArgoCD:    &config.ArgoCDConfig{Namespace: "argocd"},
+			Pipelines: &config.PipelinesConfig{Name: "tst-cicd", PipelineNamePrefix: "team-a-", DryRunTrigger: "all"},
+		},
+		Environments: []*config.Environment{{Name: "tst-dev"}},
+	}
+	_, err := yaml.WriteResources(fakeFs, "/gitops", res.Resources{pipelinesFile: m})
+	fatalIfError(t, err)
+
+	err = BuildResources(&BuildParameters{PipelinesFolderPath: "/gitops", OutputPath: "/gitops", Only: BuildOnlyEventListener}, fakeFs)
+	fatalIfError(t, err)
+
+	files := []string{}
+	err = afero.Walk(fakeFs, "/gitops", func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		files = append(files, p)
+		return nil
+	})
+	fatalIfError(t, err)
+	want := []string{
+		"/gitops/config/tst-cicd/base/05-bindings/team-a-github-pull-request-binding.yaml",
+		"/gitops/config/tst-cicd/base/05-bindings/team-a-github-push-binding.yaml",
+		"/gitops/config/tst-cicd/base/06-templates/app-ci-build-from-push-template.yaml",
+		"/gitops/config/tst-cicd/base/06-templates/ci-dryrun-from-push-template.yaml",
+		"/gitops/config/tst-cicd/base/07-eventlisteners/cicd-event-listener.yaml",
+		"/gitops/config/tst-cicd/base/08-routes/gitops-webhook-event-listener.yaml",
+		"/gitops/pipelines.yaml",
+	}
+	if diff := cmp.Diff(want, files); diff != "" {
+		t.Fatalf("built files didn't match:\n%s", diff)
+	}
+}
+
+func TestBuildResourcesOnlyEventListenerRewritesTemplatesAndRoute(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	m := &config.Manifest{
+		GitOpsURL: testGitOpsRepo,
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{
+				Name:               "tst-cicd",
+				PipelineNamePrefix: "team-a-",
+				DisableAppCI:       true,
+				TektonAPIVersion:   "v1",
+				PipelineRunTTL:     "24h",
+				RouteSubdomain:     "webhooks",
+				WebhookTLS:         true,
+			},
+		},
+		Environments: []*config.Environment{{Name: "tst-dev"}},
+	}
+	_, err := yaml.WriteResources(fakeFs, "/gitops", res.Resources{
+		pipelinesFile: m,
+		"config/tst-cicd/base/06-templates/ci-dryrun-from-push-template.yaml": "stale",
+		"config/tst-cicd/base/08-routes/gitops-webhook-event-listener.yaml":   "stale",
+	})
+	fatalIfError(t, err)
+
+	err = BuildResources(&BuildParameters{PipelinesFolderPath: "/gitops", OutputPath: "/gitops", Only: BuildOnlyEventListener}, fakeFs)
+	fatalIfError(t, err)
+
+	template, err := afero.ReadFile(fakeFs, "/gitops/config/tst-cicd/base/06-templates/ci-dryrun-from-push-template.yaml")
+	fatalIfError(t, err)
+	for _, s := range []string{"name: team-a-ci-dryrun-from-push-template", "name: team-a-ci-dryrun-from-push-pipeline", "pruner.tekton.dev/ttlSecondsAfterFinished: \"86400\"", "apiVersion: tekton.dev/v1\n", "taskRunTemplate:"} {
+		if !strings.Contains(string(template), s) {
+			t.Errorf("template didn't contain %q:\n%s", s, template)
+		}
+	}
+	route, err := afero.ReadFile(fakeFs, "/gitops/config/tst-cicd/base/08-routes/gitops-webhook-event-listener.yaml")
+	fatalIfError(t, err)
+	for _, s := range []string{"subdomain: webhooks", "termination: edge"} {
+		if !strings.Contains(string(route), s) {
+			t.Errorf("route didn't contain %q:\n%s", s, route)
+		}
+	}
+	if exists, _ := afero.Exists(fakeFs, "/gitops/config/tst-cicd/base/06-templates/app-ci-build-from-push-template.yaml"); exists {
+		t.Error("app-ci template was built with app-ci disabled")
+	}
+}
+
+func TestBuildResourcesOnlyEventListenerUpdatesKustomization(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	m := &config.Manifest{
+		GitOpsURL: testGitOpsRepo,
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{Name: "tst-cicd", DryRunTrigger: "all"},
+		},
+		Environments: []*config.Environment{{Name: "tst-dev"}},
+	}
+	_, err := yaml.WriteResources(fakeFs, "/gitops", res.Resources{
+		pipelinesFile: m,
+		"config/tst-cicd/base/kustomization.yaml": res.Kustomization{
+			Resources: []string{"05-bindings/github-push-binding.yaml", "07-eventlisteners/cicd-event-listener.yaml", "08-routes/gitops-webhook-event-listener.yaml"},
+		},
+	})
+	fatalIfError(t, err)
+
+	err = BuildResources(&BuildParameters{PipelinesFolderPath: "/gitops", OutputPath: "/gitops", Only: BuildOnlyEventListener}, fakeFs)
+	fatalIfError(t, err)
+
+	data, err := afero.ReadFile(fakeFs, "/gitops/config/tst-cicd/base/kustomization.yaml")
+	fatalIfError(t, err)
+	var k res.Kustomization
+	fatalIfError(t, sigsyaml.Unmarshal(data, &k))
+	want := []string{
+		"05-bindings/github-pull-request-binding.yaml",
+		"05-bindings/github-push-binding.yaml",
+		"07-eventlisteners/cicd-event-listener.yaml",
+		"08-routes/gitops-webhook-event-listener.yaml",
+	}
+	if diff := cmp.Diff(want, k.Resources); diff != "" {
+		t.Fatalf("base kustomization resources didn't match:\n%s", diff)
+	}
+}
+
+func TestBuildResourcesOnlyEventListenerWithoutPipelines(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	m := &config.Manifest{GitOpsURL: testGitOpsRepo, Environments: []*config.Environment{{Name: "tst-dev"}}}
+	_, err := yaml.WriteResources(fakeFs, "/gitops", res.Resources{pipelinesFile: m})
+	fatalIfError(t, err)
+
+	err = BuildResources(&BuildParameters{PipelinesFolderPath: "/gitops", OutputPath: "/gitops", Only: BuildOnlyEventListener}, fakeFs)
+	test.AssertErrorMatch(t, "no pipelines configuration", err)
+}
+
+func TestBuildResourcesWithGitLabMergeRequestBinding(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	m := &config.Manifest{
+		GitOpsURL: "https://gitlab.com/my-org/gitops.git",
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{Name: "tst-cicd"},
+		},
+		Environments: []*config.Environment{{Name: "tst-dev"}},
+	}
+	_, err := yaml.WriteResources(fakeFs, "/gitops", res.Resources{
+		pipelinesFile: m,
+		"config/tst-cicd/base/kustomization.yaml": res.Kustomization{
+			Resources: []string{"05-bindings/gitlab-push-binding.yaml", "07-eventlisteners/cicd-event-listener.yaml"},
+		},
+	})
+	fatalIfError(t, err)
+
+	err = BuildResources(&BuildParameters{PipelinesFolderPath: "/gitops", OutputPath: "/gitops"}, fakeFs)
+	fatalIfError(t, err)
+
+	exists, err := afero.Exists(fakeFs, "/gitops/config/tst-cicd/base/05-bindings/gitlab-merge-request-binding.yaml")
+	fatalIfError(t, err)
+	if !exists {
+		t.Fatal("the merge request binding was not built")
+	}
+	data, err := afero.ReadFile(fakeFs, "/gitops/config/tst-cicd/base/kustomization.yaml")
+	fatalIfError(t, err)
+	var k res.Kustomization
+	fatalIfError(t, sigsyaml.Unmarshal(data, &k))
+	want := []string{
+		"05-bindings/gitlab-merge-request-binding.yaml",
+		"05-bindings/gitlab-push-binding.yaml",
+		"07-eventlisteners/cicd-event-listener.yaml",
+	}
+	if diff := cmp.Diff(want, k.Resources); diff != "" {
+		t.Fatalf("base kustomization resources didn't match:\n%s", diff)
+	}
+}
+
+func TestBuildResourcesWithValues(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	template := `gitops_url: https://github.com/my-org/${TEAM}-gitops.git
+config:
+  argocd:
+    namespace: argocd
+environments:
+- name: ${TEAM}-dev
+`
+	fatalIfError(t, afero.WriteFile(fakeFs, "/template/pipelines.yaml", []byte(template), 0644))
+	fatalIfError(t, afero.WriteFile(fakeFs, "/values.yaml", []byte("TEAM: team-a\n"), 0644))
+
+	err := BuildResources(&BuildParameters{PipelinesFolderPath: "/template", OutputPath: "/gitops", ValuesFile: "/values.yaml"}, fakeFs)
+	fatalIfError(t, err)
+
+	m, err := config.LoadManifest(fakeFs, "/gitops")
+	fatalIfError(t, err)
+	if m.GitOpsURL != "https://github.com/my-org/team-a-gitops.git" {
+		t.Fatalf("got GitOps URL %q in the built manifest", m.GitOpsURL)
+	}
+	exists, err := afero.Exists(fakeFs, "/gitops/environments/team-a-dev/env/base/team-a-dev-environment.yaml")
+	fatalIfError(t, err)
+	if !exists {
+		t.Fatal("the environment from the template was not built")
+	}
+}
+
+func TestBuildResourcesWithMissingValues(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	fatalIfError(t, afero.WriteFile(fakeFs, "/template/pipelines.yaml", []byte("environments:\n- name: ${TEAM}-${ENV}\n"), 0644))
+	fatalIfError(t, afero.WriteFile(fakeFs, "/values.yaml", []byte("TEAM: team-a\n"), 0644))
+
+	err := BuildResources(&BuildParameters{PipelinesFolderPath: "/template", OutputPath: "/gitops", ValuesFile: "/values.yaml"}, fakeFs)
+	test.AssertErrorMatch(t, `no values provided for the placeholders \$\{ENV\}`, err)
+}
+
+func TestBuildResourcesWithValidate(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	m := &config.Manifest{
+		GitOpsURL: testGitOpsRepo,
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{Name: "tst-cicd"},
+			ArgoCD:    &config.ArgoCDConfig{Namespace: "argocd"},
+		},
+		Environments: []*config.Environment{
+			{
+				Name: "tst-dev",
+				Apps: []*config.Application{
+					{
+						Name: "app-taxi",
+						Services: []*config.Service{
+							{
+								Name:      "taxi",
+								SourceURL: testSvcRepo,
+								Webhook: &config.Webhook{
+									Secret: &config.Secret{Name: "github-webhook-secret-taxi-svc", Namespace: "tst-cicd"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	_, err := yaml.WriteResources(fakeFs, "/gitops", res.Resources{pipelinesFile: m})
+	fatalIfError(t, err)
+
+	err = BuildResources(&BuildParameters{PipelinesFolderPath: "/gitops", OutputPath: "/gitops", Validate: true}, fakeFs)
+	fatalIfError(t, err)
+
+	exists, err := afero.Exists(fakeFs, "/gitops/config/tst-cicd/base/07-eventlisteners/cicd-event-listener.yaml")
+	fatalIfError(t, err)
+	if !exists {
+		t.Fatal("the validated resources were not written")
+	}
+}
+
+func TestExportResources(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	m := &config.Manifest{
+		GitOpsURL: testGitOpsRepo,
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{Name: "tst-cicd"},
+			ArgoCD:    &config.ArgoCDConfig{Namespace: "argocd"},
+		},
+		Environments: []*config.Environment{{Name: "tst-dev"}},
+	}
+	_, err := yaml.WriteResources(fakeFs, "/gitops", res.Resources{pipelinesFile: m})
+	fatalIfError(t, err)
+
+	var b bytes.Buffer
+	err = ExportResources(&b, &BuildParameters{PipelinesFolderPath: "/gitops"}, fakeFs)
+	fatalIfError(t, err)
+
+	paths := []string{}
+	for _, line := range strings.Split(b.String(), "\n") {
+		if strings.HasPrefix(line, "# ") {
+			paths = append(paths, strings.TrimPrefix(line, "# "))
+		}
+	}
+	want := []string{
+		"config/argocd/argo-app.yaml",
+		"config/argocd/cicd-app.yaml",
+		"config/argocd/tst-dev-env-app.yaml",
+		"config/tst-cicd/base/05-bindings/github-push-binding.yaml",
+		"config/tst-cicd/base/07-eventlisteners/cicd-event-listener.yaml",
+		"environments/tst-dev/env/base/argocd-admin.yaml",
+		"environments/tst-dev/env/base/tst-dev-environment.yaml",
+	}
+	if diff := cmp.Diff(want, paths); diff != "" {
+		t.Fatalf("exported resources didn't match:\n%s", diff)
+	}
+	if n := strings.Count(b.String(), "---\n"); n != len(want) {
+		t.Fatalf("got %d documents, want %d", n, len(want))
+	}
+	exists, err := afero.Exists(fakeFs, "/gitops/config/argocd/argo-app.yaml")
+	fatalIfError(t, err)
+	if exists {
+		t.Fatal("the exported resources were written to the tree")
+	}
+}
+
+func TestBuildResourcesWithChecksums(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	m := &config.Manifest{
+		GitOpsURL: testGitOpsRepo,
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{Name: "tst-cicd"},
+			ArgoCD:    &config.ArgoCDConfig{Namespace: "argocd"},
+		},
+		Environments: []*config.Environment{{Name: "tst-dev"}},
+	}
+	_, err := yaml.WriteResources(fakeFs, "/gitops", res.Resources{pipelinesFile: m})
+	fatalIfError(t, err)
+
+	err = BuildResources(&BuildParameters{PipelinesFolderPath: "/gitops", OutputPath: "/gitops", WriteChecksums: true}, fakeFs)
+	fatalIfError(t, err)
+
+	sums, err := readChecksums(fakeFs, "/gitops")
+	fatalIfError(t, err)
+	if _, ok := sums["config/tst-cicd/base/07-eventlisteners/cicd-event-listener.yaml"]; !ok {
+		t.Fatalf("no checksum for the built EventListener in %v", sums)
+	}
+	drift, err := VerifyChecksums(fakeFs, "/gitops")
+	fatalIfError(t, err)
+	if !drift.Empty() {
+		t.Fatalf("the built tree doesn't match the checksums: %#v", drift)
+	}
+}
+
+func TestDumpManifest(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	manifest := `environments:
+- name: stage
+  apps:
+  - name: web
+    services:
+    - name: ui
+    - name: api
+  - name: app-1
+    services:
+    - name: worker
+- name: dev
+gitops_url: https://github.com/my-org/gitops.git
+`
+	fatalIfError(t, afero.WriteFile(fakeFs, "/gitops/pipelines.yaml", []byte(manifest), 0644))
+
+	var b bytes.Buffer
+	fatalIfError(t, DumpManifest(&b, &BuildParameters{PipelinesFolderPath: "/gitops"}, fakeFs))
+
+	want := `environments:
+- name: dev
+- apps:
+  - name: app-1
+    services:
+    - name: worker
+  - name: web
+    services:
+    - name: api
+    - name: ui
+  name: stage
+gitops_url: https://github.com/my-org/gitops.git
+`
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Fatalf("dumped manifest didn't match:\n%s", diff)
+	}
+	exists, err := afero.Exists(fakeFs, "/gitops/config")
+	fatalIfError(t, err)
+	if exists {
+		t.Fatal("resources were built when dumping the manifest")
+	}
+}
@@ -0,0 +1,137 @@
+package pipelines
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/afero"
+)
+
+// ChecksumsFile is the name of the file that the checksums of the generated
+// files are written to, at the root of the GitOps tree.
+const ChecksumsFile = "checksums.txt"
+
+// WriteChecksums writes the sha256 checksums of the files, relative to path,
+// to the ChecksumsFile in path.
+//
+// The checksums are written in the format of sha256sum, sorted by filename,
+// so the file can also be checked with sha256sum -c.  The checksums of files
+// that were written previously are kept, so building a subset of the files
+// doesn't drop the checksums of the files that weren't built.
+func WriteChecksums(appFs afero.Fs, path string, filenames []string) error {
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path to the tree: %w", err)
+	}
+	sums, err := readChecksums(appFs, path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if sums == nil {
+		sums = map[string]string{}
+	}
+	for _, filename := range filenames {
+		filename = filepath.ToSlash(filename)
+		if filename == ChecksumsFile {
+			continue
+		}
+		sum, err := fileChecksum(appFs, filepath.Join(path, filename))
+		if err != nil {
+			return err
+		}
+		sums[filename] = sum
+	}
+
+	names := make([]string, 0, len(sums))
+	for name := range sums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s  %s\n", sums[name], name)
+	}
+	if err := afero.WriteFile(appFs, filepath.Join(path, ChecksumsFile), b.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", ChecksumsFile, err)
+	}
+	return nil
+}
+
+// VerifyChecksums compares the files in the tree at path to the checksums in
+// its ChecksumsFile.
+//
+// Only the files with checksums are verified, the Drift has no extra files.
+func VerifyChecksums(appFs afero.Fs, path string) (*Drift, error) {
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path to the tree: %w", err)
+	}
+	sums, err := readChecksums(appFs, path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no %s found in %s, write the checksums with --write-checksums", ChecksumsFile, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	drift := &Drift{}
+	for filename, want := range sums {
+		got, err := fileChecksum(appFs, filepath.Join(path, filename))
+		if os.IsNotExist(err) {
+			drift.Missing = append(drift.Missing, filename)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if got != want {
+			drift.Changed = append(drift.Changed, filename)
+		}
+	}
+	sort.Strings(drift.Changed)
+	sort.Strings(drift.Missing)
+	return drift, nil
+}
+
+// readChecksums reads the checksums from the ChecksumsFile in path, keyed by
+// the filenames.
+func readChecksums(appFs afero.Fs, path string) (map[string]string, error) {
+	f, err := appFs.Open(filepath.Join(path, ChecksumsFile))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sums := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if scanner.Text() == "" {
+			continue
+		}
+		parts := strings.SplitN(scanner.Text(), "  ", 2)
+		if len(parts) != 2 || len(parts[0]) != sha256.Size*2 || parts[1] == "" {
+			return nil, fmt.Errorf("invalid checksum on line %d of %s", line, ChecksumsFile)
+		}
+		sums[parts[1]] = parts[0]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ChecksumsFile, err)
+	}
+	return sums, nil
+}
+
+func fileChecksum(appFs afero.Fs, filename string) (string, error) {
+	data, err := afero.ReadFile(appFs, filename)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
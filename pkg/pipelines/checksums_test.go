@@ -0,0 +1,110 @@
+package pipelines
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestWriteChecksums(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	fatalIfError(t, afero.WriteFile(fakeFs, "/gitops/pipelines.yaml", []byte("environments: []\n"), 0644))
+	fatalIfError(t, afero.WriteFile(fakeFs, "/gitops/config/argocd/kustomization.yaml", []byte("resources: []\n"), 0644))
+
+	fatalIfError(t, WriteChecksums(fakeFs, "/gitops", []string{"pipelines.yaml", "config/argocd/kustomization.yaml"}))
+
+	b, err := afero.ReadFile(fakeFs, "/gitops/checksums.txt")
+	fatalIfError(t, err)
+	want := "6c71762bddd54c97932b035aedf43fd9d460da4df3c19a294dc46e1b356f781d  config/argocd/kustomization.yaml\n" +
+		"a1b36d3c948f2ce40e5edf70ccf88663d5259d30523a385e486dca69c5a9116e  pipelines.yaml\n"
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Fatalf("checksums didn't match:\n%s", diff)
+	}
+}
+
+func TestWriteChecksumsKeepsPreviousChecksums(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	fatalIfError(t, afero.WriteFile(fakeFs, "/gitops/pipelines.yaml", []byte("environments: []\n"), 0644))
+	fatalIfError(t, afero.WriteFile(fakeFs, "/gitops/config/argocd/kustomization.yaml", []byte("resources: []\n"), 0644))
+	fatalIfError(t, WriteChecksums(fakeFs, "/gitops", []string{"pipelines.yaml", "config/argocd/kustomization.yaml"}))
+
+	fatalIfError(t, afero.WriteFile(fakeFs, "/gitops/config/argocd/kustomization.yaml", []byte("resources:\n- app.yaml\n"), 0644))
+	fatalIfError(t, WriteChecksums(fakeFs, "/gitops", []string{"config/argocd/kustomization.yaml"}))
+
+	drift, err := VerifyChecksums(fakeFs, "/gitops")
+	fatalIfError(t, err)
+	if !drift.Empty() {
+		t.Fatalf("the tree doesn't match the checksums: %#v", drift)
+	}
+	sums, err := readChecksums(fakeFs, "/gitops")
+	fatalIfError(t, err)
+	if l := len(sums); l != 2 {
+		t.Fatalf("got %d checksums, want 2", l)
+	}
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/gitops",
+		WriteChecksums:       true,
+	}
+	written, err := BootstrapToFs(params, fakeFs)
+	fatalIfError(t, err)
+
+	sums, err := readChecksums(fakeFs, "/gitops")
+	fatalIfError(t, err)
+	for filename := range written {
+		if _, ok := sums[filename]; !ok && !strings.HasPrefix(filename, "../") {
+			t.Errorf("no checksum for %s", filename)
+		}
+	}
+	drift, err := VerifyChecksums(fakeFs, "/gitops")
+	fatalIfError(t, err)
+	if !drift.Empty() {
+		t.Fatalf("the bootstrapped tree doesn't match the checksums: %#v", drift)
+	}
+}
+
+func TestVerifyChecksumsWithModifiedFiles(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	fatalIfError(t, afero.WriteFile(fakeFs, "/gitops/pipelines.yaml", []byte("environments: []\n"), 0644))
+	fatalIfError(t, afero.WriteFile(fakeFs, "/gitops/config/argocd/kustomization.yaml", []byte("resources: []\n"), 0644))
+	fatalIfError(t, WriteChecksums(fakeFs, "/gitops", []string{"pipelines.yaml", "config/argocd/kustomization.yaml"}))
+
+	fatalIfError(t, afero.WriteFile(fakeFs, "/gitops/pipelines.yaml", []byte("environments:\n- name: prod\n"), 0644))
+	fatalIfError(t, fakeFs.Remove("/gitops/config/argocd/kustomization.yaml"))
+
+	drift, err := VerifyChecksums(fakeFs, "/gitops")
+	fatalIfError(t, err)
+
+	want := &Drift{
+		Changed: []string{"pipelines.yaml"},
+		Missing: []string{"config/argocd/kustomization.yaml"},
+	}
+	if diff := cmp.Diff(want, drift); diff != "" {
+		t.Fatalf("drift didn't match:\n%s", diff)
+	}
+}
+
+func TestVerifyChecksumsErrors(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	_, err := VerifyChecksums(fakeFs, "/gitops")
+	test.AssertErrorMatch(t, `no checksums.txt found in /gitops`, err)
+
+	fatalIfError(t, afero.WriteFile(fakeFs, "/gitops/checksums.txt", []byte("abc pipelines.yaml\n"), 0644))
+	_, err = VerifyChecksums(fakeFs, "/gitops")
+	test.AssertErrorMatch(t, `invalid checksum on line 1 of checksums.txt`, err)
+}
@@ -1,14 +1,33 @@
 package clientconfig
 
 import (
+	"net/http"
+	"net/url"
+
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
+var proxyURL *url.URL
+
+// SetProxy sets the proxy that the clients created with the config from
+// GetRESTConfig send their requests to the cluster through, instead of the
+// proxy from the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables.
+func SetProxy(u *url.URL) {
+	proxyURL = u
+}
+
 // GetRESTConfig returns client config to be used to create client
 func GetRESTConfig() (*rest.Config, error) {
 	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
 	configOverrides := &clientcmd.ConfigOverrides{}
 	kubeconfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
-	return kubeconfig.ClientConfig()
+	config, err := kubeconfig.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL != nil {
+		config.Proxy = http.ProxyURL(proxyURL)
+	}
+	return config, nil
 }
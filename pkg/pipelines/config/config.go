@@ -2,13 +2,21 @@ package config
 
 import (
 	"fmt"
+	"path"
 	"path/filepath"
 	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 const (
 	// PipelinesFile is the name of the pipelines manifest file
 	PipelinesFile = "pipelines.yaml"
+
+	// DefaultServiceAccountName is the service account that the generated CI
+	// resources run as, OpenShift Pipelines creates it in every namespace.
+	DefaultServiceAccountName = "pipeline"
 )
 
 // PathForService gives a repo-rooted path within a repository.
@@ -16,6 +24,31 @@ func PathForService(app *Application, env *Environment, serviceName string) stri
 	return filepath.Join(PathForApplication(env, app), "services", serviceName)
 }
 
+// PathForSharedService gives a repo-rooted path to a service that the
+// environments share, with a base and an overlay for each environment.
+func PathForSharedService(app *Application, serviceName string) string {
+	return filepath.Join("apps", app.Name, "services", serviceName)
+}
+
+// PathForServiceBase gives a repo-rooted path to the base for a service, with
+// per-environment overlays this is shared by the environments.
+func PathForServiceBase(app *Application, env *Environment, serviceName string, perEnv bool) string {
+	if perEnv {
+		return filepath.Join(PathForSharedService(app, serviceName), "base")
+	}
+	return filepath.Join(PathForService(app, env, serviceName), "base")
+}
+
+// PathForServiceOverlay gives a repo-rooted path to the overlay for a service,
+// with per-environment overlays this is named for the environment, alongside
+// the shared base.
+func PathForServiceOverlay(app *Application, env *Environment, serviceName string, perEnv bool) string {
+	if perEnv {
+		return filepath.Join(PathForSharedService(app, serviceName), "overlays", env.Name)
+	}
+	return filepath.Join(PathForService(app, env, serviceName), "overlays")
+}
+
 // PathForApplication generates a repo-rooted path within a repository.
 func PathForApplication(env *Environment, app *Application) string {
 	return filepath.Join(PathForEnvironment(env), "apps", app.Name)
@@ -38,7 +71,10 @@ func PathForArgoCD() string {
 
 // Manifest describes a set of environments, apps and services for deployment.
 type Manifest struct {
-	GitOpsURL    string         `json:"gitops_url,omitempty"`
+	GitOpsURL string `json:"gitops_url,omitempty"`
+	// RepoSubpath is the folder within the GitOps repository that the
+	// configuration is in, if it isn't the root of the repository.
+	RepoSubpath  string         `json:"repo_subpath,omitempty"`
 	Environments []*Environment `json:"environments,omitempty"`
 	Config       *Config        `json:"config,omitempty"`
 	Version      int            `json:"version,omitempty"`
@@ -69,6 +105,37 @@ func (m *Manifest) GetApplication(environment, application string) *Application
 	return nil
 }
 
+// GetService returns a named service, within an application in an
+// environment, if it exists.
+func (m *Manifest) GetService(environment, application, service string) *Service {
+	app := m.GetApplication(environment, application)
+	if app == nil {
+		return nil
+	}
+	for _, svc := range app.Services {
+		if svc.Name == service {
+			return svc
+		}
+	}
+	return nil
+}
+
+// RemoveService removes a named service from an application in an
+// environment, if it exists.
+func (m *Manifest) RemoveService(environment, application, service string) {
+	app := m.GetApplication(environment, application)
+	if app == nil {
+		return
+	}
+	services := []*Service{}
+	for _, svc := range app.Services {
+		if svc.Name != service {
+			services = append(services, svc)
+		}
+	}
+	app.Services = services
+}
+
 // AddService adds a new service to a specific environment and creates a
 // reference to it within an Application.
 func (m *Manifest) AddService(envName, appName string, svc *Service) error {
@@ -93,6 +160,51 @@ func (m *Manifest) GetPipelinesConfig() *PipelinesConfig {
 	return nil
 }
 
+// IsNamespacedInstall returns true if only namespaced resources should be
+// generated.
+func (m *Manifest) IsNamespacedInstall() bool {
+	return m.Config != nil && m.Config.NamespacedInstall
+}
+
+// UseProjectRequests returns true if ProjectRequests should be generated
+// instead of Namespaces.
+func (m *Manifest) UseProjectRequests() bool {
+	return m.Config != nil && m.Config.UseProjectRequests
+}
+
+// GetPipelineNamePrefix returns the prefix for the names of the generated CI
+// resources, if any.
+func (m *Manifest) GetPipelineNamePrefix() string {
+	if cfg := m.GetPipelinesConfig(); cfg != nil {
+		return cfg.PipelineNamePrefix
+	}
+	return ""
+}
+
+// GetServiceAccountName returns the name of the service account that the
+// generated CI resources run as.
+func (m *Manifest) GetServiceAccountName() string {
+	if cfg := m.GetPipelinesConfig(); cfg != nil && cfg.ServiceAccountName != "" {
+		return cfg.ServiceAccountName
+	}
+	return DefaultServiceAccountName
+}
+
+// GetDefaultBranch returns the branch of the service repositories whose
+// pushes trigger the app-ci pipelines, if any.
+func (m *Manifest) GetDefaultBranch() string {
+	if cfg := m.GetPipelinesConfig(); cfg != nil {
+		return cfg.DefaultBranch
+	}
+	return ""
+}
+
+// UsePerEnvOverlays returns true if the service overlays should be named for
+// their environments.
+func (m *Manifest) UsePerEnvOverlays() bool {
+	return m.Config != nil && m.Config.PerEnvOverlays
+}
+
 // GetArgoCDConfig returns the global ArgoCD configuration, if one exists.
 func (m *Manifest) GetArgoCDConfig() *ArgoCDConfig {
 	if m.Config != nil {
@@ -108,6 +220,28 @@ type Environment struct {
 	Cluster   string         `json:"cluster,omitempty"`
 	Pipelines *Pipelines     `json:"pipelines,omitempty"`
 	Apps      []*Application `json:"apps,omitempty"`
+	// Branches restricts the GitOps repository branches that trigger the CI
+	// dry-run for this environment, a trailing "*" matches any suffix.
+	Branches []string `json:"branches,omitempty"`
+	// SyncOptions are added to the sync policy of the Argo CD applications
+	// for this environment and its apps e.g. ServerSideApply=true.
+	SyncOptions []string `json:"sync_options,omitempty"`
+	// AutoSync enables automated syncing of the Argo CD applications for this
+	// environment and its apps, it defaults to true.
+	AutoSync *bool `json:"auto_sync,omitempty"`
+	// ArgoCDAppNamespace is the namespace that the Argo CD applications for
+	// this environment and its apps are created in, rather than the Argo CD
+	// namespace, Argo CD must watch it for applications.
+	ArgoCDAppNamespace string `json:"argocd_app_namespace,omitempty"`
+	// IgnoreDifferences are the fields that the Argo CD applications for this
+	// environment and its apps ignore when comparing the live resources.
+	IgnoreDifferences []*IgnoreDifference `json:"ignore_differences,omitempty"`
+}
+
+// IsAutoSync returns true unless automated syncing is disabled for the
+// environment.
+func (e *Environment) IsAutoSync() bool {
+	return e.AutoSync == nil || *e.AutoSync
 }
 
 // Config represents the configuration for non-application environments.
@@ -115,16 +249,197 @@ type Config struct {
 	Pipelines *PipelinesConfig `json:"pipelines,omitempty"`
 	ArgoCD    *ArgoCDConfig    `json:"argocd,omitempty"`
 	Git       *GitConfig       `json:"git,omitempty"`
+	// SecretsRepo is a separate repository that the secrets are delivered
+	// from.
+	SecretsRepo *Repository `json:"secrets_repo,omitempty"`
+	// NamespacedInstall indicates that no cluster-scoped resources are
+	// generated, the namespaces must already exist.
+	NamespacedInstall bool `json:"namespaced_install,omitempty"`
+	// UseProjectRequests indicates that OpenShift ProjectRequests are
+	// generated for the namespaces instead of Namespaces.
+	UseProjectRequests bool `json:"use_project_requests,omitempty"`
+	// PerEnvOverlays indicates that each service has a base that the
+	// environments share, and an overlay named for each environment, which
+	// the environment's application uses.
+	PerEnvOverlays bool `json:"per_env_overlays,omitempty"`
 }
 
 // PipelinesConfig provides configuration for the CI/CD pipelines.
 type PipelinesConfig struct {
 	Name string `json:"name,omitempty"`
+	// DisableAppCI indicates that images are built outside of the generated
+	// pipelines, so no app-ci triggers are generated for services.
+	DisableAppCI bool `json:"disable_app_ci,omitempty"`
+	// PipelineNamePrefix is prefixed to the names of the generated CI
+	// Pipelines, TriggerTemplates and TriggerBindings, so that several
+	// configurations can share a namespace.
+	PipelineNamePrefix string `json:"pipeline_name_prefix,omitempty"`
+	// WebhookInterceptorURL is the URL of a Service that every event received
+	// by the EventListener is also forwarded to e.g. for auditing.
+	WebhookInterceptorURL string `json:"webhook_interceptor_url,omitempty"`
+	// EventListenerResources are the compute resources of the EventListener's
+	// pod, the Triggers defaults are used if they're not set.
+	EventListenerResources *Resources `json:"event_listener_resources,omitempty"`
+	// DryRunTrigger is the events in the GitOps repository that trigger the
+	// CI dry-run, one of push, pull-request or all.  If it's not set, pushes
+	// are dry-run, and so are the merge requests to GitLab repositories.
+	DryRunTrigger string `json:"dry_run_trigger,omitempty"`
+	// ServiceAccountName is the service account that the EventListener and
+	// the PipelineRuns run as, and that is bound to the environments, it
+	// defaults to DefaultServiceAccountName.
+	ServiceAccountName string `json:"service_account_name,omitempty"`
+	// DefaultBranch is the branch of the service repositories whose pushes
+	// trigger the app-ci pipelines, if it's not set, pushes to any branch
+	// trigger them.
+	DefaultBranch string `json:"default_branch,omitempty"`
+	// TektonAPIVersion is the tekton.dev API version of the generated
+	// OpenShift Pipelines resources, it defaults to v1beta1.
+	TektonAPIVersion string `json:"tekton_api_version,omitempty"`
+	// PipelineRunTTL is how long the PipelineRuns created by the CI triggers
+	// are kept after they finish, e.g. 24h, if it's not set, they aren't
+	// pruned.
+	PipelineRunTTL string `json:"pipelinerun_ttl,omitempty"`
+	// RouteWildcardPolicy is the wildcard policy of the EventListener's
+	// Route, it defaults to None.
+	RouteWildcardPolicy string `json:"route_wildcard_policy,omitempty"`
+	// RouteSubdomain is the subdomain within the router's domain that the
+	// EventListener's Route requests.
+	RouteSubdomain string `json:"route_subdomain,omitempty"`
+	// WebhookTLS indicates that the EventListener's Route terminates TLS at
+	// the router, and redirects insecure requests.
+	WebhookTLS bool `json:"webhook_tls,omitempty"`
+}
+
+// Resources are the compute resource requests and limits of a container, in
+// the Kubernetes quantity format e.g. 250m or 512Mi.
+type Resources struct {
+	CPURequest    string `json:"cpu_request,omitempty"`
+	CPULimit      string `json:"cpu_limit,omitempty"`
+	MemoryRequest string `json:"memory_request,omitempty"`
+	MemoryLimit   string `json:"memory_limit,omitempty"`
+}
+
+// Requirements returns the ResourceRequirements for the quantities that are
+// set.
+func (r *Resources) Requirements() (corev1.ResourceRequirements, error) {
+	requirements := corev1.ResourceRequirements{}
+	if r == nil {
+		return requirements, nil
+	}
+	var err error
+	if requirements.Requests, err = resourceList(r.CPURequest, r.MemoryRequest); err != nil {
+		return corev1.ResourceRequirements{}, err
+	}
+	if requirements.Limits, err = resourceList(r.CPULimit, r.MemoryLimit); err != nil {
+		return corev1.ResourceRequirements{}, err
+	}
+	return requirements, nil
+}
+
+func resourceList(cpu, memory string) (corev1.ResourceList, error) {
+	var list corev1.ResourceList
+	for _, r := range []struct {
+		name  corev1.ResourceName
+		value string
+	}{{corev1.ResourceCPU, cpu}, {corev1.ResourceMemory, memory}} {
+		if r.value == "" {
+			continue
+		}
+		q, err := resource.ParseQuantity(r.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s quantity %q: %w", r.name, r.value, err)
+		}
+		if list == nil {
+			list = corev1.ResourceList{}
+		}
+		list[r.name] = q
+	}
+	return list, nil
 }
 
 // ArgoCDConfig provides configuration for the ArgoCD application generation.
 type ArgoCDConfig struct {
 	Namespace string `json:"namespace,omitempty"`
+	// ImageUpdater enables the Argo CD Image Updater annotations on the
+	// applications for the services that configure an image_update.
+	ImageUpdater *ImageUpdaterConfig `json:"image_updater,omitempty"`
+	// ApplyMode is how Argo CD applies the resources, one of client-side or
+	// server-side, it defaults to client-side.
+	ApplyMode string `json:"apply_mode,omitempty"`
+	// ApplicationNamespaces are the namespaces other than its own that Argo
+	// CD watches for applications, the names can be glob patterns e.g.
+	// team-*.
+	ApplicationNamespaces []string `json:"application_namespaces,omitempty"`
+	// Project is the Argo CD project that the applications are created in,
+	// if set an AppProject that only allows the applications' source
+	// repositories and destinations is generated, otherwise the applications
+	// are in the default project.
+	Project string `json:"project,omitempty"`
+	// AutoSync enables automated syncing of the Argo CD applications, it
+	// defaults to true, Environments can disable it with their AutoSync.
+	AutoSync *bool `json:"auto_sync,omitempty"`
+	// Prune deletes the resources that are no longer in Git when the
+	// applications are synced automatically, it defaults to true.
+	Prune *bool `json:"prune,omitempty"`
+	// SelfHeal syncs the applications automatically when the live resources
+	// drift from Git, it defaults to true.
+	SelfHeal *bool `json:"self_heal,omitempty"`
+	// CreateNamespace adds CreateNamespace=true to the sync options of the
+	// applications, so that Argo CD creates their destination namespaces.
+	CreateNamespace bool `json:"create_namespace,omitempty"`
+}
+
+// IsAutoSync returns true unless automated syncing of the applications is
+// disabled.
+func (c *ArgoCDConfig) IsAutoSync() bool {
+	return c == nil || c.AutoSync == nil || *c.AutoSync
+}
+
+// IsPrune returns true unless pruning is disabled for the automated syncs.
+func (c *ArgoCDConfig) IsPrune() bool {
+	return c == nil || c.Prune == nil || *c.Prune
+}
+
+// IsSelfHeal returns true unless self-healing is disabled for the automated
+// syncs.
+func (c *ArgoCDConfig) IsSelfHeal() bool {
+	return c == nil || c.SelfHeal == nil || *c.SelfHeal
+}
+
+// IsServerSideApply returns true if Argo CD applies the resources with
+// server-side apply.
+func (c *ArgoCDConfig) IsServerSideApply() bool {
+	return c != nil && c.ApplyMode == ApplyModeServerSide
+}
+
+// WatchesNamespace returns true if Argo CD watches the namespace for
+// applications, this is its own namespace, or one that matches the
+// ApplicationNamespaces.
+func (c *ArgoCDConfig) WatchesNamespace(ns string) bool {
+	if c == nil {
+		return false
+	}
+	if ns == c.Namespace {
+		return true
+	}
+	for _, pattern := range c.ApplicationNamespaces {
+		if ok, err := path.Match(pattern, ns); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ImageUpdaterConfig configures how the Argo CD Image Updater updates the
+// images of the services.
+type ImageUpdaterConfig struct {
+	// UpdateStrategy is how the new image tag is picked, one of semver,
+	// latest, digest or name, it defaults to latest.
+	UpdateStrategy string `json:"update_strategy,omitempty"`
+	// WriteBackMethod is how the new image tag is recorded, git commits it to
+	// the GitOps repository and argocd changes the application, it defaults
+	// to git.
+	WriteBackMethod string `json:"write_back_method,omitempty"`
 }
 
 // GitConfig configures the git drivers.
@@ -152,6 +467,69 @@ type Service struct {
 	Webhook   *Webhook   `json:"webhook,omitempty"`
 	SourceURL string     `json:"source_url,omitempty"`
 	Pipelines *Pipelines `json:"pipelines,omitempty"`
+	// Image pins the image deployed for this service in this environment.
+	// If omitted, the deployment tracks whatever tag its configuration uses.
+	Image *Image `json:"image,omitempty"`
+	// SyncOptions are added to the sync policy of the Argo CD application
+	// that deploys this service e.g. Replace=true.
+	SyncOptions []string `json:"sync_options,omitempty"`
+	// ImageUpdate is the image repository that the Argo CD Image Updater
+	// watches for new tags of the service's image.
+	ImageUpdate *ImageUpdate `json:"image_update,omitempty"`
+	// PRPreviews deploys a preview of the service for each open pull request
+	// to its source repository, with an Argo CD ApplicationSet.
+	PRPreviews *PRPreviews `json:"pr_previews,omitempty"`
+	// IgnoreDifferences are the fields that the Argo CD application that
+	// deploys this service ignores when comparing the live resources.
+	IgnoreDifferences []*IgnoreDifference `json:"ignore_differences,omitempty"`
+}
+
+// IgnoreDifference identifies fields of the resources that Argo CD ignores
+// when comparing the live resources with the GitOps repository, because a
+// controller changes them e.g. the replicas of a Deployment that's scaled by a
+// HorizontalPodAutoscaler.
+type IgnoreDifference struct {
+	Group string `json:"group,omitempty"`
+	Kind  string `json:"kind,omitempty"`
+	// Name restricts the ignored fields to the resources with this name.
+	Name string `json:"name,omitempty"`
+	// JSONPointers are the RFC 6901 pointers to the ignored fields e.g.
+	// /spec/replicas.
+	JSONPointers []string `json:"json_pointers,omitempty"`
+}
+
+// PRPreviews configures the previews of the pull requests to a service's
+// source repository.
+type PRPreviews struct {
+	// Repository is the image repository that the images built for the pull
+	// requests are pushed to.
+	Repository string `json:"repository,omitempty"`
+	// ImageName is the image name used in the service's deployment
+	// configuration, that is replaced with the image built for the pull
+	// request.
+	ImageName string `json:"image_name,omitempty"`
+	// TokenSecret is the name of a secret in the Argo CD namespace, with an
+	// access token for listing the pull requests in its token key.
+	TokenSecret string `json:"token_secret,omitempty"`
+}
+
+// ImageUpdate identifies the image that the Argo CD Image Updater updates.
+type ImageUpdate struct {
+	// Repository is the image repository that is watched for new tags.
+	Repository string `json:"repository,omitempty"`
+	// ImageName is the image name used in the service's deployment
+	// configuration that is replaced, it defaults to the Repository.
+	ImageName string `json:"image_name,omitempty"`
+}
+
+// Image is a kustomize image override applied when deploying a service.
+type Image struct {
+	// Name is the image name used in the service's deployment configuration.
+	Name string `json:"name,omitempty"`
+	// NewName optionally replaces the image name.
+	NewName string `json:"new_name,omitempty"`
+	// Tag is the image tag to deploy.
+	Tag string `json:"tag,omitempty"`
 }
 
 // Webhook provides Github webhook secret for eventlisteners
@@ -191,6 +191,30 @@ func TestGetArgoCDConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestWatchesNamespace(t *testing.T) {
+	cfg := &ArgoCDConfig{Namespace: "argocd", ApplicationNamespaces: []string{"team-*", "shared-apps"}}
+	nsTests := []struct {
+		cfg  *ArgoCDConfig
+		ns   string
+		want bool
+	}{
+		{cfg, "argocd", true},
+		{cfg, "team-a", true},
+		{cfg, "shared-apps", true},
+		{cfg, "dev", false},
+		{&ArgoCDConfig{Namespace: "argocd"}, "team-a", false},
+		{nil, "argocd", false},
+	}
+	for _, tt := range nsTests {
+		t.Run(tt.ns, func(rt *testing.T) {
+			if got := tt.cfg.WatchesNamespace(tt.ns); got != tt.want {
+				rt.Errorf("WatchesNamespace(%q) got %v, want %v", tt.ns, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestGetEnvironment(t *testing.T) {
 	m := &Manifest{Environments: makeEnvs([]testEnv{{name: "prod"}, {name: "testing"}})}
 	env := m.GetEnvironment("prod")
@@ -202,6 +226,33 @@ func TestGetEnvironment(t *testing.T) {
 		t.Fatalf("found an unknown env: %#v", unknown)
 	}
 }
+
+func TestGetAndRemoveService(t *testing.T) {
+	m := &Manifest{
+		Environments: []*Environment{
+			{
+				Name: "dev",
+				Apps: []*Application{
+					{Name: "taxi", Services: []*Service{{Name: "gateway"}, {Name: "taxi"}}},
+				},
+			},
+		},
+	}
+	if svc := m.GetService("dev", "taxi", "gateway"); svc == nil || svc.Name != "gateway" {
+		t.Fatalf("got the wrong service back: %#v", svc)
+	}
+	for _, missing := range [][]string{{"prod", "taxi", "gateway"}, {"dev", "bus", "gateway"}, {"dev", "taxi", "unknown"}} {
+		if svc := m.GetService(missing[0], missing[1], missing[2]); svc != nil {
+			t.Fatalf("found an unknown service %v: %#v", missing, svc)
+		}
+	}
+
+	m.RemoveService("dev", "taxi", "gateway")
+	if diff := cmp.Diff([]*Service{{Name: "taxi"}}, m.GetApplication("dev", "taxi").Services); diff != "" {
+		t.Fatalf("services after removal didn't match:\n%s", diff)
+	}
+}
+
 func makeEnvs(ns []testEnv) []*Environment {
 	n := make([]*Environment, len(ns))
 	for i, v := range ns {
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+)
+
+// placeholder matches the ${NAME} placeholders in a manifest template.
+var placeholder = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Substitute replaces the ${NAME} placeholders in a manifest template with
+// the values, it returns an error listing the placeholders that have no value.
+func Substitute(template []byte, values map[string]string) ([]byte, error) {
+	missing := map[string]bool{}
+	out := placeholder.ReplaceAllFunc(template, func(match []byte) []byte {
+		name := string(placeholder.FindSubmatch(match)[1])
+		v, ok := values[name]
+		if !ok {
+			missing["${"+name+"}"] = true
+			return match
+		}
+		return []byte(v)
+	})
+	if len(missing) > 0 {
+		names := []string{}
+		for k := range missing {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("no values provided for the placeholders %s", strings.Join(names, ", "))
+	}
+	return out, nil
+}
+
+// LoadValues reads a YAML or JSON file of the values to substitute into a
+// manifest template e.g.
+//
+//	TEAM: team-a
+//	REPLICAS: 2
+//
+// The values must be scalars.
+func LoadValues(fs afero.Fs, path string) (map[string]string, error) {
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read values file %q: %w", path, err)
+	}
+	parsed := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse values file %q: %w", path, err)
+	}
+	values := map[string]string{}
+	for k, v := range parsed {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}, nil:
+			return nil, fmt.Errorf("failed to parse values file %q: the value of %s must be a string, number or boolean", path, k)
+		}
+		values[k] = fmt.Sprint(v)
+	}
+	return values, nil
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestSubstitute(t *testing.T) {
+	template := `environments:
+- name: ${TEAM}-${ENV}
+  pipelines:
+    integration:
+      template: $(params.template)
+`
+	got, err := Substitute([]byte(template), map[string]string{"TEAM": "team-a", "ENV": "dev", "UNUSED": "value"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `environments:
+- name: team-a-dev
+  pipelines:
+    integration:
+      template: $(params.template)
+`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Fatalf("substituted template didn't match:\n%s", diff)
+	}
+}
+
+func TestSubstituteWithMissingValues(t *testing.T) {
+	_, err := Substitute([]byte("name: ${TEAM}-${ENV}\nnamespace: ${TEAM}-${NAMESPACE}\n"), map[string]string{"ENV": "dev"})
+	test.AssertErrorMatch(t, `no values provided for the placeholders \$\{NAMESPACE\}, \$\{TEAM\}`, err)
+}
+
+func TestLoadValues(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	if err := afero.WriteFile(fs, "/values.yaml", []byte("TEAM: team-a\nREPLICAS: 2\nENABLED: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, "/nested.yaml", []byte("TEAM:\n  name: team-a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadValues(fs, "/values.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"TEAM": "team-a", "REPLICAS": "2", "ENABLED": "true"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("values didn't match:\n%s", diff)
+	}
+
+	_, err = LoadValues(fs, "/nested.yaml")
+	test.AssertErrorMatch(t, `failed to parse values file "/nested.yaml": the value of TEAM must be a string, number or boolean`, err)
+
+	_, err = LoadValues(fs, "/missing.yaml")
+	test.AssertErrorMatch(t, `failed to read values file "/missing.yaml"`, err)
+}
@@ -1,10 +1,13 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"path/filepath"
 
 	"github.com/jenkins-x/go-scm/scm/factory"
 	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
 )
 
 // LoadManifest reads a manifest file, and configures the environment based on
@@ -14,18 +17,63 @@ func LoadManifest(fs afero.Fs, path string) (*Manifest, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to load manifest: %w", err)
 	}
+	return configureManifest(m)
+}
+
+// LoadManifestTemplate is the same as LoadManifest, but the ${NAME}
+// placeholders in the manifest are substituted with the values before it is
+// parsed.
+func LoadManifestTemplate(fs afero.Fs, path string, values map[string]string) (*Manifest, error) {
+	template, err := afero.ReadFile(fs, filepath.Join(path, PipelinesFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifest: %w", err)
+	}
+	data, err := Substitute(template, values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifest: %w", err)
+	}
+	m, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifest: %w", err)
+	}
+	return configureManifest(m)
+}
+
+func configureManifest(m *Manifest) (*Manifest, error) {
 	if !(m.Config == nil || m.Config.Git == nil || m.Config.Git.Drivers == nil) {
-		drivers := []factory.MappingFunc{}
-		for k, v := range m.Config.Git.Drivers {
-			drivers = append(drivers, factory.Mapping(k, v))
-		}
-		if len(drivers) > 0 {
-			id := factory.NewDriverIdentifier(drivers...)
-			factory.DefaultIdentifier = id
-		}
+		SetDriverMappings(m.Config.Git.Drivers)
 	}
 	if err := m.Validate(); err != nil {
 		return nil, err
 	}
 	return m, nil
 }
+
+// LoadDriverMap reads a YAML or JSON file of host to driver mappings e.g.
+//
+//	github.example.com: github
+//	gitlab.example.com: gitlab
+func LoadDriverMap(fs afero.Fs, path string) (map[string]string, error) {
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read driver map file %q: %w", path, err)
+	}
+	drivers := map[string]string{}
+	if err := yaml.Unmarshal(data, &drivers); err != nil {
+		return nil, fmt.Errorf("failed to parse driver map file %q: %w", path, err)
+	}
+	return drivers, nil
+}
+
+// SetDriverMappings replaces the default driver identifier with one that
+// recognises the provided host to driver mappings.
+func SetDriverMappings(drivers map[string]string) {
+	if len(drivers) == 0 {
+		return
+	}
+	mappings := []factory.MappingFunc{}
+	for k, v := range drivers {
+		mappings = append(mappings, factory.Mapping(k, v))
+	}
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(mappings...)
+}
@@ -1,12 +1,14 @@
 package config
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jenkins-x/go-scm/scm/factory"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
 	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
+	"github.com/spf13/afero"
 )
 
 func TestLoadManifestUpdatesDrivers(t *testing.T) {
@@ -48,3 +50,65 @@ func TestLoadManifestUpdatesDrivers(t *testing.T) {
 		t.Fatalf("incorrectly identified driver, got %q, want %q", d, "github")
 	}
 }
+
+func TestLoadDriverMap(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	if err := afero.WriteFile(fs, "/drivers.yaml", []byte("github.example.com: github\ngitlab.example.com: gitlab\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, "/drivers.json", []byte(`{"github.example.com": "github"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path    string
+		want    map[string]string
+		wantErr string
+	}{
+		{"/drivers.yaml", map[string]string{"github.example.com": "github", "gitlab.example.com": "gitlab"}, ""},
+		{"/drivers.json", map[string]string{"github.example.com": "github"}, ""},
+		{"/missing.yaml", nil, `failed to read driver map file "/missing.yaml"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(rt *testing.T) {
+			got, err := LoadDriverMap(fs, tt.path)
+			if tt.wantErr == "" && err != nil {
+				rt.Fatal(err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.wantErr)) {
+				rt.Fatalf("error mismatch: got %v, want %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				rt.Fatalf("driver map mismatch:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoadManifestTemplate(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	template := `gitops_url: https://github.com/my-org/${TEAM}-gitops.git
+environments:
+- name: ${TEAM}-dev
+`
+	if err := afero.WriteFile(fs, "/manifest/pipelines.yaml", []byte(template), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := LoadManifestTemplate(fs, "/manifest", map[string]string{"TEAM": "team-a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Manifest{
+		GitOpsURL:    "https://github.com/my-org/team-a-gitops.git",
+		Environments: []*Environment{{Name: "team-a-dev"}},
+	}
+	if diff := cmp.Diff(want, m); diff != "" {
+		t.Fatalf("diff in loading manifest template:\n%s", diff)
+	}
+
+	_, err = LoadManifestTemplate(fs, "/manifest", map[string]string{})
+	if err == nil || !strings.Contains(err.Error(), "no values provided for the placeholders ${TEAM}") {
+		t.Fatalf("got error %v", err)
+	}
+}
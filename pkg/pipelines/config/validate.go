@@ -2,11 +2,18 @@ package config
 
 import (
 	"fmt"
+	"net/url"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/mkmik/multierror"
+	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
 	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+	"github.com/redhat-developer/kam/pkg/pipelines/tekton"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/api/validation"
 	"knative.dev/pkg/apis"
 )
@@ -16,6 +23,35 @@ const (
 	serviceNameLimit = 47
 )
 
+// ImageUpdateStrategies are the update strategies supported by the Argo CD
+// Image Updater.
+var ImageUpdateStrategies = []string{"semver", "latest", "digest", "name"}
+
+// ImageWriteBackMethods are the write-back methods supported by the Argo CD
+// Image Updater.
+var ImageWriteBackMethods = []string{"git", "argocd"}
+
+const (
+	// ApplyModeClientSide is the default apply mode, Argo CD applies the
+	// resources with client-side apply.
+	ApplyModeClientSide = "client-side"
+	// ApplyModeServerSide has Argo CD apply the resources with server-side
+	// apply.
+	ApplyModeServerSide = "server-side"
+)
+
+// ApplyModes are the supported modes that Argo CD applies the resources with.
+var ApplyModes = []string{ApplyModeClientSide, ApplyModeServerSide}
+
+// PRPreviewDrivers are the Git hosts whose pull requests can be previewed.
+var PRPreviewDrivers = []string{"github", "gitlab"}
+
+var (
+	imageTagRegexp      = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+	branchPatternRegexp = regexp.MustCompile(`^([\w-][\w.-]*/)*([\w-][\w.-]*\*?|\*)$`)
+	syncOptionRegexp    = regexp.MustCompile(`^\w+=\S+$`)
+)
+
 type validateVisitor struct {
 	errs         []error
 	envNames     map[string]bool
@@ -23,6 +59,7 @@ type validateVisitor struct {
 	serviceNames map[string]bool
 	serviceURLs  map[string][]string
 	configNames  map[string]bool
+	argoCD       *ArgoCDConfig
 }
 
 // Validate validates the Manifest, returning a multi-error representing all the
@@ -94,6 +131,26 @@ func (vv *validateVisitor) Environment(env *Environment) error {
 	if err := validatePipelines(env.Pipelines, envPath); err != nil {
 		vv.errs = append(vv.errs, err...)
 	}
+	if env.Cluster != "" && !IsValidClusterURL(env.Cluster) {
+		vv.errs = append(vv.errs, invalidClusterError(env.Cluster, []string{yamlJoin(envPath, "cluster")}))
+	}
+	if err := validateBranches(env.Branches, envPath); err != nil {
+		vv.errs = append(vv.errs, err...)
+	}
+	if err := validateSyncOptions(env.SyncOptions, envPath); err != nil {
+		vv.errs = append(vv.errs, err...)
+	}
+	if err := validateIgnoreDifferences(env.IgnoreDifferences, envPath); err != nil {
+		vv.errs = append(vv.errs, err...)
+	}
+	if ns := env.ArgoCDAppNamespace; ns != "" {
+		nsPath := yamlJoin(envPath, "argocd_app_namespace")
+		if err := validateName(ns, nsPath); err != nil {
+			vv.errs = append(vv.errs, err)
+		} else if !vv.argoCD.WatchesNamespace(ns) {
+			vv.errs = append(vv.errs, unwatchedNamespaceError(ns, []string{nsPath}))
+		}
+	}
 	return nil
 }
 
@@ -154,10 +211,59 @@ func (vv *validateVisitor) Service(app *Application, env *Environment, svc *Serv
 	if err := validatePipelines(svc.Pipelines, svcPath); err != nil {
 		vv.errs = append(vv.errs, err...)
 	}
+	if err := validateImage(svc.Image, svcPath); err != nil {
+		vv.errs = append(vv.errs, err...)
+	}
+	if err := validateSyncOptions(svc.SyncOptions, svcPath); err != nil {
+		vv.errs = append(vv.errs, err...)
+	}
+	if err := validateIgnoreDifferences(svc.IgnoreDifferences, svcPath); err != nil {
+		vv.errs = append(vv.errs, err...)
+	}
+	if svc.ImageUpdate != nil && svc.ImageUpdate.Repository == "" {
+		vv.errs = append(vv.errs, missingFieldsError([]string{"repository"}, []string{yamlJoin(svcPath, "image_update")}))
+	}
+	if err := validatePRPreviews(svc, yamlJoin(svcPath, "pr_previews")); err != nil {
+		vv.errs = append(vv.errs, err...)
+	}
 	vv.serviceNames[svc.Name] = true
 	return nil
 }
 
+func validatePRPreviews(svc *Service, path string) []error {
+	previews := svc.PRPreviews
+	if previews == nil {
+		return nil
+	}
+	missingFields := []string{}
+	if previews.Repository == "" {
+		missingFields = append(missingFields, "repository")
+	}
+	if previews.ImageName == "" {
+		missingFields = append(missingFields, "image_name")
+	}
+	if previews.TokenSecret == "" {
+		missingFields = append(missingFields, "token_secret")
+	}
+	errs := []error{}
+	if len(missingFields) > 0 {
+		errs = append(errs, missingFieldsError(missingFields, []string{path}))
+	} else if err := validateName(previews.TokenSecret, yamlJoin(path, "token_secret")); err != nil {
+		errs = append(errs, err)
+	}
+	if svc.SourceURL == "" {
+		return append(errs, previewWithoutSourceError([]string{path}))
+	}
+	driver, err := scm.GetDriverName(svc.SourceURL)
+	if err != nil {
+		return append(errs, err)
+	}
+	if !contains(PRPreviewDrivers, driver) {
+		errs = append(errs, unsupportedValueError("pull request preview driver", driver, PRPreviewDrivers, []string{path}))
+	}
+	return errs
+}
+
 func validateConfigRepo(repo *Repository, path string) []error {
 	missingFields := []string{}
 	errs := []error{}
@@ -190,6 +296,118 @@ func validateWebhook(hook *Webhook, path string) []error {
 	return errs
 }
 
+func validateImage(image *Image, path string) []error {
+	if image == nil {
+		return nil
+	}
+	missingFields := []string{}
+	if image.Name == "" {
+		missingFields = append(missingFields, "name")
+	}
+	if image.Tag == "" {
+		missingFields = append(missingFields, "tag")
+	}
+	if len(missingFields) > 0 {
+		return list(missingFieldsError(missingFields, []string{yamlJoin(path, "image")}))
+	}
+	if !imageTagRegexp.MatchString(image.Tag) {
+		return list(invalidImageTagError(image.Tag, []string{yamlJoin(path, "image", "tag")}))
+	}
+	return nil
+}
+
+func validateImageUpdater(cfg *ImageUpdaterConfig, path string) []error {
+	errs := []error{}
+	if cfg == nil {
+		return nil
+	}
+	if s := cfg.UpdateStrategy; s != "" && !IsSupportedImageUpdateStrategy(s) {
+		errs = append(errs, unsupportedValueError("update strategy", s, ImageUpdateStrategies, []string{yamlJoin(path, "update_strategy")}))
+	}
+	if m := cfg.WriteBackMethod; m != "" && !IsSupportedImageWriteBackMethod(m) {
+		errs = append(errs, unsupportedValueError("write-back method", m, ImageWriteBackMethods, []string{yamlJoin(path, "write_back_method")}))
+	}
+	return errs
+}
+
+func validateResources(r *Resources, path string) []error {
+	if r == nil {
+		return nil
+	}
+	errs := []error{}
+	quantities := map[string]resource.Quantity{}
+	for _, q := range []struct {
+		field string
+		value string
+	}{
+		{"cpu_request", r.CPURequest},
+		{"cpu_limit", r.CPULimit},
+		{"memory_request", r.MemoryRequest},
+		{"memory_limit", r.MemoryLimit},
+	} {
+		if q.value == "" {
+			continue
+		}
+		parsed, err := resource.ParseQuantity(q.value)
+		if err != nil {
+			errs = append(errs, invalidQuantityError(q.value, err.Error(), []string{yamlJoin(path, q.field)}))
+			continue
+		}
+		quantities[q.field] = parsed
+	}
+	for _, name := range []string{"cpu", "memory"} {
+		request, hasRequest := quantities[name+"_request"]
+		limit, hasLimit := quantities[name+"_limit"]
+		if hasRequest && hasLimit && request.Cmp(limit) > 0 {
+			errs = append(errs, requestExceedsLimitError(name, []string{yamlJoin(path, name+"_request"), yamlJoin(path, name+"_limit")}))
+		}
+	}
+	return errs
+}
+
+func validateBranches(branches []string, path string) []error {
+	errs := []error{}
+	for i, branch := range branches {
+		if !branchPatternRegexp.MatchString(branch) || strings.Contains(branch, "..") {
+			errs = append(errs, invalidBranchError(branch, []string{yamlJoin(path, fmt.Sprintf("branches[%d]", i))}))
+		}
+	}
+	return errs
+}
+
+func validateSyncOptions(options []string, path string) []error {
+	errs := []error{}
+	for i, option := range options {
+		if !syncOptionRegexp.MatchString(option) {
+			errs = append(errs, invalidSyncOptionError(option, []string{yamlJoin(path, fmt.Sprintf("sync_options[%d]", i))}))
+		}
+	}
+	return errs
+}
+
+func validateIgnoreDifferences(differences []*IgnoreDifference, path string) []error {
+	errs := []error{}
+	for i, d := range differences {
+		diffPath := yamlJoin(path, fmt.Sprintf("ignore_differences[%d]", i))
+		missing := []string{}
+		if d.Kind == "" {
+			missing = append(missing, "kind")
+		}
+		if len(d.JSONPointers) == 0 {
+			missing = append(missing, "json_pointers")
+		}
+		if len(missing) > 0 {
+			errs = append(errs, missingFieldsError(missing, []string{diffPath}))
+		}
+		for j, pointer := range d.JSONPointers {
+			if !strings.HasPrefix(pointer, "/") {
+				errs = append(errs, invalidJSONPointerError(pointer, []string{yamlJoin(diffPath, fmt.Sprintf("json_pointers[%d]", j))}))
+			}
+		}
+	}
+	return errs
+}
+
 func validatePipelines(pipelines *Pipelines, path string) []error {
 	errs := []error{}
 	if pipelines == nil {
@@ -207,23 +425,133 @@ func validatePipelines(pipelines *Pipelines, path string) []error {
 }
 func (vv *validateVisitor) validateConfig(manifest *Manifest) []error {
 	errs := []error{}
+	if manifest.RepoSubpath != "" && !IsValidRepoSubpath(manifest.RepoSubpath) {
+		errs = append(errs, invalidRepoSubpathError(manifest.RepoSubpath, []string{"repo_subpath"}))
+	}
 	if manifest.Config != nil {
 		if manifest.Config.ArgoCD != nil {
+			vv.argoCD = manifest.Config.ArgoCD
 			if err := validateName(manifest.Config.ArgoCD.Namespace, yamlPath(PathForArgoCD())); err != nil {
 				errs = append(errs, err)
 			}
 			vv.configNames[manifest.Config.ArgoCD.Namespace] = true
+			errs = append(errs, validateImageUpdater(manifest.Config.ArgoCD.ImageUpdater, "config.argocd.image_updater")...)
+			if m := manifest.Config.ArgoCD.ApplyMode; m != "" && !IsSupportedApplyMode(m) {
+				errs = append(errs, unsupportedValueError("apply mode", m, ApplyModes, []string{"config.argocd.apply_mode"}))
+			}
+			if p := manifest.Config.ArgoCD.Project; p != "" && !IsValidArgoCDProject(p) {
+				errs = append(errs, invalidArgoCDProjectError(p, []string{"config.argocd.project"}))
+			}
 		}
 		if manifest.Config.Pipelines != nil {
 			if err := validateName(manifest.Config.Pipelines.Name, yamlPath(PathForPipelines(manifest.Config.Pipelines))); err != nil {
 				errs = append(errs, err)
 			}
 			vv.configNames[manifest.Config.Pipelines.Name] = true
+			if p := manifest.Config.Pipelines.PipelineNamePrefix; p != "" && !IsValidPipelineNamePrefix(p) {
+				errs = append(errs, invalidPipelineNamePrefixError(p, []string{"config.pipelines.pipeline_name_prefix"}))
+			}
+			if u := manifest.Config.Pipelines.WebhookInterceptorURL; u != "" {
+				if _, err := eventlisteners.WebhookInterceptor(u); err != nil {
+					errs = append(errs, invalidWebhookInterceptorURLError(u, []string{"config.pipelines.webhook_interceptor_url"}))
+				}
+			}
+			if b := manifest.Config.Pipelines.DefaultBranch; b != "" && !IsValidBranch(b) {
+				errs = append(errs, invalidDefaultBranchError(b, []string{"config.pipelines.default_branch"}))
+			}
+			if sa := manifest.Config.Pipelines.ServiceAccountName; sa != "" && !IsValidServiceAccountName(sa) {
+				errs = append(errs, invalidServiceAccountNameError(sa, []string{"config.pipelines.service_account_name"}))
+			}
+			errs = append(errs, validateResources(manifest.Config.Pipelines.EventListenerResources, "config.pipelines.event_listener_resources")...)
+			if t := manifest.Config.Pipelines.DryRunTrigger; t != "" && !eventlisteners.IsSupportedDryRunTrigger(t) {
+				errs = append(errs, unsupportedValueError("dry-run trigger", t, eventlisteners.DryRunTriggers, []string{"config.pipelines.dry_run_trigger"}))
+			}
+			if v := manifest.Config.Pipelines.TektonAPIVersion; v != "" && !tekton.IsSupportedAPIVersion(v) {
+				errs = append(errs, unsupportedValueError("Tekton API version", v, tekton.SupportedAPIVersions, []string{"config.pipelines.tekton_api_version"}))
+			}
+			if ttl := manifest.Config.Pipelines.PipelineRunTTL; ttl != "" && !IsValidPipelineRunTTL(ttl) {
+				errs = append(errs, invalidPipelineRunTTLError(ttl, []string{"config.pipelines.pipelinerun_ttl"}))
+			}
+			if p := manifest.Config.Pipelines.RouteWildcardPolicy; p != "" && !eventlisteners.IsSupportedWildcardPolicy(p) {
+				errs = append(errs, unsupportedValueError("route wildcard policy", p, eventlisteners.WildcardPolicies, []string{"config.pipelines.route_wildcard_policy"}))
+			}
+			if d := manifest.Config.Pipelines.RouteSubdomain; d != "" && len(validation.NameIsDNSSubdomain(d, false)) > 0 {
+				errs = append(errs, invalidRouteSubdomainError(d, []string{"config.pipelines.route_subdomain"}))
+			}
+		}
+		if manifest.Config.SecretsRepo != nil {
+			errs = append(errs, validateConfigRepo(manifest.Config.SecretsRepo, "config.secrets_repo")...)
 		}
 	}
 	return errs
 }
 
+// IsValidRepoSubpath returns true if the subpath is a clean, relative,
+// slash-separated path that is within the repository.
+func IsValidRepoSubpath(subpath string) bool {
+	return !path.IsAbs(subpath) && path.Clean(subpath) == subpath &&
+		subpath != "." && subpath != ".." && !strings.HasPrefix(subpath, "../")
+}
+
+// IsSupportedImageUpdateStrategy returns true if the Argo CD Image Updater
+// supports the update strategy.
+func IsSupportedImageUpdateStrategy(strategy string) bool {
+	return contains(ImageUpdateStrategies, strategy)
+}
+
+// IsSupportedImageWriteBackMethod returns true if the Argo CD Image Updater
+// supports the write-back method.
+func IsSupportedImageWriteBackMethod(method string) bool {
+	return contains(ImageWriteBackMethods, method)
+}
+
+// IsSupportedApplyMode returns true if Argo CD can apply the resources with
+// the apply mode.
+func IsSupportedApplyMode(mode string) bool {
+	return contains(ApplyModes, mode)
+}
+
+// IsValidPipelineNamePrefix returns true if the prefix can be prefixed to
+// the names of resources.
+func IsValidPipelineNamePrefix(prefix string) bool {
+	return len(validation.NameIsDNSSubdomain(prefix+"pipeline", false)) == 0
+}
+
+// IsValidClusterURL returns true if the URL can be the destination server of
+// the Argo CD applications e.g. https://api.cluster.example.com:6443.
+func IsValidClusterURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
+
+// IsValidBranch returns true if the branch is a valid Git branch name,
+// without the trailing "*" that the environment branches can have.
+func IsValidBranch(branch string) bool {
+	return !strings.Contains(branch, "*") && len(validateBranches([]string{branch}, "")) == 0
+}
+
+// IsValidArgoCDProject returns true if the name can be the name of an
+// AppProject other than the default project.
+func IsValidArgoCDProject(name string) bool {
+	return name != "default" && len(validation.NameIsDNSSubdomain(name, false)) == 0
+}
+
+// IsValidPipelineRunTTL returns true if the TTL is a duration of at least a
+// second, the Tekton pruner's TTLs are in seconds.
+func IsValidPipelineRunTTL(ttl string) bool {
+	d, err := time.ParseDuration(ttl)
+	return err == nil && d >= time.Second
+}
+
+// IsValidServiceAccountName returns true if the name can be the name of a
+// ServiceAccount.
+func IsValidServiceAccountName(name string) bool {
+	return len(validation.ValidateServiceAccountName(name, false)) == 0
+}
+
 func validateName(name, path string) *apis.FieldError {
 	err := validation.NameIsDNS1035Label(name, true)
 	if len(err) > 0 {
@@ -247,6 +575,15 @@ func list(errs ...error) []error {
 	return errs
 }
 
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 func invalidEnvironment(name, details string, paths []string) *apis.FieldError {
 	return &apis.FieldError{
 		Message: fmt.Sprintf("invalid environment %q", name),
@@ -277,6 +614,148 @@ func duplicateFieldsError(fields, paths []string) *apis.FieldError {
 	}
 }
 
+func invalidImageTagError(tag string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid image tag %q", tag),
+		Details: "image tags must be valid ASCII and may contain letters, digits, underscores, periods and dashes",
+		Paths:   paths,
+	}
+}
+
+func invalidBranchError(branch string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid branch pattern %q", branch),
+		Details: "branch patterns may contain letters, digits, underscores, periods, dashes and slashes, with an optional trailing \"*\"",
+		Paths:   paths,
+	}
+}
+
+func invalidDefaultBranchError(branch string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid default branch %q", branch),
+		Details: "branch names may contain letters, digits, underscores, periods, dashes and slashes",
+		Paths:   paths,
+	}
+}
+
+func invalidRepoSubpathError(subpath string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid repository subpath %q", subpath),
+		Details: "the subpath must be a relative path within the repository",
+		Paths:   paths,
+	}
+}
+
+func invalidSyncOptionError(option string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid sync option %q", option),
+		Details: "sync options must be of the form Name=value e.g. ServerSideApply=true",
+		Paths:   paths,
+	}
+}
+
+func invalidJSONPointerError(pointer string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid JSON pointer %q", pointer),
+		Details: "JSON pointers must start with / e.g. /spec/replicas",
+		Paths:   paths,
+	}
+}
+
+func unsupportedValueError(field, value string, supported, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("unsupported %s %q", field, value),
+		Details: fmt.Sprintf("must be one of %s", strings.Join(supported, ", ")),
+		Paths:   paths,
+	}
+}
+
+func invalidPipelineNamePrefixError(prefix string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid pipeline name prefix %q", prefix),
+		Details: "the prefix may only contain lowercase letters, digits, periods and dashes, and must start with a letter or digit",
+		Paths:   paths,
+	}
+}
+
+func invalidWebhookInterceptorURLError(url string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid webhook interceptor URL %q", url),
+		Details: "the URL must be of the form http://<service>.<namespace>.svc",
+		Paths:   paths,
+	}
+}
+
+func invalidServiceAccountNameError(name string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid service account name %q", name),
+		Details: "the name may only contain lowercase letters, digits, periods and dashes, and must start and end with a letter or digit",
+		Paths:   paths,
+	}
+}
+
+func invalidPipelineRunTTLError(ttl string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid PipelineRun TTL %q", ttl),
+		Details: "the TTL must be a duration of at least 1s e.g. 24h",
+		Paths:   paths,
+	}
+}
+
+func invalidRouteSubdomainError(subdomain string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid route subdomain %q", subdomain),
+		Details: "the subdomain may only contain lowercase letters, digits, periods and dashes, and must start and end with a letter or digit",
+		Paths:   paths,
+	}
+}
+
+func invalidArgoCDProjectError(project string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid Argo CD project %q", project),
+		Details: "the project may only contain lowercase letters, digits, periods and dashes, must start and end with a letter or digit, and can't be the default project",
+		Paths:   paths,
+	}
+}
+
+func invalidClusterError(cluster string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid cluster %q", cluster),
+		Details: "the cluster must be the URL of the cluster's API server e.g. https://api.cluster.example.com:6443",
+		Paths:   paths,
+	}
+}
+
+func invalidQuantityError(quantity, details string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid quantity %q", quantity),
+		Details: details,
+		Paths:   paths,
+	}
+}
+
+func requestExceedsLimitError(name string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("the %s request is greater than the %s limit", name, name),
+		Paths:   paths,
+	}
+}
+
+func unwatchedNamespaceError(ns string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("namespace %q is not watched by Argo CD", ns),
+		Details: "the namespace must be the Argo CD namespace, or match one of config.argocd.application_namespaces",
+		Paths:   paths,
+	}
+}
+
+func previewWithoutSourceError(paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: "pull requests can't be previewed for a service without a source_url",
+		Paths:   paths,
+	}
+}
+
 func missingServiceError(app string, paths []string) *apis.FieldError {
 	return &apis.FieldError{
 		Message: fmt.Sprintf("missing service app %q", app),
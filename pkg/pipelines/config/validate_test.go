@@ -6,7 +6,10 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/mkmik/multierror"
+	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/tekton"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"knative.dev/pkg/apis"
 )
 
@@ -70,6 +73,146 @@ var validateTests = []struct {
 			missingFieldsError([]string{"integration"}, []string{"environments.development.apps.app-1.services.service-1.pipelines"}),
 		}),
 	},
+	{
+		"Invalid service image error",
+		"testdata/image_error.yaml",
+		multierror.Join([]error{
+			missingFieldsError([]string{"name"}, []string{"environments.development.apps.app-1.services.service-1.image"}),
+			invalidImageTagError("-v1.0.0", []string{"environments.development.apps.app-1.services.service-2.image.tag"}),
+		}),
+	},
+	{
+		"Invalid environment branch patterns",
+		"testdata/branches_error.yaml",
+		multierror.Join([]error{
+			invalidBranchError("feature/*/test", []string{"environments.development.branches[2]"}),
+			invalidBranchError("release..1", []string{"environments.production.branches[1]"}),
+			invalidBranchError("main'", []string{"environments.production.branches[2]"}),
+		}),
+	},
+	{
+		"Invalid cluster",
+		"testdata/cluster_error.yaml",
+		multierror.Join([]error{
+			invalidClusterError("testing.cluster", []string{"environments.development.cluster"}),
+			invalidClusterError("ftp://api.cluster.example.com", []string{"environments.production.cluster"}),
+		}),
+	},
+	{
+		"Invalid ignore differences",
+		"testdata/ignore_differences_error.yaml",
+		multierror.Join([]error{
+			invalidJSONPointerError("spec/clusterIP", []string{"environments.development.apps.app-1.services.service-1.ignore_differences[0].json_pointers[0]"}),
+			missingFieldsError([]string{"kind", "json_pointers"}, []string{"environments.development.ignore_differences[1]"}),
+		}),
+	},
+	{
+		"Invalid sync options",
+		"testdata/sync_options_error.yaml",
+		multierror.Join([]error{
+			invalidSyncOptionError("Replace = true", []string{"environments.development.apps.app-1.services.service-1.sync_options[1]"}),
+			invalidSyncOptionError("ServerSideApply", []string{"environments.development.sync_options[1]"}),
+		}),
+	},
+	{
+		"Invalid repository subpath",
+		"testdata/repo_subpath_error.yaml",
+		multierror.Join([]error{
+			invalidRepoSubpathError("../platform/gitops", []string{"repo_subpath"}),
+		}),
+	},
+	{
+		"Invalid image updater configuration",
+		"testdata/image_updater_error.yaml",
+		multierror.Join([]error{
+			unsupportedValueError("update strategy", "newest", ImageUpdateStrategies, []string{"config.argocd.image_updater.update_strategy"}),
+			unsupportedValueError("write-back method", "helm", ImageWriteBackMethods, []string{"config.argocd.image_updater.write_back_method"}),
+			missingFieldsError([]string{"repository"}, []string{"environments.development.apps.my-app-1.services.service-http.image_update"}),
+		}),
+	},
+	{
+		"Invalid pull request previews",
+		"testdata/pr_previews_error.yaml",
+		multierror.Join([]error{
+			missingFieldsError([]string{"repository", "token_secret"}, []string{"environments.development.apps.my-app-1.services.service-http.pr_previews"}),
+			previewWithoutSourceError([]string{"environments.development.apps.my-app-1.services.service-http.pr_previews"}),
+			invalidNameError("Bitbucket_Token", DNS1035Error, []string{"environments.development.apps.my-app-1.services.service-api.pr_previews.token_secret"}),
+		}),
+	},
+	{
+		"Invalid apply mode",
+		"testdata/apply_mode_error.yaml",
+		multierror.Join([]error{
+			unsupportedValueError("apply mode", "replace", ApplyModes, []string{"config.argocd.apply_mode"}),
+		}),
+	},
+	{
+		"Argo CD app namespace not watched",
+		"testdata/argocd_app_namespace_error.yaml",
+		multierror.Join([]error{
+			unwatchedNamespaceError("production-apps", []string{"environments.production.argocd_app_namespace"}),
+		}),
+	},
+	{
+		"Invalid pipeline name prefix",
+		"testdata/pipeline_name_prefix_error.yaml",
+		multierror.Join([]error{
+			invalidPipelineNamePrefixError("Team_A-", []string{"config.pipelines.pipeline_name_prefix"}),
+		}),
+	},
+	{
+		"Invalid Argo CD project",
+		"testdata/argocd_project_error.yaml",
+		multierror.Join([]error{
+			invalidArgoCDProjectError("default", []string{"config.argocd.project"}),
+		}),
+	},
+	{
+		"Invalid default branch",
+		"testdata/default_branch_error.yaml",
+		multierror.Join([]error{
+			invalidDefaultBranchError("release/*", []string{"config.pipelines.default_branch"}),
+		}),
+	},
+	{
+		"Invalid service account name",
+		"testdata/service_account_name_error.yaml",
+		multierror.Join([]error{
+			invalidServiceAccountNameError("CI_Bot", []string{"config.pipelines.service_account_name"}),
+		}),
+	},
+	{
+		"Invalid webhook interceptor URL",
+		"testdata/webhook_interceptor_url_error.yaml",
+		multierror.Join([]error{
+			invalidWebhookInterceptorURLError("https://audit.example.com/events", []string{"config.pipelines.webhook_interceptor_url"}),
+		}),
+	},
+	{
+		"Invalid event listener resources",
+		"testdata/event_listener_resources_error.yaml",
+		multierror.Join([]error{
+			invalidQuantityError("lots", resource.ErrFormatWrong.Error(), []string{"config.pipelines.event_listener_resources.memory_request"}),
+			requestExceedsLimitError("cpu", []string{"config.pipelines.event_listener_resources.cpu_request", "config.pipelines.event_listener_resources.cpu_limit"}),
+		}),
+	},
+	{
+		"Invalid dry-run trigger",
+		"testdata/dry_run_trigger_error.yaml",
+		multierror.Join([]error{
+			unsupportedValueError("dry-run trigger", "merge", eventlisteners.DryRunTriggers, []string{"config.pipelines.dry_run_trigger"}),
+		}),
+	},
+	{
+		"Invalid EventListener template and route options",
+		"testdata/event_listener_options_error.yaml",
+		multierror.Join([]error{
+			unsupportedValueError("Tekton API version", "v1alpha1", tekton.SupportedAPIVersions, []string{"config.pipelines.tekton_api_version"}),
+			invalidPipelineRunTTLError("10ms", []string{"config.pipelines.pipelinerun_ttl"}),
+			unsupportedValueError("route wildcard policy", "All", eventlisteners.WildcardPolicies, []string{"config.pipelines.route_wildcard_policy"}),
+			invalidRouteSubdomainError("Webhooks", []string{"config.pipelines.route_subdomain"}),
+		}),
+	},
 	{
 		"Missing service and config repo from application",
 		"testdata/missing_service_error.yaml",
@@ -80,6 +223,13 @@ var validateTests = []struct {
 			apis.ErrMultipleOneOf("environments.development.apps.app-5.services", "environments.development.apps.app-5.config_repo"),
 		}),
 	},
+	{
+		"Missing path from secrets repo",
+		"testdata/secrets_repo_error.yaml",
+		multierror.Join([]error{
+			missingFieldsError([]string{"path"}, []string{"config.secrets_repo"}),
+		}),
+	},
 	{
 		"duplicate environment name error",
 		"testdata/duplicate_environment.yaml",
@@ -168,3 +318,23 @@ func matchMultiErrors(t *testing.T, a, b error) error {
 	}
 	return nil
 }
+
+func TestIsValidRepoSubpath(t *testing.T) {
+	subpathTests := []struct {
+		subpath string
+		want    bool
+	}{
+		{"gitops", true},
+		{"platform/gitops", true},
+		{"/platform/gitops", false},
+		{"platform/../gitops", false},
+		{"platform/gitops/", false},
+		{"../gitops", false},
+		{".", false},
+	}
+	for _, tt := range subpathTests {
+		if got := IsValidRepoSubpath(tt.subpath); got != tt.want {
+			t.Errorf("IsValidRepoSubpath(%q) got %v, want %v", tt.subpath, got, tt.want)
+		}
+	}
+}
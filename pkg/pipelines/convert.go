@@ -0,0 +1,128 @@
+package pipelines
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
+)
+
+// ConvertParameters encapsulates the parameters for converting an existing
+// kustomize repository to a manifest.
+type ConvertParameters struct {
+	InputPath     string // The root of the existing kustomize repository.
+	OutputPath    string // Where to write the pipelines.yaml.
+	GitOpsRepoURL string // The URL of the existing repository, if known.
+	Overwrite     bool   // If true, an existing pipelines.yaml is replaced.
+}
+
+// Convert scans a kustomize repository laid out in environments, apps and
+// services, and writes a best-effort manifest describing it.
+//
+// The returned warnings describe the configuration that couldn't be inferred
+// from the repository.
+func Convert(o *ConvertParameters, appFs afero.Fs) ([]string, error) {
+	outputFile := filepath.Join(o.OutputPath, pipelinesFile)
+	if exists, _ := afero.Exists(appFs, outputFile); exists && !o.Overwrite {
+		return nil, fmt.Errorf("%s already exists. If you want to replace it, please rerun with --overwrite", outputFile)
+	}
+	m, warnings, err := manifestFromRepository(appFs, o.InputPath)
+	if err != nil {
+		return nil, err
+	}
+	m.GitOpsURL = o.GitOpsRepoURL
+	if m.GitOpsURL == "" {
+		warnings = append(warnings, "the GitOps repository URL could not be inferred, set gitops_url in the manifest")
+	}
+	if err := m.Validate(); err != nil {
+		warnings = append(warnings, fmt.Sprintf("the generated manifest is not valid: %s", err))
+	}
+	_, err = yaml.WriteResources(appFs, o.OutputPath, res.Resources{pipelinesFile: m})
+	return warnings, err
+}
+
+func manifestFromRepository(appFs afero.Fs, root string) (*config.Manifest, []string, error) {
+	warnings := []string{}
+	m := &config.Manifest{Version: version}
+
+	configDirs, err := listDirs(appFs, filepath.Join(root, "config"))
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, name := range configDirs {
+		if m.Config == nil {
+			m.Config = &config.Config{}
+		}
+		if name == filepath.Base(config.PathForArgoCD()) {
+			m.Config.ArgoCD = &config.ArgoCDConfig{Namespace: argocd.ArgoCDNamespace}
+			continue
+		}
+		if m.Config.Pipelines != nil {
+			warnings = append(warnings, fmt.Sprintf("ignoring config/%s, the CI/CD configuration is already config/%s", name, m.Config.Pipelines.Name))
+			continue
+		}
+		m.Config.Pipelines = &config.PipelinesConfig{Name: name}
+	}
+
+	envsPath := filepath.Join(root, "environments")
+	envNames, err := listDirs(appFs, envsPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(envNames) == 0 {
+		return nil, nil, fmt.Errorf("no environments found in %s", envsPath)
+	}
+	for _, envName := range envNames {
+		env := &config.Environment{Name: envName}
+		appsPath := filepath.Join(envsPath, envName, "apps")
+		appNames, err := listDirs(appFs, appsPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, appName := range appNames {
+			app := &config.Application{Name: appName}
+			svcNames, err := listDirs(appFs, filepath.Join(appsPath, appName, "services"))
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(svcNames) == 0 {
+				warnings = append(warnings, fmt.Sprintf("no services found for app %s in environment %s, the app is treated as a single service", appName, envName))
+				svcNames = []string{appName}
+			}
+			for _, svcName := range svcNames {
+				app.Services = append(app.Services, &config.Service{Name: svcName})
+				warnings = append(warnings, fmt.Sprintf("the source_url, webhook and image repository for service %s in environment %s could not be inferred", svcName, envName))
+			}
+			env.Apps = append(env.Apps, app)
+		}
+		m.Environments = append(m.Environments, env)
+	}
+	return m, warnings, nil
+}
+
+// listDirs returns the sorted names of the directories in path, or nothing if
+// path doesn't exist.
+func listDirs(appFs afero.Fs, path string) ([]string, error) {
+	infos, err := afero.ReadDir(appFs, path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	names := []string{}
+	for _, info := range infos {
+		if info.IsDir() {
+			names = append(names, info.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,101 @@
+package pipelines
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestConvert(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	inputPath := afero.GetTempDir(fakeFs, "repo")
+	outputPath := afero.GetTempDir(fakeFs, "out")
+	for _, dir := range []string{
+		"config/argocd",
+		"config/tst-cicd/base",
+		"environments/tst-dev/env/base",
+		"environments/tst-dev/apps/app-taxi/services/taxi/base",
+		"environments/tst-dev/apps/app-taxi/services/gateway/base",
+		"environments/tst-stage/apps/app-taxi/base",
+	} {
+		fatalIfError(t, fakeFs.MkdirAll(filepath.Join(inputPath, dir), 0755))
+	}
+
+	warnings, err := Convert(&ConvertParameters{
+		InputPath:     inputPath,
+		OutputPath:    outputPath,
+		GitOpsRepoURL: testGitOpsRepo,
+	}, fakeFs)
+	fatalIfError(t, err)
+
+	got, err := config.ParseFile(fakeFs, filepath.Join(outputPath, pipelinesFile))
+	fatalIfError(t, err)
+	want := &config.Manifest{
+		Version:   version,
+		GitOpsURL: testGitOpsRepo,
+		Config: &config.Config{
+			ArgoCD:    &config.ArgoCDConfig{Namespace: argocd.ArgoCDNamespace},
+			Pipelines: &config.PipelinesConfig{Name: "tst-cicd"},
+		},
+		Environments: []*config.Environment{
+			{
+				Name: "tst-dev",
+				Apps: []*config.Application{
+					{
+						Name: "app-taxi",
+						Services: []*config.Service{
+							{Name: "gateway"},
+							{Name: "taxi"},
+						},
+					},
+				},
+			},
+			{
+				Name: "tst-stage",
+				Apps: []*config.Application{
+					{
+						Name:     "app-taxi",
+						Services: []*config.Service{{Name: "app-taxi"}},
+					},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("converted manifest didn't match:\n%s", diff)
+	}
+	wantWarnings := []string{
+		"the source_url, webhook and image repository for service gateway in environment tst-dev could not be inferred",
+		"the source_url, webhook and image repository for service taxi in environment tst-dev could not be inferred",
+		"no services found for app app-taxi in environment tst-stage, the app is treated as a single service",
+		"the source_url, webhook and image repository for service app-taxi in environment tst-stage could not be inferred",
+	}
+	if diff := cmp.Diff(wantWarnings, warnings); diff != "" {
+		t.Fatalf("warnings didn't match:\n%s", diff)
+	}
+}
+
+func TestConvertWithExistingManifest(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	inputPath := afero.GetTempDir(fakeFs, "repo")
+	fatalIfError(t, fakeFs.MkdirAll(filepath.Join(inputPath, "environments/tst-dev"), 0755))
+	fatalIfError(t, afero.WriteFile(fakeFs, filepath.Join(inputPath, pipelinesFile), []byte("environments: []\n"), 0644))
+
+	_, err := Convert(&ConvertParameters{InputPath: inputPath, OutputPath: inputPath}, fakeFs)
+	test.AssertErrorMatch(t, "pipelines.yaml already exists", err)
+}
+
+func TestConvertWithNoEnvironments(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	inputPath := afero.GetTempDir(fakeFs, "repo")
+
+	_, err := Convert(&ConvertParameters{InputPath: inputPath, OutputPath: inputPath}, fakeFs)
+	test.AssertErrorMatch(t, "no environments found in .*/environments", err)
+}
@@ -0,0 +1,52 @@
+package pipelines
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+// BootstrappedPaths returns the folders that DeleteBootstrapped removes for
+// the GitOps configuration at path, the path itself and the secrets folder
+// that is a sibling of it, if it exists.
+//
+// The path must contain a pipelines.yaml, so that folders that weren't
+// bootstrapped aren't removed.
+func BootstrappedPaths(appFs afero.Fs, path string) ([]string, error) {
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path to the GitOps configuration: %w", err)
+	}
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path to the GitOps configuration: %w", err)
+	}
+	if exists, _ := ioutils.IsExisting(appFs, filepath.Join(path, pipelinesFile)); !exists {
+		return nil, fmt.Errorf("no %s found in %s, refusing to delete a folder that wasn't bootstrapped", pipelinesFile, path)
+	}
+	paths := []string{path}
+	secretsPath := filepath.Join(path, "..", "secrets")
+	if exists, _ := ioutils.IsExisting(appFs, secretsPath); exists {
+		paths = append(paths, secretsPath)
+	}
+	return paths, nil
+}
+
+// DeleteBootstrapped removes the GitOps configuration at path, and the
+// secrets folder that is a sibling of it, and returns the removed folders.
+func DeleteBootstrapped(appFs afero.Fs, path string) ([]string, error) {
+	paths, err := BootstrappedPaths(appFs, path)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range paths {
+		if err := appFs.RemoveAll(p); err != nil {
+			return nil, fmt.Errorf("failed to remove %s: %w", p, err)
+		}
+	}
+	return paths, nil
+}
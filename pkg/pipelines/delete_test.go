@@ -0,0 +1,70 @@
+package pipelines
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestDeleteBootstrapped(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/work/gitops",
+	}
+	_, err := BootstrapToFs(params, fakeFs)
+	fatalIfError(t, err)
+	fatalIfError(t, afero.WriteFile(fakeFs, "/work/README.md", []byte("# Work\n"), 0644))
+
+	deleted, err := DeleteBootstrapped(fakeFs, "/work/gitops")
+	fatalIfError(t, err)
+
+	if diff := cmp.Diff([]string{"/work/gitops", "/work/secrets"}, deleted); diff != "" {
+		t.Fatalf("deleted paths didn't match:\n%s", diff)
+	}
+	for _, p := range deleted {
+		if exists, _ := afero.Exists(fakeFs, p); exists {
+			t.Errorf("%s was not deleted", p)
+		}
+	}
+	if exists, _ := afero.Exists(fakeFs, "/work/README.md"); !exists {
+		t.Fatal("a file outside the bootstrapped folders was deleted")
+	}
+}
+
+func TestDeleteBootstrappedWithoutSecrets(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	fatalIfError(t, afero.WriteFile(fakeFs, "/work/gitops/pipelines.yaml", []byte("environments: []\n"), 0644))
+
+	deleted, err := DeleteBootstrapped(fakeFs, "/work/gitops")
+	fatalIfError(t, err)
+
+	if diff := cmp.Diff([]string{"/work/gitops"}, deleted); diff != "" {
+		t.Fatalf("deleted paths didn't match:\n%s", diff)
+	}
+}
+
+func TestDeleteBootstrappedWithoutManifest(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	fatalIfError(t, afero.WriteFile(fakeFs, "/work/gitops/README.md", []byte("# GitOps\n"), 0644))
+	fatalIfError(t, fakeFs.MkdirAll("/work/secrets", 0755))
+
+	_, err := DeleteBootstrapped(fakeFs, "/work/gitops")
+	test.AssertErrorMatch(t, "no pipelines.yaml found in /work/gitops, refusing to delete", err)
+
+	for _, p := range []string{"/work/gitops/README.md", "/work/secrets"} {
+		if exists, _ := afero.Exists(fakeFs, p); !exists {
+			t.Errorf("%s was deleted", p)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
 )
@@ -46,6 +47,29 @@ func ContainerPort(p int32) PodSpecFunc {
 	}
 }
 
+// Resources configures the compute resources of the first container in the
+// PodSpec.
+func Resources(r corev1.ResourceRequirements) PodSpecFunc {
+	return func(c *corev1.PodSpec) {
+		c.Containers[0].Resources = r
+	}
+}
+
+// ReadinessProbe configures an HTTP GET readiness probe of the path on the
+// port for the first container in the PodSpec.
+func ReadinessProbe(path string, port int32) PodSpecFunc {
+	return func(c *corev1.PodSpec) {
+		c.Containers[0].ReadinessProbe = &corev1.Probe{
+			Handler: corev1.Handler{
+				HTTPGet: &corev1.HTTPGetAction{
+					Path: path,
+					Port: intstr.FromInt(int(port)),
+				},
+			},
+		}
+	}
+}
+
 // Create creates and returns a Deployment with the specified configuration.
 func Create(partOf, ns, name, image string, opts ...PodSpecFunc) *appsv1.Deployment {
 	return &appsv1.Deployment{
@@ -5,7 +5,9 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
@@ -152,3 +154,50 @@ func TestPodTemplateContainerPort(t *testing.T) {
 		t.Fatalf("podTemplate diff: %s", diff)
 	}
 }
+
+func TestPodTemplateResources(t *testing.T) {
+	resources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+		Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+	}
+	spec := podTemplate(testComponentPartOf, testComponent, testImage, Resources(resources))
+
+	want := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				KubernetesAppNameLabel: testComponent,
+				KubernetesPartOfLabel:  testComponentPartOf,
+			},
+		},
+		Spec: corev1.PodSpec{
+			ServiceAccountName: "default",
+			Containers: []corev1.Container{
+				{
+					Name:            testComponent,
+					Image:           testImage,
+					ImagePullPolicy: corev1.PullAlways,
+					Resources:       resources,
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, spec); diff != "" {
+		t.Fatalf("podTemplate diff: %s", diff)
+	}
+}
+
+func TestPodTemplateReadinessProbe(t *testing.T) {
+	spec := podTemplate(testComponentPartOf, testComponent, testImage, ReadinessProbe("/healthz", 8080))
+
+	want := &corev1.Probe{
+		Handler: corev1.Handler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: "/healthz",
+				Port: intstr.FromInt(8080),
+			},
+		},
+	}
+	if diff := cmp.Diff(want, spec.Spec.Containers[0].ReadinessProbe); diff != "" {
+		t.Fatalf("readiness probe diff: %s", diff)
+	}
+}
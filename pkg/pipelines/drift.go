@@ -0,0 +1,101 @@
+package pipelines
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
+)
+
+// Drift is the difference between a GitOps tree and the resources that are
+// built from its manifest, the paths are relative to the tree.
+type Drift struct {
+	Changed []string // Generated files that differ from the files in the tree.
+	Missing []string // Generated files that are not in the tree.
+	Extra   []string // Files in the generated folders that are not generated.
+}
+
+// Empty returns true if the tree matches the built resources.
+func (d *Drift) Empty() bool {
+	return len(d.Changed) == 0 && len(d.Missing) == 0 && len(d.Extra) == 0
+}
+
+// VerifyTree builds the resources from the manifest in path in memory, and
+// compares them to the files in the tree at path.
+//
+// Only the resources that BuildResources generates are compared, so the
+// extra files are only reported in the folders that resources are generated
+// in, e.g. the Argo CD application for an environment that was removed from
+// the manifest.  Files at the root of the tree, e.g. the manifest, and hidden
+// files e.g. .gitkeep are never extra.
+func VerifyTree(appFs afero.Fs, path string) (*Drift, error) {
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path to the tree: %w", err)
+	}
+	m, err := config.LoadManifest(appFs, path)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := buildResources(appFs, m)
+	if err != nil {
+		return nil, err
+	}
+	memFs := ioutils.NewMemoryFilesystem()
+	if _, err := yaml.WriteResources(memFs, "/", resources); err != nil {
+		return nil, err
+	}
+
+	drift := &Drift{}
+	folders := map[string]bool{}
+	for filename := range resources {
+		if folder := filepath.Dir(filename); folder != "." {
+			folders[folder] = true
+		}
+		want, err := afero.ReadFile(memFs, filepath.Join("/", filename))
+		if err != nil {
+			return nil, err
+		}
+		got, err := afero.ReadFile(appFs, filepath.Join(path, filename))
+		if os.IsNotExist(err) {
+			drift.Missing = append(drift.Missing, filename)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+		}
+		if !bytes.Equal(want, got) {
+			drift.Changed = append(drift.Changed, filename)
+		}
+	}
+
+	for folder := range folders {
+		infos, err := afero.ReadDir(appFs, filepath.Join(path, folder))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", folder, err)
+		}
+		for _, info := range infos {
+			filename := filepath.Join(folder, info.Name())
+			if _, ok := resources[filename]; !ok && !info.IsDir() && !strings.HasPrefix(info.Name(), ".") {
+				drift.Extra = append(drift.Extra, filename)
+			}
+		}
+	}
+
+	sort.Strings(drift.Changed)
+	sort.Strings(drift.Missing)
+	sort.Strings(drift.Extra)
+	return drift, nil
+}
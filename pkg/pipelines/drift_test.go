@@ -0,0 +1,66 @@
+package pipelines
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+func TestVerifyTree(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	bootstrapTree(t, fakeFs, "/gitops")
+
+	drift, err := VerifyTree(fakeFs, "/gitops")
+	fatalIfError(t, err)
+	if !drift.Empty() {
+		t.Fatalf("the bootstrapped tree has drifted: %#v", drift)
+	}
+}
+
+func TestVerifyTreeWithDrift(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	bootstrapTree(t, fakeFs, "/gitops")
+
+	fatalIfError(t, afero.WriteFile(fakeFs, "/gitops/environments/tst-dev/env/base/tst-dev-environment.yaml", []byte("edited: true\n"), 0644))
+	fatalIfError(t, fakeFs.Remove("/gitops/config/argocd/tst-stage-env-app.yaml"))
+	fatalIfError(t, afero.WriteFile(fakeFs, "/gitops/config/argocd/tst-prod-env-app.yaml", []byte("kind: Application\n"), 0644))
+	fatalIfError(t, afero.WriteFile(fakeFs, "/gitops/config/argocd/.gitkeep", []byte{}, 0644))
+
+	drift, err := VerifyTree(fakeFs, "/gitops")
+	fatalIfError(t, err)
+
+	want := &Drift{
+		Changed: []string{"environments/tst-dev/env/base/tst-dev-environment.yaml"},
+		Missing: []string{"config/argocd/tst-stage-env-app.yaml"},
+		Extra:   []string{"config/argocd/tst-prod-env-app.yaml"},
+	}
+	if diff := cmp.Diff(want, drift); diff != "" {
+		t.Fatalf("drift didn't match:\n%s", diff)
+	}
+}
+
+func TestVerifyTreeWithoutManifest(t *testing.T) {
+	_, err := VerifyTree(ioutils.NewMemoryFilesystem(), "/gitops")
+	if err == nil {
+		t.Fatal("expected an error verifying a tree without a manifest")
+	}
+}
+
+func bootstrapTree(t *testing.T, appFs afero.Fs, path string) {
+	t.Helper()
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           path,
+	}
+	_, err := BootstrapToFs(params, appFs)
+	fatalIfError(t, err)
+}
@@ -11,6 +11,7 @@ is_argocd=false
 argo_path="config/argocd"
 cicd_path="config/{{ .CICDEnv }}"
 cmd={{ .Cmd }}
+envs="$(inputs.params.ENVIRONMENTS)"
 overall_exit=0
 
 execute() {
@@ -31,6 +32,9 @@ printf "Apply $(basename ${cicd_path}) environment\n"
 execute "${cicd_path}/overlays"
 
 for dir in $(ls -d environments/*/); do
+  if [[ ! -z "${envs}" && ! " ${envs} " =~ " $(basename ${dir}) " ]]; then
+    continue
+  fi
   if ! $is_argocd; then
     printf "Apply $(basename ${dir}) environment\n"
     execute "${dir}env/overlays"
@@ -41,7 +41,7 @@ func TestMakeScriptWithArgo(t *testing.T) {
 	assertNoError(t, err)
 
 	want := logsWithArgoCD
-	got := executeScript(t, fs, tempDir, s)
+	got := executeScript(t, fs, tempDir, s, "")
 	if got != want {
 		t.Fatalf("makeScript() failed: got \n%s want: \n%s", got, want)
 	}
@@ -57,7 +57,46 @@ func TestMakeScriptWithoutArgo(t *testing.T) {
 	assertNoError(t, err)
 
 	want := logsWithoutArgoCD
-	got := executeScript(t, fs, tempDir, s)
+	got := executeScript(t, fs, tempDir, s, "")
+	if got != want {
+		t.Fatalf("makeScript() failed: got \n%s want: \n%s", got, want)
+	}
+}
+
+func TestMakeScriptInRepoSubpath(t *testing.T) {
+	tempDir, cleanup := tempDir(t)
+	defer cleanup()
+
+	fs := ioutils.NewFilesystem()
+	setupGitOpsTree(t, fs, filepath.Join(tempDir, "platform", "gitops"), true)
+	s, err := MakeScript("", "cicd")
+	assertNoError(t, err)
+	step := tasks.CreateDeployFromSourceTask("cicd", s, "platform/gitops").Spec.Steps[0]
+	// The repository is cloned into /workspace/source.
+	workingDir, err := filepath.Rel("/workspace/source", step.WorkingDir)
+	assertNoError(t, err)
+
+	want := logsWithArgoCD
+	got := executeScript(t, fs, filepath.Join(tempDir, workingDir), step.Script, "")
+	if got != want {
+		t.Fatalf("makeScript() failed: got \n%s want: \n%s", got, want)
+	}
+}
+
+func TestMakeScriptWithEnvironments(t *testing.T) {
+	tempDir, cleanup := tempDir(t)
+	defer cleanup()
+
+	fs := ioutils.NewFilesystem()
+	setupGitOpsTree(t, fs, tempDir, false)
+	s, err := MakeScript("", "cicd")
+	assertNoError(t, err)
+
+	want := strings.Join([]string{
+		"Apply cicd environment",
+		"Apply stage environment\n",
+	}, "\n")
+	got := executeScript(t, fs, tempDir, s, "stage")
 	if got != want {
 		t.Fatalf("makeScript() failed: got \n%s want: \n%s", got, want)
 	}
@@ -75,7 +114,7 @@ func setupGitOpsTree(t *testing.T, fs afero.Fs, base string, withArgoCD bool) {
 		"environments/stage/apps/go-app/kustomization.yaml":  res.Kustomization{Bases: []string{"../overlays"}},
 		"config/cicd/base/kustomization.yaml":                res.Kustomization{Resources: []string{"task.yaml"}},
 		"config/cicd/overlays/kustomization.yaml":            res.Kustomization{Bases: []string{"../base"}},
-		"config/cicd/base/task.yaml":                         tasks.CreateDeployFromSourceTask("cicd", script),
+		"config/cicd/base/task.yaml":                         tasks.CreateDeployFromSourceTask("cicd", script, ""),
 	}
 	if withArgoCD {
 		argoDir := res.Resources{
@@ -88,8 +127,11 @@ func setupGitOpsTree(t *testing.T, fs afero.Fs, base string, withArgoCD bool) {
 	assertNoError(t, err)
 }
 
-func executeScript(t *testing.T, fs afero.Fs, baseDir, script string) string {
+// executeScript runs the script with the ENVIRONMENTS param substituted, as
+// Tekton would when running the task.
+func executeScript(t *testing.T, fs afero.Fs, baseDir, script, environments string) string {
 	t.Helper()
+	script = strings.ReplaceAll(script, "$(inputs.params.ENVIRONMENTS)", environments)
 	scriptPath := filepath.Join(baseDir, "dryrun_script.sh") // Don't call filepath.ToSlash
 	err := afero.WriteFile(fs, scriptPath, []byte(script), 0777)
 	assertNoError(t, err)
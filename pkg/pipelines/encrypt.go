@@ -0,0 +1,64 @@
+package pipelines
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	// SecretBackendNone leaves the generated secrets unencrypted.
+	SecretBackendNone = ""
+	// SecretBackendSOPS encrypts the generated secrets with sops.
+	SecretBackendSOPS = "sops"
+	// SecretBackendVault generates ExternalSecrets that fetch the secrets from
+	// HashiCorp Vault.
+	SecretBackendVault = "vault"
+
+	encryptedSecretSuffix = ".enc.yaml"
+)
+
+// EncryptSecrets encrypts the generated secrets in the secrets folder that is a
+// sibling of the GitOpsPath, writing <name>.enc.yaml files and removing the
+// unencrypted secrets.
+//
+// Only the data and stringData fields are encrypted, so that the encrypted files
+// can still be identified.
+func EncryptSecrets(o *BootstrapOptions, e executor, appFs afero.Fs) error {
+	if o.SecretBackend != SecretBackendSOPS {
+		return nil
+	}
+	secretsPath := filepath.Join(o.GitOpsPath(), "..", "secrets")
+	files, err := afero.Glob(appFs, filepath.Join(secretsPath, "*.yaml"))
+	if err != nil {
+		return fmt.Errorf("failed to find secrets in %q: %w", secretsPath, err)
+	}
+	for _, f := range files {
+		if strings.HasSuffix(f, encryptedSecretSuffix) {
+			continue
+		}
+		encrypted := strings.TrimSuffix(filepath.Base(f), ".yaml") + encryptedSecretSuffix
+		args := append([]string{"--encrypt"}, sopsKeyArgs(o)...)
+		args = append(args, "--encrypted-regex", "^(data|stringData)$", "--output", encrypted, filepath.Base(f))
+		if out, err := e.execute(secretsPath, "sops", args...); err != nil {
+			return fmt.Errorf("failed to encrypt %q with sops %q: %s", f, string(out), err)
+		}
+		if err := appFs.Remove(f); err != nil {
+			return fmt.Errorf("failed to remove unencrypted secret %q: %w", f, err)
+		}
+	}
+	return nil
+}
+
+func sopsKeyArgs(o *BootstrapOptions) []string {
+	args := []string{}
+	if o.SOPSAgeRecipients != "" {
+		args = append(args, "--age", o.SOPSAgeRecipients)
+	}
+	if o.SOPSPGPKey != "" {
+		args = append(args, "--pgp", o.SOPSPGPKey)
+	}
+	return args
+}
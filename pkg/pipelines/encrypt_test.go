@@ -0,0 +1,70 @@
+package pipelines
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/test"
+	"github.com/spf13/afero"
+)
+
+func TestEncryptSecrets(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	for _, f := range []string{"/secrets/git-host-access-token.yaml", "/secrets/previous.enc.yaml"} {
+		fatalIfError(t, afero.WriteFile(fakeFs, f, []byte("kind: Secret\n"), 0644))
+	}
+	opts := &BootstrapOptions{
+		OutputPath:        "/gitops",
+		SecretBackend:     SecretBackendSOPS,
+		SOPSAgeRecipients: "age1test",
+	}
+	e := newMockExecutor()
+
+	err := EncryptSecrets(opts, e, fakeFs)
+	fatalIfError(t, err)
+
+	want := []execution{
+		{
+			BaseDir: "/secrets",
+			Command: "sops",
+			Args:    []string{"--encrypt", "--age", "age1test", "--encrypted-regex", "^(data|stringData)$", "--output", "git-host-access-token.enc.yaml", "git-host-access-token.yaml"},
+		},
+	}
+	e.assertCommandsExecuted(t, want)
+	if exists, _ := ioutils.IsExisting(fakeFs, "/secrets/git-host-access-token.yaml"); exists {
+		t.Fatal("unencrypted secret was not removed")
+	}
+}
+
+func TestEncryptSecretsWithNoBackend(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	fatalIfError(t, afero.WriteFile(fakeFs, "/secrets/git-host-access-token.yaml", []byte("kind: Secret\n"), 0644))
+	e := newMockExecutor()
+
+	err := EncryptSecrets(&BootstrapOptions{OutputPath: "/gitops"}, e, fakeFs)
+	fatalIfError(t, err)
+
+	if diff := cmp.Diff([]execution{}, e.executed); diff != "" {
+		t.Fatalf("unexpected commands executed:\n%s", diff)
+	}
+}
+
+func TestEncryptSecrets_handling_errors(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	fatalIfError(t, afero.WriteFile(fakeFs, "/secrets/git-host-access-token.yaml", []byte("kind: Secret\n"), 0644))
+	opts := &BootstrapOptions{
+		OutputPath:    "/gitops",
+		SecretBackend: SecretBackendSOPS,
+		SOPSPGPKey:    "ABCDEF",
+	}
+	e := newMockExecutor([]byte("could not find key"))
+	e.errors.push(errors.New("exit status 128"))
+
+	err := EncryptSecrets(opts, e, fakeFs)
+	test.AssertErrorMatch(t, "failed to encrypt.*could not find key", err)
+	if exists, _ := ioutils.IsExisting(fakeFs, "/secrets/git-host-access-token.yaml"); !exists {
+		t.Fatal("unencrypted secret was removed after failing to encrypt")
+	}
+}
@@ -15,6 +15,7 @@ type EnvParameters struct {
 	PipelinesFolderPath string
 	EnvName             string
 	Cluster             string
+	ManualSync          bool
 }
 
 // AddEnv adds a new environment to the pipelines file.
@@ -35,7 +36,14 @@ func AddEnv(o *EnvParameters, appFs afero.Fs) error {
 	if o.Cluster != "" {
 		newEnv.Cluster = o.Cluster
 	}
+	if o.ManualSync {
+		autoSync := false
+		newEnv.AutoSync = &autoSync
+	}
 	m.Environments = append(m.Environments, newEnv)
+	if err := m.Validate(); err != nil {
+		return err
+	}
 	files[pipelinesFile] = m
 	built, err := buildResources(appFs, m)
 	if err != nil {
@@ -55,7 +63,7 @@ func newEnvironment(m *config.Manifest, name string) (*config.Environment, error
 		}
 		return &config.Environment{
 			Name:      name,
-			Pipelines: defaultPipelines(r),
+			Pipelines: defaultPipelines(r, m.GetPipelineNamePrefix()),
 		}, nil
 	}
 
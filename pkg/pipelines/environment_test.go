@@ -32,6 +32,7 @@ func TestAddEnv(t *testing.T) {
 		"environments/dev/env/base/kustomization.yaml",
 		"environments/dev/env/base/dev-environment.yaml",
 		"environments/dev/env/overlays/kustomization.yaml",
+		"kustomization.yaml",
 	}
 	for _, path := range wantedPaths {
 		t.Run(fmt.Sprintf("checking path %s already exists", path), func(rt *testing.T) {
@@ -63,7 +64,7 @@ func TestAddEnvWithClusterProvided(t *testing.T) {
 	envParameters := EnvParameters{
 		PipelinesFolderPath: gitopsPath,
 		EnvName:             "dev",
-		Cluster:             "testing.cluster",
+		Cluster:             "https://api.testing.cluster:6443",
 	}
 	_ = afero.WriteFile(fakeFs, pipelinesFilePath, []byte("environments:"), 0644)
 
@@ -91,7 +92,7 @@ func TestAddEnvWithClusterProvided(t *testing.T) {
 	want := map[string]interface{}{
 		"environments": []interface{}{
 			map[string]interface{}{
-				"cluster": "testing.cluster",
+				"cluster": "https://api.testing.cluster:6443",
 				"name":    "dev",
 			},
 		},
@@ -102,6 +103,35 @@ func TestAddEnvWithClusterProvided(t *testing.T) {
 	}
 }
 
+func TestAddEnvWithManualSync(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	gitopsPath := afero.GetTempDir(fakeFs, "test")
+	pipelinesFilePath := filepath.ToSlash(filepath.Join(gitopsPath, pipelinesFile))
+	envParameters := EnvParameters{
+		PipelinesFolderPath: gitopsPath,
+		EnvName:             "prod",
+		ManualSync:          true,
+	}
+	_ = afero.WriteFile(fakeFs, pipelinesFilePath, []byte("environments:"), 0644)
+
+	if err := AddEnv(&envParameters, fakeFs); err != nil {
+		t.Fatalf("AddEnv() failed :%s", err)
+	}
+
+	got := mustReadFileAsMap(t, fakeFs, pipelinesFilePath)
+	want := map[string]interface{}{
+		"environments": []interface{}{
+			map[string]interface{}{
+				"auto_sync": false,
+				"name":      "prod",
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("written environments failed:\n%s", diff)
+	}
+}
+
 func TestAddEnvWithExistingName(t *testing.T) {
 	fakeFs := ioutils.NewMemoryFilesystem()
 	gitopsPath := afero.GetTempDir(fakeFs, "test")
@@ -118,6 +148,24 @@ func TestAddEnvWithExistingName(t *testing.T) {
 	}
 }
 
+func TestAddEnvWithInvalidName(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	gitopsPath := afero.GetTempDir(fakeFs, "test")
+
+	pipelinesFile := filepath.ToSlash(filepath.Join(gitopsPath, pipelinesFile))
+	envParameters := EnvParameters{
+		PipelinesFolderPath: gitopsPath,
+		EnvName:             "Prod_1",
+	}
+	_ = afero.WriteFile(fakeFs, pipelinesFile, []byte("environments:\n - name: dev\n"), 0644)
+
+	err := AddEnv(&envParameters, fakeFs)
+	test.AssertErrorMatch(t, `invalid name "Prod_1"`, err)
+	if exists, _ := afero.DirExists(fakeFs, filepath.Join(gitopsPath, "environments", "Prod_1")); exists {
+		t.Fatal("AddEnv() generated the invalid environment")
+	}
+}
+
 func TestNewEnvironment(t *testing.T) {
 	tests := []struct {
 		m      *config.Manifest
@@ -34,13 +34,17 @@ const (
 )
 
 type envBuilder struct {
-	files           res.Resources
-	pipelinesConfig *config.PipelinesConfig
-	fs              afero.Fs
-	saName          string
-	appLinks        AppLinks
-	gitOpsRepoURL   string
-	repoPath        string
+	files             res.Resources
+	pipelinesConfig   *config.PipelinesConfig
+	fs                afero.Fs
+	saName            string
+	appLinks          AppLinks
+	gitOpsRepoURL     string
+	repoPath          string
+	namespacedInstall bool
+	projectRequests   bool
+	perEnvOverlays    bool
+	argoNS            string
 }
 
 // Build generates a set of resources from the manifest, related to the
@@ -56,34 +60,57 @@ func Build(fs afero.Fs, m *config.Manifest, saName string, o AppLinks) (res.Reso
 	repoPath := strings.TrimPrefix(strings.TrimSuffix(parsed.Path, ".git"), "/")
 
 	eb := &envBuilder{
-		fs:              fs,
-		files:           files,
-		pipelinesConfig: cfg,
-		saName:          saName,
-		appLinks:        o,
-		gitOpsRepoURL:   m.GitOpsURL,
-		repoPath:        repoPath,
+		fs:                fs,
+		files:             files,
+		pipelinesConfig:   cfg,
+		saName:            saName,
+		appLinks:          o,
+		gitOpsRepoURL:     m.GitOpsURL,
+		repoPath:          repoPath,
+		namespacedInstall: m.IsNamespacedInstall(),
+		projectRequests:   m.UseProjectRequests(),
+		perEnvOverlays:    m.UsePerEnvOverlays(),
+		argoNS:            argocd.Namespace(m),
 	}
 	return eb.files, m.Walk(eb)
 }
 
+// addFiles copies the files into the built files, rather than merging them
+// into a new set, which would copy all of the files built so far for each app
+// and service.
+func (b *envBuilder) addFiles(files res.Resources) {
+	for k, v := range files {
+		b.files[k] = v
+	}
+}
+
 func (b *envBuilder) Application(env *config.Environment, app *config.Application) error {
 	appPath := filepath.ToSlash(filepath.Join(config.PathForApplication(env, app)))
-	appFiles, err := filesForApplication(env, b.repoPath, appPath, app)
+	appFiles, err := filesForApplication(env, b.repoPath, appPath, app, b.perEnvOverlays)
 	if err != nil {
 		return err
 	}
-	b.files = res.Merge(appFiles, b.files)
+	b.addFiles(appFiles)
 	return nil
 }
 
 func (b *envBuilder) Service(app *config.Application, env *config.Environment, svc *config.Service) error {
-	svcPath := config.PathForService(app, env, svc.Name)
-	svcFiles, err := filesForService(svcPath)
+	basePath := config.PathForServiceBase(app, env, svc.Name, b.perEnvOverlays)
+	overlayPath := config.PathForServiceOverlay(app, env, svc.Name, b.perEnvOverlays)
+	svcFiles, err := filesForService(basePath, overlayPath, env.Name, svc.Image)
 	if err != nil {
 		return err
 	}
-	b.files = res.Merge(svcFiles, b.files)
+	// The application uses the per-environment overlays directly.
+	if !b.perEnvOverlays {
+		svcPath := config.PathForService(app, env, svc.Name)
+		svcRel, err := filepath.Rel(svcPath, overlayPath)
+		if err != nil {
+			return err
+		}
+		svcFiles[filepath.ToSlash(filepath.Join(svcPath, kustomization))] = &res.Kustomization{Bases: []string{filepath.ToSlash(svcRel)}}
+	}
+	b.addFiles(svcFiles)
 	// RoleBinding is created only when an environment has a service and the
 	// CICD environment is defined.
 	if b.pipelinesConfig == nil {
@@ -100,7 +127,12 @@ func (b *envBuilder) Service(app *config.Application, env *config.Environment, s
 func (b *envBuilder) Environment(env *config.Environment) error {
 	envPath := filepath.ToSlash(filepath.Join(config.PathForEnvironment(env), "env"))
 	basePath := filepath.ToSlash(filepath.Join(envPath, "base"))
-	envFiles := filesForEnvironment(basePath, env, b.gitOpsRepoURL)
+	envFiles := res.Resources{}
+	// The Namespace is cluster-scoped, so it must already exist for a
+	// namespaced install.
+	if !b.namespacedInstall {
+		envFiles = filesForEnvironment(basePath, env, b.gitOpsRepoURL, b.projectRequests)
+	}
 	kustomizedFilenames, err := ListFiles(b.fs, basePath)
 	if err != nil {
 		return fmt.Errorf("failed to list initial files for %s: %s", basePath, err)
@@ -112,7 +144,7 @@ func (b *envBuilder) Environment(env *config.Environment) error {
 
 	argocdAdminPath := filepath.ToSlash(filepath.Join(basePath, "argocd-admin.yaml"))
 	if _, ok := b.files[argocdAdminPath]; !ok {
-		envFiles[argocdAdminPath] = argocd.MakeApplicationControllerAdmin(env.Name)
+		envFiles[argocdAdminPath] = argocd.MakeApplicationControllerAdmin(b.argoNS, env.Name)
 	}
 
 	for k := range envFiles {
@@ -133,19 +165,25 @@ func (b *envBuilder) Environment(env *config.Environment) error {
 	if err != nil {
 		return err
 	}
-	envFiles[filepath.ToSlash(filepath.Join(overlaysPath, kustomization))] = &res.Kustomization{Bases: []string{filepath.ToSlash(relPath)}}
-	b.files = res.Merge(envFiles, b.files)
+	overlay := &res.Kustomization{Bases: []string{filepath.ToSlash(relPath)}}
+	// Kustomize doesn't know that ProjectRequests are cluster-scoped, so it
+	// would set their namespace.
+	if !b.projectRequests {
+		overlay.Namespace = env.Name
+	}
+	envFiles[filepath.ToSlash(filepath.Join(overlaysPath, kustomization))] = overlay
+	b.addFiles(envFiles)
 	return nil
 }
 
-func filesForEnvironment(basePath string, env *config.Environment, gitOpsRepoURL string) res.Resources {
+func filesForEnvironment(basePath string, env *config.Environment, gitOpsRepoURL string, projectRequests bool) res.Resources {
 	envFiles := res.Resources{}
 	filename := filepath.ToSlash(filepath.Join(basePath, fmt.Sprintf("%s-environment.yaml", env.Name)))
-	envFiles[filename] = namespaces.Create(env.Name, gitOpsRepoURL)
+	envFiles[filename] = namespaces.Generate(env.Name, gitOpsRepoURL, projectRequests)
 	return envFiles
 }
 
-func filesForApplication(env *config.Environment, fullname, appPath string, app *config.Application) (res.Resources, error) {
+func filesForApplication(env *config.Environment, fullname, appPath string, app *config.Application, perEnvOverlays bool) (res.Resources, error) {
 	envFiles := res.Resources{}
 	basePath := filepath.ToSlash(filepath.Join(appPath, "base"))
 	overlaysPath := filepath.ToSlash(filepath.Join(appPath, "overlays"))
@@ -158,6 +196,9 @@ func filesForApplication(env *config.Environment, fullname, appPath string, app
 	relServices := []string{}
 	for _, v := range app.Services {
 		svcPath := config.PathForService(app, env, v.Name)
+		if perEnvOverlays {
+			svcPath = config.PathForServiceOverlay(app, env, v.Name, perEnvOverlays)
+		}
 		relService, err := filepath.Rel(filepath.Dir(baseKustomization), svcPath)
 		if err != nil {
 			return nil, err
@@ -175,7 +216,8 @@ func filesForApplication(env *config.Environment, fullname, appPath string, app
 		Bases: relServices,
 	}
 	envFiles[overlaysFile] = &res.Kustomization{
-		Bases: []string{filepath.ToSlash(overlayRel)},
+		Bases:     []string{filepath.ToSlash(overlayRel)},
+		Namespace: env.Name,
 	}
 	return envFiles, nil
 }
@@ -185,18 +227,19 @@ func createRoleBinding(env *config.Environment, cicdNS, saName string) *v1.RoleB
 	return roles.CreateRoleBinding(meta.NamespacedName(env.Name, fmt.Sprintf("%s-rolebinding", env.Name)), sa, "ClusterRole", "edit")
 }
 
-func filesForService(svcPath string) (res.Resources, error) {
+func filesForService(basePath, overlaysPath, namespace string, image *config.Image) (res.Resources, error) {
 	envFiles := res.Resources{}
-	basePath := filepath.ToSlash(filepath.Join(svcPath, "base"))
-	overlaysPath := filepath.ToSlash(filepath.Join(svcPath, "overlays"))
 	overlaysFile := filepath.ToSlash(filepath.Join(overlaysPath, kustomization))
 	overlayRel, err := filepath.Rel(overlaysPath, basePath)
 	if err != nil {
 		return nil, err
 	}
-	envFiles[filepath.ToSlash(filepath.Join(svcPath, kustomization))] = &res.Kustomization{Bases: []string{"overlays"}}
-	envFiles[filepath.ToSlash(filepath.Join(svcPath, "base", kustomization))] = &res.Kustomization{Bases: []string{"./config"}}
-	envFiles[overlaysFile] = &res.Kustomization{Bases: []string{filepath.ToSlash(overlayRel)}}
+	envFiles[filepath.ToSlash(filepath.Join(basePath, kustomization))] = &res.Kustomization{Bases: []string{"./config"}}
+	overlay := &res.Kustomization{Bases: []string{filepath.ToSlash(overlayRel)}, Namespace: namespace}
+	if image != nil {
+		overlay.Images = []res.ImageTransform{{Name: image.Name, NewName: image.NewName, NewTag: image.Tag}}
+	}
+	envFiles[overlaysFile] = overlay
 
 	return envFiles, nil
 }
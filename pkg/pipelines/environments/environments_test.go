@@ -1,8 +1,10 @@
 package environments
 
 import (
+	"fmt"
 	"os"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -31,23 +33,23 @@ func TestBuildEnvironmentFilesWithAppsToEnvironment(t *testing.T) {
 				"../services/service-metrics",
 			},
 		},
-		"environments/test-dev/env/base/argocd-admin.yaml": argocd.MakeApplicationControllerAdmin("test-dev"),
+		"environments/test-dev/env/base/argocd-admin.yaml": argocd.MakeApplicationControllerAdmin(argocd.ArgoCDNamespace, "test-dev"),
 		"environments/test-dev/apps/my-app-1/kustomization.yaml": &res.Kustomization{
 			Bases: []string{"overlays"},
 			CommonLabels: map[string]string{
 				vcsSourceLabel: "example/example",
 			}},
-		"environments/test-dev/apps/my-app-1/overlays/kustomization.yaml":                          &res.Kustomization{Bases: []string{"../base"}},
+		"environments/test-dev/apps/my-app-1/overlays/kustomization.yaml":                          &res.Kustomization{Bases: []string{"../base"}, Namespace: "test-dev"},
 		"environments/test-dev/env/base/test-dev-environment.yaml":                                 namespaces.Create("test-dev", testGitOpsRepoURL),
 		"environments/test-dev/env/base/test-dev-rolebinding.yaml":                                 createRoleBinding(m.Environments[0], "cicd", "pipelines"),
 		"environments/test-dev/env/base/kustomization.yaml":                                        &res.Kustomization{Resources: []string{"argocd-admin.yaml", "test-dev-environment.yaml", "test-dev-rolebinding.yaml"}},
-		"environments/test-dev/env/overlays/kustomization.yaml":                                    &res.Kustomization{Bases: []string{"../base"}},
+		"environments/test-dev/env/overlays/kustomization.yaml":                                    &res.Kustomization{Bases: []string{"../base"}, Namespace: "test-dev"},
 		"environments/test-dev/apps/my-app-1/services/service-http/kustomization.yaml":             &res.Kustomization{Bases: []string{"overlays"}},
 		"environments/test-dev/apps/my-app-1/services/service-http/base/kustomization.yaml":        &res.Kustomization{Bases: []string{"./config"}},
-		"environments/test-dev/apps/my-app-1/services/service-http/overlays/kustomization.yaml":    &res.Kustomization{Bases: []string{"../base"}},
+		"environments/test-dev/apps/my-app-1/services/service-http/overlays/kustomization.yaml":    &res.Kustomization{Bases: []string{"../base"}, Namespace: "test-dev"},
 		"environments/test-dev/apps/my-app-1/services/service-metrics/kustomization.yaml":          &res.Kustomization{Bases: []string{"overlays"}},
 		"environments/test-dev/apps/my-app-1/services/service-metrics/base/kustomization.yaml":     &res.Kustomization{Bases: []string{"./config"}},
-		"environments/test-dev/apps/my-app-1/services/service-metrics/overlays/kustomization.yaml": &res.Kustomization{Bases: []string{"../base"}},
+		"environments/test-dev/apps/my-app-1/services/service-metrics/overlays/kustomization.yaml": &res.Kustomization{Bases: []string{"../base"}, Namespace: "test-dev"},
 	}
 
 	if diff := cmp.Diff(want, files); diff != "" {
@@ -70,27 +72,27 @@ func TestBuildEnvironmentFilesWithEnvironmentsToApps(t *testing.T) {
 				"../services/service-metrics",
 			},
 		},
-		"environments/test-dev/env/base/argocd-admin.yaml": argocd.MakeApplicationControllerAdmin("test-dev"),
+		"environments/test-dev/env/base/argocd-admin.yaml": argocd.MakeApplicationControllerAdmin(argocd.ArgoCDNamespace, "test-dev"),
 		"environments/test-dev/apps/my-app-1/kustomization.yaml": &res.Kustomization{
 			Bases: []string{"overlays"},
 			CommonLabels: map[string]string{
 				vcsSourceLabel: "example/example",
 			},
 		},
-		"environments/test-dev/apps/my-app-1/overlays/kustomization.yaml": &res.Kustomization{Bases: []string{"../base"}},
+		"environments/test-dev/apps/my-app-1/overlays/kustomization.yaml": &res.Kustomization{Bases: []string{"../base"}, Namespace: "test-dev"},
 		"environments/test-dev/env/base/test-dev-environment.yaml":        namespaces.Create("test-dev", testGitOpsRepoURL),
 		"environments/test-dev/env/base/test-dev-rolebinding.yaml":        createRoleBinding(m.Environments[0], "cicd", "pipelines"),
 		"environments/test-dev/env/base/kustomization.yaml": &res.Kustomization{
 			Resources: []string{"argocd-admin.yaml", "test-dev-environment.yaml", "test-dev-rolebinding.yaml"},
 			Bases:     []string{"../../apps/my-app-1/overlays"},
 		},
-		"environments/test-dev/env/overlays/kustomization.yaml":                                    &res.Kustomization{Bases: []string{"../base"}},
+		"environments/test-dev/env/overlays/kustomization.yaml":                                    &res.Kustomization{Bases: []string{"../base"}, Namespace: "test-dev"},
 		"environments/test-dev/apps/my-app-1/services/service-http/kustomization.yaml":             &res.Kustomization{Bases: []string{"overlays"}},
 		"environments/test-dev/apps/my-app-1/services/service-http/base/kustomization.yaml":        &res.Kustomization{Bases: []string{"./config"}},
-		"environments/test-dev/apps/my-app-1/services/service-http/overlays/kustomization.yaml":    &res.Kustomization{Bases: []string{"../base"}},
+		"environments/test-dev/apps/my-app-1/services/service-http/overlays/kustomization.yaml":    &res.Kustomization{Bases: []string{"../base"}, Namespace: "test-dev"},
 		"environments/test-dev/apps/my-app-1/services/service-metrics/kustomization.yaml":          &res.Kustomization{Bases: []string{"overlays"}},
 		"environments/test-dev/apps/my-app-1/services/service-metrics/base/kustomization.yaml":     &res.Kustomization{Bases: []string{"./config"}},
-		"environments/test-dev/apps/my-app-1/services/service-metrics/overlays/kustomization.yaml": &res.Kustomization{Bases: []string{"../base"}},
+		"environments/test-dev/apps/my-app-1/services/service-metrics/overlays/kustomization.yaml": &res.Kustomization{Bases: []string{"../base"}, Namespace: "test-dev"},
 	}
 
 	if diff := cmp.Diff(want, files); diff != "" {
@@ -122,6 +124,85 @@ func TestBuildEnvironmentsDoesNotOutputCIorArgo(t *testing.T) {
 	}
 }
 
+func BenchmarkBuild(b *testing.B) {
+	appFs := ioutils.NewMemoryFilesystem()
+	for i := 0; i < b.N; i++ {
+		if _, err := Build(appFs, manifestWithEnvironments(50), "pipelines", AppsToEnvironments); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestBuildEnvironmentsWithNamespacedInstall(t *testing.T) {
+	var appFs = ioutils.NewMemoryFilesystem()
+	m := &config.Manifest{
+		GitOpsURL: testGitOpsRepoURL,
+		Config: &config.Config{
+			Pipelines:         &config.PipelinesConfig{Name: "cicd"},
+			NamespacedInstall: true,
+		},
+		Environments: []*config.Environment{{Name: "test-dev"}},
+	}
+
+	files, err := Build(appFs, m, "pipelines", AppsToEnvironments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := files["environments/test-dev/env/base/test-dev-environment.yaml"]; ok {
+		t.Fatal("namespace generated for a namespaced install")
+	}
+	want := &res.Kustomization{Resources: []string{"argocd-admin.yaml"}}
+	if diff := cmp.Diff(want, files["environments/test-dev/env/base/kustomization.yaml"]); diff != "" {
+		t.Fatalf("kustomization didn't match: %s\n", diff)
+	}
+}
+
+func TestBuildEnvironmentsWithPerEnvOverlays(t *testing.T) {
+	var appFs = ioutils.NewMemoryFilesystem()
+	m := buildManifestWithCICD()
+	m.Config.PerEnvOverlays = true
+	m.Environments = append(m.Environments, &config.Environment{
+		Name: "test-stage",
+		Apps: []*config.Application{
+			{
+				Name:     "my-app-1",
+				Services: []*config.Service{{Name: "service-http", SourceURL: "https://github.com/myproject/myservice.git"}},
+			},
+		},
+	})
+
+	files, err := Build(appFs, m, "pipelines", AppsToEnvironments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := res.Resources{
+		"environments/test-dev/apps/my-app-1/base/kustomization.yaml": &res.Kustomization{
+			Bases: []string{
+				"../../../../../apps/my-app-1/services/service-http/overlays/test-dev",
+				"../../../../../apps/my-app-1/services/service-metrics/overlays/test-dev",
+			},
+		},
+		"environments/test-stage/apps/my-app-1/base/kustomization.yaml": &res.Kustomization{
+			Bases: []string{"../../../../../apps/my-app-1/services/service-http/overlays/test-stage"},
+		},
+		"apps/my-app-1/services/service-http/base/kustomization.yaml":                &res.Kustomization{Bases: []string{"./config"}},
+		"apps/my-app-1/services/service-http/overlays/test-dev/kustomization.yaml":   &res.Kustomization{Bases: []string{"../../base"}, Namespace: "test-dev"},
+		"apps/my-app-1/services/service-http/overlays/test-stage/kustomization.yaml": &res.Kustomization{Bases: []string{"../../base"}, Namespace: "test-stage"},
+	}
+	for k, v := range want {
+		if diff := cmp.Diff(v, files[k]); diff != "" {
+			t.Errorf("%s didn't match: %s\n", k, diff)
+		}
+	}
+	for k := range files {
+		if strings.Contains(k, "/services/") && strings.HasPrefix(k, "environments/") {
+			t.Errorf("service file %s generated in the environment with per-environment overlays", k)
+		}
+	}
+}
+
 func mustWriteFile(t *testing.T, fs afero.Fs, path string, data []byte, perm os.FileMode) {
 	t.Helper()
 	err := afero.WriteFile(fs, path, data, perm)
@@ -169,6 +250,25 @@ func TestBuildEnvironmentsAddsKustomizedFiles(t *testing.T) {
 	}
 }
 
+func TestBuildEnvironmentOverlayWithProjectRequests(t *testing.T) {
+	m := buildManifest()
+	m.Config = &config.Config{UseProjectRequests: true}
+
+	files, err := Build(ioutils.NewMemoryFilesystem(), m, "pipelines", AppsToEnvironments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &res.Kustomization{Bases: []string{"../base"}}
+	if diff := cmp.Diff(want, files["environments/test-dev/env/overlays/kustomization.yaml"]); diff != "" {
+		t.Fatalf("environment overlay didn't match:\n%s", diff)
+	}
+	want = &res.Kustomization{Bases: []string{"../base"}, Namespace: "test-dev"}
+	if diff := cmp.Diff(want, files["environments/test-dev/apps/my-app-1/overlays/kustomization.yaml"]); diff != "" {
+		t.Fatalf("application overlay didn't match:\n%s", diff)
+	}
+}
+
 func TestBuildEnvironmentFilesWithNoCICDEnv(t *testing.T) {
 	var appFs = ioutils.NewMemoryFilesystem()
 	m := buildManifest()
@@ -191,17 +291,17 @@ func TestBuildEnvironmentFilesWithNoCICDEnv(t *testing.T) {
 				vcsSourceLabel: "example/example",
 			},
 		},
-		"environments/test-dev/env/base/argocd-admin.yaml":                                         argocd.MakeApplicationControllerAdmin("test-dev"),
-		"environments/test-dev/apps/my-app-1/overlays/kustomization.yaml":                          &res.Kustomization{Bases: []string{"../base"}},
+		"environments/test-dev/env/base/argocd-admin.yaml":                                         argocd.MakeApplicationControllerAdmin(argocd.ArgoCDNamespace, "test-dev"),
+		"environments/test-dev/apps/my-app-1/overlays/kustomization.yaml":                          &res.Kustomization{Bases: []string{"../base"}, Namespace: "test-dev"},
 		"environments/test-dev/env/base/test-dev-environment.yaml":                                 namespaces.Create("test-dev", testGitOpsRepoURL),
 		"environments/test-dev/env/base/kustomization.yaml":                                        &res.Kustomization{Resources: []string{"argocd-admin.yaml", "test-dev-environment.yaml"}},
-		"environments/test-dev/env/overlays/kustomization.yaml":                                    &res.Kustomization{Bases: []string{"../base"}},
+		"environments/test-dev/env/overlays/kustomization.yaml":                                    &res.Kustomization{Bases: []string{"../base"}, Namespace: "test-dev"},
 		"environments/test-dev/apps/my-app-1/services/service-http/kustomization.yaml":             &res.Kustomization{Bases: []string{"overlays"}},
 		"environments/test-dev/apps/my-app-1/services/service-http/base/kustomization.yaml":        &res.Kustomization{Bases: []string{"./config"}},
-		"environments/test-dev/apps/my-app-1/services/service-http/overlays/kustomization.yaml":    &res.Kustomization{Bases: []string{"../base"}},
+		"environments/test-dev/apps/my-app-1/services/service-http/overlays/kustomization.yaml":    &res.Kustomization{Bases: []string{"../base"}, Namespace: "test-dev"},
 		"environments/test-dev/apps/my-app-1/services/service-metrics/kustomization.yaml":          &res.Kustomization{Bases: []string{"overlays"}},
 		"environments/test-dev/apps/my-app-1/services/service-metrics/base/kustomization.yaml":     &res.Kustomization{Bases: []string{"./config"}},
-		"environments/test-dev/apps/my-app-1/services/service-metrics/overlays/kustomization.yaml": &res.Kustomization{Bases: []string{"../base"}},
+		"environments/test-dev/apps/my-app-1/services/service-metrics/overlays/kustomization.yaml": &res.Kustomization{Bases: []string{"../base"}, Namespace: "test-dev"},
 	}
 
 	if diff := cmp.Diff(want, files); diff != "" {
@@ -256,3 +356,42 @@ func createEnvironment() []*config.Environment {
 		},
 	}
 }
+
+func TestFilesForServiceWithImage(t *testing.T) {
+	svcPath := "environments/test-prod/apps/my-app-1/services/service-http"
+	files, err := filesForService(svcPath+"/base", svcPath+"/overlays", "test-prod", &config.Image{Name: "quay.io/example/service-http", Tag: "v1.2.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &res.Kustomization{
+		Bases:     []string{"../base"},
+		Namespace: "test-prod",
+		Images:    []res.ImageTransform{{Name: "quay.io/example/service-http", NewTag: "v1.2.0"}},
+	}
+	if diff := cmp.Diff(want, files[svcPath+"/overlays/kustomization.yaml"]); diff != "" {
+		t.Fatalf("overlay kustomization did not match:\n%s", diff)
+	}
+}
+
+// manifestWithEnvironments returns a manifest with n environments, in reverse
+// order of their names, each with an app of ten services.
+func manifestWithEnvironments(n int) *config.Manifest {
+	m := &config.Manifest{
+		GitOpsURL: testGitOpsRepoURL,
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{Name: "cicd"},
+		},
+	}
+	for i := n - 1; i >= 0; i-- {
+		app := &config.Application{Name: "my-app"}
+		for j := 0; j < 10; j++ {
+			app.Services = append(app.Services, &config.Service{Name: fmt.Sprintf("service-%d", j)})
+		}
+		m.Environments = append(m.Environments, &config.Environment{
+			Name: fmt.Sprintf("env-%03d", i),
+			Apps: []*config.Application{app},
+		})
+	}
+	return m
+}
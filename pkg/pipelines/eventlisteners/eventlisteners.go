@@ -1,8 +1,14 @@
 package eventlisteners
 
 import (
+	"fmt"
+	"net/url"
+	"strings"
+
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
 
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
 	"github.com/redhat-developer/kam/pkg/pipelines/scm"
@@ -19,19 +25,140 @@ const (
 	WebhookSecretKey = "webhook-secret-key"
 )
 
+// The events in the GitOps repository that trigger the CI dry-run.
+const (
+	// DryRunTriggerPush dry-runs the pushes to the repository.
+	DryRunTriggerPush = "push"
+	// DryRunTriggerPullRequest dry-runs the pull requests to the repository
+	// before they're merged.
+	DryRunTriggerPullRequest = "pull-request"
+	// DryRunTriggerAll dry-runs both the pushes and the pull requests.
+	DryRunTriggerAll = "all"
+)
+
+// DryRunTriggers are the supported triggers for the CI dry-run.
+var DryRunTriggers = []string{DryRunTriggerPush, DryRunTriggerPullRequest, DryRunTriggerAll}
+
+// IsSupportedDryRunTrigger returns true if t is one of the DryRunTriggers.
+func IsSupportedDryRunTrigger(t string) bool {
+	for _, v := range DryRunTriggers {
+		if t == v {
+			return true
+		}
+	}
+	return false
+}
+
+// DryRunEvents returns whether the pushes and the pull requests to the
+// repository trigger the CI dry-run.
+//
+// If no trigger is provided, the pushes are dry-run, and so are the merge
+// requests to GitLab repositories.
+func DryRunEvents(repo scm.Repository, trigger string) (push, pullRequests bool) {
+	switch trigger {
+	case DryRunTriggerPush:
+		return true, false
+	case DryRunTriggerPullRequest:
+		return false, true
+	case DryRunTriggerAll:
+		return true, true
+	}
+	driver, err := scm.GetDriverName(repo.URL())
+	return true, err == nil && driver == "gitlab"
+}
+
 var (
 	eventListenerTypeMeta = meta.TypeMeta("EventListener", "triggers.tekton.dev/v1alpha1")
 )
 
 // Generate will create the required eventlisteners.
-func Generate(repo scm.Repository, ns, saName, secretName string) triggersv1.EventListener {
+//
+// The trigger references the TriggerTemplate and TriggerBinding names with the
+// pipelineNamePrefix, and the interceptors are added to the triggers after the
+// interceptors that filter the events.
+//
+// The events that trigger the CI dry-run are chosen by the dryRunTrigger, see
+// DryRunEvents.
+func Generate(repo scm.Repository, ns, saName, secretName, pipelineNamePrefix, dryRunTrigger string, interceptors ...*triggersv1.EventInterceptor) triggersv1.EventListener {
+	triggers := []triggersv1.EventListenerTrigger{}
+	push, pullRequests := DryRunEvents(repo, dryRunTrigger)
+	if push {
+		triggers = append(triggers, repo.CreatePushTrigger("ci-dryrun-from-push", secretName, ns, pipelineNamePrefix+"ci-dryrun-from-push-template", []string{pipelineNamePrefix + repo.PushBindingName()}))
+	}
+	if mrTrigger, ok := MergeRequestTrigger(repo, ns, secretName, pipelineNamePrefix); ok && pullRequests {
+		triggers = append(triggers, mrTrigger)
+	}
 	return triggersv1.EventListener{
 		TypeMeta:   eventListenerTypeMeta,
 		ObjectMeta: createListenerObjectMeta("cicd-event-listener", ns),
 		Spec: triggersv1.EventListenerSpec{
 			ServiceAccountName: saName,
-			Triggers: []triggersv1.EventListenerTrigger{
-				repo.CreatePushTrigger("ci-dryrun-from-push", secretName, ns, "ci-dryrun-from-push-template", []string{"github-push-binding"}),
+			Triggers:           AddInterceptors(triggers, interceptors...),
+		},
+	}
+}
+
+// MergeRequestTrigger returns the trigger that runs the CI dry-run for the
+// merge requests to the repository, if the repository's merge request events
+// are supported.
+func MergeRequestTrigger(repo scm.Repository, ns, secretName, pipelineNamePrefix string) (triggersv1.EventListenerTrigger, bool) {
+	bindingName := repo.MergeRequestBindingName()
+	if bindingName == "" {
+		return triggersv1.EventListenerTrigger{}, false
+	}
+	return repo.CreateMergeRequestTrigger("ci-dryrun-from-merge-request", secretName, ns, pipelineNamePrefix+"ci-dryrun-from-push-template", []string{pipelineNamePrefix + bindingName}), true
+}
+
+// AddInterceptors appends the interceptors to each of the triggers, after the
+// interceptors they already have.
+func AddInterceptors(triggers []triggersv1.EventListenerTrigger, interceptors ...*triggersv1.EventInterceptor) []triggersv1.EventListenerTrigger {
+	if len(interceptors) == 0 {
+		return triggers
+	}
+	for i := range triggers {
+		triggers[i].Interceptors = append(append([]*triggersv1.EventInterceptor{}, triggers[i].Interceptors...), interceptors...)
+	}
+	return triggers
+}
+
+// WebhookInterceptor creates a webhook interceptor that forwards events to the
+// Service at the URL, e.g. http://audit.audit-ns.svc.
+//
+// Webhook interceptors can only reference a Service in the cluster, so the URL
+// must be an http URL for a Service, without a port or path.
+func WebhookInterceptor(serviceURL string) (*triggersv1.EventInterceptor, error) {
+	u, err := url.Parse(serviceURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse webhook interceptor URL %q: %w", serviceURL, err)
+	}
+	if u.Scheme != "http" || u.Port() != "" || strings.Trim(u.Path, "/") != "" || u.RawQuery != "" || u.User != nil {
+		return nil, fmt.Errorf("invalid webhook interceptor URL %q, expected a URL of the form http://<service>.<namespace>.svc", serviceURL)
+	}
+	parts := strings.Split(strings.TrimSuffix(u.Hostname(), ".cluster.local"), ".")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] != "svc" {
+		return nil, fmt.Errorf("invalid webhook interceptor URL %q, expected a URL of the form http://<service>.<namespace>.svc", serviceURL)
+	}
+	return &triggersv1.EventInterceptor{
+		Webhook: &triggersv1.WebhookInterceptor{
+			ObjectRef: &corev1.ObjectReference{
+				APIVersion: "v1",
+				Kind:       "Service",
+				Name:       parts[0],
+				Namespace:  parts[1],
+			},
+		},
+	}, nil
+}
+
+// SetResources sets the compute resources of the EventListener's pod, which
+// the Triggers controller creates from the kubernetesResource's pod template.
+func SetResources(el *triggersv1.EventListener, resources corev1.ResourceRequirements) {
+	el.Spec.Resources.KubernetesResource = &triggersv1.KubernetesResource{
+		WithPodSpec: duckv1.WithPodSpec{
+			Template: duckv1.PodSpecable{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Resources: resources}},
+				},
 			},
 		},
 	}
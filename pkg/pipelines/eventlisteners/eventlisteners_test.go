@@ -1,11 +1,14 @@
 package eventlisteners
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/redhat-developer/kam/pkg/pipelines/scm"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -56,12 +59,185 @@ func TestGenerateEventListener(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	eventListener := Generate(repo, "testing", "pipeline", "test")
+	eventListener := Generate(repo, "testing", "pipeline", "test", "", "")
 	if diff := cmp.Diff(validEventListener, eventListener); diff != "" {
 		t.Fatalf("Generate() failed:\n%s", diff)
 	}
 }
 
+func TestGenerateEventListenerForGitLab(t *testing.T) {
+	repo, err := scm.NewRepository("https://gitlab.com/org/test.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eventListener := Generate(repo, "testing", "pipeline", "test", "", "")
+
+	trigger := eventListener.Spec.Triggers[0]
+	wantInterceptor := &triggersv1.GitLabInterceptor{
+		SecretRef: &triggersv1.SecretRef{
+			SecretName: "test",
+			SecretKey:  WebhookSecretKey,
+		},
+	}
+	if diff := cmp.Diff(wantInterceptor, trigger.Interceptors[0].GitLab); diff != "" {
+		t.Fatalf("Generate() interceptor failed:\n%s", diff)
+	}
+	wantBindings := []*triggersv1.EventListenerBinding{{Ref: "gitlab-push-binding"}}
+	if diff := cmp.Diff(wantBindings, trigger.Bindings); diff != "" {
+		t.Fatalf("Generate() bindings failed:\n%s", diff)
+	}
+}
+
+func TestGenerateEventListenerWithMergeRequestsForGitLab(t *testing.T) {
+	repo, err := scm.NewRepository("https://gitlab.com/org/test.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	interceptor, err := WebhookInterceptor("http://audit.audit-ns.svc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eventListener := Generate(repo, "testing", "pipeline", "test", "tst-", "", interceptor)
+
+	if l := len(eventListener.Spec.Triggers); l != 2 {
+		t.Fatalf("got %d triggers, want 2", l)
+	}
+	trigger := eventListener.Spec.Triggers[1]
+	if trigger.Name != "ci-dryrun-from-merge-request" {
+		t.Fatalf("got trigger %q, want ci-dryrun-from-merge-request", trigger.Name)
+	}
+	wantBindings := []*triggersv1.EventListenerBinding{{Ref: "tst-gitlab-merge-request-binding"}}
+	if diff := cmp.Diff(wantBindings, trigger.Bindings); diff != "" {
+		t.Fatalf("Generate() bindings failed:\n%s", diff)
+	}
+	if *trigger.Template.Ref != "tst-ci-dryrun-from-push-template" {
+		t.Fatalf("got template %q, want tst-ci-dryrun-from-push-template", *trigger.Template.Ref)
+	}
+	if diff := cmp.Diff(interceptor, trigger.Interceptors[2]); diff != "" {
+		t.Fatalf("Generate() webhook interceptor failed:\n%s", diff)
+	}
+}
+
+func TestGenerateEventListenerWithoutMergeRequestsForGitHub(t *testing.T) {
+	repo, err := scm.NewRepository("https://github.com/org/test.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eventListener := Generate(repo, "testing", "pipeline", "test", "", "")
+
+	if l := len(eventListener.Spec.Triggers); l != 1 {
+		t.Fatalf("got %d triggers, want 1", l)
+	}
+}
+
+func TestGenerateEventListenerWithDryRunTrigger(t *testing.T) {
+	tests := []struct {
+		repoURL       string
+		dryRunTrigger string
+		want          []string
+	}{
+		{"https://github.com/org/test.git", "", []string{"ci-dryrun-from-push"}},
+		{"https://github.com/org/test.git", DryRunTriggerPush, []string{"ci-dryrun-from-push"}},
+		{"https://github.com/org/test.git", DryRunTriggerPullRequest, []string{"ci-dryrun-from-merge-request"}},
+		{"https://github.com/org/test.git", DryRunTriggerAll, []string{"ci-dryrun-from-push", "ci-dryrun-from-merge-request"}},
+		{"https://gitlab.com/org/test.git", "", []string{"ci-dryrun-from-push", "ci-dryrun-from-merge-request"}},
+		{"https://gitlab.com/org/test.git", DryRunTriggerPush, []string{"ci-dryrun-from-push"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.repoURL+" "+tt.dryRunTrigger, func(t *testing.T) {
+			repo, err := scm.NewRepository(tt.repoURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			eventListener := Generate(repo, "testing", "pipeline", "test", "", tt.dryRunTrigger)
+
+			got := []string{}
+			for _, trigger := range eventListener.Spec.Triggers {
+				got = append(got, trigger.Name)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("Generate() triggers failed:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGenerateEventListenerWithInterceptors(t *testing.T) {
+	repo, err := scm.NewRepository("https://github.com/org/test.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	interceptor, err := WebhookInterceptor("http://audit.audit-ns.svc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eventListener := Generate(repo, "testing", "pipeline", "test", "", "", interceptor)
+
+	interceptors := eventListener.Spec.Triggers[0].Interceptors
+	if l := len(interceptors); l != 3 {
+		t.Fatalf("got %d interceptors, want 3", l)
+	}
+	if interceptors[1].CEL == nil {
+		t.Fatalf("the CEL filter is not before the webhook interceptor: %#v", interceptors[1])
+	}
+	if diff := cmp.Diff(interceptor, interceptors[2]); diff != "" {
+		t.Fatalf("Generate() webhook interceptor failed:\n%s", diff)
+	}
+}
+
+func TestSetResources(t *testing.T) {
+	eventListener := CreateELFromTriggers("testing", "pipeline", nil)
+	resources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+		Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+	}
+	SetResources(eventListener, resources)
+
+	containers := eventListener.Spec.Resources.KubernetesResource.Template.Spec.Containers
+	if l := len(containers); l != 1 {
+		t.Fatalf("got %d containers, want 1", l)
+	}
+	if diff := cmp.Diff(resources, containers[0].Resources); diff != "" {
+		t.Fatalf("SetResources() resources didn't match:\n%s", diff)
+	}
+}
+
+func TestWebhookInterceptor(t *testing.T) {
+	urlTests := []struct {
+		url       string
+		wantName  string
+		wantNS    string
+		wantError string
+	}{
+		{"http://audit.audit-ns.svc", "audit", "audit-ns", ""},
+		{"http://slack-notifier.tools.svc.cluster.local/", "slack-notifier", "tools", ""},
+		{"https://audit.audit-ns.svc", "", "", "expected a URL of the form http://<service>.<namespace>.svc"},
+		{"http://audit.audit-ns.svc:8080", "", "", "expected a URL of the form"},
+		{"http://audit.audit-ns.svc/events", "", "", "expected a URL of the form"},
+		{"http://audit.example.com", "", "", "expected a URL of the form"},
+		{"http://audit.svc", "", "", "expected a URL of the form"},
+		{"http://%zz", "", "", "failed to parse webhook interceptor URL"},
+	}
+	for _, tt := range urlTests {
+		t.Run(tt.url, func(rt *testing.T) {
+			interceptor, err := WebhookInterceptor(tt.url)
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					rt.Fatalf("got error %v, want %q", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				rt.Fatal(err)
+			}
+			want := &corev1.ObjectReference{APIVersion: "v1", Kind: "Service", Name: tt.wantName, Namespace: tt.wantNS}
+			if diff := cmp.Diff(want, interceptor.Webhook.ObjectRef); diff != "" {
+				rt.Fatalf("WebhookInterceptor() failed:\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestCreateListenerObjectMeta(t *testing.T) {
 	validObjectMeta := metav1.ObjectMeta{
 		Name:      "sample",
@@ -18,12 +18,59 @@ var (
 
 const defaultRoutePortName = "http-listener"
 
+// WildcardPolicies are the wildcard policies that a Route can have.
+var WildcardPolicies = []string{string(routev1.WildcardPolicyNone), string(routev1.WildcardPolicySubdomain)}
+
+// IsSupportedWildcardPolicy returns true if p is one of the WildcardPolicies.
+func IsSupportedWildcardPolicy(p string) bool {
+	for _, v := range WildcardPolicies {
+		if p == v {
+			return true
+		}
+	}
+	return false
+}
+
+// RouteOption modifies the generated Route.
+type RouteOption func(*routev1.Route)
+
+// WithWildcardPolicy sets the wildcard policy of the Route, e.g. Subdomain for
+// a router that serves wildcard routes.
+func WithWildcardPolicy(p string) RouteOption {
+	return func(r *routev1.Route) {
+		r.Spec.WildcardPolicy = routev1.WildcardPolicyType(p)
+	}
+}
+
+// WithSubdomain requests the subdomain within the router's domain for the
+// Route, rather than a generated host.
+func WithSubdomain(s string) RouteOption {
+	return func(r *routev1.Route) {
+		r.Spec.Subdomain = s
+	}
+}
+
+// WithEdgeTLS terminates TLS at the router for the Route, with the router's
+// certificate, and redirects insecure requests to HTTPS, so that the webhooks
+// can be delivered to an HTTPS endpoint.
+func WithEdgeTLS() RouteOption {
+	return func(r *routev1.Route) {
+		r.Spec.TLS = &routev1.TLSConfig{
+			Termination:                   routev1.TLSTerminationEdge,
+			InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+		}
+	}
+}
+
 // GenerateRoute generates an OpenShift route for the EventListener.
 //
 // It strips out the Status field from the route as this causes issues when
 // being created in a cluster.
-func GenerateRoute(ns string) (interface{}, error) {
+func GenerateRoute(ns string, opts ...RouteOption) (interface{}, error) {
 	r := createRoute(ns)
+	for _, o := range opts {
+		o(&r)
+	}
 	b, err := json.Marshal(r)
 	if err != nil {
 		return nil, err
@@ -38,6 +38,48 @@ func TestGenerateRoute(t *testing.T) {
 	}
 }
 
+func TestGenerateRouteWithOptions(t *testing.T) {
+	route, err := GenerateRoute("cicd-environment", WithWildcardPolicy("Subdomain"), WithSubdomain("webhooks"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := route.(map[string]interface{})["spec"].(map[string]interface{})
+	if spec["wildcardPolicy"] != "Subdomain" {
+		t.Fatalf("got wildcardPolicy %v, want Subdomain", spec["wildcardPolicy"])
+	}
+	if spec["subdomain"] != "webhooks" {
+		t.Fatalf("got subdomain %v, want webhooks", spec["subdomain"])
+	}
+}
+
+func TestGenerateRouteWithEdgeTLS(t *testing.T) {
+	route, err := GenerateRoute("cicd-environment", WithEdgeTLS())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"termination":                   "edge",
+		"insecureEdgeTerminationPolicy": "Redirect",
+	}
+	spec := route.(map[string]interface{})["spec"].(map[string]interface{})
+	if diff := cmp.Diff(want, spec["tls"]); diff != "" {
+		t.Fatalf("GenerateRoute() tls didn't match:\n%s", diff)
+	}
+}
+
+func TestIsSupportedWildcardPolicy(t *testing.T) {
+	for _, p := range []string{"None", "Subdomain"} {
+		if !IsSupportedWildcardPolicy(p) {
+			t.Errorf("IsSupportedWildcardPolicy(%q) got false, want true", p)
+		}
+	}
+	for _, p := range []string{"", "none", "Host"} {
+		if IsSupportedWildcardPolicy(p) {
+			t.Errorf("IsSupportedWildcardPolicy(%q) got true, want false", p)
+		}
+	}
+}
+
 func TestCreateRoute(t *testing.T) {
 	weight := int32(100)
 	validRoute := routev1.Route{
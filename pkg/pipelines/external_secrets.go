@@ -0,0 +1,42 @@
+package pipelines
+
+import (
+	"fmt"
+	"path/filepath"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
+)
+
+const externalSecretsPath = "09-externalsecrets"
+
+// addExternalSecrets adds an ExternalSecret to the base of the CI/CD
+// configuration for each of the generated secrets, so that the secrets are
+// fetched from the SecretStore rather than applied from the secrets folder.
+//
+// The ExternalSecrets are named after the files of the secrets, e.g.
+// 09-externalsecrets/gitops-webhook-secret.yaml.
+func addExternalSecrets(bootstrapped, otherResources res.Resources, storeName string) (res.Resources, error) {
+	m := bootstrapped[pipelinesFile].(*config.Manifest)
+	kustomizePath := filepath.Join(config.PathForPipelines(m.GetPipelinesConfig()), "base", "kustomization.yaml")
+	k, ok := bootstrapped[kustomizePath].(res.Kustomization)
+	if !ok {
+		return nil, fmt.Errorf("no kustomization for the %s environment found", kustomizePath)
+	}
+	externalSecrets := res.Resources{}
+	for _, f := range getResourceFiles(otherResources) {
+		secret, ok := otherResources[f].(*corev1.Secret)
+		if !ok {
+			continue
+		}
+		filename := filepath.ToSlash(filepath.Join(externalSecretsPath, filepath.Base(f)))
+		externalSecrets[filepath.Join(pipelinesPath(m.Config), filename)] = secrets.CreateExternalSecret(secret, storeName)
+		k.AddResources(filename)
+	}
+	bootstrapped = res.Merge(externalSecrets, bootstrapped)
+	bootstrapped[kustomizePath] = k
+	return bootstrapped, nil
+}
@@ -0,0 +1,61 @@
+package git
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/jenkins-x/go-scm/scm/transport"
+	"golang.org/x/oauth2"
+)
+
+// NewClientFactory returns a function that creates the go-scm clients for
+// repository URLs, like factory.FromRepoURL, with their own HTTP transport
+// that sends the requests through the proxy at proxyURL.
+//
+// If proxyURL is nil, the clients use the default HTTP transport, with the
+// proxy from the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables.
+func NewClientFactory(proxyURL *url.URL) func(string) (*scm.Client, error) {
+	if proxyURL == nil {
+		return factory.FromRepoURL
+	}
+	return func(repoURL string) (*scm.Client, error) {
+		client, err := factory.FromRepoURL(repoURL)
+		if err != nil {
+			return nil, err
+		}
+		setBaseTransport(client, proxyTransport(proxyURL))
+		return client, nil
+	}
+}
+
+// proxyTransport returns a copy of the default HTTP transport that sends the
+// requests through the proxy at proxyURL.
+func proxyTransport(proxyURL *url.URL) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyURL(proxyURL)
+	return t
+}
+
+// setBaseTransport sends the requests of the client through the base
+// transport, after they're authenticated by the transport that the factory
+// configured for the driver, if any.
+func setBaseTransport(client *scm.Client, base http.RoundTripper) {
+	if client.Client == nil || client.Client == http.DefaultClient {
+		client.Client = &http.Client{Transport: base}
+		return
+	}
+	switch t := client.Client.Transport.(type) {
+	case *oauth2.Transport:
+		t.Base = base
+	case *transport.PrivateToken:
+		t.Base = base
+	case *transport.Authorization:
+		t.Base = base
+	case *transport.BasicAuth:
+		t.Base = base
+	case nil:
+		client.Client.Transport = base
+	}
+}
@@ -0,0 +1,75 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestNewClientFactoryWithProxy(t *testing.T) {
+	proxyTests := []struct {
+		name     string
+		repoURL  string
+		wantHost string
+	}{
+		{"github without token", "https://github.com/example/test.git", "api.github.com:443"},
+		{"github with token", "https://:token@github.com/example/test.git", "api.github.com:443"},
+		{"gitlab with token", "https://:token@gitlab.com/example/test.git", "gitlab.com:443"},
+	}
+	for _, tt := range proxyTests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotMethod, gotHost string
+			proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotMethod, gotHost = r.Method, r.Host
+				w.WriteHeader(http.StatusBadGateway)
+			}))
+			defer proxy.Close()
+			proxyURL, err := url.Parse(proxy.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client, err := NewClientFactory(proxyURL)(tt.repoURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := client.Repositories.Find(context.Background(), "example/test"); err == nil {
+				t.Fatal("expected the request through the proxy to fail")
+			}
+
+			if gotMethod != http.MethodConnect || gotHost != tt.wantHost {
+				t.Fatalf("got %s %s through the proxy, want CONNECT %s", gotMethod, gotHost, tt.wantHost)
+			}
+		})
+	}
+}
+
+func TestNewClientFactoryDoesNotChangeDefaultTransport(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://api.github.com/user", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := http.DefaultTransport.(*http.Transport).Proxy(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxyURL, err := url.Parse("http://proxy.example.com:3128")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewClientFactory(proxyURL)("https://:token@github.com/example/test.git"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := http.DefaultTransport.(*http.Transport).Proxy(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got default proxy %v, want %v", got, want)
+	}
+}
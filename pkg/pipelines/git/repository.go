@@ -5,12 +5,67 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"sort"
 	"strings"
 
 	"github.com/jenkins-x/go-scm/scm"
-	"github.com/jenkins-x/go-scm/scm/factory"
 )
 
+// webhookEvents are the webhook events that can be subscribed to for each
+// driver, and how they're enabled in the go-scm events.
+var webhookEvents = map[string]map[string]func(*scm.HookEvents){
+	"github": {
+		"push":          func(e *scm.HookEvents) { e.Push = true },
+		"pull_request":  func(e *scm.HookEvents) { e.PullRequest = true },
+		"issue_comment": func(e *scm.HookEvents) { e.IssueComment = true },
+		"release":       func(e *scm.HookEvents) { e.Release = true },
+	},
+	"gitlab": {
+		"push":          func(e *scm.HookEvents) { e.Push = true },
+		"merge_request": func(e *scm.HookEvents) { e.PullRequest = true },
+		"tag_push":      func(e *scm.HookEvents) { e.Tag = true },
+		"note":          func(e *scm.HookEvents) { e.IssueComment = true },
+		"release":       func(e *scm.HookEvents) { e.Release = true },
+	},
+}
+
+// defaultWebhookEvents are the push and pull request events that the
+// EventListener triggers handle.
+var defaultWebhookEvents = scm.HookEvents{
+	PullRequest: true,
+	Push:        true,
+}
+
+// WebhookEvents returns the go-scm events for the named webhook events of the
+// driver, or the push and pull request events if no events are named.
+func WebhookEvents(driver string, names []string) (scm.HookEvents, error) {
+	if len(names) == 0 {
+		return defaultWebhookEvents, nil
+	}
+	supported, ok := webhookEvents[driver]
+	if !ok {
+		return scm.HookEvents{}, fmt.Errorf("webhook events can't be configured for the %s driver", driver)
+	}
+	events := scm.HookEvents{}
+	for _, name := range names {
+		enable, ok := supported[name]
+		if !ok {
+			return scm.HookEvents{}, fmt.Errorf("unsupported webhook event %q for the %s driver, must be one of %s", name, driver, strings.Join(supportedWebhookEvents(supported), ", "))
+		}
+		enable(&events)
+	}
+	return events, nil
+}
+
+func supportedWebhookEvents(events map[string]func(*scm.HookEvents)) []string {
+	names := []string{}
+	for name := range events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Repository represent a Git repository ofa specific Git repository URL
 type Repository struct {
 	*scm.Client
@@ -21,12 +76,19 @@ type Repository struct {
 
 // NewRepository creates a new Git repository object
 func NewRepository(rawURL, token string) (*Repository, error) {
+	return NewRepositoryWithProxy(rawURL, token, nil)
+}
+
+// NewRepositoryWithProxy creates a new Git repository object whose requests
+// are sent through the proxy at proxyURL, or through the proxy from the
+// environment if proxyURL is nil.
+func NewRepositoryWithProxy(rawURL, token string, proxyURL *url.URL) (*Repository, error) {
 	parsed, err := url.Parse(rawURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse repository URL %q: %w", rawURL, err)
 	}
 	parsed.User = url.UserPassword("", token)
-	client, err := factory.FromRepoURL(parsed.String())
+	client, err := NewClientFactory(proxyURL)(parsed.String())
 	if err != nil {
 		return nil, err
 	}
@@ -69,16 +131,18 @@ func (r *Repository) DeleteWebhooks(ids []string) ([]string, error) {
 	return deleted, nil
 }
 
-// CreateWebhook creates a new webhook in the repository
+// CreateWebhook creates a new webhook in the repository that delivers the
+// named events, or the push and pull request events if none are named.
 // It returns ID of the created webhook
-func (r *Repository) CreateWebhook(listenerURL, secret string) (string, error) {
+func (r *Repository) CreateWebhook(listenerURL, secret string, events []string) (string, error) {
+	hookEvents, err := WebhookEvents(r.Client.Driver.String(), events)
+	if err != nil {
+		return "", err
+	}
 	in := &scm.HookInput{
 		Target: listenerURL,
 		Secret: secret,
-		Events: scm.HookEvents{
-			PullRequest: true,
-			Push:        true,
-		},
+		Events: hookEvents,
 	}
 
 	created, _, err := r.Client.Repositories.CreateHook(context.Background(), r.name, in)
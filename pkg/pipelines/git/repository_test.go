@@ -1,12 +1,16 @@
 package git
 
 import (
+	"fmt"
 	"net/url"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/h2non/gock"
+	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/go-scm/scm/factory"
+
+	"github.com/redhat-developer/kam/test"
 )
 
 var mockHeaders = map[string]string{
@@ -36,7 +40,7 @@ func TestWebhookWithFakeClient(t *testing.T) {
 	}
 
 	// create a webhook
-	id, err := repo.CreateWebhook(listenerURL, "secret")
+	id, err := repo.CreateWebhook(listenerURL, "secret", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -143,7 +147,7 @@ func TestCreateWebHook(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	created, err := repo.CreateWebhook("http://example.com/webhook", "mysecret")
+	created, err := repo.CreateWebhook("http://example.com/webhook", "mysecret", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -153,6 +157,61 @@ func TestCreateWebHook(t *testing.T) {
 	}
 }
 
+func TestCreateWebHookWithEvents(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Post("/repos/foo/bar/hooks").
+		BodyString(`"events":\["push","release"\]`).
+		Reply(201).
+		Type("application/json").
+		SetHeaders(mockHeaders).
+		File("testdata/hook.json")
+
+	repo, err := NewRepository("https://github.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := repo.CreateWebhook("http://example.com/webhook", "mysecret", []string{"push", "release"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if created != "1" {
+		t.Errorf("failed to create webhook, got %q, want %q", created, "1")
+	}
+}
+
+func TestWebhookEvents(t *testing.T) {
+	eventTests := []struct {
+		driver  string
+		names   []string
+		want    scm.HookEvents
+		wantErr string
+	}{
+		{"github", nil, scm.HookEvents{Push: true, PullRequest: true}, ""},
+		{"bitbucket", nil, scm.HookEvents{Push: true, PullRequest: true}, ""},
+		{"github", []string{"push", "release"}, scm.HookEvents{Push: true, Release: true}, ""},
+		{"gitlab", []string{"merge_request", "tag_push"}, scm.HookEvents{PullRequest: true, Tag: true}, ""},
+		{"github", []string{"merge_request"}, scm.HookEvents{}, `unsupported webhook event "merge_request" for the github driver, must be one of issue_comment, pull_request, push, release`},
+		{"gitlab", []string{"pull_request"}, scm.HookEvents{}, `unsupported webhook event "pull_request" for the gitlab driver, must be one of merge_request, note, push, release, tag_push`},
+		{"bitbucket", []string{"push"}, scm.HookEvents{}, "webhook events can't be configured for the bitbucket driver"},
+	}
+
+	for _, tt := range eventTests {
+		t.Run(fmt.Sprintf("%s %v", tt.driver, tt.names), func(t *testing.T) {
+			got, err := WebhookEvents(tt.driver, tt.names)
+			if !test.ErrorMatch(t, tt.wantErr, err) {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("events didn't match:\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestGetRepoName(t *testing.T) {
 	urlTests := []struct {
 		url      string
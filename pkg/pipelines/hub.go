@@ -0,0 +1,105 @@
+package pipelines
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/spf13/afero"
+)
+
+// BootstrapHub registers the service from the ServiceRepoURL in the existing
+// manifest of a central "hub" GitOps repository that is checked out at the
+// GitOpsPath, rather than bootstrapping a new GitOps repository.
+//
+// The service is added to the dev environment, in an application named after
+// the service repository, in the same way as kam service add.
+func BootstrapHub(o *BootstrapOptions, appFs afero.Fs) error {
+	if exists, _ := ioutils.IsExisting(appFs, filepath.Join(o.GitOpsPath(), pipelinesFile)); !exists {
+		return fmt.Errorf("no %s found in %s, the hub GitOps repository must be checked out to --output", pipelinesFile, o.GitOpsPath())
+	}
+	serviceOptions, err := hubServiceOptions(o)
+	if err != nil {
+		return err
+	}
+	return AddService(serviceOptions, appFs)
+}
+
+// PushHubPullRequest commits the changes to the hub GitOps repository that is
+// checked out at the GitOpsPath to a new branch, pushes it and opens a pull
+// request to merge it to the default branch.
+func PushHubPullRequest(o *BootstrapOptions, f clientFactory, e executor, appFs afero.Fs) (*scm.PullRequest, error) {
+	serviceOptions, err := hubServiceOptions(o)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(o.GitOpsRepoURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitOps repo URL %q: %w", o.GitOpsRepoURL, err)
+	}
+	fullName := strings.TrimSuffix(strings.TrimPrefix(u.Path, "/"), ".git")
+	u.User = url.UserPassword("", o.GitHostAccessToken)
+	client, err := f(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a client to access %q: %w", o.GitOpsRepoURL, err)
+	}
+
+	branch := hubBranch(serviceOptions.ServiceName)
+	title := fmt.Sprintf("Add the %s service to %s", serviceOptions.ServiceName, serviceOptions.EnvName)
+	paths := []string{}
+	for _, p := range []string{pipelinesFile, "config", "environments"} {
+		paths = append(paths, path.Join(o.RepoSubpath, p))
+	}
+	commands := [][]string{
+		{"checkout", "-b", branch},
+		append([]string{"add"}, paths...),
+		{"commit", "-m", title},
+		{"push", "-u", "origin", branch},
+	}
+	for _, args := range commands {
+		if out, err := e.execute(o.GitOpsPath(), "git", args...); err != nil {
+			return nil, fmt.Errorf("failed to run git %s in %q %q: %s", args[0], o.GitOpsPath(), string(out), err)
+		}
+	}
+
+	pr, _, err := client.PullRequests.Create(context.Background(), fullName, &scm.PullRequestInput{
+		Title: title,
+		Head:  branch,
+		Base:  gitBranch(o),
+		Body:  fmt.Sprintf("Registers the service from %s in the %s environment.", o.ServiceRepoURL, serviceOptions.EnvName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open a pull request to %q: %w", fullName, err)
+	}
+	return pr, nil
+}
+
+// hubServiceOptions returns the options to add the service from the
+// ServiceRepoURL to the manifest, with the names that Bootstrap would give it.
+func hubServiceOptions(o *BootstrapOptions) (*AddServiceOptions, error) {
+	repoName, err := repoFromURL(o.ServiceRepoURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid app repo URL: %v", err)
+	}
+	return &AddServiceOptions{
+		AppName:             repoToAppName(repoName),
+		EnvName:             o.DevEnvironment(),
+		GitRepoURL:          o.ServiceRepoURL,
+		ImageRepo:           o.ImageRepo,
+		PipelinesFolderPath: o.GitOpsPath(),
+		ServiceName:         repoName,
+		WebhookSecret:       o.ServiceWebhookSecret,
+		Overwrite:           o.Overwrite,
+	}, nil
+}
+
+// hubBranch returns the branch that the service is added to the hub GitOps
+// repository on.
+func hubBranch(serviceName string) string {
+	return "kam/add-" + serviceName
+}
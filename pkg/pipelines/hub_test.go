@@ -0,0 +1,108 @@
+package pipelines
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestBootstrapHub(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/out",
+	}
+	_, err := BootstrapToFs(params, fakeFs)
+	fatalIfError(t, err)
+
+	hub := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "quay.io/example/payments",
+		ServiceRepoURL:       "https://github.com/my-org/payments.git",
+		ServiceWebhookSecret: "789",
+		OutputPath:           "/out",
+		Hub:                  true,
+	}
+	fatalIfError(t, BootstrapHub(hub, fakeFs))
+
+	m, err := config.LoadManifest(fakeFs, "/out")
+	fatalIfError(t, err)
+	if svc := m.GetService("tst-dev", "app-http-api", "http-api"); svc == nil {
+		t.Fatal("the bootstrapped service was removed from the manifest")
+	}
+	svc := m.GetService("tst-dev", "app-payments", "payments")
+	if svc == nil {
+		t.Fatal("the service wasn't added to the manifest")
+	}
+	if svc.SourceURL != hub.ServiceRepoURL {
+		t.Fatalf("got source URL %q, want %q", svc.SourceURL, hub.ServiceRepoURL)
+	}
+	secretName := secrets.MakeServiceWebhookSecretName("tst-dev", "payments")
+	if exists, _ := ioutils.IsExisting(fakeFs, "/secrets/"+secretName+".yaml"); !exists {
+		t.Fatalf("the webhook secret %s wasn't written", secretName)
+	}
+}
+
+func TestBootstrapHubWithoutManifest(t *testing.T) {
+	hub := &BootstrapOptions{
+		Prefix:         "tst-",
+		GitOpsRepoURL:  testGitOpsRepo,
+		ServiceRepoURL: "https://github.com/my-org/payments.git",
+		OutputPath:     "/out",
+		Hub:            true,
+	}
+	err := BootstrapHub(hub, ioutils.NewMemoryFilesystem())
+	test.AssertErrorMatch(t, "no pipelines.yaml found in /out", err)
+}
+
+func TestPushHubPullRequest(t *testing.T) {
+	token := "this-is-a-test-token"
+	factory, fakeData := newMockClientFactory(t, token)
+	opts := &BootstrapOptions{
+		GitOpsRepoURL:      "https://example.com/testing/hub.git",
+		GitHostAccessToken: token,
+		ServiceRepoURL:     "https://github.com/my-org/payments.git",
+		Prefix:             "tst-",
+		OutputPath:         "/tmp/hub",
+	}
+	fakeFs := ioutils.NewMemoryFilesystem()
+	fatalIfError(t, afero.WriteFile(fakeFs, "/tmp/hub/pipelines.yaml", []byte("environments: []\n"), 0644))
+	e := newMockExecutor()
+
+	pr, err := PushHubPullRequest(opts, factory, e, fakeFs)
+	assertNoError(t, err)
+
+	title := "Add the payments service to tst-dev"
+	want := []execution{
+		{BaseDir: "/tmp/hub", Command: "git", Args: []string{"checkout", "-b", "kam/add-payments"}},
+		{BaseDir: "/tmp/hub", Command: "git", Args: []string{"add", "pipelines.yaml", "config", "environments"}},
+		{BaseDir: "/tmp/hub", Command: "git", Args: []string{"commit", "-m", title}},
+		{BaseDir: "/tmp/hub", Command: "git", Args: []string{"push", "-u", "origin", "kam/add-payments"}},
+	}
+	e.assertCommandsExecuted(t, want)
+
+	if diff := cmp.Diff(fakeData.PullRequests[pr.Number], pr); diff != "" {
+		t.Fatalf("pull request wasn't recorded:\n%s", diff)
+	}
+	wantPR := scm.PullRequestBranch{Ref: "main", Repo: scm.Repository{Namespace: "testing", Name: "hub", FullName: "testing/hub"}}
+	if diff := cmp.Diff(wantPR, pr.Base); diff != "" {
+		t.Fatalf("pull request base didn't match:\n%s", diff)
+	}
+	if pr.Head.Ref != "kam/add-payments" || pr.Title != title {
+		t.Fatalf("got pull request from %q titled %q", pr.Head.Ref, pr.Title)
+	}
+}
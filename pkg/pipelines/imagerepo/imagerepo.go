@@ -1,6 +1,7 @@
 package imagerepo
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -13,10 +14,18 @@ import (
 
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
 
+	"github.com/google/go-containerregistry/pkg/name"
 	corev1 "k8s.io/api/core/v1"
 )
 
-const registryURL = "image-registry.openshift-image-registry.svc:5000"
+const (
+	registryURL = "image-registry.openshift-image-registry.svc:5000"
+
+	// gitLabRegistry is the container registry of gitlab.com, its
+	// repositories are named for the project, which can be in nested
+	// subgroups, and optionally an image name within the project.
+	gitLabRegistry = "registry.gitlab.com"
+)
 
 // ValidateImageRepo validates the input image repo.  It determines if it is
 // for internal registry and prepend internal registry hostname if necessary.
@@ -34,6 +43,14 @@ func ValidateImageRepo(imageRepo string) (bool, string, error) {
 		}
 	}
 
+	if components[0] == gitLabRegistry {
+		// registry.gitlab.com/<group>/[<subgroups>/]<project>[/<image>]
+		if len(components) < 3 {
+			return false, "", imageRepoValidationErrors(imageRepo)
+		}
+		return false, imageRepo, nil
+	}
+
 	if len(components) == 2 {
 		if components[0] == "docker.io" || components[0] == "quay.io" {
 			// we recognize docker.io and quay.io.  It is missing one component
@@ -53,6 +70,42 @@ func ValidateImageRepo(imageRepo string) (bool, string, error) {
 	return false, "", imageRepoValidationErrors(imageRepo)
 }
 
+// RegistryHost returns the registry of the image repository, the first
+// component of its name.
+func RegistryHost(imageRepo string) string {
+	return strings.Split(imageRepo, "/")[0]
+}
+
+// ParseGitLabDeployToken splits a GitLab deploy token of the form
+// <username>:<token> e.g. gitlab+deploy-token-1:abcdef, into the username and
+// the token that the registry is authenticated with.
+func ParseGitLabDeployToken(deployToken string) (string, string, error) {
+	parts := strings.SplitN(deployToken, ":", 2)
+	if len(parts) != 2 || isBlank(parts[0]) || isBlank(parts[1]) {
+		return "", "", errors.New("the GitLab deploy token must be of the form <username>:<token> e.g. gitlab+deploy-token-1:abcdef")
+	}
+	return parts[0], parts[1], nil
+}
+
+// ValidateImageReference returns an error if the image isn't a valid image
+// reference e.g. quay.io/org/image:tag, the registry and tag are optional.
+func ValidateImageReference(image string) error {
+	if _, err := name.ParseReference(image); err != nil {
+		return fmt.Errorf("failed to parse image reference %q: %w", image, err)
+	}
+	return nil
+}
+
+// ImageName returns the image reference without the tag or digest, this is
+// the name that a kustomize image transformer matches.
+func ImageName(image string) string {
+	image = strings.Split(image, "@")[0]
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i]
+	}
+	return image
+}
+
 func isBlank(s string) bool {
 	return strings.TrimSpace(s) == "" || len(s) > len(strings.TrimSpace(s))
 }
@@ -63,22 +116,37 @@ func imageRepoValidationErrors(imageRepo string) error {
 
 // CreateInternalRegistryResources creates and returns a set of resources, along
 // with the filenames of those resources.
-func CreateInternalRegistryResources(cfg *config.PipelinesConfig, sa *corev1.ServiceAccount, imageRepo, gitOpsRepoURL string) ([]string, res.Resources, error) {
+//
+// The Namespace for the image project is not created if it's one of the
+// existingNamespaces, e.g. if images are pushed to an environment's namespace,
+// and it's created as a ProjectRequest if projectRequests is true.
+func CreateInternalRegistryResources(cfg *config.PipelinesConfig, sa *corev1.ServiceAccount, imageRepo, gitOpsRepoURL string, projectRequests bool, existingNamespaces ...string) ([]string, res.Resources, error) {
 	// Provide access to service account for using internal registry
 	namespace := strings.Split(imageRepo, "/")[1]
 
 	resources := res.Resources{}
 	filenames := []string{}
 
-	filename := filepath.ToSlash(filepath.Join("01-namespaces", fmt.Sprintf("%s-environment.yaml", namespace)))
-	namespacePath := filepath.ToSlash(filepath.Join(config.PathForPipelines(cfg), "base", filename))
-	resources[namespacePath] = namespaces.Create(namespace, gitOpsRepoURL)
-	filenames = append(filenames, filename)
+	if !contains(existingNamespaces, namespace) {
+		filename := filepath.ToSlash(filepath.Join("01-namespaces", fmt.Sprintf("%s-environment.yaml", namespace)))
+		namespacePath := filepath.ToSlash(filepath.Join(config.PathForPipelines(cfg), "base", filename))
+		resources[namespacePath] = namespaces.Generate(namespace, gitOpsRepoURL, projectRequests)
+		filenames = append(filenames, filename)
+	}
 
 	filename, roleBinding := createInternalRegistryRoleBinding(cfg, namespace, sa)
 	return append(filenames, filename), res.Merge(roleBinding, resources), nil
 }
 
+func contains(names []string, name string) bool {
+	for _, v := range names {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
 func createInternalRegistryRoleBinding(cfg *config.PipelinesConfig, ns string, sa *corev1.ServiceAccount) (string, res.Resources) {
 	roleBindingName := fmt.Sprintf("internal-registry-%s-binding", ns)
 	roleBindingFilname := filepath.ToSlash(filepath.Join("02-rolebindings", fmt.Sprintf("%s.yaml", roleBindingName)))
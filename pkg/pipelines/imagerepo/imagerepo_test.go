@@ -2,6 +2,7 @@ package imagerepo
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -12,6 +13,36 @@ import (
 	v1rbac "k8s.io/api/rbac/v1"
 )
 
+func TestCreateInternalRegistryResources(t *testing.T) {
+	pipelinesConfig := &config.PipelinesConfig{Name: "test-cicd"}
+	sa := roles.CreateServiceAccount(meta.NamespacedName("test-cicd", "pipeline"))
+	imageRepo := registryURL + "/new-proj/app"
+
+	tests := []struct {
+		name       string
+		existing   []string
+		wantFiles  []string
+		wantLength int
+	}{
+		{"new project", nil, []string{"01-namespaces/new-proj-environment.yaml", "02-rolebindings/internal-registry-new-proj-binding.yaml"}, 2},
+		{"existing project", []string{"test-dev", "new-proj"}, []string{"02-rolebindings/internal-registry-new-proj-binding.yaml"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(rt *testing.T) {
+			files, resources, err := CreateInternalRegistryResources(pipelinesConfig, sa, imageRepo, "https://github.com/org/gitops.git", false, tt.existing...)
+			if err != nil {
+				rt.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.wantFiles, files); diff != "" {
+				rt.Errorf("filenames do not match:\n%s", diff)
+			}
+			if len(resources) != tt.wantLength {
+				rt.Errorf("got %d resources, want %d", len(resources), tt.wantLength)
+			}
+		})
+	}
+}
+
 func TestCreateInternalRegistryRoleBinding(t *testing.T) {
 	pipelinesConfig := &config.PipelinesConfig{
 		Name: "test-cicd",
@@ -140,6 +171,27 @@ func TestValidateImageRepo(t *testing.T) {
 			false,
 			"",
 		},
+		{
+			"Valid GitLab registry URL",
+			"registry.gitlab.com/group/project",
+			"",
+			false,
+			"registry.gitlab.com/group/project",
+		},
+		{
+			"Valid GitLab registry URL with subgroups and image name",
+			"registry.gitlab.com/group/subgroup/project/app",
+			"",
+			false,
+			"registry.gitlab.com/group/subgroup/project/app",
+		},
+		{
+			"Invalid GitLab registry URL with missing project",
+			"registry.gitlab.com/group",
+			fmt.Sprintf(errorMsg, "registry.gitlab.com/group"),
+			false,
+			"",
+		},
 		{
 			"Invalid not enough URL components, no slash",
 			"docker.io",
@@ -166,3 +218,79 @@ func TestValidateImageRepo(t *testing.T) {
 		})
 	}
 }
+
+func TestParseGitLabDeployToken(t *testing.T) {
+	tokenErr := "the GitLab deploy token must be of the form <username>:<token> e.g. gitlab+deploy-token-1:abcdef"
+	tests := []struct {
+		deployToken  string
+		wantUsername string
+		wantToken    string
+		wantErr      string
+	}{
+		{"gitlab+deploy-token-1:abcdef", "gitlab+deploy-token-1", "abcdef", ""},
+		{"ci-pusher:gldt-abc:def", "ci-pusher", "gldt-abc:def", ""},
+		{"gldt-abcdef", "", "", tokenErr},
+		{":abcdef", "", "", tokenErr},
+		{"gitlab+deploy-token-1:", "", "", tokenErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.deployToken, func(t *testing.T) {
+			username, token, err := ParseGitLabDeployToken(tt.deployToken)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("got error %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if username != tt.wantUsername || token != tt.wantToken {
+				t.Fatalf("got %q and %q, want %q and %q", username, token, tt.wantUsername, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestValidateImageReference(t *testing.T) {
+	tests := []struct {
+		image   string
+		wantErr string
+	}{
+		{"nginxinc/nginx-unprivileged:latest", ""},
+		{"registry.example.com:5000/mirror/nginx:1.21", ""},
+		{"quay.io/org/image@sha256:" + strings.Repeat("a", 64), ""},
+		{"nginx", ""},
+		{"Quay.io/Org/Image", `failed to parse image reference "Quay.io/Org/Image"`},
+		{"quay.io/org/image:not valid", `failed to parse image reference "quay.io/org/image:not valid"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			err := ValidateImageReference(tt.image)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImageName(t *testing.T) {
+	tests := map[string]string{
+		"nginxinc/nginx-unprivileged:latest":          "nginxinc/nginx-unprivileged",
+		"registry.example.com:5000/mirror/nginx:1.21": "registry.example.com:5000/mirror/nginx",
+		"registry.example.com:5000/mirror/nginx":      "registry.example.com:5000/mirror/nginx",
+		"quay.io/org/image@sha256:abc":                "quay.io/org/image",
+		"nginx":                                       "nginx",
+	}
+	for image, want := range tests {
+		if got := ImageName(image); got != want {
+			t.Errorf("ImageName(%q) got %q, want %q", image, got, want)
+		}
+	}
+}
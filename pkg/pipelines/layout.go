@@ -0,0 +1,168 @@
+package pipelines
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+	"github.com/redhat-developer/kam/pkg/pipelines/imagerepo"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	"github.com/redhat-developer/kam/pkg/pipelines/namespaces"
+	"github.com/redhat-developer/kam/pkg/pipelines/roles"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
+)
+
+// BootstrapLayout returns the paths of the files that Bootstrap would generate
+// with the options, without generating anything.
+//
+// The paths are relative to the GitOpsPath, and the secrets are in a sibling
+// of the GitOpsPath, e.g. "../secrets/gitops-webhook-secret.yaml".
+//
+// Bootstrap requires an access token, so the files for the access token are
+// always included.
+func BootstrapLayout(o *BootstrapOptions, appFs afero.Fs) ([]string, error) {
+	ns := namespaces.EnvironmentNamesWithPrefix(o.Prefix, o.EnvironmentNames())
+	appRepo, err := scm.NewRepository(o.ServiceRepoURL)
+	if err != nil {
+		return nil, err
+	}
+	repoName, err := repoFromURL(appRepo.URL())
+	if err != nil {
+		return nil, fmt.Errorf("invalid app repo URL: %v", err)
+	}
+	isInternalRegistry, imageRepo, err := imagerepo.ValidateImageRepo(imageRepoOrDefault(o, ns["cicd"], repoName))
+	if err != nil {
+		return nil, err
+	}
+	gitOpsRepo, err := scm.NewRepository(o.GitOpsRepoURL)
+	if err != nil {
+		return nil, err
+	}
+	secretName := secrets.MakeServiceWebhookSecretName(o.DevEnvironment(), repoName)
+	m, err := bootstrapManifest(o, appFs, appRepo, gitOpsRepo, secretName, ns)
+	if err != nil {
+		return nil, err
+	}
+	devEnv := m.GetEnvironment(o.DevEnvironment())
+	if devEnv == nil {
+		return nil, errors.New("unable to bootstrap without dev environment")
+	}
+	app := m.GetApplication(o.DevEnvironment(), repoToAppName(repoName))
+	if app == nil {
+		return nil, errors.New("unable to bootstrap without application")
+	}
+	cfg := m.GetPipelinesConfig()
+
+	paths := []string{pipelinesFile}
+	for _, f := range cicdLayout(gitOpsRepo, o) {
+		paths = append(paths, filepath.ToSlash(filepath.Join(pipelinesPath(m.Config), f)))
+	}
+	cicdKustomizations, err := getCICDKustomization(nil)
+	if err != nil {
+		return nil, err
+	}
+	for f := range cicdKustomizations {
+		paths = append(paths, filepath.ToSlash(filepath.Join(config.PathForPipelines(cfg), f)))
+	}
+	if !o.NoAppCI {
+		_, filename, _ := createSvcImageBinding(cfg, devEnv, app.Name, repoName, imageRepo, !isInternalRegistry)
+		paths = append(paths, makeImageBindingPath(cfg, filename))
+		if isInternalRegistry {
+			_, resources, err := imagerepo.CreateInternalRegistryResources(
+				cfg, roles.CreateServiceAccount(meta.NamespacedName(cfg.Name, serviceAccountName(o))),
+				imageRepo, o.GitOpsRepoURL, m.UseProjectRequests(), existingNamespaces(m, imageRepo)...)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get resources for internal image repository: %v", err)
+			}
+			for f := range resources {
+				paths = append(paths, f)
+			}
+		}
+	}
+	svcFiles, err := bootstrapServiceDeployment(devEnv, app, m.UsePerEnvOverlays(), bootstrapImage(o), bootstrapContainerResources(o), o.RouteHealthCheckPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bootstrap service: %w", err)
+	}
+	built, err := buildResources(ioutils.NewMemoryFilesystem(), m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build resources: %v", err)
+	}
+	for _, r := range []map[string]interface{}{svcFiles, built} {
+		for f := range r {
+			paths = append(paths, filepath.ToSlash(f))
+		}
+	}
+	if o.WriteChecksums {
+		paths = append(paths, ChecksumsFile)
+	}
+	for _, f := range secretsLayout(o, secretName) {
+		paths = append(paths, filepath.ToSlash(filepath.Join("..", "secrets", f)))
+		if o.SecretBackend == SecretBackendVault {
+			paths = append(paths, filepath.ToSlash(filepath.Join(pipelinesPath(m.Config), externalSecretsPath, f)))
+		}
+	}
+	return uniqueSorted(paths), nil
+}
+
+// cicdLayout returns the files generated in the base of the CI/CD
+// configuration.
+func cicdLayout(gitOpsRepo scm.Repository, o *BootstrapOptions) []string {
+	files := []string{rolebindingsPath, argocdAdminRolePath, serviceAccountPath,
+		commitStatusTaskPath, gitopsTasksPath, ciPipelinesPath, pushTemplatePath,
+		filepath.ToSlash(filepath.Join("05-bindings", o.PipelineNamePrefix+gitOpsRepo.PushBindingName()+".yaml")),
+		eventListenerPath, routePath}
+	if _, pullRequests := eventlisteners.DryRunEvents(gitOpsRepo, o.DryRunTrigger); pullRequests {
+		if name := gitOpsRepo.MergeRequestBindingName(); name != "" {
+			files = append(files, filepath.ToSlash(filepath.Join("05-bindings", o.PipelineNamePrefix+name+".yaml")))
+		}
+	}
+	if o.NamespacedInstall || o.ExistingClusterRole == "" {
+		files = append(files, rolesPath)
+	}
+	if !o.NamespacedInstall {
+		files = append(files, namespacesPath)
+	}
+	if !o.NoAppCI {
+		files = append(files, appCiPipelinesPath, appCIPushTemplatePath)
+	}
+	return files
+}
+
+// secretsLayout returns the files generated in the secrets folder.
+func secretsLayout(o *BootstrapOptions, serviceSecretName string) []string {
+	files := []string{"gitops-webhook-secret.yaml", serviceSecretName + ".yaml",
+		authTokenSecretName + ".yaml", basicAuthTokenName + ".yaml"}
+	if hasDockerConfig(o) {
+		files = append(files, "docker-config.yaml")
+	}
+	if o.WithPRPreviews {
+		files = append(files, prPreviewsTokenSecretName+".yaml")
+	}
+	if o.SecretBackend == SecretBackendSOPS {
+		for i, f := range files {
+			files[i] = strings.TrimSuffix(f, ".yaml") + encryptedSecretSuffix
+		}
+	}
+	return files
+}
+
+func uniqueSorted(paths []string) []string {
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, p := range paths {
+		if !seen[p] {
+			seen[p] = true
+			unique = append(unique, p)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
@@ -0,0 +1,96 @@
+package pipelines
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+func TestBootstrapLayoutMatchesBootstrap(t *testing.T) {
+	tests := []struct {
+		name    string
+		options func(*BootstrapOptions)
+	}{
+		{"default options", func(o *BootstrapOptions) {}},
+		{"external image repository", func(o *BootstrapOptions) {
+			o.ImageRepo = "quay.io/my-org/http-api"
+			o.DockerConfigJSONFilename = "/config.json"
+			o.SecretsRepoURL = "https://github.com/my-org/secrets.git"
+		}},
+		{"namespaced install without app-ci", func(o *BootstrapOptions) {
+			o.NamespacedInstall = true
+			o.NoAppCI = true
+		}},
+		{"existing cluster role", func(o *BootstrapOptions) {
+			o.ExistingClusterRole = "pipeline-runner"
+		}},
+		{"internal registry project", func(o *BootstrapOptions) {
+			o.InternalRegistryProject = "images"
+		}},
+		{"pipeline name prefix", func(o *BootstrapOptions) {
+			o.PipelineNamePrefix = "team-a-"
+		}},
+		{"vault secret backend", func(o *BootstrapOptions) {
+			o.SecretBackend = SecretBackendVault
+			o.VaultSecretStore = "vault-backend"
+		}},
+		{"custom environments", func(o *BootstrapOptions) {
+			o.Environments = "sandbox,integration,prod"
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(rt *testing.T) {
+			fakeFs := ioutils.NewMemoryFilesystem()
+			fatalIfError(rt, afero.WriteFile(fakeFs, "/config.json", []byte(`{"auths":{"quay.io":{}}}`), 0644))
+			outputPath := filepath.Join("/", "out", "gitops")
+			o := &BootstrapOptions{
+				Prefix:               "tst-",
+				GitOpsRepoURL:        testGitOpsRepo,
+				GitOpsWebhookSecret:  "123",
+				GitHostAccessToken:   "test-token",
+				ServiceRepoURL:       testSvcRepo,
+				ServiceWebhookSecret: "456",
+				OutputPath:           outputPath,
+			}
+			tt.options(o)
+
+			layout, err := BootstrapLayout(o, fakeFs)
+			fatalIfError(rt, err)
+			fatalIfError(rt, Bootstrap(o, fakeFs))
+
+			generated := []string{}
+			err = afero.Walk(fakeFs, filepath.Dir(outputPath), func(p string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return err
+				}
+				rel, err := filepath.Rel(outputPath, p)
+				generated = append(generated, filepath.ToSlash(rel))
+				return err
+			})
+			fatalIfError(rt, err)
+			sort.Strings(generated)
+			if diff := cmp.Diff(generated, layout); diff != "" {
+				rt.Fatalf("layout didn't match the generated files:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSecretsLayoutWithSOPS(t *testing.T) {
+	got := secretsLayout(&BootstrapOptions{SecretBackend: SecretBackendSOPS}, "webhook-secret-tst-dev-http-api")
+	want := []string{
+		"gitops-webhook-secret.enc.yaml",
+		"webhook-secret-tst-dev-http-api.enc.yaml",
+		"git-host-access-token.enc.yaml",
+		"git-host-basic-auth-token.enc.yaml",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("secrets layout didn't match:\n%s", diff)
+	}
+}
@@ -1,7 +1,11 @@
 package meta
 
 import (
+	"reflect"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -51,6 +55,51 @@ func AddAnnotations(l map[string]string) ObjectMetaOpt {
 	}
 }
 
+// AnnotateObject additively applies the provided annotations to an already
+// created object, and returns the annotated object.
+//
+// Objects that are not pointers are annotated as a copy, so the returned
+// object must be used in their place. Unstructured objects, e.g. the
+// map[string]interface{} of a Route, are annotated if they have metadata.
+// Objects without ObjectMeta, e.g. a Kustomization, are returned unchanged.
+func AnnotateObject(obj interface{}, l map[string]string) interface{} {
+	if m, ok := obj.(map[string]interface{}); ok {
+		if _, ok := m["metadata"].(map[string]interface{}); !ok {
+			return obj
+		}
+		u := &unstructured.Unstructured{Object: m}
+		u.SetAnnotations(mergeAnnotations(u.GetAnnotations(), l))
+		return m
+	}
+	if o, err := apimeta.Accessor(obj); err == nil {
+		o.SetAnnotations(mergeAnnotations(o.GetAnnotations(), l))
+		return obj
+	}
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Struct {
+		return obj
+	}
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	o, err := apimeta.Accessor(p.Interface())
+	if err != nil {
+		return obj
+	}
+	o.SetAnnotations(mergeAnnotations(o.GetAnnotations(), l))
+	return p.Elem().Interface()
+}
+
+func mergeAnnotations(existing, l map[string]string) map[string]string {
+	merged := map[string]string{}
+	for k, v := range existing {
+		merged[k] = v
+	}
+	for k, v := range l {
+		merged[k] = v
+	}
+	return merged
+}
+
 // ObjectMetaOpt is a function that can change a newly created meta.ObjectMeta
 // when it's being created.
 type ObjectMetaOpt func(om *metav1.ObjectMeta)
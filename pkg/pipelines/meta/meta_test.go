@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -56,3 +57,48 @@ func TestAnnotations(t *testing.T) {
 		t.Fatalf("failed to add labels:\n%s", diff)
 	}
 }
+
+func TestAnnotateObject(t *testing.T) {
+	annotations := map[string]string{"app": "my-app"}
+	existing := map[string]string{"my-annotation": "my-value"}
+	want := map[string]string{"my-annotation": "my-value", "app": "my-app"}
+
+	ptr := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns", Annotations: existing}}
+	if got := AnnotateObject(ptr, annotations).(*corev1.Namespace); got != ptr {
+		t.Fatal("pointer was not annotated in place")
+	}
+	if diff := cmp.Diff(want, ptr.Annotations); diff != "" {
+		t.Errorf("failed to annotate pointer:\n%s", diff)
+	}
+
+	value := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns", Annotations: existing}}
+	got := AnnotateObject(value, annotations).(corev1.Namespace)
+	if diff := cmp.Diff(want, got.Annotations); diff != "" {
+		t.Errorf("failed to annotate value:\n%s", diff)
+	}
+	if diff := cmp.Diff(existing, value.Annotations); diff != "" {
+		t.Errorf("original value was modified:\n%s", diff)
+	}
+
+	unstructured := map[string]interface{}{
+		"kind":     "Route",
+		"metadata": map[string]interface{}{"name": "route"},
+	}
+	AnnotateObject(unstructured, annotations)
+	wantUnstructured := map[string]interface{}{
+		"kind": "Route",
+		"metadata": map[string]interface{}{
+			"name":        "route",
+			"annotations": map[string]interface{}{"app": "my-app"},
+		},
+	}
+	if diff := cmp.Diff(wantUnstructured, unstructured); diff != "" {
+		t.Errorf("failed to annotate unstructured object:\n%s", diff)
+	}
+
+	type kustomization struct{ Resources []string }
+	k := kustomization{Resources: []string{"a.yaml"}}
+	if diff := cmp.Diff(k, AnnotateObject(k, annotations)); diff != "" {
+		t.Errorf("object without metadata was modified:\n%s", diff)
+	}
+}
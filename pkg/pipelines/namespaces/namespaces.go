@@ -3,7 +3,9 @@ package namespaces
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	projectv1 "github.com/openshift/api/project/v1"
 	"github.com/redhat-developer/kam/pkg/pipelines/clientconfig"
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
 	corev1 "k8s.io/api/core/v1"
@@ -15,14 +17,16 @@ const (
 	vcsURIAnnotation = "app.openshift.io/vcs-uri"
 )
 
-var (
-	namespaceBaseNames = map[string]string{
-		"dev":   "dev",
-		"stage": "stage",
-		"cicd":  "cicd",
-	}
+// CICDName is the name of the CI/CD namespace without the prefix.
+const CICDName = "cicd"
 
-	namespaceTypeMeta = meta.TypeMeta("Namespace", "v1")
+// DefaultEnvironments are the environments that are bootstrapped if none are
+// provided, the first is the dev environment.
+var DefaultEnvironments = []string{"dev", "stage"}
+
+var (
+	namespaceTypeMeta      = meta.TypeMeta("Namespace", "v1")
+	projectRequestTypeMeta = meta.TypeMeta("ProjectRequest", "project.openshift.io/v1")
 )
 
 // Namespaces create namespaces for the given names.
@@ -37,13 +41,57 @@ func Namespaces(names []string, gitOpsRepoURL string) []*corev1.Namespace {
 // NamesWithPrefix returns namespaces of all environments based on the prefix,
 // and using the set of predefined names: dev, stage, cicd.
 func NamesWithPrefix(prefix string) map[string]string {
-	prefixedNames := make(map[string]string)
-	for k, v := range namespaceBaseNames {
-		prefixedNames[k] = fmt.Sprintf("%s%s", prefix, v)
+	return EnvironmentNamesWithPrefix(prefix, DefaultEnvironments)
+}
+
+// EnvironmentNamesWithPrefix returns the namespaces of the environments and
+// the cicd namespace based on the prefix, keyed by their names without the
+// prefix.
+func EnvironmentNamesWithPrefix(prefix string, envs []string) map[string]string {
+	prefixedNames := map[string]string{CICDName: prefix + CICDName}
+	for _, v := range envs {
+		prefixedNames[v] = fmt.Sprintf("%s%s", prefix, v)
 	}
 	return prefixedNames
 }
 
+// SplitEnvironments returns the names in a comma separated list of
+// environments, or the DefaultEnvironments if the list is empty.
+func SplitEnvironments(list string) []string {
+	envs := []string{}
+	for _, env := range strings.Split(list, ",") {
+		if env = strings.TrimSpace(env); env != "" {
+			envs = append(envs, env)
+		}
+	}
+	if len(envs) == 0 {
+		return DefaultEnvironments
+	}
+	return envs
+}
+
+// Generate creates a ProjectRequest for the name if useProjectRequests is true,
+// or a Namespace otherwise.
+func Generate(name, gitOpsRepoURL string, useProjectRequests bool) interface{} {
+	if useProjectRequests {
+		return CreateProjectRequest(name, gitOpsRepoURL)
+	}
+	return Create(name, gitOpsRepoURL)
+}
+
+// CreateProjectRequest creates a ProjectRequest value from a string, with the
+// same metadata as Create.
+//
+// ProjectRequests can be used on OpenShift clusters where users are allowed to
+// provision projects but not to create Namespaces.
+func CreateProjectRequest(name, gitOpsRepoURL string) *projectv1.ProjectRequest {
+	ns := Create(name, gitOpsRepoURL)
+	return &projectv1.ProjectRequest{
+		TypeMeta:   projectRequestTypeMeta,
+		ObjectMeta: ns.ObjectMeta,
+	}
+}
+
 // Create creates a Namespace value from a string.
 func Create(name, gitOpsRepoURL string) *corev1.Namespace {
 	ns := &corev1.Namespace{
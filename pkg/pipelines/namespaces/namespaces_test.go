@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	projectv1 "github.com/openshift/api/project/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	testclient "k8s.io/client-go/kubernetes/fake"
@@ -28,6 +29,32 @@ func TestCreate(t *testing.T) {
 	}
 }
 
+func TestCreateProjectRequest(t *testing.T) {
+	pr := CreateProjectRequest("test-environment", testGitOpsRepoURL)
+	want := &projectv1.ProjectRequest{
+		TypeMeta: projectRequestTypeMeta,
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-environment",
+			Annotations: map[string]string{
+				vcsURIAnnotation: testGitOpsRepoURL + "?ref=HEAD",
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, pr); diff != "" {
+		t.Fatalf("CreateProjectRequest() failed got\n%s", diff)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	if diff := cmp.Diff(Create("test-environment", testGitOpsRepoURL), Generate("test-environment", testGitOpsRepoURL, false)); diff != "" {
+		t.Fatalf("Generate() without project requests failed got\n%s", diff)
+	}
+	if diff := cmp.Diff(CreateProjectRequest("test-environment", testGitOpsRepoURL), Generate("test-environment", testGitOpsRepoURL, true)); diff != "" {
+		t.Fatalf("Generate() with project requests failed got\n%s", diff)
+	}
+}
+
 func TestNamesWithPrefix(t *testing.T) {
 	ns := NamesWithPrefix("test-")
 	want := map[string]string{
@@ -40,6 +67,35 @@ func TestNamesWithPrefix(t *testing.T) {
 	}
 }
 
+func TestEnvironmentNamesWithPrefix(t *testing.T) {
+	ns := EnvironmentNamesWithPrefix("test-", []string{"sandbox", "integration"})
+	want := map[string]string{
+		"sandbox":     "test-sandbox",
+		"integration": "test-integration",
+		"cicd":        "test-cicd",
+	}
+	if diff := cmp.Diff(want, ns); diff != "" {
+		t.Fatalf("EnvironmentNamesWithPrefix() failed got\n%s", diff)
+	}
+}
+
+func TestSplitEnvironments(t *testing.T) {
+	splitTests := []struct {
+		list string
+		want []string
+	}{
+		{"", []string{"dev", "stage"}},
+		{" , ", []string{"dev", "stage"}},
+		{"sandbox", []string{"sandbox"}},
+		{"sandbox, integration,prod", []string{"sandbox", "integration", "prod"}},
+	}
+	for _, tt := range splitTests {
+		if diff := cmp.Diff(tt.want, SplitEnvironments(tt.list)); diff != "" {
+			t.Errorf("SplitEnvironments(%q) failed got\n%s", tt.list, diff)
+		}
+	}
+}
+
 func TestNamespaces(t *testing.T) {
 	ns := Namespaces([]string{
 		"test-dev",
@@ -0,0 +1,184 @@
+// Package openapi generates the OpenAPI schemas that kustomize uses to merge
+// strategic-merge patches to the generated resources.
+//
+// Kustomize's built-in schema only describes the Kubernetes kinds, so patches
+// to lists in custom resources, e.g. the triggers of an EventListener, replace
+// the list rather than merging into it.  A kustomization can reference a
+// custom schema instead, but it replaces the built-in schema, so the schemas
+// generated here include the definitions of the built-in kinds alongside the
+// custom resources.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/openapi/kubernetesapi"
+)
+
+// Filename is the name of the schema that is generated alongside a
+// kustomization.yaml.
+const Filename = "openapi.json"
+
+const (
+	gvkExtension           = "x-kubernetes-group-version-kind"
+	patchMergeKeyExtension = "x-kubernetes-patch-merge-key"
+	patchStrategyExtension = "x-kubernetes-patch-strategy"
+
+	objectMetaRef = "#/definitions/io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"
+	definitionRef = "#/definitions/"
+)
+
+// EventListener is the definition of the Tekton Triggers EventListener, its
+// triggers are merged by name.
+var EventListener = Definition{
+	Name:    "dev.tekton.triggers.v1alpha1.EventListener",
+	Group:   "triggers.tekton.dev",
+	Version: "v1alpha1",
+	Kind:    "EventListener",
+	Spec: object(map[string]interface{}{
+		"triggers": mergedList("name", object(map[string]interface{}{
+			"bindings":     list(object(nil)),
+			"interceptors": list(object(nil)),
+		})),
+	}),
+}
+
+// Application is the definition of the Argo CD Application, its Helm
+// parameters and info are merged by name, and its sync options are merged as
+// a set.
+var Application = Definition{
+	Name:    "io.argoproj.v1alpha1.Application",
+	Group:   "argoproj.io",
+	Version: "v1alpha1",
+	Kind:    "Application",
+	Spec: object(map[string]interface{}{
+		"source": object(map[string]interface{}{
+			"helm": object(map[string]interface{}{
+				"parameters":     mergedList("name", object(nil)),
+				"fileParameters": mergedList("name", object(nil)),
+			}),
+		}),
+		"info": mergedList("name", object(nil)),
+		"syncPolicy": object(map[string]interface{}{
+			"syncOptions": mergedSet(),
+		}),
+	}),
+}
+
+// Definition describes a custom resource for kustomize, only the fields that
+// need patch strategies are described.
+type Definition struct {
+	Name    string
+	Group   string
+	Version string
+	Kind    string
+	Spec    map[string]interface{}
+}
+
+func (d Definition) schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"apiVersion": map[string]interface{}{"type": "string"},
+			"kind":       map[string]interface{}{"type": "string"},
+			"metadata":   map[string]interface{}{"$ref": objectMetaRef},
+			"spec":       d.Spec,
+		},
+		gvkExtension: []interface{}{
+			map[string]interface{}{"group": d.Group, "version": d.Version, "kind": d.Kind},
+		},
+	}
+}
+
+// Schema returns a schema with the custom resource definitions, and the
+// definitions of the built-in kinds e.g. io.k8s.api.core.v1.ServiceAccount,
+// along with the definitions that they refer to.
+func Schema(builtins []string, definitions ...Definition) (map[string]interface{}, error) {
+	var swagger struct {
+		Definitions map[string]interface{} `json:"definitions"`
+	}
+	if err := json.Unmarshal(kubernetesapi.OpenAPIMustAsset[kubernetesapi.DefaultOpenAPI](builtinSchemaAsset()), &swagger); err != nil {
+		return nil, fmt.Errorf("failed to parse the built-in schema: %w", err)
+	}
+	defs := map[string]interface{}{}
+	pending := append([]string{}, builtins...)
+	for _, d := range definitions {
+		defs[d.Name] = d.schema()
+		pending = append(pending, refs(d.Spec)...)
+	}
+	pending = append(pending, strings.TrimPrefix(objectMetaRef, definitionRef))
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		if _, ok := defs[name]; ok {
+			continue
+		}
+		def, ok := swagger.Definitions[name]
+		if !ok {
+			return nil, fmt.Errorf("failed to find %s in the built-in schema", name)
+		}
+		defs[name] = def
+		pending = append(pending, refs(def)...)
+	}
+	return map[string]interface{}{
+		"swagger":     "2.0",
+		"info":        map[string]interface{}{"title": "kam", "version": "v1"},
+		"paths":       map[string]interface{}{},
+		"definitions": defs,
+	}, nil
+}
+
+// builtinSchemaAsset returns the name of the asset of the default built-in
+// schema e.g. kubernetesapi/v1204/swagger.json.
+func builtinSchemaAsset() string {
+	return "kubernetesapi/" + kubernetesapi.DefaultOpenAPI + "/swagger.json"
+}
+
+// refs returns the names of the definitions referred to in a schema.
+func refs(v interface{}) []string {
+	found := []string{}
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			if ref, ok := child.(string); ok && k == "$ref" {
+				found = append(found, strings.TrimPrefix(ref, definitionRef))
+				continue
+			}
+			found = append(found, refs(child)...)
+		}
+	case []interface{}:
+		for _, child := range t {
+			found = append(found, refs(child)...)
+		}
+	}
+	sort.Strings(found)
+	return found
+}
+
+func object(properties map[string]interface{}) map[string]interface{} {
+	o := map[string]interface{}{"type": "object"}
+	if properties != nil {
+		o["properties"] = properties
+	}
+	return o
+}
+
+func list(items map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": "array", "items": items}
+}
+
+func mergedList(key string, items map[string]interface{}) map[string]interface{} {
+	l := list(items)
+	l[patchMergeKeyExtension] = key
+	l[patchStrategyExtension] = "merge"
+	return l
+}
+
+func mergedSet() map[string]interface{} {
+	l := list(map[string]interface{}{"type": "string"})
+	l[patchStrategyExtension] = "merge"
+	return l
+}
@@ -0,0 +1,142 @@
+package openapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+)
+
+func TestSchemaIncludesReferencedDefinitions(t *testing.T) {
+	s, err := Schema([]string{"io.k8s.api.core.v1.ServiceAccount"}, EventListener)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defs := s["definitions"].(map[string]interface{})
+	for _, name := range []string{
+		EventListener.Name,
+		"io.k8s.api.core.v1.ServiceAccount",
+		"io.k8s.api.core.v1.ObjectReference",
+		"io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta",
+	} {
+		if _, ok := defs[name]; !ok {
+			t.Errorf("schema is missing %s", name)
+		}
+	}
+}
+
+func TestSchemaWithUnknownBuiltin(t *testing.T) {
+	_, err := Schema([]string{"io.k8s.api.core.v1.Unknown"})
+	if err == nil || !strings.Contains(err.Error(), "failed to find io.k8s.api.core.v1.Unknown in the built-in schema") {
+		t.Fatalf("got error %v", err)
+	}
+}
+
+func TestSchemaMergesPatches(t *testing.T) {
+	s, err := Schema([]string{"io.k8s.api.core.v1.ServiceAccount"}, EventListener, Application)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := filesys.MakeFsInMemory()
+	files := map[string]string{
+		"/base/kustomization.yaml": "resources:\n- resources.yaml\n",
+		"/base/resources.yaml": `apiVersion: triggers.tekton.dev/v1alpha1
+kind: EventListener
+metadata:
+  name: cicd-event-listener
+spec:
+  triggers:
+  - name: ci-dryrun-from-push
+    template:
+      name: ci-dryrun-from-push-template
+  - name: app-ci-build-from-push
+    template:
+      name: app-ci-template
+---
+apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: dev-app
+spec:
+  syncPolicy:
+    syncOptions:
+    - CreateNamespace=true
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: pipeline
+secrets:
+- name: regcred
+`,
+		"/overlays/kustomization.yaml": `bases:
+- ../base
+openapi:
+  path: openapi.json
+patchesStrategicMerge:
+- patch.yaml
+`,
+		"/overlays/patch.yaml": `apiVersion: triggers.tekton.dev/v1alpha1
+kind: EventListener
+metadata:
+  name: cicd-event-listener
+spec:
+  triggers:
+  - name: app-ci-build-from-push
+    template:
+      name: custom-template
+---
+apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: dev-app
+spec:
+  syncPolicy:
+    syncOptions:
+    - PruneLast=true
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: pipeline
+secrets:
+- name: extra
+`,
+		"/overlays/" + Filename: string(b),
+	}
+	for name, content := range files {
+		if err := fs.WriteFile(name, []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer openapi.ResetOpenAPI()
+	openapi.ResetOpenAPI()
+
+	m, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fs, "/overlays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := m.AsYaml()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"name: ci-dryrun-from-push-template",
+		"name: custom-template",
+		"- CreateNamespace=true",
+		"- PruneLast=true",
+		"- name: regcred",
+		"- name: extra",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("patched resources are missing %q:\n%s", want, out)
+		}
+	}
+}
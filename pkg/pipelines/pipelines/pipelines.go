@@ -18,8 +18,41 @@ var (
 
 const pipelineWorkspace = "shared-data"
 
+// BuildOption configures the build-image task of the AppCIPipeline.
+type BuildOption func(*pipelinev1.PipelineTask)
+
+// BuilderImage sets the image that the buildah task builds with, instead of
+// the default of the ClusterTask.
+func BuilderImage(image string) BuildOption {
+	return func(t *pipelinev1.PipelineTask) {
+		t.Params = append(t.Params, createTaskParam("BUILDER_IMAGE", image))
+	}
+}
+
+// BuildArgs passes the KEY=value args to the build as --build-arg flags.
+//
+// The values are single-quoted in the buildah command, so they must not
+// contain single quotes.
+func BuildArgs(args ...string) BuildOption {
+	return func(t *pipelinev1.PipelineTask) {
+		for i, p := range t.Params {
+			if p.Name != "BUILD_EXTRA_ARGS" {
+				continue
+			}
+			for _, arg := range args {
+				p.Value.StringVal += fmt.Sprintf(" --build-arg='%s'", arg)
+			}
+			t.Params[i] = p
+		}
+	}
+}
+
 // CreateAppCIPipeline creates AppCIPipeline
-func CreateAppCIPipeline(name types.NamespacedName) *pipelinev1.Pipeline {
+func CreateAppCIPipeline(name types.NamespacedName, opts ...BuildOption) *pipelinev1.Pipeline {
+	buildTask := createBuildImageTask("build-image", "clone-source")
+	for _, o := range opts {
+		o(&buildTask)
+	}
 	return &pipelinev1.Pipeline{
 		TypeMeta:   pipelineTypeMeta,
 		ObjectMeta: meta.ObjectMeta(name),
@@ -38,7 +71,7 @@ func CreateAppCIPipeline(name types.NamespacedName) *pipelinev1.Pipeline {
 			Tasks: []pipelinev1.PipelineTask{
 				createCommitStatusPipelineTask("set-pending-status", "pending", "The build has started"),
 				createGitCloneTask("clone-source"),
-				createBuildImageTask("build-image", "clone-source"),
+				buildTask,
 			},
 			Workspaces: []pipelinev1.PipelineWorkspaceDeclaration{
 				{Name: pipelineWorkspace, Description: "This workspace will receive the cloned git repo."},
@@ -67,6 +100,10 @@ func createBuildImageTask(name, runAfter string) pipelinev1.PipelineTask {
 }
 
 func createGitCloneTask(name string) pipelinev1.PipelineTask {
+	return createGitCloneTaskForRevision(name, "$(params.GIT_REF)")
+}
+
+func createGitCloneTaskForRevision(name, revision string) pipelinev1.PipelineTask {
 	// The output workspace mapping here comes from the git-clone task.
 	return pipelinev1.PipelineTask{
 		Name:    name,
@@ -76,7 +113,7 @@ func createGitCloneTask(name string) pipelinev1.PipelineTask {
 		},
 		Params: []pipelinev1.Param{
 			createTaskParam("url", "$(params.GIT_REPO)"),
-			createTaskParam("revision", "$(params.GIT_REF)"),
+			createTaskParam("revision", revision),
 		},
 		RunAfter: []string{"set-pending-status"},
 	}
@@ -123,7 +160,31 @@ func CreateCIPipeline(name types.NamespacedName, stageNamespace string) *pipelin
 				createCommitStatusPipelineTask("set-pending-status", "pending", "The build has started"),
 				createCIPipelineTask("apply-source"),
 			},
-			Params: paramSpecs("REPO", "COMMIT_SHA", "GIT_REPO"),
+			Params: append(paramSpecs("REPO", "COMMIT_SHA", "GIT_REPO"), paramSpecDefault("ENVIRONMENTS", "")),
+			Finally: []pipelinev1.PipelineTask{
+				createCommitStatusPipelineTask("set-final-status", "$(tasks.apply-source.status)", "The build is complete"),
+			},
+		},
+	}
+}
+
+// CreateCIWorkspacePipeline creates a CI pipeline that clones the source into
+// a workspace rather than using a git PipelineResource, PipelineResources are
+// not available in tekton.dev/v1.
+func CreateCIWorkspacePipeline(name types.NamespacedName, stageNamespace string) *pipelinev1.Pipeline {
+	return &pipelinev1.Pipeline{
+		TypeMeta:   pipelineTypeMeta,
+		ObjectMeta: meta.ObjectMeta(name),
+		Spec: pipelinev1.PipelineSpec{
+			Params: append(paramSpecs("REPO", "COMMIT_SHA", "GIT_REPO"), paramSpecDefault("ENVIRONMENTS", "")),
+			Tasks: []pipelinev1.PipelineTask{
+				createCommitStatusPipelineTask("set-pending-status", "pending", "The build has started"),
+				createGitCloneTaskForRevision("clone-source", "$(params.COMMIT_SHA)"),
+				createCIWorkspacePipelineTask("apply-source", "clone-source"),
+			},
+			Workspaces: []pipelinev1.PipelineWorkspaceDeclaration{
+				{Name: pipelineWorkspace, Description: "This workspace will receive the cloned git repo."},
+			},
 			Finally: []pipelinev1.PipelineTask{
 				createCommitStatusPipelineTask("set-final-status", "$(tasks.apply-source.status)", "The build is complete"),
 			},
@@ -158,11 +219,27 @@ func createCIPipelineTask(taskName string) pipelinev1.PipelineTask {
 		},
 		Params: []pipelinev1.Param{
 			createTaskParam("DRYRUN", "true"),
+			createTaskParam("ENVIRONMENTS", "$(params.ENVIRONMENTS)"),
 		},
 		RunAfter: []string{"set-pending-status"},
 	}
 }
 
+func createCIWorkspacePipelineTask(taskName, runAfter string) pipelinev1.PipelineTask {
+	return pipelinev1.PipelineTask{
+		Name:    taskName,
+		TaskRef: createTaskRef("deploy-from-source-task", pipelinev1.NamespacedTaskKind),
+		Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+			{Name: "source", Workspace: pipelineWorkspace},
+		},
+		Params: []pipelinev1.Param{
+			createTaskParam("DRYRUN", "true"),
+			createTaskParam("ENVIRONMENTS", "$(params.ENVIRONMENTS)"),
+		},
+		RunAfter: []string{runAfter},
+	}
+}
+
 func createDevCDDeployImageTask(name, devNamespace, deploymentPath string) pipelinev1.PipelineTask {
 	return pipelinev1.PipelineTask{
 		Name:     name,
@@ -269,3 +346,9 @@ func paramSpecs(s ...string) []pipelinev1.ParamSpec {
 func paramSpec(name string) pipelinev1.ParamSpec {
 	return pipelinev1.ParamSpec{Name: name, Type: "string"}
 }
+
+func paramSpecDefault(name, value string) pipelinev1.ParamSpec {
+	spec := paramSpec(name)
+	spec.Default = &pipelinev1.ArrayOrString{Type: pipelinev1.ParamTypeString, StringVal: value}
+	return spec
+}
@@ -117,3 +117,71 @@ func TestCreateAppCIPipeline(t *testing.T) {
 		t.Fatalf("CreateAppCIPipeline failed:\n%s", diff)
 	}
 }
+
+func TestCreateAppCIPipelineWithBuildOptions(t *testing.T) {
+	name := types.NamespacedName{Name: "test-pipeline", Namespace: "test-ns"}
+	p := CreateAppCIPipeline(name,
+		BuilderImage("registry.example.com/mirror/buildah:v1"),
+		BuildArgs("HTTP_PROXY=http://proxy.example.com:3128", "BASE_IMAGE=registry.example.com/ubi8"))
+
+	want := []pipelinev1.Param{
+		createTaskParam("TLSVERIFY", "$(params.TLSVERIFY)"),
+		createTaskParam("BUILD_EXTRA_ARGS", metadataLabelArgs()+
+			" --build-arg='HTTP_PROXY=http://proxy.example.com:3128' --build-arg='BASE_IMAGE=registry.example.com/ubi8'"),
+		createTaskParam("IMAGE", "$(params.IMAGE)"),
+		createTaskParam("BUILDER_IMAGE", "registry.example.com/mirror/buildah:v1"),
+	}
+	if diff := cmp.Diff(want, p.Spec.Tasks[2].Params); diff != "" {
+		t.Fatalf("build-image task params didn't match:\n%s", diff)
+	}
+}
+
+func TestCreateCIWorkspacePipeline(t *testing.T) {
+	name := types.NamespacedName{Name: "test-pipeline", Namespace: "test-ns"}
+	p := CreateCIWorkspacePipeline(name, "test-ns")
+
+	want := &pipelinev1.Pipeline{
+		TypeMeta:   pipelineTypeMeta,
+		ObjectMeta: meta.ObjectMeta(name),
+		Spec: pipelinev1.PipelineSpec{
+			Params: append(paramSpecs("REPO", "COMMIT_SHA", "GIT_REPO"), paramSpecDefault("ENVIRONMENTS", "")),
+			Workspaces: []pipelinev1.PipelineWorkspaceDeclaration{
+				{Name: pipelineWorkspace, Description: "This workspace will receive the cloned git repo."},
+			},
+			Tasks: []pipelinev1.PipelineTask{
+				createCommitStatusPipelineTask("set-pending-status", "pending", "The build has started"),
+				{
+					Name:    "clone-source",
+					TaskRef: &pipelinev1.TaskRef{Name: "git-clone", Kind: "ClusterTask"},
+					Params: []pipelinev1.Param{
+						createTaskParam("url", "$(params.GIT_REPO)"),
+						createTaskParam("revision", "$(params.COMMIT_SHA)"),
+					},
+					Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+						{Name: "output", Workspace: pipelineWorkspace},
+					},
+					RunAfter: []string{"set-pending-status"},
+				},
+				{
+					Name:     "apply-source",
+					RunAfter: []string{"clone-source"},
+					TaskRef:  &pipelinev1.TaskRef{Name: "deploy-from-source-task", Kind: "Task"},
+					Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+						{Name: "source", Workspace: pipelineWorkspace},
+					},
+					Params: []pipelinev1.Param{
+						createTaskParam("DRYRUN", "true"),
+						createTaskParam("ENVIRONMENTS", "$(params.ENVIRONMENTS)"),
+					},
+				},
+			},
+			Finally: []v1beta1.PipelineTask{
+				createCommitStatusPipelineTask("set-final-status", "$(tasks.apply-source.status)", "The build is complete"),
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, p); diff != "" {
+		t.Fatalf("CreateCIWorkspacePipeline failed:\n%s", diff)
+	}
+}
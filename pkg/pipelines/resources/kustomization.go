@@ -4,15 +4,95 @@ import "sort"
 
 // Kustomization is a structural representation of the Kustomize file format.
 type Kustomization struct {
-	Resources    []string          `json:"resources,omitempty"`
-	Bases        []string          `json:"bases,omitempty"`
-	CommonLabels map[string]string `json:"commonLabels,omitempty"`
+	Resources          []string          `json:"resources,omitempty"`
+	Bases              []string          `json:"bases,omitempty"`
+	Namespace          string            `json:"namespace,omitempty"`
+	CommonLabels       map[string]string `json:"commonLabels,omitempty"`
+	Images             []ImageTransform  `json:"images,omitempty"`
+	OpenAPI            *OpenAPI          `json:"openapi,omitempty"`
+	ConfigMapGenerator []ConfigMapArgs   `json:"configMapGenerator,omitempty"`
+	SecretGenerator    []SecretArgs      `json:"secretGenerator,omitempty"`
 }
 
+// ConfigMapArgs is a Kustomize ConfigMap generator, it generates a ConfigMap
+// from literal key=value pairs and the contents of files.
+type ConfigMapArgs struct {
+	Name     string   `json:"name"`
+	Literals []string `json:"literals,omitempty"`
+	Files    []string `json:"files,omitempty"`
+}
+
+// OpenAPI is the schema that Kustomize uses to merge patches, instead of the
+// built-in schema of the Kubernetes kinds.
+type OpenAPI struct {
+	Path string `json:"path"`
+}
+
+// ImageTransform is a Kustomize image transform, it overrides the name and tag
+// or digest of matching container images, the Digest takes precedence over the
+// NewTag.
+type ImageTransform struct {
+	Name    string `json:"name"`
+	NewName string `json:"newName,omitempty"`
+	NewTag  string `json:"newTag,omitempty"`
+	Digest  string `json:"digest,omitempty"`
+}
+
+// ImageTag is the name that the image bindings were first generated with, it's
+// the same type as the ImageTransform.
+type ImageTag = ImageTransform
+
 func (k *Kustomization) AddResources(s ...string) {
 	k.Resources = removeDuplicatesAndSort(append(k.Resources, s...))
 }
 
+// SecretArgs is a Kustomize Secret generator, it generates a Secret of the
+// Type, e.g. kubernetes.io/tls, from literal key=value pairs and the contents
+// of files, the Type defaults to Opaque.
+type SecretArgs struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type,omitempty"`
+	Literals []string `json:"literals,omitempty"`
+	Files    []string `json:"files,omitempty"`
+}
+
+// AddImage pins the tag of the images with the name, replacing the tag or
+// digest of any existing transform for the name.
+func (k *Kustomization) AddImage(name, newTag string) {
+	for i, img := range k.Images {
+		if img.Name == name {
+			k.Images[i].NewTag = newTag
+			k.Images[i].Digest = ""
+			return
+		}
+	}
+	k.Images = append(k.Images, ImageTransform{Name: name, NewTag: newTag})
+}
+
+// AddConfigMapGenerator adds a ConfigMap generator, replacing any existing
+// generator with the same name.
+func (k *Kustomization) AddConfigMapGenerator(args ConfigMapArgs) {
+	for i, g := range k.ConfigMapGenerator {
+		if g.Name == args.Name {
+			k.ConfigMapGenerator[i] = args
+			return
+		}
+	}
+	k.ConfigMapGenerator = append(k.ConfigMapGenerator, args)
+}
+
+// AddSecretGenerator adds a Secret generator, replacing any existing generator
+// with the same name.
+func (k *Kustomization) AddSecretGenerator(args SecretArgs) {
+	for i, g := range k.SecretGenerator {
+		if g.Name == args.Name {
+			k.SecretGenerator[i] = args
+			return
+		}
+	}
+	k.SecretGenerator = append(k.SecretGenerator, args)
+}
+
 func removeDuplicatesAndSort(s []string) []string {
 	exists := make(map[string]bool)
 	out := []string{}
@@ -1,9 +1,16 @@
 package resources
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/yaml"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	res "github.com/redhat-developer/kam/pkg/pipelines/yaml"
 )
 
 func Test_AddResource(t *testing.T) {
@@ -33,3 +40,210 @@ func Test_AddResource_sorts_elements(t *testing.T) {
 		t.Fatalf("failed to sort resources:\n%s", diff)
 	}
 }
+
+func TestKustomizationImagesSerialization(t *testing.T) {
+	k := Kustomization{
+		Bases: []string{"../base"},
+		Images: []ImageTransform{
+			{Name: "quay.io/example/http-svc", NewTag: "v1.2.0"},
+			{Name: "worker", NewName: "quay.io/example/worker", NewTag: "v0.3.1"},
+			{Name: "sidecar", Digest: "sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3"},
+		},
+	}
+	b, err := yaml.Marshal(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `bases:
+- ../base
+images:
+- name: quay.io/example/http-svc
+  newTag: v1.2.0
+- name: worker
+  newName: quay.io/example/worker
+  newTag: v0.3.1
+- digest: sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3
+  name: sidecar
+`
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Fatalf("failed to marshal images:\n%s", diff)
+	}
+
+	var got Kustomization
+	if err := yaml.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(k, got); diff != "" {
+		t.Fatalf("failed to unmarshal images:\n%s", diff)
+	}
+}
+
+func TestAddImage(t *testing.T) {
+	k := Kustomization{
+		Images: []ImageTransform{
+			{Name: "worker", NewName: "quay.io/example/worker", Digest: "sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3"},
+		},
+	}
+	k.AddImage("quay.io/example/http-svc", "v1.2.0")
+	k.AddImage("worker", "v0.3.1")
+	k.AddImage("quay.io/example/http-svc", "v1.3.0")
+
+	want := []ImageTransform{
+		{Name: "worker", NewName: "quay.io/example/worker", NewTag: "v0.3.1"},
+		{Name: "quay.io/example/http-svc", NewTag: "v1.3.0"},
+	}
+	if diff := cmp.Diff(want, k.Images); diff != "" {
+		t.Fatalf("failed to add images:\n%s", diff)
+	}
+}
+
+func TestKustomizationWithoutImages(t *testing.T) {
+	b, err := yaml.Marshal(Kustomization{Resources: []string{"deployment.yaml"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("resources:\n- deployment.yaml\n", string(b)); diff != "" {
+		t.Fatalf("kustomization without images didn't match:\n%s", diff)
+	}
+}
+
+func TestAddConfigMapGenerator(t *testing.T) {
+	k := Kustomization{}
+	k.AddConfigMapGenerator(ConfigMapArgs{Name: "app-config", Literals: []string{"LOG_LEVEL=info"}})
+	k.AddConfigMapGenerator(ConfigMapArgs{Name: "app-files", Files: []string{"config/app.properties"}})
+	k.AddConfigMapGenerator(ConfigMapArgs{Name: "app-config", Literals: []string{"LOG_LEVEL=debug"}})
+
+	want := []ConfigMapArgs{
+		{Name: "app-config", Literals: []string{"LOG_LEVEL=debug"}},
+		{Name: "app-files", Files: []string{"config/app.properties"}},
+	}
+	if diff := cmp.Diff(want, k.ConfigMapGenerator); diff != "" {
+		t.Fatalf("failed to add config map generators:\n%s", diff)
+	}
+}
+
+func TestKustomizationConfigMapGeneratorSerialization(t *testing.T) {
+	k := Kustomization{Bases: []string{"../base"}}
+	k.AddConfigMapGenerator(ConfigMapArgs{
+		Name:     "app-config",
+		Literals: []string{"LOG_LEVEL=info", "REPLICAS=2"},
+		Files:    []string{"config/app.properties"},
+	})
+	fs := ioutils.NewMemoryFilesystem()
+	if _, err := res.WriteResources(fs, "/gitops", map[string]interface{}{"kustomization.yaml": k}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := fs.ReadFile("/gitops/kustomization.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `bases:
+- ../base
+configMapGenerator:
+- files:
+  - config/app.properties
+  literals:
+  - LOG_LEVEL=info
+  - REPLICAS=2
+  name: app-config
+`
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Fatalf("failed to write config map generators:\n%s", diff)
+	}
+
+	var got Kustomization
+	if err := yaml.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(k, got); diff != "" {
+		t.Fatalf("failed to unmarshal config map generators:\n%s", diff)
+	}
+}
+
+func TestAddSecretGenerator(t *testing.T) {
+	k := Kustomization{}
+	k.AddSecretGenerator(SecretArgs{Name: "app-tls", Type: "kubernetes.io/tls", Files: []string{"tls.crt", "tls.key"}})
+	k.AddSecretGenerator(SecretArgs{Name: "app-secret", Literals: []string{"PASSWORD=secret"}})
+	k.AddSecretGenerator(SecretArgs{Name: "app-tls", Type: "kubernetes.io/tls", Files: []string{"certs/tls.crt", "certs/tls.key"}})
+
+	want := []SecretArgs{
+		{Name: "app-tls", Type: "kubernetes.io/tls", Files: []string{"certs/tls.crt", "certs/tls.key"}},
+		{Name: "app-secret", Literals: []string{"PASSWORD=secret"}},
+	}
+	if diff := cmp.Diff(want, k.SecretGenerator); diff != "" {
+		t.Fatalf("failed to add secret generators:\n%s", diff)
+	}
+}
+
+func TestKustomizationSecretGeneratorSerialization(t *testing.T) {
+	k := Kustomization{}
+	k.AddSecretGenerator(SecretArgs{
+		Name:  "app-tls",
+		Type:  "kubernetes.io/tls",
+		Files: []string{"tls.crt", "tls.key"},
+	})
+	fs := ioutils.NewMemoryFilesystem()
+	if _, err := res.WriteResources(fs, "/gitops", map[string]interface{}{"kustomization.yaml": k}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := fs.ReadFile("/gitops/kustomization.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `secretGenerator:
+- files:
+  - tls.crt
+  - tls.key
+  name: app-tls
+  type: kubernetes.io/tls
+`
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Fatalf("failed to write secret generators:\n%s", diff)
+	}
+
+	var got Kustomization
+	if err := yaml.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(k, got); diff != "" {
+		t.Fatalf("failed to unmarshal secret generators:\n%s", diff)
+	}
+}
+
+func TestKustomizationGeneratorsBuild(t *testing.T) {
+	k := Kustomization{}
+	k.AddConfigMapGenerator(ConfigMapArgs{Name: "app-config", Literals: []string{"LOG_LEVEL=info"}})
+	k.AddSecretGenerator(SecretArgs{Name: "app-tls", Type: "kubernetes.io/tls", Files: []string{"tls.crt", "tls.key"}})
+	b, err := yaml.Marshal(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := filesys.MakeFsInMemory()
+	for name, content := range map[string]string{
+		"/app/kustomization.yaml": string(b),
+		"/app/tls.crt":            "certificate",
+		"/app/tls.key":            "key",
+	} {
+		if err := fs.WriteFile(name, []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fs, "/app")
+	if err != nil {
+		t.Fatalf("failed to build the kustomization:\n%s\n%s", b, err)
+	}
+	out, err := m.AsYaml()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"LOG_LEVEL: info",
+		"tls.crt: Y2VydGlmaWNhdGU=",
+		"type: kubernetes.io/tls",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("generated resources are missing %q:\n%s", want, out)
+		}
+	}
+}
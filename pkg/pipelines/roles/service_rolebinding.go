@@ -20,6 +20,10 @@ const (
 	// ClusterRoleName is the name of the ClusterRole created to allow the
 	// servie account to deploy into different environments.
 	ClusterRoleName = "pipelines-clusterrole"
+
+	// RoleName is the name of the Role created in place of the ClusterRole
+	// when only namespaced resources can be created.
+	RoleName = "pipelines-role"
 )
 
 // CreateServiceAccount creates and returns a new ServiceAccount in the provided
@@ -0,0 +1,145 @@
+package pipelines
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	openapi_v2 "github.com/googleapis/gnostic/openapiv2"
+	"github.com/mkmik/multierror"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/kubectl/pkg/util/openapi"
+	"k8s.io/kubectl/pkg/util/openapi/validation"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/kustomize/kyaml/openapi/kubernetesapi"
+
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+)
+
+// validatable is implemented by the Tekton Pipelines and Triggers types,
+// which are validated by their admission webhooks rather than an OpenAPI
+// schema.
+type validatable interface {
+	apis.Defaultable
+	Validate(context.Context) *apis.FieldError
+}
+
+var (
+	schemaOnce      sync.Once
+	schemaValidator *validation.SchemaValidation
+	schemaErr       error
+)
+
+// ValidateSchemas checks the generated resources against the Kubernetes
+// OpenAPI schemas that kustomize embeds, and the Tekton resources against
+// the Tekton validation, without contacting a cluster.
+//
+// Files that aren't Kubernetes resources, e.g. kustomizations, are skipped,
+// as are kinds that there is no schema for.
+func ValidateSchemas(resources res.Resources) error {
+	validator, err := kubernetesSchemaValidator()
+	if err != nil {
+		return err
+	}
+	paths := make([]string, 0, len(resources))
+	for path := range resources {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	errs := []error{}
+	for _, path := range paths {
+		for _, err := range validateResource(validator, resources[path]) {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return multierror.Join(errs)
+}
+
+func validateResource(validator *validation.SchemaValidation, obj interface{}) []error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return []error{err}
+	}
+	var header struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+		Metadata   struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil || header.APIVersion == "" || header.Kind == "" {
+		return nil
+	}
+	id := fmt.Sprintf("%s %s", header.Kind, header.Metadata.Name)
+
+	errs := []error{}
+	if err := validator.ValidateBytes(data); err != nil {
+		for _, e := range flattenErrors(err) {
+			errs = append(errs, fmt.Errorf("%s: %w", id, e))
+		}
+	}
+	if v, ok := asValidatable(obj, data); ok {
+		// The webhooks default the resources before they're validated.
+		ctx := triggersv1.WithUpgradeViaDefaulting(context.Background())
+		v.SetDefaults(ctx)
+		if err := v.Validate(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s", id, err.Error()))
+		}
+	}
+	return errs
+}
+
+// asValidatable decodes the data into a new value of the same type as obj if
+// it can be validated, so that defaulting doesn't modify obj.
+func asValidatable(obj interface{}, data []byte) (validatable, bool) {
+	t := reflect.TypeOf(obj)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, false
+	}
+	v, ok := reflect.New(t).Interface().(validatable)
+	if !ok {
+		return nil, false
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+func flattenErrors(err error) []error {
+	if agg, ok := err.(utilerrors.Aggregate); ok {
+		return utilerrors.Flatten(agg).Errors()
+	}
+	return []error{err}
+}
+
+// kubernetesSchemaValidator parses the built-in Kubernetes schema that
+// kustomize embeds, this is only parsed once.
+func kubernetesSchemaValidator() (*validation.SchemaValidation, error) {
+	schemaOnce.Do(func() {
+		swagger := kubernetesapi.OpenAPIMustAsset[kubernetesapi.DefaultOpenAPI]("kubernetesapi/" + kubernetesapi.DefaultOpenAPI + "/swagger.json")
+		doc, err := openapi_v2.ParseDocument(swagger)
+		if err != nil {
+			schemaErr = fmt.Errorf("failed to parse the Kubernetes OpenAPI schema: %w", err)
+			return
+		}
+		models, err := openapi.NewOpenAPIData(doc)
+		if err != nil {
+			schemaErr = fmt.Errorf("failed to parse the Kubernetes OpenAPI schema: %w", err)
+			return
+		}
+		schemaValidator = validation.NewSchemaValidation(models)
+	})
+	return schemaValidator, schemaErr
+}
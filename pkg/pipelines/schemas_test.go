@@ -0,0 +1,64 @@
+package pipelines
+
+import (
+	"testing"
+
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestValidateSchemasWithBootstrappedResources(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+	}
+	r, _, err := bootstrapResources(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	fatalIfError(t, ValidateSchemas(r))
+}
+
+func TestValidateSchemasWithInvalidKubernetesResource(t *testing.T) {
+	r := res.Resources{
+		"config/tst-cicd/base/01-namespaces/cicd-environment.yaml": map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Namespace",
+			"metadata":   map[string]interface{}{"name": "tst-cicd"},
+			"spec":       map[string]interface{}{"finalizers": "kubernetes"},
+		},
+		"config/tst-cicd/base/kustomization.yaml": &res.Kustomization{Resources: []string{"01-namespaces/cicd-environment.yaml"}},
+	}
+
+	err := ValidateSchemas(r)
+	test.AssertErrorMatch(t, `config/tst-cicd/base/01-namespaces/cicd-environment.yaml: Namespace tst-cicd: .*spec\.finalizers.*`, err)
+}
+
+func TestValidateSchemasWithInvalidTektonResource(t *testing.T) {
+	r := res.Resources{
+		"config/tst-cicd/base/pipelines/app-ci-pipeline.yaml": &pipelinev1.Pipeline{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "tekton.dev/v1beta1", Kind: "Pipeline"},
+			ObjectMeta: meta.ObjectMeta(meta.NamespacedName("tst-cicd", "app-ci-pipeline")),
+			Spec: pipelinev1.PipelineSpec{
+				Tasks: []pipelinev1.PipelineTask{{Name: "build-image"}},
+			},
+		},
+		"config/tst-cicd/base/02-rolebindings/pipeline-service-account.yaml": corev1.ServiceAccount{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+			ObjectMeta: meta.ObjectMeta(meta.NamespacedName("tst-cicd", "pipeline")),
+		},
+	}
+
+	err := ValidateSchemas(r)
+	test.AssertErrorMatch(t, `config/tst-cicd/base/pipelines/app-ci-pipeline.yaml: Pipeline app-ci-pipeline: .*spec\.tasks\[0\]`, err)
+}
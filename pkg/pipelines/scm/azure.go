@@ -0,0 +1,142 @@
+package scm
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+)
+
+const (
+	azureType = "azure"
+
+	// Azure DevOps service hooks have no event header, the event type is in
+	// the payload, and the repository is identified by its project and name.
+	azurePushEventFilters = "(body.eventType == 'git.push' && body.resource.repository.project.name + '/' + body.resource.repository.name == '%s')"
+
+	azurePushRef = "body.resource.refUpdates[0].name"
+
+	azureHost = "dev.azure.com"
+)
+
+type azureSpec struct {
+	pushBinding string
+}
+
+func init() {
+	gits[azureType] = newAzure
+}
+
+func newAzure(rawURL string) (Repository, error) {
+	path, err := processRawURL(rawURL, processAzurePath)
+	if err != nil {
+		return nil, err
+	}
+	return &repository{url: rawURL, path: path, spec: &azureSpec{pushBinding: "azure-push-binding"}}, nil
+}
+
+// processAzurePath returns the project and name of the repository, from the
+// path of Azure DevOps repository URLs e.g. /org/project/_git/repo.
+func processAzurePath(parsedURL *url.URL) (string, error) {
+	components, err := splitAzurePath(parsedURL)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(components[len(components)-2:], "/"), nil
+}
+
+// splitAzurePath returns the components of the path of an Azure DevOps
+// repository URL, without the "_git" component, the last two components are
+// the project and the name of the repository, and the components before them
+// are the organization or collection.
+func splitAzurePath(parsedURL *url.URL) ([]string, error) {
+	components, err := splitRepositoryPath(parsedURL)
+	if err != nil {
+		return nil, err
+	}
+	n := len(components)
+	if n < 3 || components[n-2] != "_git" {
+		return nil, invalidRepoPathError(azureType, parsedURL.Path)
+	}
+	if strings.EqualFold(parsedURL.Hostname(), azureHost) && n < 4 {
+		return nil, invalidRepoPathError(azureType, parsedURL.Path)
+	}
+	return append(components[:n-2:n-2], components[n-1]), nil
+}
+
+// isAzureHost returns true for the hosts of the Azure DevOps Services, the
+// hosts of Azure DevOps Servers are mapped to the driver like other hosts.
+func isAzureHost(host string) bool {
+	return host == azureHost || strings.HasSuffix(host, ".visualstudio.com")
+}
+
+// AzureDevOpsAPIURL returns the URL of the organization or collection that
+// hosts the repository, that the Azure DevOps REST API paths are relative to,
+// e.g. https://dev.azure.com/my-org.
+//
+// If the repository isn't identified as an azure repository, the URL is
+// empty.
+func AzureDevOpsAPIURL(rawURL string) (string, error) {
+	driver, err := GetDriverName(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if driver != azureType {
+		return "", nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	components, err := splitAzurePath(u)
+	if err != nil {
+		return "", err
+	}
+	scheme := u.Scheme
+	if scheme != "http" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s", scheme, strings.Join(append([]string{u.Host}, components[:len(components)-2]...), "/")), nil
+}
+
+func (r *azureSpec) pushBindingName() string {
+	return r.pushBinding
+}
+
+// pushBindingParams binds the "Code pushed" service hook payload, the
+// fullname is the project and name of the repository.
+func (r *azureSpec) pushBindingParams() []triggersv1.Param {
+	return []triggersv1.Param{
+		createBindingParam("gitrepositoryurl", "$(body.resource.repository.remoteUrl)"),
+		createBindingParam("fullname", "$(body.resource.repository.project.name)/$(body.resource.repository.name)"),
+		createBindingParam(triggers.GitRef, "$(extensions.ref)"),
+		createBindingParam(triggers.GitCommitID, "$(body.resource.refUpdates[0].newObjectId)"),
+		createBindingParam(triggers.GitCommitDate, "$(body.resource.date)"),
+		createBindingParam(triggers.GitCommitMessage, "$(body.resource.commits[0].comment)"),
+		createBindingParam(triggers.GitCommitAuthor, "$(body.resource.pushedBy.displayName)"),
+	}
+}
+
+func (r *azureSpec) pushEventFilters() string {
+	return azurePushEventFilters
+}
+
+func (r *azureSpec) pushRef() string {
+	return azurePushRef
+}
+
+// eventInterceptor returns a GitLab interceptor, Azure DevOps doesn't sign the
+// service hook payloads, so the service hooks must send the webhook secret in
+// an X-GitLab-Token header, which the interceptor checks.
+func (r *azureSpec) eventInterceptor(secretNamespace, secretName string) *triggersv1.EventInterceptor {
+	return &triggersv1.EventInterceptor{
+		GitLab: &triggersv1.GitLabInterceptor{
+			SecretRef: &triggersv1.SecretRef{
+				SecretName: secretName,
+				SecretKey:  webhookSecretKey,
+			},
+		},
+	}
+}
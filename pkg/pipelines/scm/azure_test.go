@@ -0,0 +1,180 @@
+package scm
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	"github.com/tektoncd/triggers/pkg/interceptors/gitlab"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCreatePushBindingForAzure(t *testing.T) {
+	repo, err := NewRepository("https://dev.azure.com/my-org/my-project/_git/my-repo")
+	assertNoError(t, err)
+	want := triggersv1.TriggerBinding{
+		TypeMeta: triggers.TriggerBindingTypeMeta,
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "azure-push-binding",
+			Namespace: "testns",
+		},
+		Spec: triggersv1.TriggerBindingSpec{
+			Params: []triggersv1.Param{
+				{Name: "gitrepositoryurl", Value: "$(body.resource.repository.remoteUrl)"},
+				{Name: "fullname", Value: "$(body.resource.repository.project.name)/$(body.resource.repository.name)"},
+				{Name: triggers.GitRef, Value: "$(extensions.ref)"},
+				{Name: triggers.GitCommitID, Value: "$(body.resource.refUpdates[0].newObjectId)"},
+				{Name: triggers.GitCommitDate, Value: "$(body.resource.date)"},
+				{Name: triggers.GitCommitMessage, Value: "$(body.resource.commits[0].comment)"},
+				{Name: triggers.GitCommitAuthor, Value: "$(body.resource.pushedBy.displayName)"},
+			},
+		},
+	}
+	got, name := repo.CreatePushBinding("testns")
+	if name != "azure-push-binding" {
+		t.Fatalf("CreatePushBinding() returned a wrong binding: want %v got %v", "azure-push-binding", name)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePushBinding() failed:\n%s", diff)
+	}
+}
+
+func TestCreateCDTriggersForAzure(t *testing.T) {
+	repo, err := NewRepository("https://dev.azure.com/my-org/my-project/_git/my-repo")
+	assertNoError(t, err)
+	name := "test-template"
+	want := triggersv1.EventListenerTrigger{
+		Name: "test",
+		Bindings: []*triggersv1.EventListenerBinding{
+			{Ref: "test-binding"},
+		},
+		Template: &triggersv1.EventListenerTemplate{Ref: &name},
+		Interceptors: []*triggersv1.EventInterceptor{
+			{
+				GitLab: &triggersv1.GitLabInterceptor{
+					SecretRef: &triggersv1.SecretRef{SecretKey: "webhook-secret-key", SecretName: "secret"},
+				},
+			},
+			{
+				CEL: &triggersv1.CELInterceptor{
+					Filter: fmt.Sprintf(azurePushEventFilters, "my-project/my-repo"),
+					Overlays: []triggersv1.CELOverlay{
+						{Key: "ref", Expression: "body.resource.refUpdates[0].name.split('/')[2]"},
+					},
+				},
+			},
+		},
+	}
+	got := repo.CreatePushTrigger("test", "secret", "ns", "test-template", []string{"test-binding"})
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePushTrigger() failed:\n%s", diff)
+	}
+}
+
+func TestCreateBranchPushTriggerForAzure(t *testing.T) {
+	repo, err := NewRepository("https://dev.azure.com/my-org/my-project/_git/my-repo")
+	assertNoError(t, err)
+	got := repo.CreateBranchPushTrigger("test", "secret", "ns", "test-template", []string{"test-binding"}, []string{"main", "release/*"})
+
+	want := fmt.Sprintf(azurePushEventFilters, "my-project/my-repo") + " && (body.resource.refUpdates[0].name == 'refs/heads/main' || body.resource.refUpdates[0].name.startsWith('refs/heads/release/'))"
+	if diff := cmp.Diff(want, got.Interceptors[1].CEL.Filter); diff != "" {
+		t.Fatalf("CreateBranchPushTrigger() filter didn't match:\n%s", diff)
+	}
+}
+
+func TestAzureHasNoMergeRequestBinding(t *testing.T) {
+	repo, err := NewRepository("https://dev.azure.com/my-org/my-project/_git/my-repo")
+	assertNoError(t, err)
+	if name := repo.MergeRequestBindingName(); name != "" {
+		t.Fatalf("got merge request binding %q, want none", name)
+	}
+}
+
+func TestNewAzureRepository(t *testing.T) {
+	tests := []struct {
+		url      string
+		repoPath string
+		errMsg   string
+	}{
+		{"https://dev.azure.com/my-org/my-project/_git/my-repo", "my-project/my-repo", ""},
+		{"https://my-org@dev.azure.com/my-org/my-project/_git/my-repo.git", "my-project/my-repo", ""},
+		{"https://my-org.visualstudio.com/my-project/_git/my-repo", "my-project/my-repo", ""},
+		{"https://dev.azure.com/my-project/_git/my-repo", "", "invalid repository path for azure: /my-project/_git/my-repo"},
+		{"https://dev.azure.com/my-org/my-project/my-repo", "", "invalid repository path for azure: /my-org/my-project/my-repo"},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("Test %d", i), func(rt *testing.T) {
+			repo, err := NewRepository(tt.url)
+			if err != nil {
+				if diff := cmp.Diff(tt.errMsg, err.Error()); diff != "" {
+					rt.Fatalf("repo path errMsg mismatch: \n%s", diff)
+				}
+				return
+			}
+			if diff := cmp.Diff(tt.repoPath, repo.(*repository).path); diff != "" {
+				rt.Fatalf("repo path mismatch: got\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAzurePushTriggerValidatesToken(t *testing.T) {
+	repo, err := NewRepository("https://dev.azure.com/my-org/my-project/_git/my-repo")
+	assertNoError(t, err)
+	trigger := repo.CreatePushTrigger("test", "gitops-webhook-secret", "cicd", "test-template", []string{"azure-push-binding"})
+	params := interceptorParams(t, trigger.Interceptors[0].GitLab)
+	interceptor := gitlab.NewInterceptor(fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{Name: "gitops-webhook-secret", Namespace: "cicd"},
+		Data:       map[string][]byte{webhookSecretKey: []byte("secret-token")},
+	}), nil)
+	body := `{"eventType":"git.push","resource":{"refUpdates":[{"name":"refs/heads/main","newObjectId":"da1560886d4f094c3e6c9ef40349f7d38b5d27d7"}],"repository":{"name":"my-repo","project":{"name":"my-project"}}}}`
+
+	tests := []struct {
+		name         string
+		header       map[string][]string
+		wantContinue bool
+	}{
+		{"matching token", map[string][]string{"X-Gitlab-Token": {"secret-token"}}, true},
+		{"incorrect token", map[string][]string{"X-Gitlab-Token": {"wrong-token"}}, false},
+		{"missing token", map[string][]string{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(rt *testing.T) {
+			resp := interceptor.Process(context.Background(), &triggersv1.InterceptorRequest{
+				Body:              body,
+				Header:            tt.header,
+				InterceptorParams: params,
+				Context:           &triggersv1.TriggerContext{TriggerID: "namespaces/cicd/triggers/test"},
+			})
+			if resp.Continue != tt.wantContinue {
+				rt.Fatalf("got Continue %v, want %v: %v", resp.Continue, tt.wantContinue, resp.Status)
+			}
+		})
+	}
+}
+
+func TestAzureDevOpsAPIURL(t *testing.T) {
+	urlTests := []struct {
+		repoURL string
+		want    string
+	}{
+		{"https://dev.azure.com/my-org/my-project/_git/my-repo", "https://dev.azure.com/my-org"},
+		{"https://my-org@dev.azure.com/my-org/my-project/_git/my-repo", "https://dev.azure.com/my-org"},
+		{"https://my-org.visualstudio.com/my-project/_git/my-repo", "https://my-org.visualstudio.com"},
+		{"https://github.com/my-org/my-repo.git", ""},
+	}
+	for _, tt := range urlTests {
+		t.Run(tt.repoURL, func(t *testing.T) {
+			got, err := AzureDevOpsAPIURL(tt.repoURL)
+			assertNoError(t, err)
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
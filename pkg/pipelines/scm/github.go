@@ -11,10 +11,15 @@ import (
 const (
 	githubPushEventFilters = "(header.match('X-GitHub-Event', 'push') && body.repository.full_name == '%s')"
 	githubType             = "github"
+
+	// Pull requests are dry-run when they're opened or reopened, and when
+	// new commits are pushed to their branch.
+	githubPullRequestEventFilters = "header.match('X-GitHub-Event', 'pull_request') && body.repository.full_name == '%s' && body.action in ['opened', 'reopened', 'synchronize']"
 )
 
 type githubSpec struct {
-	pushBinding string
+	pushBinding        string
+	pullRequestBinding string
 }
 
 func init() {
@@ -26,7 +31,7 @@ func newGitHub(rawURL string) (Repository, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &repository{url: rawURL, path: path, spec: &githubSpec{pushBinding: "github-push-binding"}}, nil
+	return &repository{url: rawURL, path: path, spec: &githubSpec{pushBinding: "github-push-binding", pullRequestBinding: "github-pull-request-binding"}}, nil
 }
 
 func proccessGitHubPath(parsedURL *url.URL) (string, error) {
@@ -62,6 +67,28 @@ func (r *githubSpec) pushEventFilters() string {
 	return githubPushEventFilters
 }
 
+func (r *githubSpec) mergeRequestBindingName() string {
+	return r.pullRequestBinding
+}
+
+// mergeRequestBindingParams binds the head branch and commit of the pull
+// request, the head repository can be a fork of the repository.
+func (r *githubSpec) mergeRequestBindingParams() []triggersv1.Param {
+	return []triggersv1.Param{
+		createBindingParam("gitrepositoryurl", "$(body.pull_request.head.repo.clone_url)"),
+		createBindingParam("fullname", "$(body.repository.full_name)"),
+		createBindingParam(triggers.GitRef, "$(body.pull_request.head.ref)"),
+		createBindingParam(triggers.GitCommitID, "$(body.pull_request.head.sha)"),
+		createBindingParam(triggers.GitCommitDate, "$(body.pull_request.updated_at)"),
+		createBindingParam(triggers.GitCommitMessage, "$(body.pull_request.title)"),
+		createBindingParam(triggers.GitCommitAuthor, "$(body.pull_request.user.login)"),
+	}
+}
+
+func (r *githubSpec) mergeRequestEventFilters() string {
+	return githubPullRequestEventFilters
+}
+
 func (r *githubSpec) eventInterceptor(secretNamespace, secretName string) *triggersv1.EventInterceptor {
 	return &triggersv1.EventInterceptor{
 		GitHub: &triggersv1.GitHubInterceptor{
@@ -91,6 +91,61 @@ func TestCreateCDTriggersForGithub(t *testing.T) {
 	}
 }
 
+func TestCreateBranchPushTriggerForGithub(t *testing.T) {
+	repo, err := NewRepository("http://github.com/org/test")
+	assertNoError(t, err)
+	got := repo.CreateBranchPushTrigger("test", "secret", "ns", "test-template", []string{"test-binding"}, []string{"main", "release/*"})
+	want := &triggersv1.CELInterceptor{
+		Filter:   "(header.match('X-GitHub-Event', 'push') && body.repository.full_name == 'org/test') && (body.ref == 'refs/heads/main' || body.ref.startsWith('refs/heads/release/'))",
+		Overlays: branchRefOverlay,
+	}
+	if diff := cmp.Diff(want, got.Interceptors[1].CEL); diff != "" {
+		t.Fatalf("CreateBranchPushTrigger() failed:\n%s", diff)
+	}
+}
+
+func TestCreatePullRequestBindingForGithub(t *testing.T) {
+	repo, err := NewRepository("http://github.com/org/test")
+	assertNoError(t, err)
+	want := triggersv1.TriggerBinding{
+		TypeMeta: triggers.TriggerBindingTypeMeta,
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "github-pull-request-binding",
+			Namespace: "testns",
+		},
+		Spec: triggersv1.TriggerBindingSpec{
+			Params: []triggersv1.Param{
+				{Name: "gitrepositoryurl", Value: "$(body.pull_request.head.repo.clone_url)"},
+				{Name: "fullname", Value: "$(body.repository.full_name)"},
+				{Name: triggers.GitRef, Value: "$(body.pull_request.head.ref)"},
+				{Name: triggers.GitCommitID, Value: "$(body.pull_request.head.sha)"},
+				{Name: triggers.GitCommitDate, Value: "$(body.pull_request.updated_at)"},
+				{Name: triggers.GitCommitMessage, Value: "$(body.pull_request.title)"},
+				{Name: triggers.GitCommitAuthor, Value: "$(body.pull_request.user.login)"},
+			},
+		},
+	}
+	got, name := repo.CreateMergeRequestBinding("testns")
+	if name != "github-pull-request-binding" {
+		t.Fatalf("CreateMergeRequestBinding() returned a wrong binding: want %v got %v", "github-pull-request-binding", name)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreateMergeRequestBinding() failed:\n%s", diff)
+	}
+}
+
+func TestCreatePullRequestTriggerForGithub(t *testing.T) {
+	repo, err := NewRepository("http://github.com/org/test")
+	assertNoError(t, err)
+	got := repo.CreateMergeRequestTrigger("test", "secret", "ns", "test-template", []string{"test-binding"})
+	want := &triggersv1.CELInterceptor{
+		Filter: "header.match('X-GitHub-Event', 'pull_request') && body.repository.full_name == 'org/test' && body.action in ['opened', 'reopened', 'synchronize']",
+	}
+	if diff := cmp.Diff(want, got.Interceptors[1].CEL); diff != "" {
+		t.Fatalf("CreateMergeRequestTrigger() failed:\n%s", diff)
+	}
+}
+
 func TestNewGitHubRepository(t *testing.T) {
 	tests := []struct {
 		url      string
@@ -11,10 +11,16 @@ import (
 const (
 	gitlabPushEventFilters = "header.match('X-Gitlab-Event','Push Hook') && body.project.path_with_namespace == '%s'"
 	gitlabType             = "gitlab"
+
+	// Merge requests are dry-run when they're opened or reopened, and when
+	// they're updated with new commits, which is when GitLab sends the
+	// previous revision.
+	gitlabMergeRequestEventFilters = "header.match('X-Gitlab-Event','Merge Request Hook') && body.project.path_with_namespace == '%s' && (body.object_attributes.action in ['open', 'reopen'] || (body.object_attributes.action == 'update' && has(body.object_attributes.oldrev)))"
 )
 
 type gitlabSpec struct {
-	pushBinding string
+	pushBinding         string
+	mergeRequestBinding string
 }
 
 func init() {
@@ -26,7 +32,7 @@ func newGitLab(rawURL string) (Repository, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &repository{url: rawURL, path: path, spec: &gitlabSpec{pushBinding: "gitlab-push-binding"}}, nil
+	return &repository{url: rawURL, path: path, spec: &gitlabSpec{pushBinding: "gitlab-push-binding", mergeRequestBinding: "gitlab-merge-request-binding"}}, nil
 }
 
 func proccessGitLabPath(parsedURL *url.URL) (string, error) {
@@ -61,6 +67,31 @@ func (r *gitlabSpec) pushEventFilters() string {
 	return gitlabPushEventFilters
 }
 
+func (r *gitlabSpec) mergeRequestBindingName() string {
+	return r.mergeRequestBinding
+}
+
+// mergeRequestBindingParams binds the source branch and commit of the merge
+// request, the source project can be a fork of the project.
+func (r *gitlabSpec) mergeRequestBindingParams() []triggersv1.Param {
+	return []triggersv1.Param{
+		createBindingParam("gitrepositoryurl", "$(body.object_attributes.source.git_http_url)"),
+		createBindingParam("fullname", "$(body.project.path_with_namespace)"),
+		createBindingParam(triggers.GitRef, "$(body.object_attributes.source_branch)"),
+		createBindingParam(triggers.GitCommitID, "$(body.object_attributes.last_commit.id)"),
+		createBindingParam(triggers.GitCommitDate, "$(body.object_attributes.last_commit.timestamp)"),
+		createBindingParam(triggers.GitCommitMessage, "$(body.object_attributes.last_commit.message)"),
+		createBindingParam(triggers.GitCommitAuthor, "$(body.object_attributes.last_commit.author.name)"),
+	}
+}
+
+func (r *gitlabSpec) mergeRequestEventFilters() string {
+	return gitlabMergeRequestEventFilters
+}
+
+// eventInterceptor returns a GitLab interceptor, this rejects events where the
+// X-Gitlab-Token header doesn't match the webhook secret, GitLab doesn't sign
+// the payload.
 func (r *gitlabSpec) eventInterceptor(secretNamespace, secretName string) *triggersv1.EventInterceptor {
 	return &triggersv1.EventInterceptor{
 		GitLab: &triggersv1.GitLabInterceptor{
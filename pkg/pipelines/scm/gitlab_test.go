@@ -1,13 +1,18 @@
 package scm
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	"github.com/tektoncd/triggers/pkg/interceptors/gitlab"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestCreatePushBindingForGitlab(t *testing.T) {
@@ -92,6 +97,65 @@ func TestCreateCDTriggersForGitLab(t *testing.T) {
 	}
 }
 
+func TestCreateMergeRequestBindingForGitLab(t *testing.T) {
+	repo, err := newGitLab("https://gitlab.com/org/test")
+	assertNoError(t, err)
+	want := triggersv1.TriggerBinding{
+		TypeMeta: triggers.TriggerBindingTypeMeta,
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "gitlab-merge-request-binding",
+			Namespace: "testns",
+		},
+		Spec: triggersv1.TriggerBindingSpec{
+			Params: []triggersv1.Param{
+				{Name: "gitrepositoryurl", Value: "$(body.object_attributes.source.git_http_url)"},
+				{Name: "fullname", Value: "$(body.project.path_with_namespace)"},
+				{Name: triggers.GitRef, Value: "$(body.object_attributes.source_branch)"},
+				{Name: triggers.GitCommitID, Value: "$(body.object_attributes.last_commit.id)"},
+				{Name: triggers.GitCommitDate, Value: "$(body.object_attributes.last_commit.timestamp)"},
+				{Name: triggers.GitCommitMessage, Value: "$(body.object_attributes.last_commit.message)"},
+				{Name: triggers.GitCommitAuthor, Value: "$(body.object_attributes.last_commit.author.name)"},
+			},
+		},
+	}
+	got, name := repo.CreateMergeRequestBinding("testns")
+	if name != "gitlab-merge-request-binding" {
+		t.Fatalf("CreateMergeRequestBinding() returned a wrong binding: want %v got %v", "gitlab-merge-request-binding", name)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreateMergeRequestBinding() failed:\n%s", diff)
+	}
+}
+
+func TestCreateMergeRequestTriggerForGitLab(t *testing.T) {
+	repo, err := NewRepository("http://gitlab.com/org/test")
+	assertNoError(t, err)
+	name := "test-template"
+	want := triggersv1.EventListenerTrigger{
+		Name: "test",
+		Bindings: []*triggersv1.EventListenerBinding{
+			{Ref: "test-binding"},
+		},
+		Template: &triggersv1.EventListenerTemplate{Ref: &name},
+		Interceptors: []*triggersv1.EventInterceptor{
+			{
+				GitLab: &triggersv1.GitLabInterceptor{
+					SecretRef: &triggersv1.SecretRef{SecretKey: "webhook-secret-key", SecretName: "secret"},
+				},
+			},
+			{
+				CEL: &triggersv1.CELInterceptor{
+					Filter: fmt.Sprintf(gitlabMergeRequestEventFilters, "org/test"),
+				},
+			},
+		},
+	}
+	got := repo.CreateMergeRequestTrigger("test", "secret", "ns", "test-template", []string{"test-binding"})
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreateMergeRequestTrigger() failed:\n%s", diff)
+	}
+}
+
 func TestNewGitlabRepository(t *testing.T) {
 	tests := []struct {
 		url      string
@@ -141,3 +205,47 @@ func TestNewGitlabRepository(t *testing.T) {
 		})
 	}
 }
+
+func TestGitLabPushTriggerValidatesToken(t *testing.T) {
+	repo, err := NewRepository("https://gitlab.com/org/test.git")
+	assertNoError(t, err)
+	trigger := repo.CreatePushTrigger("test", "gitops-webhook-secret", "cicd", "test-template", []string{"gitlab-push-binding"})
+	params := interceptorParams(t, trigger.Interceptors[0].GitLab)
+	interceptor := gitlab.NewInterceptor(fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{Name: "gitops-webhook-secret", Namespace: "cicd"},
+		Data:       map[string][]byte{webhookSecretKey: []byte("secret-token")},
+	}), nil)
+	body := `{"object_kind":"push","ref":"refs/heads/main","after":"da1560886d4f094c3e6c9ef40349f7d38b5d27d7","project":{"path_with_namespace":"org/test","git_http_url":"https://gitlab.com/org/test.git"}}`
+
+	tests := []struct {
+		name         string
+		header       map[string][]string
+		wantContinue bool
+	}{
+		{"matching token", map[string][]string{"X-Gitlab-Event": {"Push Hook"}, "X-Gitlab-Token": {"secret-token"}}, true},
+		{"incorrect token", map[string][]string{"X-Gitlab-Event": {"Push Hook"}, "X-Gitlab-Token": {"wrong-token"}}, false},
+		{"missing token", map[string][]string{"X-Gitlab-Event": {"Push Hook"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(rt *testing.T) {
+			resp := interceptor.Process(context.Background(), &triggersv1.InterceptorRequest{
+				Body:              body,
+				Header:            tt.header,
+				InterceptorParams: params,
+				Context:           &triggersv1.TriggerContext{TriggerID: "namespaces/cicd/triggers/test"},
+			})
+			if resp.Continue != tt.wantContinue {
+				rt.Fatalf("got Continue %v, want %v: %v", resp.Continue, tt.wantContinue, resp.Status)
+			}
+		})
+	}
+}
+
+func interceptorParams(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	b, err := json.Marshal(v)
+	assertNoError(t, err)
+	params := map[string]interface{}{}
+	assertNoError(t, json.Unmarshal(b, &params))
+	return params
+}
@@ -16,6 +16,19 @@ type Repository interface {
 	// Create an eventlistener trigger for Push event
 	CreatePushTrigger(name, secretName, secretNs, template string, bindings []string) triggersv1.EventListenerTrigger
 
+	// Create an eventlistener trigger for Push events to the matching branches
+	CreateBranchPushTrigger(name, secretName, secretNs, template string, bindings, branches []string) triggersv1.EventListenerTrigger
+
+	// Get the Merge Request TriggerBinding name for this repository provider,
+	// this is empty if merge request events aren't supported for the provider
+	MergeRequestBindingName() string
+
+	// Create a TriggerBinding for Merge Request hooks
+	CreateMergeRequestBinding(namespace string) (triggersv1.TriggerBinding, string)
+
+	// Create an eventlistener trigger for Merge Request events
+	CreateMergeRequestTrigger(name, secretName, secretNs, template string, bindings []string) triggersv1.EventListenerTrigger
+
 	// Git Repository URL
 	URL() string
 }
@@ -27,6 +27,20 @@ type triggerSpec interface {
 	pushBindingName() string
 }
 
+// pushRefSpec is implemented by the specs for the providers whose push events
+// don't have the pushed ref at body.ref.
+type pushRefSpec interface {
+	pushRef() string
+}
+
+// mergeRequestSpec is implemented by the specs for the providers whose merge
+// request events trigger the CI dry-run.
+type mergeRequestSpec interface {
+	mergeRequestBindingName() string
+	mergeRequestBindingParams() []triggersv1.Param
+	mergeRequestEventFilters() string
+}
+
 // NewRepository returns a suitable Repository instance
 // based on the driver name (github,gitlab,etc)
 func NewRepository(url string) (Repository, error) {
@@ -61,6 +75,50 @@ func (r *repository) CreatePushTrigger(name, secretName, secretNS, template stri
 		r.spec.eventInterceptor(secretNS, secretName))
 }
 
+// CreateMergeRequestBinding implements the Repository interface, this returns
+// an empty TriggerBinding if merge request events aren't supported.
+func (r *repository) CreateMergeRequestBinding(ns string) (triggersv1.TriggerBinding, string) {
+	spec, ok := r.spec.(mergeRequestSpec)
+	if !ok {
+		return triggersv1.TriggerBinding{}, ""
+	}
+	return triggersv1.TriggerBinding{
+		TypeMeta:   triggers.TriggerBindingTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, spec.mergeRequestBindingName())),
+		Spec: triggersv1.TriggerBindingSpec{
+			Params: spec.mergeRequestBindingParams(),
+		},
+	}, spec.mergeRequestBindingName()
+}
+
+// CreateMergeRequestTrigger implements the Repository interface, this returns
+// an empty trigger if merge request events aren't supported.
+//
+// Merge request events have no ref, so the interceptor doesn't add the branch
+// to the extensions.
+func (r *repository) CreateMergeRequestTrigger(name, secretName, secretNS, template string, bindings []string) triggersv1.EventListenerTrigger {
+	spec, ok := r.spec.(mergeRequestSpec)
+	if !ok {
+		return triggersv1.EventListenerTrigger{}
+	}
+	return triggersv1.EventListenerTrigger{
+		Name: name,
+		Interceptors: []*triggersv1.EventInterceptor{
+			r.spec.eventInterceptor(secretNS, secretName),
+			createFilterInterceptor(spec.mergeRequestEventFilters(), r.path),
+		},
+		Bindings: createBindings(bindings),
+		Template: createListenerTemplate(&template),
+	}
+}
+
+// CreateBranchPushTrigger implements the Repository interface.
+func (r *repository) CreateBranchPushTrigger(name, secretName, secretNS, template string, bindings, branches []string) triggersv1.EventListenerTrigger {
+	return r.createTrigger(name, r.spec.pushEventFilters()+" && "+branchFilter(branches, r.pushRef()),
+		template, bindings,
+		r.spec.eventInterceptor(secretNS, secretName))
+}
+
 // URL implements the Repository interface.
 func (r *repository) URL() string {
 	return r.url
@@ -71,12 +129,29 @@ func (r *repository) PushBindingName() string {
 	return r.spec.pushBindingName()
 }
 
+// MergeRequestBindingName returns the name of the merge request binding, or
+// an empty string if merge request events aren't supported.
+func (r *repository) MergeRequestBindingName() string {
+	if spec, ok := r.spec.(mergeRequestSpec); ok {
+		return spec.mergeRequestBindingName()
+	}
+	return ""
+}
+
+// pushRef returns the field of the push event payloads with the pushed ref.
+func (r *repository) pushRef() string {
+	if spec, ok := r.spec.(pushRefSpec); ok {
+		return spec.pushRef()
+	}
+	return pushRef
+}
+
 func (r *repository) createTrigger(name, filters, template string, bindings []string, interceptor *triggersv1.EventInterceptor) triggersv1.EventListenerTrigger {
 	return triggersv1.EventListenerTrigger{
 		Name: name,
 		Interceptors: []*triggersv1.EventInterceptor{
 			interceptor,
-			createEventInterceptor(filters, r.path),
+			createEventInterceptor(filters, r.path, r.pushRef()),
 		},
 		Bindings: createBindings(bindings),
 		Template: createListenerTemplate(&template),
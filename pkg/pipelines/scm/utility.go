@@ -9,10 +9,12 @@ import (
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 )
 
+// pushRef is the field of the push event payloads with the pushed ref e.g.
+// refs/heads/main, for the providers that don't implement pushRefSpec.
+const pushRef = "body.ref"
+
 var (
-	branchRefOverlay = []triggersv1.CELOverlay{
-		{Key: "ref", Expression: "body.ref.split('/')[2]"},
-	}
+	branchRefOverlay = refOverlay(pushRef)
 )
 
 func invalidRepoPathError(gitType, path string) error {
@@ -27,11 +29,42 @@ func invalidRepoURLError(repoURL, reason string) error {
 	return fmt.Errorf("invalid repository URL %s: %s", repoURL, reason)
 }
 
-func createEventInterceptor(filter, repoName string) *triggersv1.EventInterceptor {
+// refOverlay adds the branch from the pushed ref at the field to the
+// extensions, as extensions.ref.
+func refOverlay(ref string) []triggersv1.CELOverlay {
+	return []triggersv1.CELOverlay{
+		{Key: "ref", Expression: ref + ".split('/')[2]"},
+	}
+}
+
+func createEventInterceptor(filter, repoName, ref string) *triggersv1.EventInterceptor {
 	return &triggersv1.EventInterceptor{
 		CEL: &triggersv1.CELInterceptor{
 			Filter:   fmt.Sprintf(filter, repoName),
-			Overlays: branchRefOverlay,
+			Overlays: refOverlay(ref),
+		},
+	}
+}
+
+// branchFilter returns a CEL expression that matches pushes of the ref at the
+// field to any of the branches, a trailing "*" matches any branch with that
+// prefix.
+func branchFilter(branches []string, ref string) string {
+	matches := make([]string, len(branches))
+	for i, branch := range branches {
+		if strings.HasSuffix(branch, "*") {
+			matches[i] = fmt.Sprintf("%s.startsWith('refs/heads/%s')", ref, strings.TrimSuffix(branch, "*"))
+			continue
+		}
+		matches[i] = fmt.Sprintf("%s == 'refs/heads/%s'", ref, branch)
+	}
+	return "(" + strings.Join(matches, " || ") + ")"
+}
+
+func createFilterInterceptor(filter, repoName string) *triggersv1.EventInterceptor {
+	return &triggersv1.EventInterceptor{
+		CEL: &triggersv1.CELInterceptor{
+			Filter: fmt.Sprintf(filter, repoName),
 		},
 	}
 }
@@ -90,15 +123,43 @@ func splitRepositoryPath(parsedURL *url.URL) ([]string, error) {
 }
 
 // GetDriverName gets the driver to be used for this repo url, using the go-scm
-// default identifier.
+// default identifier, go-scm has no driver for the Azure DevOps Services so
+// their hosts are identified separately.
 func GetDriverName(rawURL string) (string, error) {
 	host, err := HostnameFromURL(rawURL)
 	if err != nil {
 		return "", err
 	}
+	if isAzureHost(host) {
+		return azureType, nil
+	}
 	return factory.DefaultIdentifier.Identify(host)
 }
 
+// GitHubEnterpriseAPIURL returns the API URL of the GitHub Enterprise Server
+// that hosts the repository, e.g. https://github.example.com/api/v3.
+//
+// If the repository isn't identified as a github repository, or it's on
+// github.com, the API URL is empty.
+func GitHubEnterpriseAPIURL(rawURL string) (string, error) {
+	driver, err := GetDriverName(rawURL)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if driver != "github" || strings.EqualFold(u.Hostname(), "github.com") {
+		return "", nil
+	}
+	scheme := u.Scheme
+	if scheme != "http" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s/api/v3", scheme, u.Host), nil
+}
+
 // HostnameFromURL returns the host from a URL.
 func HostnameFromURL(rawURL string) (string, error) {
 	u, err := url.Parse(rawURL)
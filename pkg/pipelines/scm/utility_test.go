@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/jenkins-x/go-scm/scm/factory"
 
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 )
@@ -36,7 +37,7 @@ func TestCreateEventInterceptor(t *testing.T) {
 			Overlays: branchRefOverlay,
 		},
 	}
-	eventInterceptor := createEventInterceptor("sampleFilter %s", "sample")
+	eventInterceptor := createEventInterceptor("sampleFilter %s", "sample", pushRef)
 	if diff := cmp.Diff(validEventInterceptor, *eventInterceptor); diff != "" {
 		t.Fatalf("createEventInterceptor() failed:\n%s", diff)
 	}
@@ -68,3 +69,33 @@ func TestHostnameFromURL(t *testing.T) {
 		}
 	}
 }
+
+func TestGitHubEnterpriseAPIURL(t *testing.T) {
+	defer func(id factory.HostDriverIdentifier) {
+		factory.DefaultIdentifier = id
+	}(factory.DefaultIdentifier)
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(
+		factory.Mapping("ghe.example.com", "github"),
+		factory.Mapping("ghe.example.com:8443", "github"),
+		factory.Mapping("gitlab.example.com", "gitlab"))
+
+	urlTests := []struct {
+		repoURL string
+		want    string
+	}{
+		{"https://github.com/example/example.git", ""},
+		{"https://gitlab.example.com/example/example.git", ""},
+		{"https://ghe.example.com/example/example.git", "https://ghe.example.com/api/v3"},
+		{"https://ghe.example.com:8443/example/example.git", "https://ghe.example.com:8443/api/v3"},
+	}
+	for _, tt := range urlTests {
+		got, err := GitHubEnterpriseAPIURL(tt.repoURL)
+		if err != nil {
+			t.Errorf("GitHubEnterpriseAPIURL(%q) failed: %s", tt.repoURL, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("GitHubEnterpriseAPIURL(%q) got %q, want %q", tt.repoURL, got, tt.want)
+		}
+	}
+}
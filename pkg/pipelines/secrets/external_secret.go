@@ -0,0 +1,108 @@
+package secrets
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+)
+
+var externalSecretTypeMeta = meta.TypeMeta("ExternalSecret", "external-secrets.io/v1beta1")
+
+const externalSecretRefreshInterval = "1h"
+
+// ExternalSecret is an External Secrets Operator ExternalSecret, only the
+// fields that are generated are included.
+type ExternalSecret struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              ExternalSecretSpec `json:"spec"`
+}
+
+// ExternalSecretSpec configures the Secret that the ExternalSecret creates,
+// and where its data is fetched from.
+type ExternalSecretSpec struct {
+	RefreshInterval string               `json:"refreshInterval,omitempty"`
+	SecretStoreRef  SecretStoreRef       `json:"secretStoreRef"`
+	Target          ExternalSecretTarget `json:"target"`
+	Data            []ExternalSecretData `json:"data"`
+}
+
+// SecretStoreRef references the SecretStore that the data is fetched from.
+type SecretStoreRef struct {
+	Name string `json:"name"`
+	Kind string `json:"kind,omitempty"`
+}
+
+// ExternalSecretTarget is the Secret that the ExternalSecret creates.
+type ExternalSecretTarget struct {
+	Name     string                  `json:"name"`
+	Template *ExternalSecretTemplate `json:"template,omitempty"`
+}
+
+// ExternalSecretTemplate is the type and metadata of the created Secret.
+type ExternalSecretTemplate struct {
+	Type     corev1.SecretType              `json:"type,omitempty"`
+	Metadata ExternalSecretTemplateMetadata `json:"metadata,omitempty"`
+}
+
+// ExternalSecretTemplateMetadata is the metadata of the created Secret.
+type ExternalSecretTemplateMetadata struct {
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ExternalSecretData maps a key of the created Secret to a property of a
+// secret in the SecretStore.
+type ExternalSecretData struct {
+	SecretKey string                      `json:"secretKey"`
+	RemoteRef ExternalSecretDataRemoteRef `json:"remoteRef"`
+}
+
+// ExternalSecretDataRemoteRef is the secret and property in the SecretStore.
+type ExternalSecretDataRemoteRef struct {
+	Key      string `json:"key"`
+	Property string `json:"property,omitempty"`
+}
+
+// CreateExternalSecret creates an ExternalSecret that creates a Secret with
+// the same name, type, annotations and keys as the secret, from the
+// SecretStore with the storeName.
+//
+// The data is fetched from the <namespace>/<name> secret in the store, with a
+// property for each key of the secret e.g. cicd/gitops-webhook-secret with
+// the webhook-secret-key property.
+func CreateExternalSecret(secret *corev1.Secret, storeName string) *ExternalSecret {
+	keys := []string{}
+	for k := range secret.Data {
+		keys = append(keys, k)
+	}
+	for k := range secret.StringData {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	data := []ExternalSecretData{}
+	for _, k := range keys {
+		data = append(data, ExternalSecretData{
+			SecretKey: k,
+			RemoteRef: ExternalSecretDataRemoteRef{Key: secret.Namespace + "/" + secret.Name, Property: k},
+		})
+	}
+	return &ExternalSecret{
+		TypeMeta:   externalSecretTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(secret.Namespace, secret.Name)),
+		Spec: ExternalSecretSpec{
+			RefreshInterval: externalSecretRefreshInterval,
+			SecretStoreRef:  SecretStoreRef{Name: storeName, Kind: "SecretStore"},
+			Target: ExternalSecretTarget{
+				Name: secret.Name,
+				Template: &ExternalSecretTemplate{
+					Type:     secret.Type,
+					Metadata: ExternalSecretTemplateMetadata{Annotations: secret.Annotations},
+				},
+			},
+			Data: data,
+		},
+	}
+}
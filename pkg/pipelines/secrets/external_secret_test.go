@@ -0,0 +1,44 @@
+package secrets
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+)
+
+func TestCreateExternalSecret(t *testing.T) {
+	secret := createBasicAuthSecret(meta.NamespacedName("cicd", "git-host-basic-auth-token"), DefaultBasicAuthUsername, testToken,
+		meta.AddAnnotations(map[string]string{"tekton.dev/git-0": "https://github.com"}))
+
+	want := &ExternalSecret{
+		TypeMeta: metav1.TypeMeta{Kind: "ExternalSecret", APIVersion: "external-secrets.io/v1beta1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "git-host-basic-auth-token",
+			Namespace: "cicd",
+		},
+		Spec: ExternalSecretSpec{
+			RefreshInterval: "1h",
+			SecretStoreRef:  SecretStoreRef{Name: "vault-backend", Kind: "SecretStore"},
+			Target: ExternalSecretTarget{
+				Name: "git-host-basic-auth-token",
+				Template: &ExternalSecretTemplate{
+					Type: corev1.SecretTypeBasicAuth,
+					Metadata: ExternalSecretTemplateMetadata{
+						Annotations: map[string]string{"tekton.dev/git-0": "https://github.com"},
+					},
+				},
+			},
+			Data: []ExternalSecretData{
+				{SecretKey: "password", RemoteRef: ExternalSecretDataRemoteRef{Key: "cicd/git-host-basic-auth-token", Property: "password"}},
+				{SecretKey: "username", RemoteRef: ExternalSecretDataRemoteRef{Key: "cicd/git-host-basic-auth-token", Property: "username"}},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, CreateExternalSecret(secret, "vault-backend")); diff != "" {
+		t.Fatalf("CreateExternalSecret() failed:\n%s", diff)
+	}
+}
@@ -2,6 +2,8 @@ package secrets
 
 import (
 	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -17,6 +19,19 @@ var (
 	secretTypeMeta = meta.TypeMeta("Secret", "v1")
 )
 
+// DefaultBasicAuthUsername is the username of a BasicAuth secret if no other
+// username is provided, Git hosts ignore the username of an access token.
+const DefaultBasicAuthUsername = "tekton"
+
+// The annotations that have kubernetes-reflector replicate a Secret to other
+// namespaces.
+const (
+	reflectionAllowedAnnotation           = "reflector.v1.k8s.emberstack.com/reflection-allowed"
+	reflectionAllowedNamespacesAnnotation = "reflector.v1.k8s.emberstack.com/reflection-allowed-namespaces"
+	reflectionAutoEnabledAnnotation       = "reflector.v1.k8s.emberstack.com/reflection-auto-enabled"
+	reflectionAutoNamespacesAnnotation    = "reflector.v1.k8s.emberstack.com/reflection-auto-namespaces"
+)
+
 // PublicKeyFunc retruns a public key  give a service namedspaced name
 type PublicKeyFunc func(service types.NamespacedName) (*rsa.PublicKey, error)
 
@@ -25,6 +40,35 @@ func MakeServiceWebhookSecretName(envName, serviceName string) string {
 	return fmt.Sprintf("webhook-secret-%s-%s", envName, serviceName)
 }
 
+// ReflectionAnnotations returns the annotations that allow kubernetes-reflector
+// to replicate a Secret to the namespaces, and have it create the replicas
+// automatically.
+func ReflectionAnnotations(namespaces []string) map[string]string {
+	joined := strings.Join(namespaces, ",")
+	return map[string]string{
+		reflectionAllowedAnnotation:           "true",
+		reflectionAllowedNamespacesAnnotation: joined,
+		reflectionAutoEnabledAnnotation:       "true",
+		reflectionAutoNamespacesAnnotation:    joined,
+	}
+}
+
+// DockerConfigJSON returns a Docker config.json that authenticates with the
+// registry as the username with the token, e.g. for a Quay.io robot account.
+func DockerConfigJSON(registry, username, token string) ([]byte, error) {
+	auth := base64.StdEncoding.EncodeToString([]byte(username + ":" + token))
+	config := map[string]interface{}{
+		"auths": map[string]interface{}{
+			registry: map[string]string{"auth": auth},
+		},
+	}
+	b, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the Docker config: %w", err)
+	}
+	return b, nil
+}
+
 // CreateUnsealedDockerConfigSecret creates an Unsealed Secret with the given name and reader
 func CreateUnsealedDockerConfigSecret(name types.NamespacedName, in io.Reader) (*corev1.Secret, error) {
 	secret, err := createDockerConfigSecret(name, in)
@@ -43,10 +87,10 @@ func CreateUnsealedSecret(name types.NamespacedName, data, secretKey string) (*c
 }
 
 // CreateUnsealedBasicAuthSecret creates a SealedSecret with a BasicAuth type
-// secret.
-func CreateUnsealedBasicAuthSecret(name types.NamespacedName, token string,
+// secret, authenticating as username with the token.
+func CreateUnsealedBasicAuthSecret(name types.NamespacedName, username, token string,
 	opts ...meta.ObjectMetaOpt) *corev1.Secret {
-	return createBasicAuthSecret(name, token, opts...)
+	return createBasicAuthSecret(name, username, token, opts...)
 }
 
 // createOpaqueSecret creates a Kubernetes v1/Secret with the provided name and
@@ -62,13 +106,13 @@ func createDockerConfigSecret(name types.NamespacedName, in io.Reader) (*corev1.
 	return createSecret(name, ".dockerconfigjson", corev1.SecretTypeDockerConfigJson, in)
 }
 
-func createBasicAuthSecret(name types.NamespacedName, token string, opts ...meta.ObjectMetaOpt) *corev1.Secret {
+func createBasicAuthSecret(name types.NamespacedName, username, token string, opts ...meta.ObjectMetaOpt) *corev1.Secret {
 	return &corev1.Secret{
 		TypeMeta:   secretTypeMeta,
 		ObjectMeta: meta.ObjectMeta(name, opts...),
 		Type:       corev1.SecretTypeBasicAuth,
 		StringData: map[string]string{
-			"username": "tekton",
+			"username": username,
 			"password": token,
 		},
 	}
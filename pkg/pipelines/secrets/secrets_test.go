@@ -55,6 +55,17 @@ func TestCreateOpaqueSecret(t *testing.T) {
 	}
 }
 
+func TestDockerConfigJSON(t *testing.T) {
+	data, err := DockerConfigJSON("quay.io", "my-org+ci", "robot-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"auths":{"quay.io":{"auth":"bXktb3JnK2NpOnJvYm90LXRva2Vu"}}}`
+	if diff := cmp.Diff(want, string(data)); diff != "" {
+		t.Fatalf("DockerConfigJSON() failed:\n%s", diff)
+	}
+}
+
 func TestCreateDockerConfigSecretWithErrorReading(t *testing.T) {
 	testErr := errors.New("test failure")
 	_, err := createDockerConfigSecret(meta.NamespacedName("cici", "github-auth"), errorReader{testErr})
@@ -87,7 +98,7 @@ func TestCreateDockerConfigSecret(t *testing.T) {
 
 func TestBasicAuthSecret(t *testing.T) {
 	host := "https://github.com"
-	secret := createBasicAuthSecret(meta.NamespacedName("cicd", "github-auth"), testToken, meta.AddAnnotations(
+	secret := createBasicAuthSecret(meta.NamespacedName("cicd", "github-auth"), DefaultBasicAuthUsername, testToken, meta.AddAnnotations(
 		map[string]string{
 			"tekton.dev/git-0": host,
 		}),
@@ -121,3 +132,15 @@ type errorReader struct {
 func (e errorReader) Read(p []byte) (int, error) {
 	return 0, e.err
 }
+
+func TestReflectionAnnotations(t *testing.T) {
+	want := map[string]string{
+		"reflector.v1.k8s.emberstack.com/reflection-allowed":            "true",
+		"reflector.v1.k8s.emberstack.com/reflection-allowed-namespaces": "tst-dev,tst-stage",
+		"reflector.v1.k8s.emberstack.com/reflection-auto-enabled":       "true",
+		"reflector.v1.k8s.emberstack.com/reflection-auto-namespaces":    "tst-dev,tst-stage",
+	}
+	if diff := cmp.Diff(want, ReflectionAnnotations([]string{"tst-dev", "tst-stage"})); diff != "" {
+		t.Fatalf("ReflectionAnnotations() failed:\n%s", diff)
+	}
+}
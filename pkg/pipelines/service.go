@@ -30,6 +30,7 @@ type AddServiceOptions struct {
 	PipelinesFolderPath string
 	ServiceName         string
 	WebhookSecret       string
+	Overwrite           bool // If true, an existing service with the same name is replaced.
 }
 
 // AddService is the entry-point from the CLI for adding new services.
@@ -72,7 +73,7 @@ func serviceResources(m *config.Manifest, appFs afero.Fs, o *AddServiceOptions)
 	svc := createService(o.ServiceName, o.GitRepoURL)
 	cfg := m.GetPipelinesConfig()
 	if cfg != nil && o.WebhookSecret == "" && o.GitRepoURL != "" {
-		gitSecret, err := secrets.GenerateString(webhookSecretLength)
+		gitSecret, err := secrets.GenerateString(WebhookSecretLength)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to generate service webhook secret: %v", err)
 		}
@@ -83,6 +84,12 @@ func serviceResources(m *config.Manifest, appFs afero.Fs, o *AddServiceOptions)
 	if env == nil {
 		return nil, nil, fmt.Errorf("environment %s does not exist", o.EnvName)
 	}
+	if m.GetService(o.EnvName, o.AppName, o.ServiceName) != nil {
+		if !o.Overwrite {
+			return nil, nil, fmt.Errorf("service %s already exists in application %s of environment %s. If you want to replace it, please rerun with --overwrite", o.ServiceName, o.AppName, o.EnvName)
+		}
+		m.RemoveService(o.EnvName, o.AppName, o.ServiceName)
+	}
 
 	// add the secret only if CI/CD env is present
 	if cfg != nil {
@@ -105,14 +112,14 @@ func serviceResources(m *config.Manifest, appFs afero.Fs, o *AddServiceOptions)
 		secretFilename := filepath.ToSlash(filepath.Join("secrets", secretName+".yaml"))
 		otherResources[secretFilename] = opaqueSecret
 
-		if m.Config.Pipelines != nil {
+		if m.Config.Pipelines != nil && !m.Config.Pipelines.DisableAppCI {
 			// add the default pipelines if they're absent
 			if env.Pipelines == nil {
 				repo, err := scm.NewRepository(m.GitOpsURL)
 				if err != nil {
 					return nil, nil, err
 				}
-				env.Pipelines = defaultPipelines(repo)
+				env.Pipelines = defaultPipelines(repo, m.GetPipelineNamePrefix())
 			}
 
 			// use internal registry if no input image registry is provided
@@ -128,6 +135,11 @@ func serviceResources(m *config.Manifest, appFs afero.Fs, o *AddServiceOptions)
 			if err != nil {
 				return nil, nil, err
 			}
+			if argoCDConfig := m.GetArgoCDConfig(); argoCDConfig != nil && argoCDConfig.ImageUpdater != nil {
+				// The image repository was validated when creating the resources.
+				_, imageRepo, _ := imagerepo.ValidateImageRepo(o.ImageRepo)
+				svc.ImageUpdate = &config.ImageUpdate{Repository: imageRepo}
+			}
 
 			files = res.Merge(resources, files)
 			svc.Pipelines = &config.Pipelines{
@@ -170,8 +182,8 @@ func createImageRepoResources(m *config.Manifest, cfg *config.PipelinesConfig, e
 
 	if isInternalRegistry {
 		files, regRes, err := imagerepo.CreateInternalRegistryResources(cfg,
-			roles.CreateServiceAccount(meta.NamespacedName(cfg.Name, saName)),
-			imageRepo, m.GitOpsURL)
+			roles.CreateServiceAccount(meta.NamespacedName(cfg.Name, m.GetServiceAccountName())),
+			imageRepo, m.GitOpsURL, m.UseProjectRequests(), existingNamespaces(m, imageRepo)...)
 		if err != nil {
 			return nil, nil, "", fmt.Errorf("failed to get resources for internal image repository: %v", err)
 		}
@@ -231,8 +243,8 @@ func createConfigFolder(m *config.Manifest, appFs afero.Fs, o *AddServiceOptions
 	}
 	env := m.GetEnvironment(o.EnvName)
 	app := m.GetApplication(o.EnvName, o.AppName)
-	servicePath := config.PathForService(app, env, o.ServiceName)
-	finalPath := filepath.Join(basePath, servicePath, "base", "config")
+	serviceBase := config.PathForServiceBase(app, env, o.ServiceName, m.UsePerEnvOverlays())
+	finalPath := filepath.Join(basePath, serviceBase, "config")
 	err = appFs.MkdirAll(finalPath, 0755)
 	if err != nil {
 		return fmt.Errorf("failed to MkDirAll")
@@ -15,6 +15,7 @@ import (
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
 	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
 	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	"github.com/redhat-developer/kam/test"
 	"github.com/spf13/afero"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -42,7 +43,7 @@ func TestServiceResourcesWithCICD(t *testing.T) {
 			CommonLabels: map[string]string{"app.openshift.io/vcs-source": "org/test"},
 		},
 		"environments/test-dev/apps/test-app/overlays/kustomization.yaml": &res.Kustomization{
-			Bases: []string{"../base"}},
+			Bases: []string{"../base"}, Namespace: "test-dev"},
 		"pipelines.yaml": &config.Manifest{
 			Config: &config.Config{
 				Pipelines: &config.PipelinesConfig{
@@ -130,7 +131,8 @@ func TestServiceResourcesWithArgoCD(t *testing.T) {
 			CommonLabels: map[string]string{"app.openshift.io/vcs-source": "org/test"},
 		},
 		"environments/test-dev/apps/test-app/overlays/kustomization.yaml": &res.Kustomization{
-			Bases: []string{"../base"},
+			Bases:     []string{"../base"},
+			Namespace: "test-dev",
 		},
 		"pipelines.yaml": &config.Manifest{
 			Config: &config.Config{
@@ -194,7 +196,7 @@ func TestServiceResourcesWithoutArgoCD(t *testing.T) {
 			CommonLabels: map[string]string{"app.openshift.io/vcs-source": "org/test"},
 		},
 		"environments/test-dev/apps/test-app/overlays/kustomization.yaml": &res.Kustomization{
-			Bases: []string{"../base"}},
+			Bases: []string{"../base"}, Namespace: "test-dev"},
 		"environments/test-dev/env/base/kustomization.yaml": &res.Kustomization{
 			Resources: []string{"argocd-admin.yaml", "test-dev-environment.yaml"},
 			Bases:     []string{"../../apps/test-app/overlays"},
@@ -249,14 +251,14 @@ func TestAddServiceWithoutApp(t *testing.T) {
 	m := buildManifest(false, false)
 	want := res.Resources{
 		"environments/test-dev/apps/new-app/base/kustomization.yaml":     &res.Kustomization{Bases: []string{"../services/test"}},
-		"environments/test-dev/apps/new-app/overlays/kustomization.yaml": &res.Kustomization{Bases: []string{"../base"}},
+		"environments/test-dev/apps/new-app/overlays/kustomization.yaml": &res.Kustomization{Bases: []string{"../base"}, Namespace: "test-dev"},
 		"environments/test-dev/apps/new-app/kustomization.yaml": &res.Kustomization{
 			Bases:        []string{"overlays"},
 			CommonLabels: map[string]string{"app.openshift.io/vcs-source": "org/test"},
 		},
 		"environments/test-dev/apps/new-app/services/test/base/kustomization.yaml":          &res.Kustomization{Bases: []string{"./config"}},
 		"environments/test-dev/apps/new-app/services/test/kustomization.yaml":               &res.Kustomization{Bases: []string{"overlays"}},
-		"environments/test-dev/apps/new-app/services/test/overlays/kustomization.yaml":      &res.Kustomization{Bases: []string{"../base"}},
+		"environments/test-dev/apps/new-app/services/test/overlays/kustomization.yaml":      &res.Kustomization{Bases: []string{"../base"}, Namespace: "test-dev"},
 		"environments/cicd/base/pipelines/03-secrets/webhook-secret-test-dev-test-svc.yaml": nil,
 		"pipelines.yaml": &config.Manifest{
 			GitOpsURL: "http://github.com/org/test",
@@ -304,6 +306,28 @@ func TestAddServiceWithoutApp(t *testing.T) {
 	}
 }
 
+func TestAddServiceWithExistingService(t *testing.T) {
+	m := buildManifest(false, false)
+	o := &AddServiceOptions{
+		AppName:             "test-app",
+		EnvName:             "test-dev",
+		GitRepoURL:          "http://github.com/org/test-svc",
+		PipelinesFolderPath: pipelinesFile,
+		WebhookSecret:       "123",
+		ServiceName:         "test-svc",
+	}
+	_, _, err := serviceResources(m, ioutils.NewMemoryFilesystem(), o)
+	test.AssertErrorMatch(t, "service test-svc already exists in application test-app of environment test-dev. If you want to replace it, please rerun with --overwrite", err)
+
+	o.Overwrite = true
+	_, _, err = serviceResources(m, ioutils.NewMemoryFilesystem(), o)
+	assertNoError(t, err)
+	want := []*config.Service{{Name: "test-svc", SourceURL: "http://github.com/org/test-svc"}}
+	if diff := cmp.Diff(want, m.GetApplication("test-dev", "test-app").Services); diff != "" {
+		t.Fatalf("services didn't match after overwrite:\n%s", diff)
+	}
+}
+
 func TestAddServiceFilePaths(t *testing.T) {
 	fakeFs := ioutils.NewMemoryFilesystem()
 	outputPath := afero.GetTempDir(fakeFs, "test")
@@ -454,6 +478,26 @@ func TestServiceWithArgoCD(t *testing.T) {
 	}
 }
 
+func TestServiceResourcesWithImageUpdater(t *testing.T) {
+	m := buildManifest(true, true)
+	m.Config.ArgoCD.ImageUpdater = &config.ImageUpdaterConfig{}
+	_, _, err := serviceResources(m, ioutils.NewMemoryFilesystem(), &AddServiceOptions{
+		AppName:             "test-app",
+		EnvName:             "test-dev",
+		GitRepoURL:          "http://github.com/org/test",
+		ImageRepo:           "quay.io/org/test",
+		PipelinesFolderPath: pipelinesFile,
+		WebhookSecret:       "123",
+		ServiceName:         "test",
+	})
+	assertNoError(t, err)
+
+	svc := m.GetEnvironment("test-dev").Apps[0].Services[1]
+	if diff := cmp.Diff(&config.ImageUpdate{Repository: "quay.io/org/test"}, svc.ImageUpdate); diff != "" {
+		t.Fatalf("service image update didn't match:\n%s", diff)
+	}
+}
+
 func TestAddServiceWithImageWithNoPipelines(t *testing.T) {
 	fakeFs := ioutils.NewMemoryFilesystem()
 	outputPath := afero.GetTempDir(fakeFs, "test")
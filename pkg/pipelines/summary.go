@@ -0,0 +1,98 @@
+package pipelines
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+)
+
+// BootstrapSummary is a machine-readable summary of what a bootstrap
+// generated, e.g. so that CI can register the webhooks for the repositories.
+type BootstrapSummary struct {
+	GitOpsRepoURL   string               `json:"gitopsRepoURL"`
+	GitOpsWebhook   *WebhookSummary      `json:"gitopsWebhook,omitempty"`
+	OutputPath      string               `json:"outputPath"`
+	ImageRepo       string               `json:"imageRepo,omitempty"`
+	CICDNamespace   string               `json:"cicdNamespace,omitempty"`
+	ArgoCDNamespace string               `json:"argocdNamespace,omitempty"`
+	Environments    []EnvironmentSummary `json:"environments"`
+	Secrets         []string             `json:"secrets"`
+}
+
+// EnvironmentSummary is an environment that was generated, and the services
+// that are deployed to it.
+type EnvironmentSummary struct {
+	Name      string           `json:"name"`
+	Namespace string           `json:"namespace"`
+	Services  []ServiceSummary `json:"services,omitempty"`
+}
+
+// ServiceSummary is a service that was generated, and the webhook that
+// triggers its CI.
+type ServiceSummary struct {
+	Name        string          `json:"name"`
+	Application string          `json:"application"`
+	SourceURL   string          `json:"sourceURL,omitempty"`
+	Webhook     *WebhookSummary `json:"webhook,omitempty"`
+}
+
+// WebhookSummary is the secret that a webhook is authenticated with.
+type WebhookSummary struct {
+	SecretName      string `json:"secretName"`
+	SecretNamespace string `json:"secretNamespace"`
+}
+
+// SummarizeBootstrap summarizes the manifest and secrets that were written to
+// appFs by a bootstrap, the secrets are summarized after they're encrypted.
+//
+// The paths of the secrets include the output path.
+func SummarizeBootstrap(o *BootstrapOptions, appFs afero.Fs) (*BootstrapSummary, error) {
+	m, err := config.LoadManifest(appFs, o.GitOpsPath())
+	if err != nil {
+		return nil, err
+	}
+	summary := &BootstrapSummary{
+		GitOpsRepoURL: m.GitOpsURL,
+		OutputPath:    o.OutputPath,
+		ImageRepo:     o.ImageRepo,
+		Environments:  []EnvironmentSummary{},
+	}
+	summarizeManifest(summary, m)
+
+	secretsPath := filepath.Join(o.GitOpsPath(), "..", "secrets")
+	secrets, err := afero.Glob(appFs, filepath.Join(secretsPath, "*.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find secrets in %q: %w", secretsPath, err)
+	}
+	sort.Strings(secrets)
+	summary.Secrets = append([]string{}, secrets...)
+	return summary, nil
+}
+
+func summarizeManifest(summary *BootstrapSummary, m *config.Manifest) {
+	if cfg := m.GetPipelinesConfig(); cfg != nil {
+		summary.CICDNamespace = cfg.Name
+		summary.GitOpsWebhook = &WebhookSummary{SecretName: eventlisteners.GitOpsWebhookSecret, SecretNamespace: cfg.Name}
+	}
+	if cfg := m.GetArgoCDConfig(); cfg != nil {
+		summary.ArgoCDNamespace = cfg.Namespace
+	}
+	for _, env := range m.Environments {
+		e := EnvironmentSummary{Name: env.Name, Namespace: env.Name}
+		for _, app := range env.Apps {
+			for _, svc := range app.Services {
+				s := ServiceSummary{Name: svc.Name, Application: app.Name, SourceURL: svc.SourceURL}
+				if svc.Webhook != nil && svc.Webhook.Secret != nil {
+					s.Webhook = &WebhookSummary{SecretName: svc.Webhook.Secret.Name, SecretNamespace: svc.Webhook.Secret.Namespace}
+				}
+				e.Services = append(e.Services, s)
+			}
+		}
+		summary.Environments = append(summary.Environments, e)
+	}
+}
@@ -0,0 +1,50 @@
+package pipelines
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+func TestSummarizeBootstrap(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	bootstrapTree(t, fakeFs, "/gitops")
+
+	summary, err := SummarizeBootstrap(&BootstrapOptions{OutputPath: "/gitops", ImageRepo: "quay.io/tst/http-api"}, fakeFs)
+	fatalIfError(t, err)
+
+	want := &BootstrapSummary{
+		GitOpsRepoURL:   testGitOpsRepo,
+		GitOpsWebhook:   &WebhookSummary{SecretName: "gitops-webhook-secret", SecretNamespace: "tst-cicd"},
+		OutputPath:      "/gitops",
+		ImageRepo:       "quay.io/tst/http-api",
+		CICDNamespace:   "tst-cicd",
+		ArgoCDNamespace: "openshift-gitops",
+		Environments: []EnvironmentSummary{
+			{
+				Name:      "tst-dev",
+				Namespace: "tst-dev",
+				Services: []ServiceSummary{
+					{
+						Name:        "http-api",
+						Application: "app-http-api",
+						SourceURL:   testSvcRepo,
+						Webhook:     &WebhookSummary{SecretName: "webhook-secret-tst-dev-http-api", SecretNamespace: "tst-cicd"},
+					},
+				},
+			},
+			{Name: "tst-stage", Namespace: "tst-stage"},
+		},
+		Secrets: []string{
+			"/secrets/git-host-access-token.yaml",
+			"/secrets/git-host-basic-auth-token.yaml",
+			"/secrets/gitops-webhook-secret.yaml",
+			"/secrets/webhook-secret-tst-dev-http-api.yaml",
+		},
+	}
+	if diff := cmp.Diff(want, summary); diff != "" {
+		t.Fatalf("bootstrap summary didn't match:\n%s", diff)
+	}
+}
@@ -1,32 +1,52 @@
 package tasks
 
 import (
+	"path"
+
 	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
 )
 
-// CreateDeployFromSourceTask creates DeployFromSourceTask
-func CreateDeployFromSourceTask(ns, script string) pipelinev1.Task {
+// CreateDeployFromSourceTask creates DeployFromSourceTask, the script runs in
+// the repoSubpath folder of the GitOps repository, if any.
+func CreateDeployFromSourceTask(ns, script, repoSubpath string) pipelinev1.Task {
 	task := pipelinev1.Task{
 		TypeMeta:   taskTypeMeta,
 		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, "deploy-from-source-task")),
 		Spec: pipelinev1.TaskSpec{
 			Params:    paramsForDeploymentFromSourceTask(),
 			Resources: createResourcesForDeployFromSourceTask(),
-			Steps:     createStepsForDeployFromSourceTask(script),
+			Steps:     createStepsForDeployFromSourceTask(script, path.Join("/workspace/source", repoSubpath)),
 		},
 	}
 	return task
 }
 
-func createStepsForDeployFromSourceTask(script string) []pipelinev1.Step {
+// CreateDeployFromSourceWorkspaceTask creates a DeployFromSourceTask that reads
+// the source from a workspace rather than a git PipelineResource,
+// PipelineResources are not available in tekton.dev/v1.
+func CreateDeployFromSourceWorkspaceTask(ns, script, repoSubpath string) pipelinev1.Task {
+	return pipelinev1.Task{
+		TypeMeta:   taskTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, "deploy-from-source-task")),
+		Spec: pipelinev1.TaskSpec{
+			Params: paramsForDeploymentFromSourceTask(),
+			Workspaces: []pipelinev1.WorkspaceDeclaration{
+				{Name: "source", Description: "The cloned GitOps repository to deploy from."},
+			},
+			Steps: createStepsForDeployFromSourceTask(script, path.Join("$(workspaces.source.path)", repoSubpath)),
+		},
+	}
+}
+
+func createStepsForDeployFromSourceTask(script, workingDir string) []pipelinev1.Step {
 	return []pipelinev1.Step{
 		{
 			Container: createContainer(
 				"run-kubectl",
 				"quay.io/redhat-developer/k8s-kubectl",
-				"/workspace/source",
+				workingDir,
 				nil,
 				nil,
 			),
@@ -43,6 +63,12 @@ func paramsForDeploymentFromSourceTask() []pipelinev1.ParamSpec {
 			pipelinev1.ParamTypeString,
 			"false",
 		),
+		createTaskParamWithDefault(
+			"ENVIRONMENTS",
+			"The space-separated names of the environments to apply, all of the environments if empty.",
+			pipelinev1.ParamTypeString,
+			"",
+		),
 	}
 }
 
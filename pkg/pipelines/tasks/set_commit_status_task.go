@@ -1,6 +1,8 @@
 package tasks
 
 import (
+	"fmt"
+
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
@@ -14,34 +16,13 @@ func CreateCommitStatusTask(namespace string) *pipelinev1.Task {
 		TypeMeta:   taskTypeMeta,
 		ObjectMeta: meta.ObjectMeta(types.NamespacedName{Name: "set-commit-status", Namespace: namespace}),
 		Spec: pipelinev1.TaskSpec{
-			Params: []v1beta1.ParamSpec{
-				createTaskParam("GIT_REPO", "", pipelinev1.ParamTypeString),
-				createTaskParam("REPO", "", pipelinev1.ParamTypeString),
-				createTaskParamWithDefault("GIT_TOKEN_SECRET_NAME", "", pipelinev1.ParamTypeString, "git-host-access-token"),
-				createTaskParamWithDefault("GIT_TOKEN_SECRET_KEY", "", pipelinev1.ParamTypeString, "token"),
-				createTaskParam("COMMIT_SHA", "", pipelinev1.ParamTypeString),
-				createTaskParam("DESCRIPTION", "", pipelinev1.ParamTypeString),
-				createTaskParamWithDefault("CONTEXT", "", pipelinev1.ParamTypeString, "continous-integration/tekton"),
-				createTaskParam("STATE", "", pipelinev1.ParamTypeString),
-			},
+			Params: commitStatusParams(),
 			Steps: []v1beta1.Step{
 				{
 					Container: v1.Container{
 						Name:  "set-commit-status",
 						Image: "quay.io/redhat-developer/gitops-commit-status@sha256:ef5b3b242bf3b42a3a5d3ff74b3c7d495c608297b7428ae57b8ece10954e7546",
-						Env: []v1.EnvVar{
-							{
-								Name: "GITHOSTACCESSTOKEN",
-								ValueFrom: &v1.EnvVarSource{
-									SecretKeyRef: &v1.SecretKeySelector{
-										LocalObjectReference: v1.LocalObjectReference{
-											Name: "$(params.GIT_TOKEN_SECRET_NAME)",
-										},
-										Key: "$(params.GIT_TOKEN_SECRET_KEY)",
-									},
-								},
-							},
-						},
+						Env:   commitStatusEnv(),
 					},
 					Script: "gitops-commit-status --url $(params.GIT_REPO) --path $(params.REPO) --sha $(params.COMMIT_SHA) --context $(params.CONTEXT) --status $(params.STATE)",
 				},
@@ -49,3 +30,134 @@ func CreateCommitStatusTask(namespace string) *pipelinev1.Task {
 		},
 	}
 }
+
+// CreateEnterpriseCommitStatusTask creates a task to add commit status to
+// repositories on a GitHub Enterprise Server, with the API at apiURL e.g.
+// https://github.example.com/api/v3.
+//
+// The task has the same params as CreateCommitStatusTask, so the pipelines
+// that set commit statuses work with either task.
+func CreateEnterpriseCommitStatusTask(namespace, apiURL string) *pipelinev1.Task {
+	return &pipelinev1.Task{
+		TypeMeta:   taskTypeMeta,
+		ObjectMeta: meta.ObjectMeta(types.NamespacedName{Name: "set-commit-status", Namespace: namespace}),
+		Spec: pipelinev1.TaskSpec{
+			Params: commitStatusParams(),
+			Steps: []v1beta1.Step{
+				{
+					Container: v1.Container{
+						Name:  "set-commit-status",
+						Image: enterpriseCommitStatusImage,
+						Env:   append(commitStatusEnv(), commitStatusParamsEnv()...),
+					},
+					Script: fmt.Sprintf(enterpriseCommitStatusScript, apiURL),
+				},
+			},
+		},
+	}
+}
+
+// CreateAzureCommitStatusTask creates a task to add commit status to Azure
+// DevOps repositories, with the API of the organization or collection at
+// apiURL e.g. https://dev.azure.com/my-org.
+//
+// The REPO param is the project and name of the repository, and the GitHub
+// states that the pipelines set are mapped to the Azure DevOps states.
+func CreateAzureCommitStatusTask(namespace, apiURL string) *pipelinev1.Task {
+	return &pipelinev1.Task{
+		TypeMeta:   taskTypeMeta,
+		ObjectMeta: meta.ObjectMeta(types.NamespacedName{Name: "set-commit-status", Namespace: namespace}),
+		Spec: pipelinev1.TaskSpec{
+			Params: commitStatusParams(),
+			Steps: []v1beta1.Step{
+				{
+					Container: v1.Container{
+						Name:  "set-commit-status",
+						Image: enterpriseCommitStatusImage,
+						Env:   append(commitStatusEnv(), commitStatusParamsEnv()...),
+					},
+					Script: fmt.Sprintf(azureCommitStatusScript, apiURL),
+				},
+			},
+		},
+	}
+}
+
+const (
+	// TODO: pin the image by digest, as the gitops-commit-status image is.
+	enterpriseCommitStatusImage = "registry.access.redhat.com/ubi8/ubi-minimal:8.4"
+
+	// jsonStringFunc quotes its argument as a JSON string, the image doesn't
+	// have jq.
+	jsonStringFunc = `json_string() {
+  local s=${1//\\/\\\\}
+  s=${s//\"/\\\"}
+  s=${s//$'\n'/\\n}
+  s=${s//$'\r'/\\r}
+  s=${s//$'\t'/\\t}
+  echo -n "\"${s}\""
+}
+`
+
+	enterpriseCommitStatusScript = `#!/bin/bash
+` + jsonStringFunc + `BODY="{\"state\": $(json_string "${STATE}"), \"description\": $(json_string "${DESCRIPTION}"), \"context\": $(json_string "${CONTEXT}")}"
+curl --fail --silent --show-error -X POST \
+  -H "Authorization: token ${GITHOSTACCESSTOKEN}" \
+  -H "Accept: application/vnd.github.v3+json" \
+  -d "${BODY}" \
+  "%s/repos/${REPO}/statuses/${COMMIT_SHA}"`
+
+	azureCommitStatusScript = `#!/bin/bash
+` + jsonStringFunc + `case "${STATE}" in
+  success) STATE=succeeded ;;
+  failure) STATE=failed ;;
+esac
+BODY="{\"state\": $(json_string "${STATE}"), \"description\": $(json_string "${DESCRIPTION}"), \"context\": {\"name\": $(json_string "${CONTEXT}")}}"
+curl --fail --silent --show-error -X POST \
+  -u ":${GITHOSTACCESSTOKEN}" \
+  -H "Content-Type: application/json" \
+  -d "${BODY}" \
+  "%s/${REPO%%%%/*}/_apis/git/repositories/${REPO#*/}/commits/${COMMIT_SHA}/statuses?api-version=6.0"`
+)
+
+func commitStatusParams() []v1beta1.ParamSpec {
+	return []v1beta1.ParamSpec{
+		createTaskParam("GIT_REPO", "", pipelinev1.ParamTypeString),
+		createTaskParam("REPO", "", pipelinev1.ParamTypeString),
+		createTaskParamWithDefault("GIT_TOKEN_SECRET_NAME", "", pipelinev1.ParamTypeString, "git-host-access-token"),
+		createTaskParamWithDefault("GIT_TOKEN_SECRET_KEY", "", pipelinev1.ParamTypeString, "token"),
+		createTaskParam("COMMIT_SHA", "", pipelinev1.ParamTypeString),
+		createTaskParam("DESCRIPTION", "", pipelinev1.ParamTypeString),
+		createTaskParamWithDefault("CONTEXT", "", pipelinev1.ParamTypeString, "continous-integration/tekton"),
+		createTaskParam("STATE", "", pipelinev1.ParamTypeString),
+	}
+}
+
+// commitStatusEnv returns the environment of the commit status step, with
+// the token to authenticate to the Git host API.
+func commitStatusEnv() []v1.EnvVar {
+	return []v1.EnvVar{
+		{
+			Name: "GITHOSTACCESSTOKEN",
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: &v1.SecretKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{
+						Name: "$(params.GIT_TOKEN_SECRET_NAME)",
+					},
+					Key: "$(params.GIT_TOKEN_SECRET_KEY)",
+				},
+			},
+		},
+	}
+}
+
+// commitStatusParamsEnv returns the environment that passes the params of the
+// commit status to the step's script, so that the values aren't interpreted
+// by the shell.
+func commitStatusParamsEnv() []v1.EnvVar {
+	env := []v1.EnvVar{}
+	for _, name := range []string{"REPO", "COMMIT_SHA", "DESCRIPTION", "CONTEXT", "STATE"} {
+		env = append(env, v1.EnvVar{Name: name, Value: "$(params." + name + ")"})
+	}
+	return env
+}
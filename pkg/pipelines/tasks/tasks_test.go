@@ -1,6 +1,7 @@
 package tasks
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -33,12 +34,42 @@ func TestDeployFromSourceTask(t *testing.T) {
 			},
 		},
 	}
-	deployFromSourceTask := CreateDeployFromSourceTask(testNS, "test")
+	deployFromSourceTask := CreateDeployFromSourceTask(testNS, "test", "")
 	if diff := cmp.Diff(wantedTask, deployFromSourceTask); diff != "" {
 		t.Fatalf("CreateDeployFromSourceTask() failed \n%s", diff)
 	}
 }
 
+func TestDeployFromSourceWorkspaceTask(t *testing.T) {
+	wantedTask := pipelinev1.Task{
+		TypeMeta: taskTypeMeta,
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "deploy-from-source-task",
+			Namespace: testNS,
+		},
+		Spec: pipelinev1.TaskSpec{
+			Params: paramsForDeploymentFromSourceTask(),
+			Workspaces: []pipelinev1.WorkspaceDeclaration{
+				{Name: "source", Description: "The cloned GitOps repository to deploy from."},
+			},
+			Steps: []pipelinev1.Step{
+				{
+					Container: corev1.Container{
+						Name:       "run-kubectl",
+						Image:      "quay.io/redhat-developer/k8s-kubectl",
+						WorkingDir: "$(workspaces.source.path)",
+					},
+					Script: "test",
+				},
+			},
+		},
+	}
+	deployFromSourceTask := CreateDeployFromSourceWorkspaceTask(testNS, "test", "")
+	if diff := cmp.Diff(wantedTask, deployFromSourceTask); diff != "" {
+		t.Fatalf("CreateDeployFromSourceWorkspaceTask() failed \n%s", diff)
+	}
+}
+
 func TestCreateTaskParamWithDefault(t *testing.T) {
 	validTaskParam := pipelinev1.ParamSpec{
 		Name:        "sample",
@@ -93,3 +124,59 @@ func TestCreateTaskResource(t *testing.T) {
 		t.Fatalf("createTaskResource() failed:\n%s", diff)
 	}
 }
+
+func TestCreateEnterpriseCommitStatusTask(t *testing.T) {
+	task := CreateEnterpriseCommitStatusTask(testNS, "https://ghe.example.com/api/v3")
+
+	if diff := cmp.Diff(CreateCommitStatusTask(testNS).Spec.Params, task.Spec.Params); diff != "" {
+		t.Fatalf("enterprise task params don't match the commit status task:\n%s", diff)
+	}
+	script := task.Spec.Steps[0].Script
+	want := `"https://ghe.example.com/api/v3/repos/${REPO}/statuses/${COMMIT_SHA}"`
+	if !strings.Contains(script, want) {
+		t.Fatalf("script doesn't post the status to %s:\n%s", want, script)
+	}
+	if name := task.Spec.Steps[0].Env[0].Name; name != "GITHOSTACCESSTOKEN" {
+		t.Fatalf("got token env %q", name)
+	}
+	assertParamsNotInScript(t, task)
+}
+
+func TestCreateAzureCommitStatusTask(t *testing.T) {
+	task := CreateAzureCommitStatusTask(testNS, "https://dev.azure.com/my-org")
+
+	if diff := cmp.Diff(CreateCommitStatusTask(testNS).Spec.Params, task.Spec.Params); diff != "" {
+		t.Fatalf("azure task params don't match the commit status task:\n%s", diff)
+	}
+	script := task.Spec.Steps[0].Script
+	for _, want := range []string{
+		`"https://dev.azure.com/my-org/${REPO%%/*}/_apis/git/repositories/${REPO#*/}/commits/${COMMIT_SHA}/statuses?api-version=6.0"`,
+		"success) STATE=succeeded ;;",
+		"failure) STATE=failed ;;",
+	} {
+		if !strings.Contains(script, want) {
+			t.Fatalf("script doesn't contain %s:\n%s", want, script)
+		}
+	}
+	assertParamsNotInScript(t, task)
+}
+
+// The params are passed to the script in the environment, so that quotes in
+// e.g. the description can't break out of the JSON body.
+func assertParamsNotInScript(t *testing.T, task *pipelinev1.Task) {
+	t.Helper()
+	step := task.Spec.Steps[0]
+	if strings.Contains(step.Script, "$(params.") {
+		t.Fatalf("script interpolates params:\n%s", step.Script)
+	}
+	want := []corev1.EnvVar{
+		{Name: "REPO", Value: "$(params.REPO)"},
+		{Name: "COMMIT_SHA", Value: "$(params.COMMIT_SHA)"},
+		{Name: "DESCRIPTION", Value: "$(params.DESCRIPTION)"},
+		{Name: "CONTEXT", Value: "$(params.CONTEXT)"},
+		{Name: "STATE", Value: "$(params.STATE)"},
+	}
+	if diff := cmp.Diff(want, step.Env[1:]); diff != "" {
+		t.Fatalf("params env didn't match:\n%s", diff)
+	}
+}
@@ -0,0 +1,164 @@
+package tekton
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+)
+
+const (
+	// V1Beta1 is the default Tekton Pipelines API version of the generated
+	// resources.
+	V1Beta1 = "v1beta1"
+	// V1 is the tekton.dev/v1 API version.
+	V1 = "v1"
+
+	pipelinesGroup = "tekton.dev"
+	triggersGroup  = "triggers.tekton.dev"
+)
+
+// SupportedAPIVersions is the list of Tekton Pipelines API versions that
+// resources can be generated for.
+var SupportedAPIVersions = []string{V1Beta1, V1}
+
+// IsSupportedAPIVersion returns true if resources can be generated for the
+// Tekton Pipelines API version.
+func IsSupportedAPIVersion(v string) bool {
+	for _, s := range SupportedAPIVersions {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// ConvertResources converts the tekton.dev/v1beta1 resources in files to the
+// requested API version, including PipelineRuns embedded in TriggerTemplates.
+//
+// Other resources are returned unchanged.
+func ConvertResources(files res.Resources, apiVersion string) (res.Resources, error) {
+	if apiVersion == "" || apiVersion == V1Beta1 {
+		return files, nil
+	}
+	if apiVersion != V1 {
+		return nil, fmt.Errorf("unsupported Tekton API version %q", apiVersion)
+	}
+	converted := res.Resources{}
+	for path, v := range files {
+		obj, err := toMap(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s: %w", path, err)
+		}
+		if !isTektonResource(obj) {
+			converted[path] = v
+			continue
+		}
+		if err := convertToV1(obj); err != nil {
+			return nil, fmt.Errorf("failed to convert %s to %s/%s: %w", path, pipelinesGroup, V1, err)
+		}
+		converted[path] = obj
+	}
+	return converted, nil
+}
+
+func toMap(v interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	obj := map[string]interface{}{}
+	// Resources that aren't objects e.g. a list of strings are left alone.
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return nil, nil
+	}
+	return obj, nil
+}
+
+func isTektonResource(obj map[string]interface{}) bool {
+	apiVersion, _ := obj["apiVersion"].(string)
+	if strings.HasPrefix(apiVersion, triggersGroup+"/") {
+		return obj["kind"] == "TriggerTemplate"
+	}
+	return apiVersion == pipelinesGroup+"/"+V1Beta1
+}
+
+func convertToV1(obj map[string]interface{}) error {
+	apiVersion, _ := obj["apiVersion"].(string)
+	if strings.HasPrefix(apiVersion, triggersGroup+"/") && obj["kind"] == "TriggerTemplate" {
+		return convertTriggerTemplate(obj)
+	}
+	if apiVersion != pipelinesGroup+"/"+V1Beta1 {
+		return nil
+	}
+	obj["apiVersion"] = pipelinesGroup + "/" + V1
+	spec, _ := obj["spec"].(map[string]interface{})
+	if spec == nil {
+		return nil
+	}
+	kind, _ := obj["kind"].(string)
+	if _, ok := spec["resources"]; ok {
+		return fmt.Errorf("%s uses PipelineResources which are not available", kind)
+	}
+	switch kind {
+	case "Task":
+		convertSteps(spec)
+	case "Pipeline":
+		for _, field := range []string{"tasks", "finally"} {
+			for _, t := range objects(spec[field]) {
+				if _, ok := t["resources"]; ok {
+					return fmt.Errorf("pipeline task %v uses PipelineResources which are not available", t["name"])
+				}
+				if taskSpec, ok := t["taskSpec"].(map[string]interface{}); ok {
+					convertSteps(taskSpec)
+				}
+			}
+		}
+	case "PipelineRun":
+		if sa, ok := spec["serviceAccountName"]; ok {
+			delete(spec, "serviceAccountName")
+			spec["taskRunTemplate"] = map[string]interface{}{"serviceAccountName": sa}
+		}
+		if timeout, ok := spec["timeout"]; ok {
+			delete(spec, "timeout")
+			spec["timeouts"] = map[string]interface{}{"pipeline": timeout}
+		}
+	}
+	return nil
+}
+
+// convertSteps renames the step resources to computeResources and replaces the
+// removed $(inputs.params.*) substitutions with $(params.*).
+func convertSteps(spec map[string]interface{}) {
+	for _, step := range objects(spec["steps"]) {
+		if r, ok := step["resources"]; ok {
+			delete(step, "resources")
+			step["computeResources"] = r
+		}
+		if script, ok := step["script"].(string); ok {
+			step["script"] = strings.ReplaceAll(script, "$(inputs.params.", "$(params.")
+		}
+	}
+}
+
+func convertTriggerTemplate(obj map[string]interface{}) error {
+	spec, _ := obj["spec"].(map[string]interface{})
+	for _, t := range objects(spec["resourcetemplates"]) {
+		if err := convertToV1(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func objects(v interface{}) []map[string]interface{} {
+	items, _ := v.([]interface{})
+	objs := []map[string]interface{}{}
+	for _, item := range items {
+		if obj, ok := item.(map[string]interface{}); ok {
+			objs = append(objs, obj)
+		}
+	}
+	return objs
+}
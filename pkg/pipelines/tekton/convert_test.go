@@ -0,0 +1,122 @@
+package tekton
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestConvertResourcesToV1(t *testing.T) {
+	task := &pipelinev1.Task{
+		TypeMeta:   meta.TypeMeta("Task", "tekton.dev/v1beta1"),
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName("test-ns", "test-task")),
+		Spec: pipelinev1.TaskSpec{
+			Steps: []pipelinev1.Step{
+				{
+					Container: corev1.Container{
+						Name: "run",
+						Resources: corev1.ResourceRequirements{
+							Limits: corev1.ResourceList{"cpu": resource.MustParse("1")},
+						},
+					},
+					Script: "echo $(inputs.params.DRYRUN)",
+				},
+			},
+		},
+	}
+	run := &pipelinev1.PipelineRun{
+		TypeMeta:   meta.TypeMeta("PipelineRun", "tekton.dev/v1beta1"),
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName("", "test-run")),
+		Spec: pipelinev1.PipelineRunSpec{
+			ServiceAccountName: "pipeline",
+			PipelineRef:        &pipelinev1.PipelineRef{Name: "test-pipeline"},
+		},
+	}
+	template := &triggersv1.TriggerTemplate{
+		TypeMeta:   meta.TypeMeta("TriggerTemplate", "triggers.tekton.dev/v1alpha1"),
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName("test-ns", "test-template")),
+		Spec: triggersv1.TriggerTemplateSpec{
+			ResourceTemplates: []triggersv1.TriggerResourceTemplate{
+				{RawExtension: runtime.RawExtension{Object: run}},
+			},
+		},
+	}
+	namespace := &corev1.Namespace{TypeMeta: meta.TypeMeta("Namespace", "v1")}
+
+	converted, err := ConvertResources(res.Resources{
+		"task.yaml":      task,
+		"template.yaml":  template,
+		"namespace.yaml": namespace,
+	}, V1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotTask := converted["task.yaml"].(map[string]interface{})
+	if v := gotTask["apiVersion"]; v != "tekton.dev/v1" {
+		t.Fatalf("got task apiVersion %v, want tekton.dev/v1", v)
+	}
+	wantStep := map[string]interface{}{
+		"name":             "run",
+		"computeResources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "1"}},
+		"script":           "echo $(params.DRYRUN)",
+	}
+	gotStep := gotTask["spec"].(map[string]interface{})["steps"].([]interface{})[0]
+	if diff := cmp.Diff(wantStep, gotStep); diff != "" {
+		t.Fatalf("task step conversion failed:\n%s", diff)
+	}
+
+	gotTemplate := converted["template.yaml"].(map[string]interface{})
+	if v := gotTemplate["apiVersion"]; v != "triggers.tekton.dev/v1alpha1" {
+		t.Fatalf("got template apiVersion %v, want triggers.tekton.dev/v1alpha1", v)
+	}
+	gotRun := gotTemplate["spec"].(map[string]interface{})["resourcetemplates"].([]interface{})[0].(map[string]interface{})
+	wantRunSpec := map[string]interface{}{
+		"pipelineRef":     map[string]interface{}{"name": "test-pipeline"},
+		"taskRunTemplate": map[string]interface{}{"serviceAccountName": "pipeline"},
+	}
+	if v := gotRun["apiVersion"]; v != "tekton.dev/v1" {
+		t.Fatalf("got pipelinerun apiVersion %v, want tekton.dev/v1", v)
+	}
+	if diff := cmp.Diff(wantRunSpec, gotRun["spec"]); diff != "" {
+		t.Fatalf("pipelinerun conversion failed:\n%s", diff)
+	}
+
+	if diff := cmp.Diff(namespace, converted["namespace.yaml"]); diff != "" {
+		t.Fatalf("non-Tekton resource was modified:\n%s", diff)
+	}
+}
+
+func TestConvertResourcesWithPipelineResources(t *testing.T) {
+	pipeline := &pipelinev1.Pipeline{
+		TypeMeta:   meta.TypeMeta("Pipeline", "tekton.dev/v1beta1"),
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName("test-ns", "test-pipeline")),
+		Spec: pipelinev1.PipelineSpec{
+			Resources: []pipelinev1.PipelineDeclaredResource{{Name: "source-repo", Type: "git"}},
+		},
+	}
+
+	_, err := ConvertResources(res.Resources{"pipeline.yaml": pipeline}, V1)
+	test.AssertErrorMatch(t, "failed to convert pipeline.yaml to tekton.dev/v1: Pipeline uses PipelineResources", err)
+}
+
+func TestConvertResourcesToV1Beta1(t *testing.T) {
+	files := res.Resources{"namespace.yaml": &corev1.Namespace{}}
+
+	converted, err := ConvertResources(files, V1Beta1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(files, converted); diff != "" {
+		t.Fatalf("resources were converted:\n%s", diff)
+	}
+}
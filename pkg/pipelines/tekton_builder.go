@@ -3,6 +3,7 @@ package pipelines
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
@@ -12,9 +13,12 @@ import (
 )
 
 type tektonBuilder struct {
-	files      res.Resources
-	gitOpsRepo string
-	triggers   []v1alpha1.EventListenerTrigger
+	files              res.Resources
+	gitOpsRepo         string
+	disableAppCI       bool
+	pipelineNamePrefix string
+	defaultBranch      string
+	triggers           []v1alpha1.EventListenerTrigger
 }
 
 func buildEventListenerResources(gitOpsRepo string, m *config.Manifest) (res.Resources, error) {
@@ -26,8 +30,8 @@ func buildEventListenerResources(gitOpsRepo string, m *config.Manifest) (res.Res
 		return nil, nil
 	}
 	files := make(res.Resources)
-	tb := &tektonBuilder{files: files, gitOpsRepo: gitOpsRepo}
-	triggers, err := createTriggersForCICD(tb.gitOpsRepo, cfg)
+	tb := &tektonBuilder{files: files, gitOpsRepo: gitOpsRepo, disableAppCI: cfg.DisableAppCI, pipelineNamePrefix: cfg.PipelineNamePrefix, defaultBranch: cfg.DefaultBranch}
+	triggers, err := createTriggersForCICD(tb.gitOpsRepo, cfg, m.Environments)
 	if err != nil {
 		return nil, err
 	}
@@ -36,21 +40,39 @@ func buildEventListenerResources(gitOpsRepo string, m *config.Manifest) (res.Res
 	if err != nil {
 		return nil, err
 	}
+	if cfg.WebhookInterceptorURL != "" {
+		interceptor, err := eventlisteners.WebhookInterceptor(cfg.WebhookInterceptorURL)
+		if err != nil {
+			return nil, err
+		}
+		tb.triggers = eventlisteners.AddInterceptors(tb.triggers, interceptor)
+	}
 	cicdPath := config.PathForPipelines(cfg)
-	files[getEventListenerPath(cicdPath)] = eventlisteners.CreateELFromTriggers(cfg.Name, saName, tb.triggers)
+	eventListener := eventlisteners.CreateELFromTriggers(cfg.Name, m.GetServiceAccountName(), tb.triggers)
+	if cfg.EventListenerResources != nil {
+		resources, err := cfg.EventListenerResources.Requirements()
+		if err != nil {
+			return nil, err
+		}
+		eventlisteners.SetResources(eventListener, resources)
+	}
+	files[getEventListenerPath(cicdPath)] = eventListener
 	return files, nil
 }
 
 func (tb *tektonBuilder) Service(app *config.Application, env *config.Environment, svc *config.Service) error {
-	if svc.SourceURL == "" {
+	if svc.SourceURL == "" || tb.disableAppCI {
 		return nil
 	}
 	repo, err := scm.NewRepository(svc.SourceURL)
 	if err != nil {
 		return err
 	}
-	pipelines := getPipelines(env, svc, repo)
+	pipelines := getPipelines(env, svc, repo, tb.pipelineNamePrefix)
 	ciTrigger := repo.CreatePushTrigger(triggerName(svc.Name), svc.Webhook.Secret.Name, svc.Webhook.Secret.Namespace, pipelines.Integration.Template, pipelines.Integration.Bindings)
+	if tb.defaultBranch != "" {
+		ciTrigger = repo.CreateBranchPushTrigger(triggerName(svc.Name), svc.Webhook.Secret.Name, svc.Webhook.Secret.Namespace, pipelines.Integration.Template, pipelines.Integration.Bindings, []string{tb.defaultBranch})
+	}
 	tb.triggers = append(tb.triggers, ciTrigger)
 	return nil
 }
@@ -59,19 +81,70 @@ func getEventListenerPath(cicdPath string) string {
 	return filepath.ToSlash(filepath.Join(cicdPath, "base", eventListenerPath))
 }
 
-func createTriggersForCICD(gitOpsRepo string, cfg *config.PipelinesConfig) ([]v1alpha1.EventListenerTrigger, error) {
+// createTriggersForCICD creates the CI dry-run triggers for the GitOps
+// repository, if any of the environments restrict the branches, a trigger is
+// created for each of these environments that dry-runs only that environment,
+// and the trigger for all pushes dry-runs the remaining environments.
+//
+// If the merge requests are dry-run, see eventlisteners.DryRunEvents, a
+// trigger is also created for the merge requests to any branch.
+func createTriggersForCICD(gitOpsRepo string, cfg *config.PipelinesConfig, envs []*config.Environment) ([]v1alpha1.EventListenerTrigger, error) {
 	triggers := []v1alpha1.EventListenerTrigger{}
 	repo, err := scm.NewRepository(gitOpsRepo)
 	if err != nil {
 		return []v1alpha1.EventListenerTrigger{}, err
 	}
-	ciTrigger := repo.CreatePushTrigger("ci-dryrun-from-push", eventlisteners.GitOpsWebhookSecret, cfg.Name, "ci-dryrun-from-push-template", []string{repo.PushBindingName()})
-	triggers = append(triggers, ciTrigger)
+	push, pullRequests := eventlisteners.DryRunEvents(repo, cfg.DryRunTrigger)
+	if push {
+		template := cfg.PipelineNamePrefix + "ci-dryrun-from-push-template"
+		bindings := []string{cfg.PipelineNamePrefix + repo.PushBindingName()}
+		unrestricted := []string{}
+		for _, env := range envs {
+			if len(env.Branches) == 0 {
+				unrestricted = append(unrestricted, env.Name)
+				continue
+			}
+			trigger := repo.CreateBranchPushTrigger("ci-dryrun-from-push-"+env.Name, eventlisteners.GitOpsWebhookSecret, cfg.Name, template, bindings, env.Branches)
+			triggers = append(triggers, withEnvironmentsBinding(trigger, env.Name))
+		}
+		if len(triggers) == 0 {
+			triggers = append(triggers, repo.CreatePushTrigger("ci-dryrun-from-push", eventlisteners.GitOpsWebhookSecret, cfg.Name, template, bindings))
+		} else if len(unrestricted) > 0 {
+			trigger := repo.CreatePushTrigger("ci-dryrun-from-push", eventlisteners.GitOpsWebhookSecret, cfg.Name, template, bindings)
+			triggers = append(triggers, withEnvironmentsBinding(trigger, strings.Join(unrestricted, " ")))
+		}
+	}
+	if mrTrigger, ok := eventlisteners.MergeRequestTrigger(repo, cfg.Name, eventlisteners.GitOpsWebhookSecret, cfg.PipelineNamePrefix); ok && pullRequests {
+		triggers = append(triggers, mrTrigger)
+	}
 	return triggers, nil
 }
 
-func getPipelines(env *config.Environment, svc *config.Service, r scm.Repository) *config.Pipelines {
-	pipelines := defaultPipelines(r)
+// withEnvironmentsBinding binds the environments param of the CI dry-run
+// template, so that the trigger dry-runs only the named environments.
+func withEnvironmentsBinding(trigger v1alpha1.EventListenerTrigger, names string) v1alpha1.EventListenerTrigger {
+	trigger.Bindings = append(trigger.Bindings, &v1alpha1.EventListenerBinding{Name: "environments", Value: &names})
+	return trigger
+}
+
+// gitOpsRepoBindings creates the bindings for the CI dry-run triggers of the
+// GitOps repository, keyed by their paths relative to the CI/CD base.
+func gitOpsRepoBindings(repo scm.Repository, ns, pipelineNamePrefix, dryRunTrigger string) res.Resources {
+	files := res.Resources{}
+	pushBinding, pushBindingName := repo.CreatePushBinding(ns)
+	pushBinding.Name = pipelineNamePrefix + pushBindingName
+	files[filepath.ToSlash(filepath.Join("05-bindings", pushBinding.Name+".yaml"))] = pushBinding
+	if _, pullRequests := eventlisteners.DryRunEvents(repo, dryRunTrigger); pullRequests {
+		if mrBinding, mrBindingName := repo.CreateMergeRequestBinding(ns); mrBindingName != "" {
+			mrBinding.Name = pipelineNamePrefix + mrBindingName
+			files[filepath.ToSlash(filepath.Join("05-bindings", mrBinding.Name+".yaml"))] = mrBinding
+		}
+	}
+	return files
+}
+
+func getPipelines(env *config.Environment, svc *config.Service, r scm.Repository, pipelineNamePrefix string) *config.Pipelines {
+	pipelines := defaultPipelines(r, pipelineNamePrefix)
 	if env.Pipelines != nil {
 		pipelines = clonePipelines(env.Pipelines)
 	}
@@ -11,6 +11,8 @@ import (
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
 	"github.com/redhat-developer/kam/pkg/pipelines/scm"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 const testRepoName = "http://github.com/org/gitops.git"
@@ -32,7 +34,7 @@ func TestBuildEventListener(t *testing.T) {
 	got, err := buildEventListenerResources(testRepoName, m)
 	assertNoError(t, err)
 	want := res.Resources{
-		getEventListenerPath(cicdPath): eventlisteners.CreateELFromTriggers("test-cicd", saName, fakeTriggers(t, m, testRepoName)),
+		getEventListenerPath(cicdPath): eventlisteners.CreateELFromTriggers("test-cicd", config.DefaultServiceAccountName, fakeTriggers(t, m, testRepoName)),
 	}
 	if diff := cmp.Diff(want, got); diff != "" {
 		t.Fatalf("resources didn't match:%s\n", diff)
@@ -56,13 +58,144 @@ func TestBuildEventListenerWithServiceWithNoURL(t *testing.T) {
 	got, err := buildEventListenerResources(gitOpsRepo, m)
 	assertNoError(t, err)
 	want := res.Resources{
-		getEventListenerPath(cicdPath): eventlisteners.CreateELFromTriggers("test-cicd", saName, fakeTriggers(t, m, gitOpsRepo)),
+		getEventListenerPath(cicdPath): eventlisteners.CreateELFromTriggers("test-cicd", config.DefaultServiceAccountName, fakeTriggers(t, m, gitOpsRepo)),
 	}
 	if diff := cmp.Diff(want, got); diff != "" {
 		t.Fatalf("resources didn't match:%s\n", diff)
 	}
 }
 
+func TestBuildEventListenerWithAppCIDisabled(t *testing.T) {
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{
+				Name:         "test-cicd",
+				DisableAppCI: true,
+			},
+		},
+		Environments: []*config.Environment{
+			testEnv(testService(), "dev"),
+		},
+		GitOpsURL: "http://github.com/org/gitops.git",
+	}
+	cicdPath := filepath.ToSlash(filepath.Join("config", "test-cicd"))
+	got, err := buildEventListenerResources(testRepoName, m)
+	assertNoError(t, err)
+	triggers, err := createTriggersForCICD(testRepoName, m.Config.Pipelines, m.Environments)
+	assertNoError(t, err)
+	want := res.Resources{
+		getEventListenerPath(cicdPath): eventlisteners.CreateELFromTriggers("test-cicd", config.DefaultServiceAccountName, triggers),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("resources didn't match:%s\n", diff)
+	}
+}
+
+func TestBuildEventListenerWithResources(t *testing.T) {
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{
+				Name:                   "test-cicd",
+				EventListenerResources: &config.Resources{CPURequest: "250m", MemoryLimit: "512Mi"},
+			},
+		},
+		Environments: []*config.Environment{
+			testEnv(testService(), "dev"),
+		},
+		GitOpsURL: "http://github.com/org/gitops.git",
+	}
+	cicdPath := filepath.ToSlash(filepath.Join("config", "test-cicd"))
+	got, err := buildEventListenerResources(testRepoName, m)
+	assertNoError(t, err)
+
+	el := got[getEventListenerPath(cicdPath)].(*triggersv1.EventListener)
+	want := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+		Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+	}
+	if diff := cmp.Diff(want, el.Spec.Resources.KubernetesResource.Template.Spec.Containers[0].Resources); diff != "" {
+		t.Fatalf("event listener resources didn't match:%s\n", diff)
+	}
+}
+
+func TestCreateTriggersForCICDWithBranches(t *testing.T) {
+	cfg := &config.PipelinesConfig{Name: "test-cicd"}
+	envs := []*config.Environment{
+		{Name: "dev"},
+		{Name: "stage", Branches: []string{"main"}},
+		{Name: "prod", Branches: []string{"release/*"}},
+	}
+	got, err := createTriggersForCICD(testRepoName, cfg, envs)
+	assertNoError(t, err)
+
+	repo, err := scm.NewRepository(testRepoName)
+	assertNoError(t, err)
+	stage := repo.CreateBranchPushTrigger("ci-dryrun-from-push-stage", eventlisteners.GitOpsWebhookSecret, "test-cicd", "ci-dryrun-from-push-template", []string{"github-push-binding"}, []string{"main"})
+	stage.Bindings = append(stage.Bindings, environmentsBinding("stage"))
+	prod := repo.CreateBranchPushTrigger("ci-dryrun-from-push-prod", eventlisteners.GitOpsWebhookSecret, "test-cicd", "ci-dryrun-from-push-template", []string{"github-push-binding"}, []string{"release/*"})
+	prod.Bindings = append(prod.Bindings, environmentsBinding("prod"))
+	dev := repo.CreatePushTrigger("ci-dryrun-from-push", eventlisteners.GitOpsWebhookSecret, "test-cicd", "ci-dryrun-from-push-template", []string{"github-push-binding"})
+	dev.Bindings = append(dev.Bindings, environmentsBinding("dev"))
+	want := []triggersv1.EventListenerTrigger{stage, prod, dev}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("triggers didn't match:%s\n", diff)
+	}
+}
+
+func TestCreateTriggersForCICDWithAllBranches(t *testing.T) {
+	cfg := &config.PipelinesConfig{Name: "test-cicd"}
+	envs := []*config.Environment{
+		{Name: "stage", Branches: []string{"main"}},
+	}
+	got, err := createTriggersForCICD(testRepoName, cfg, envs)
+	assertNoError(t, err)
+
+	repo, err := scm.NewRepository(testRepoName)
+	assertNoError(t, err)
+	stage := repo.CreateBranchPushTrigger("ci-dryrun-from-push-stage", eventlisteners.GitOpsWebhookSecret, "test-cicd", "ci-dryrun-from-push-template", []string{"github-push-binding"}, []string{"main"})
+	stage.Bindings = append(stage.Bindings, environmentsBinding("stage"))
+	want := []triggersv1.EventListenerTrigger{stage}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("triggers didn't match:%s\n", diff)
+	}
+}
+
+func TestCreateTriggersForCICDWithGitLab(t *testing.T) {
+	cfg := &config.PipelinesConfig{Name: "test-cicd", PipelineNamePrefix: "tst-"}
+	gitOpsRepo := "https://gitlab.com/org/gitops.git"
+	got, err := createTriggersForCICD(gitOpsRepo, cfg, []*config.Environment{{Name: "dev"}})
+	assertNoError(t, err)
+
+	repo, err := scm.NewRepository(gitOpsRepo)
+	assertNoError(t, err)
+	want := []triggersv1.EventListenerTrigger{
+		repo.CreatePushTrigger("ci-dryrun-from-push", eventlisteners.GitOpsWebhookSecret, "test-cicd", "tst-ci-dryrun-from-push-template", []string{"tst-gitlab-push-binding"}),
+		repo.CreateMergeRequestTrigger("ci-dryrun-from-merge-request", eventlisteners.GitOpsWebhookSecret, "test-cicd", "tst-ci-dryrun-from-push-template", []string{"tst-gitlab-merge-request-binding"}),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("triggers didn't match:%s\n", diff)
+	}
+}
+
+func TestCreateTriggersForCICDWithDryRunTrigger(t *testing.T) {
+	cfg := &config.PipelinesConfig{Name: "test-cicd", DryRunTrigger: eventlisteners.DryRunTriggerPullRequest}
+	envs := []*config.Environment{
+		{Name: "dev"},
+		{Name: "stage", Branches: []string{"main"}},
+	}
+	got, err := createTriggersForCICD(testRepoName, cfg, envs)
+	assertNoError(t, err)
+
+	repo, err := scm.NewRepository(testRepoName)
+	assertNoError(t, err)
+	want := []triggersv1.EventListenerTrigger{
+		repo.CreateMergeRequestTrigger("ci-dryrun-from-merge-request", eventlisteners.GitOpsWebhookSecret, "test-cicd", "ci-dryrun-from-push-template", []string{"github-pull-request-binding"}),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("triggers didn't match:%s\n", diff)
+	}
+}
+
 func TestBuildEventListenerWithNoGitOpsURL(t *testing.T) {
 	m := &config.Manifest{
 		Environments: []*config.Environment{
@@ -175,7 +308,7 @@ func TestGetPipelines(t *testing.T) {
 				envPipelines = clonePipelines(test.env.Pipelines)
 			}
 			repo, _ := scm.NewRepository("https://github.com/foo/bar")
-			got := getPipelines(test.env, test.svc, repo)
+			got := getPipelines(test.env, test.svc, repo, "")
 			if diff := cmp.Diff(test.want, got); diff != "" {
 				rt.Errorf("getPipelines() failed:\n%v", diff)
 			}
@@ -189,14 +322,14 @@ func TestGetPipelines(t *testing.T) {
 func fakeTriggers(t *testing.T, m *config.Manifest, gitOpsRepo string) []triggersv1.EventListenerTrigger {
 	triggers := []triggersv1.EventListenerTrigger{}
 	cfg := m.GetPipelinesConfig()
-	cicdTriggers, err := createTriggersForCICD(gitOpsRepo, cfg)
+	cicdTriggers, err := createTriggersForCICD(gitOpsRepo, cfg, m.Environments)
 	assertNoError(t, err)
 	triggers = append(triggers, cicdTriggers...)
 	for _, env := range m.Environments {
 		svc := testService()
 		repo, err := scm.NewRepository(svc.SourceURL)
 		assertNoError(t, err)
-		pipelines := getPipelines(env, svc, repo)
+		pipelines := getPipelines(env, svc, repo, "")
 		devCITrigger := repo.CreatePushTrigger(fmt.Sprintf("app-ci-build-from-push-%s", svc.Name), svc.Webhook.Secret.Name, svc.Webhook.Secret.Namespace, pipelines.Integration.Template, pipelines.Integration.Bindings)
 		triggers = append(triggers, devCITrigger)
 	}
@@ -241,3 +374,7 @@ func testPipelines(name string) *config.Pipelines {
 		},
 	}
 }
+
+func environmentsBinding(names string) *triggersv1.EventListenerBinding {
+	return &triggersv1.EventListenerBinding{Name: "environments", Value: &names}
+}
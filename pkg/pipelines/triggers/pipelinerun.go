@@ -45,19 +45,7 @@ func createDevCIPipelineRun(saName string) pipelinev1.PipelineRun {
 				createPipelineBindingParam("COMMIT_AUTHOR", "$(tt.params."+GitCommitAuthor+")"),
 				createPipelineBindingParam("COMMIT_MESSAGE", "$(tt.params."+GitCommitMessage+")"),
 			},
-			Workspaces: []pipelinev1.WorkspaceBinding{
-				{
-					Name: "shared-data",
-					VolumeClaimTemplate: &corev1.PersistentVolumeClaim{
-						Spec: corev1.PersistentVolumeClaimSpec{
-							AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
-							Resources: corev1.ResourceRequirements{
-								Requests: corev1.ResourceList{"storage": resource.MustParse("1Gi")},
-							},
-						},
-					},
-				},
-			},
+			Workspaces: []pipelinev1.WorkspaceBinding{createSharedDataWorkspace()},
 		},
 	}
 }
@@ -87,6 +75,40 @@ func createCIPipelineRun(saName string) pipelinev1.PipelineRun {
 				createPipelineBindingParam("REPO", "$(tt.params.fullname)"),
 				createPipelineBindingParam("GIT_REPO", "$(tt.params.gitrepositoryurl)"),
 				createPipelineBindingParam("COMMIT_SHA", "$(tt.params.io.openshift.build.commit.id)"),
+				createPipelineBindingParam("ENVIRONMENTS", "$(tt.params.environments)"),
+			},
+		},
+	}
+}
+
+func createCIWorkspacePipelineRun(saName string) pipelinev1.PipelineRun {
+	return pipelinev1.PipelineRun{
+		TypeMeta: pipelineRunTypeMeta,
+		ObjectMeta: meta.ObjectMeta(
+			meta.NamespacedName("", "ci-dryrun-from-push-$(uid)")),
+		Spec: pipelinev1.PipelineRunSpec{
+			ServiceAccountName: saName,
+			PipelineRef:        createPipelineRef("ci-dryrun-from-push-pipeline"),
+			Params: []pipelinev1.Param{
+				createPipelineBindingParam("REPO", "$(tt.params.fullname)"),
+				createPipelineBindingParam("GIT_REPO", "$(tt.params.gitrepositoryurl)"),
+				createPipelineBindingParam("COMMIT_SHA", "$(tt.params.io.openshift.build.commit.id)"),
+				createPipelineBindingParam("ENVIRONMENTS", "$(tt.params.environments)"),
+			},
+			Workspaces: []pipelinev1.WorkspaceBinding{createSharedDataWorkspace()},
+		},
+	}
+}
+
+func createSharedDataWorkspace() pipelinev1.WorkspaceBinding {
+	return pipelinev1.WorkspaceBinding{
+		Name: "shared-data",
+		VolumeClaimTemplate: &corev1.PersistentVolumeClaim{
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{"storage": resource.MustParse("1Gi")},
+				},
 			},
 		},
 	}
@@ -103,6 +103,7 @@ func TestCreateStageCIPipelineRun(t *testing.T) {
 				createPipelineBindingParam("REPO", "$(tt.params.fullname)"),
 				createPipelineBindingParam("GIT_REPO", "$(tt.params.gitrepositoryurl)"),
 				createPipelineBindingParam("COMMIT_SHA", "$(tt.params.io.openshift.build.commit.id)"),
+				createPipelineBindingParam("ENVIRONMENTS", "$(tt.params.environments)"),
 			},
 		},
 	}
@@ -112,6 +113,41 @@ func TestCreateStageCIPipelineRun(t *testing.T) {
 	}
 }
 
+func TestCreateCIWorkspacePipelineRun(t *testing.T) {
+	want := pipelinev1.PipelineRun{
+		TypeMeta: pipelineRunTypeMeta,
+		ObjectMeta: meta.ObjectMeta(
+			meta.NamespacedName("", "ci-dryrun-from-push-$(uid)")),
+		Spec: pipelinev1.PipelineRunSpec{
+			ServiceAccountName: sName,
+			PipelineRef:        createPipelineRef("ci-dryrun-from-push-pipeline"),
+			Params: []v1beta1.Param{
+				createPipelineBindingParam("REPO", "$(tt.params.fullname)"),
+				createPipelineBindingParam("GIT_REPO", "$(tt.params.gitrepositoryurl)"),
+				createPipelineBindingParam("COMMIT_SHA", "$(tt.params.io.openshift.build.commit.id)"),
+				createPipelineBindingParam("ENVIRONMENTS", "$(tt.params.environments)"),
+			},
+			Workspaces: []pipelinev1.WorkspaceBinding{
+				{
+					Name: "shared-data",
+					VolumeClaimTemplate: &corev1.PersistentVolumeClaim{
+						Spec: corev1.PersistentVolumeClaimSpec{
+							AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{"storage": resource.MustParse("1Gi")},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	template := createCIWorkspacePipelineRun(sName)
+	if diff := cmp.Diff(want, template); diff != "" {
+		t.Fatalf("createCIWorkspacePipelineRun failed:\n%s", diff)
+	}
+}
+
 func TestCreateDevResource(t *testing.T) {
 	want := []pipelinev1.PipelineResourceBinding{
 		{
@@ -2,7 +2,11 @@ package triggers
 
 import (
 	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
 
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 	"k8s.io/apimachinery/pkg/runtime"
 
@@ -26,6 +30,10 @@ const (
 	// GitCommitDate is a label representing the commit timestamp for this
 	// build.
 	GitCommitDate = "io.openshift.build.commit.date"
+
+	// PipelineRunTTLAnnotation is read by the Tekton pruner to delete a
+	// PipelineRun once it has been finished for the number of seconds.
+	PipelineRunTTLAnnotation = "pruner.tekton.dev/ttlSecondsAfterFinished"
 )
 
 // GenerateTemplates will return a slice of trigger templates
@@ -115,16 +123,21 @@ func CreateCDPushTemplate(ns, saName string) triggersv1.TriggerTemplate {
 }
 
 // CreateCIDryRunTemplate returns TriggerTemplate for CI Dry Try
+//
+// This is triggered by pushes to the GitOps repository and, for GitLab, by
+// merge requests, the revision is the merge request's source branch and the
+// commit is the last commit in the merge request.
 func CreateCIDryRunTemplate(ns, saName string) triggersv1.TriggerTemplate {
 	return triggersv1.TriggerTemplate{
 		TypeMeta:   triggerTemplateTypeMeta,
 		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, "ci-dryrun-from-push-template")),
 		Spec: triggersv1.TriggerTemplateSpec{
 			Params: []triggersv1.ParamSpec{
-				createTemplateParamSpecDefault(GitRef, "The git revision", "master"),
+				createTemplateParamSpecDefault(GitRef, "The git revision, or the source branch of a merge request", "master"),
 				createTemplateParamSpec(GitCommitID, "The specific commit SHA"),
-				createTemplateParamSpec("gitrepositoryurl", "The git repository url"),
+				createTemplateParamSpec("gitrepositoryurl", "The git repository url, or the source repository url of a merge request"),
 				createTemplateParamSpec("fullname", "The repository name for this PullRequest"),
+				createTemplateParamSpecDefault("environments", "The space-separated names of the environments to dry-run, all of the environments if empty", ""),
 			},
 			ResourceTemplates: []triggersv1.TriggerResourceTemplate{
 				{
@@ -137,6 +150,65 @@ func CreateCIDryRunTemplate(ns, saName string) triggersv1.TriggerTemplate {
 	}
 }
 
+// CreateCIDryRunWorkspaceTemplate returns TriggerTemplate for CI Dry Run that
+// runs the pipeline created by pipelines.CreateCIWorkspacePipeline.
+func CreateCIDryRunWorkspaceTemplate(ns, saName string) triggersv1.TriggerTemplate {
+	t := CreateCIDryRunTemplate(ns, saName)
+	t.Spec.ResourceTemplates = []triggersv1.TriggerResourceTemplate{
+		{
+			RawExtension: runtime.RawExtension{
+				Raw: createCIWorkspaceResourceTemplate(saName),
+			},
+		},
+	}
+	return t
+}
+
+// WithPipelineRunTTL annotates the PipelineRuns created by the TriggerTemplate
+// so that they are pruned once they have been finished for the ttl.
+func WithPipelineRunTTL(t triggersv1.TriggerTemplate, ttl time.Duration) (triggersv1.TriggerTemplate, error) {
+	return updatePipelineRuns(t, func(pr *pipelinev1.PipelineRun) {
+		if pr.Annotations == nil {
+			pr.Annotations = map[string]string{}
+		}
+		pr.Annotations[PipelineRunTTLAnnotation] = strconv.Itoa(int(ttl.Seconds()))
+	})
+}
+
+// WithPipelineNamePrefix prefixes the name of the TriggerTemplate, and the
+// names of the Pipelines that its PipelineRuns reference.
+func WithPipelineNamePrefix(t triggersv1.TriggerTemplate, prefix string) (triggersv1.TriggerTemplate, error) {
+	t.Name = prefix + t.Name
+	return updatePipelineRuns(t, func(pr *pipelinev1.PipelineRun) {
+		if pr.Spec.PipelineRef != nil {
+			ref := *pr.Spec.PipelineRef
+			ref.Name = prefix + ref.Name
+			pr.Spec.PipelineRef = &ref
+		}
+	})
+}
+
+// updatePipelineRuns calls update with each of the PipelineRuns created by the
+// TriggerTemplate, and returns the TriggerTemplate with the updated
+// PipelineRuns.
+func updatePipelineRuns(t triggersv1.TriggerTemplate, update func(*pipelinev1.PipelineRun)) (triggersv1.TriggerTemplate, error) {
+	templates := make([]triggersv1.TriggerResourceTemplate, len(t.Spec.ResourceTemplates))
+	for i, rt := range t.Spec.ResourceTemplates {
+		var pr pipelinev1.PipelineRun
+		if err := json.Unmarshal(rt.Raw, &pr); err != nil {
+			return t, fmt.Errorf("failed to unmarshal the resource template in %s: %w", t.Name, err)
+		}
+		update(&pr)
+		raw, err := json.Marshal(pr)
+		if err != nil {
+			return t, fmt.Errorf("failed to marshal the resource template in %s: %w", t.Name, err)
+		}
+		templates[i] = triggersv1.TriggerResourceTemplate{RawExtension: runtime.RawExtension{Raw: raw}}
+	}
+	t.Spec.ResourceTemplates = templates
+	return t, nil
+}
+
 func createTemplateParamSpecDefault(name, description, value string) triggersv1.ParamSpec {
 	return triggersv1.ParamSpec{
 		Name:        name,
@@ -172,6 +244,11 @@ func createCIResourceTemplate(saName string) []byte {
 	return byteStageCI
 }
 
+func createCIWorkspaceResourceTemplate(saName string) []byte {
+	byteStageCI, _ := json.Marshal(createCIWorkspacePipelineRun(saName))
+	return byteStageCI
+}
+
 func strPtr(s string) *string {
 	return &s
 }
@@ -1,9 +1,12 @@
 package triggers
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 	"k8s.io/apimachinery/pkg/runtime"
 
@@ -158,10 +161,11 @@ func TestCreateCIDryRunTemplate(t *testing.T) {
 
 		Spec: triggersv1.TriggerTemplateSpec{
 			Params: []triggersv1.ParamSpec{
-				{Name: GitRef, Description: "The git revision", Default: strPtr("master")},
+				{Name: GitRef, Description: "The git revision, or the source branch of a merge request", Default: strPtr("master")},
 				{Name: "io.openshift.build.commit.id", Description: "The specific commit SHA"},
-				{Name: "gitrepositoryurl", Description: "The git repository url"},
+				{Name: "gitrepositoryurl", Description: "The git repository url, or the source repository url of a merge request"},
 				{Name: "fullname", Description: "The repository name for this PullRequest"},
+				{Name: "environments", Description: "The space-separated names of the environments to dry-run, all of the environments if empty", Default: strPtr("")},
 			},
 			ResourceTemplates: []triggersv1.TriggerResourceTemplate{
 				{
@@ -177,3 +181,40 @@ func TestCreateCIDryRunTemplate(t *testing.T) {
 		t.Fatalf("createCIdryrunptemplate failed:\n%s", diff)
 	}
 }
+
+func TestWithPipelineNamePrefix(t *testing.T) {
+	template, err := WithPipelineNamePrefix(CreateCIDryRunTemplate("testns", serviceAccName), "team-a-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if template.Name != "team-a-ci-dryrun-from-push-template" {
+		t.Fatalf("got template name %q, want %q", template.Name, "team-a-ci-dryrun-from-push-template")
+	}
+	var pr pipelinev1.PipelineRun
+	if err := json.Unmarshal(template.Spec.ResourceTemplates[0].Raw, &pr); err != nil {
+		t.Fatal(err)
+	}
+	want := createCIPipelineRun(serviceAccName)
+	want.Spec.PipelineRef.Name = "team-a-ci-dryrun-from-push-pipeline"
+	if diff := cmp.Diff(want, pr); diff != "" {
+		t.Fatalf("prefixed PipelineRun didn't match:\n%s", diff)
+	}
+}
+
+func TestWithPipelineRunTTL(t *testing.T) {
+	template, err := WithPipelineRunTTL(CreateDevCIBuildPRTemplate("testns", serviceAccName), 90*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var pr pipelinev1.PipelineRun
+	if err := json.Unmarshal(template.Spec.ResourceTemplates[0].Raw, &pr); err != nil {
+		t.Fatal(err)
+	}
+	want := createDevCIPipelineRun(serviceAccName)
+	want.Annotations = map[string]string{PipelineRunTTLAnnotation: "5400"}
+	if diff := cmp.Diff(want, pr); diff != "" {
+		t.Fatalf("annotated PipelineRun didn't match:\n%s", diff)
+	}
+}
@@ -2,18 +2,44 @@ package pipelines
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
 	"github.com/spf13/afero"
 )
 
-const defaultRepoDescription = "Bootstrapped GitOps Repository"
+const (
+	defaultRepoDescription = "Bootstrapped GitOps Repository"
+
+	// defaultBranch is the branch that the bootstrapped resources are pushed
+	// to if no DefaultBranch is provided.
+	defaultBranch = "main"
+)
+
+// retryDelay is the delay before the first retry of a failed request to the
+// Git host, or push, it's doubled for each retry after that.
+var retryDelay = 2 * time.Second
+
+// the output of a failed git push that is caused by the credentials, rather
+// than the state of the Git host, so there's no point in retrying it.
+var fatalPushOutputs = []string{
+	"Permission denied",
+	"Authentication failed",
+	"could not read Username",
+	"Repository not found",
+}
+
+// matches SCP-like SSH URLs e.g. git@github.com:org/repo.git
+var scpLikeURL = regexp.MustCompile(`^(?:[\w.-]+@)?([\w.-]+):[^/]`)
 
 type clientFactory = func(string) (*scm.Client, error)
 
@@ -45,7 +71,12 @@ func BootstrapRepository(o *BootstrapOptions, f clientFactory, e executor, appFs
 	// If we're creating the repository in a personal user's account, it's a
 	// different API call that's made, clearing the org triggers go-scm to use
 	// the "create repo in personal account" endpoint.
-	currentUser, _, err := client.Users.Find(ctx)
+	var currentUser *scm.User
+	err = withRetries(o.PushRetries, func() error {
+		user, resp, err := client.Users.Find(ctx)
+		currentUser = user
+		return retriableSCMError(resp, err)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to get the user with their auth token: %w", err)
 	}
@@ -54,26 +85,44 @@ func BootstrapRepository(o *BootstrapOptions, f clientFactory, e executor, appFs
 	}
 
 	ri := &scm.RepositoryInput{
-		Private:     true,
+		Private:     o.PrivateRepo == nil || *o.PrivateRepo,
 		Description: defaultRepoDescription,
 		Namespace:   org,
 		Name:        repoName,
 	}
-	created, _, err := client.Repositories.Create(context.Background(), ri)
+	// A request that failed with a server error may still have created the
+	// repository, in which case the retry finds it already exists.
+	retried := false
+	var created *scm.Repository
+	err = withRetries(o.PushRetries, func() error {
+		repo, resp, err := client.Repositories.Create(ctx, ri)
+		created = repo
+		err = retriableSCMError(resp, err)
+		if isRetriable(err) {
+			retried = true
+		}
+		return err
+	})
 	if err != nil {
 		repo := fmt.Sprintf("%s/%s", org, repoName)
 		if org == "" {
 			repo = fmt.Sprintf("%s/%s", currentUser.Login, repoName)
 		}
-		if _, resp, err := client.Repositories.Find(context.Background(), repo); err == nil && resp.Status == 200 {
+		existing, resp, findErr := client.Repositories.Find(ctx, repo)
+		if findErr != nil || resp.Status != 200 {
+			return fmt.Errorf("failed to create repository %q in namespace %q: %w", repoName, org, err)
+		}
+		// When resuming, the repository was probably created before the push
+		// failed, so push to it rather than failing.
+		if !o.Resume && !retried {
 			return fmt.Errorf("failed to create repository, repo already exists")
 		}
-		return fmt.Errorf("failed to create repository %q in namespace %q: %w", repoName, org, err)
+		created = existing
 	}
 	if err := pushRepository(o, created.CloneSSH, e, appFs); err != nil {
 		return fmt.Errorf("failed to push bootstrapped resources: %s", err)
 	}
-	return err
+	return nil
 }
 
 func pushRepository(o *BootstrapOptions, remote string, e executor, appFs afero.Fs) error {
@@ -85,34 +134,132 @@ func pushRepository(o *BootstrapOptions, remote string, e executor, appFs afero.
 	if out, err := e.execute(o.OutputPath, "git", "init", "."); err != nil {
 		return fmt.Errorf("failed to initialize git repository in %q %q: %s", o.OutputPath, string(out), err)
 	}
-	if out, err := e.execute(o.OutputPath, "git", "add", "pipelines.yaml", "config", "environments"); err != nil {
+	paths := []string{}
+	for _, p := range []string{pipelinesFile, "config", "environments"} {
+		paths = append(paths, path.Join(o.RepoSubpath, p))
+	}
+	if out, err := e.execute(o.OutputPath, "git", append([]string{"add"}, paths...)...); err != nil {
 		return fmt.Errorf("failed to add pipelines.yaml to repository in %q %q: %s", o.OutputPath, string(out), err)
 	}
 	if out, err := e.execute(o.OutputPath, "git", "commit", "-m", "Bootstrapped commit"); err != nil {
 		return fmt.Errorf("failed to commit files to repository in %q %q: %s", o.OutputPath, string(out), err)
 	}
-	if out, err := e.execute(o.OutputPath, "git", "branch", "-m", "main"); err != nil {
-		return fmt.Errorf("failed to switch to branch 'main' in repository in %q %q: %s", o.OutputPath, string(out), err)
+	branch := gitBranch(o)
+	if out, err := e.execute(o.OutputPath, "git", "branch", "-m", branch); err != nil {
+		return fmt.Errorf("failed to switch to branch %q in repository in %q %q: %s", branch, o.OutputPath, string(out), err)
 	}
 	if out, err := e.execute(o.OutputPath, "git", "remote", "add", "origin", remote); err != nil {
 		return fmt.Errorf("failed add remote 'origin' %q to repository in %q %q: %s", remote, o.OutputPath, string(out), err)
 	}
-	if out, err := e.execute(o.OutputPath, "git", "push", "-u", "origin", "main"); err != nil {
-		return fmt.Errorf("failed push remote to repository %q %q: %s", remote, string(out), err)
+	return withRetries(o.PushRetries, func() error {
+		out, err := e.execute(o.OutputPath, "git", "push", "-u", "origin", branch)
+		if err != nil {
+			return retriablePushError(out, fmt.Errorf("failed push remote to repository %q %q: %s", remote, string(out), err))
+		}
+		return nil
+	})
+}
+
+// retriableError is an error from a request to the Git host, or a push, that
+// may succeed if it's retried.
+type retriableError struct {
+	err error
+}
+
+func (e retriableError) Error() string {
+	return e.err.Error()
+}
+
+func (e retriableError) Unwrap() error {
+	return e.err
+}
+
+func isRetriable(err error) bool {
+	var r retriableError
+	return errors.As(err, &r)
+}
+
+// retriableSCMError returns the error from a go-scm call, marked as retriable
+// if the Git host failed with a server error, or the rate limit was exceeded,
+// or there was no response at all e.g. the connection was reset.
+//
+// Other errors, e.g. the token isn't authorized, fail the same way if they're
+// retried.
+func retriableSCMError(resp *scm.Response, err error) error {
+	if err == nil {
+		return nil
+	}
+	if resp == nil || resp.Status >= 500 || resp.Status == 429 ||
+		(resp.Status == 403 && resp.Rate.Limit > 0 && resp.Rate.Remaining == 0) {
+		return retriableError{err: err}
 	}
-	return nil
+	return err
 }
 
+// retriablePushError returns the error from a git push, marked as retriable
+// unless the output shows that the push wasn't authorized.
+func retriablePushError(out []byte, err error) error {
+	for _, s := range fatalPushOutputs {
+		if strings.Contains(string(out), s) {
+			return err
+		}
+	}
+	return retriableError{err: err}
+}
+
+// withRetries calls f until it succeeds, or returns an error that isn't
+// retriable, retrying up to retries times with an exponentially increasing
+// delay between the calls.
+func withRetries(retries int, f func() error) error {
+	delay := retryDelay
+	for attempt := 0; ; attempt++ {
+		err := f()
+		if err == nil || attempt >= retries || !isRetriable(err) {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// repoURL returns the scheme and host (including any port) that is used to
+// clone the repository over HTTP(S).
+//
+// SSH URLs e.g. git@example.com:org/repo.git or ssh://git@example.com:22/org/repo.git
+// are mapped to https://example.com as the SSH port isn't used for HTTPS.
 func repoURL(u string) (string, error) {
+	if scpLikeURL.MatchString(u) {
+		return "https://" + scpLikeURL.FindStringSubmatch(u)[1], nil
+	}
 	parsed, err := url.Parse(u)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse %q: %w", u, err)
 	}
+	if parsed.Host == "" {
+		return "", fmt.Errorf("failed to parse %q: missing host", u)
+	}
+	if parsed.Scheme == "ssh" || parsed.Scheme == "git" {
+		parsed.Scheme = "https"
+		parsed.Host = parsed.Hostname()
+	}
 	parsed.Path = ""
+	parsed.RawPath = ""
 	parsed.User = nil
 	return parsed.String(), nil
 }
 
+// cloneHost returns the value for the tekton.dev/git-0 annotation, the
+// override if provided, or the host derived from the repository URL.
+func cloneHost(override, repo string) (string, error) {
+	if override == "" {
+		return repoURL(repo)
+	}
+	if !strings.Contains(override, "://") {
+		override = "https://" + override
+	}
+	return repoURL(override)
+}
+
 // NewCmdExecutor creates and returns an executor implementation that uses
 // exec.Command to execute the commands.
 func NewCmdExecutor() cmdExecutor {
@@ -0,0 +1,115 @@
+package pipelines
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/mkmik/multierror"
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+)
+
+// ValidateManifest loads the manifest in the pipelines folder, validates its
+// structure, and checks that the resources that it references resolve,
+// returning a multi-error of all the problems found.
+//
+// The TriggerBindings of the services' app-ci pipelines must be generated by
+// build, or be in the 05-bindings folder of the CI/CD configuration e.g. the
+// image repository bindings from service add, and the services' webhook
+// secrets must be in the CI/CD namespace that the EventListener reads them
+// from.  The secrets themselves aren't checked, as they're not kept in the
+// GitOps repository.
+func ValidateManifest(appFs afero.Fs, pipelinesFolderPath string) error {
+	m, err := config.ParsePipelinesFolder(appFs, pipelinesFolderPath)
+	if err != nil {
+		return fmt.Errorf("failed to load manifest: %w", err)
+	}
+	if m.Config != nil && m.Config.Git != nil && m.Config.Git.Drivers != nil {
+		config.SetDriverMappings(m.Config.Git.Drivers)
+	}
+	errs := []error{}
+	if err := m.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	errs = append(errs, validateReferences(appFs, pipelinesFolderPath, m)...)
+	if len(errs) == 0 {
+		return nil
+	}
+	return multierror.Join(errs)
+}
+
+// validateReferences checks the bindings and webhook secrets of the services
+// with source repositories, the manifest may not be valid.
+func validateReferences(appFs afero.Fs, pipelinesFolderPath string, m *config.Manifest) []error {
+	cfg := m.GetPipelinesConfig()
+	if cfg == nil || cfg.DisableAppCI {
+		return nil
+	}
+	errs := []error{}
+	var generated res.Resources
+	if gitOpsRepo, err := scm.NewRepository(m.GitOpsURL); err != nil {
+		errs = append(errs, err)
+	} else {
+		generated = gitOpsRepoBindings(gitOpsRepo, cfg.Name, cfg.PipelineNamePrefix, cfg.DryRunTrigger)
+	}
+	bindingsPath := filepath.Join(pipelinesFolderPath, config.PathForPipelines(cfg), "base")
+
+	for _, env := range m.Environments {
+		for _, app := range env.Apps {
+			for _, svc := range app.Services {
+				if svc.SourceURL == "" {
+					continue
+				}
+				errs = append(errs, validateWebhookSecret(env, svc, cfg)...)
+				// The missing integration pipelines are reported by the
+				// structural validation.
+				if (env.Pipelines != nil && env.Pipelines.Integration == nil) || (svc.Pipelines != nil && svc.Pipelines.Integration == nil) {
+					continue
+				}
+				repo, err := scm.NewRepository(svc.SourceURL)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				for _, binding := range getPipelines(env, svc, repo, cfg.PipelineNamePrefix).Integration.Bindings {
+					ok, err := bindingExists(appFs, bindingsPath, generated, binding)
+					if err != nil {
+						errs = append(errs, err)
+						continue
+					}
+					if !ok {
+						errs = append(errs, fmt.Errorf("the binding %q of service %q in environment %q isn't generated or in %s",
+							binding, svc.Name, env.Name, filepath.ToSlash(filepath.Join(config.PathForPipelines(cfg), "base", "05-bindings"))))
+					}
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// validateWebhookSecret checks that a service with a source repository has a
+// webhook secret in the CI/CD namespace.
+func validateWebhookSecret(env *config.Environment, svc *config.Service, cfg *config.PipelinesConfig) []error {
+	if svc.Webhook == nil || svc.Webhook.Secret == nil {
+		return []error{fmt.Errorf("service %q in environment %q has a source_url but no webhook secret", svc.Name, env.Name)}
+	}
+	if ns := svc.Webhook.Secret.Namespace; ns != cfg.Name {
+		return []error{fmt.Errorf("the webhook secret %q of service %q in environment %q is in namespace %q, not the CI/CD namespace %q",
+			svc.Webhook.Secret.Name, svc.Name, env.Name, ns, cfg.Name)}
+	}
+	return nil
+}
+
+// bindingExists returns true if the binding is generated by build, or its
+// file is in the 05-bindings folder of the CI/CD base.
+func bindingExists(appFs afero.Fs, basePath string, generated res.Resources, binding string) (bool, error) {
+	filename := filepath.ToSlash(filepath.Join("05-bindings", binding+".yaml"))
+	if _, ok := generated[filename]; ok {
+		return true, nil
+	}
+	return afero.Exists(appFs, filepath.Join(basePath, filename))
+}
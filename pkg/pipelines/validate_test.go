@@ -0,0 +1,79 @@
+package pipelines
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+func TestValidateManifest(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	bootstrapTree(t, fakeFs, "/gitops")
+
+	fatalIfError(t, ValidateManifest(fakeFs, "/gitops"))
+}
+
+func TestValidateManifestWithUnresolvedReferences(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	bootstrapTree(t, fakeFs, "/gitops")
+	m, err := config.ParseFile(fakeFs, "/gitops/pipelines.yaml")
+	fatalIfError(t, err)
+	svc := m.GetEnvironment("tst-dev").Apps[0].Services[0]
+	svc.Webhook.Secret.Namespace = "tst-dev"
+	svc.Pipelines.Integration.Bindings = append(svc.Pipelines.Integration.Bindings, "missing-binding")
+	m.GetEnvironment("tst-stage").Apps = []*config.Application{
+		{Name: "app-other", Services: []*config.Service{{Name: "other", SourceURL: "https://github.com/example/other.git"}}},
+	}
+	data, err := yaml.Marshal(m)
+	fatalIfError(t, err)
+	fatalIfError(t, afero.WriteFile(fakeFs, "/gitops/pipelines.yaml", data, 0644))
+
+	err = ValidateManifest(fakeFs, "/gitops")
+	if err == nil {
+		t.Fatal("expected the unresolved references to be reported")
+	}
+	for _, want := range []string{
+		`the webhook secret "webhook-secret-tst-dev-http-api" of service "http-api" in environment "tst-dev" is in namespace "tst-dev", not the CI/CD namespace "tst-cicd"`,
+		`the binding "missing-binding" of service "http-api" in environment "tst-dev" isn't generated or in config/tst-cicd/base/05-bindings`,
+		`service "other" in environment "tst-stage" has a source_url but no webhook secret`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("got error %q, want it to contain %q", err, want)
+		}
+	}
+}
+
+func TestValidateManifestReportsStructuralAndReferenceErrors(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	bootstrapTree(t, fakeFs, "/gitops")
+	m, err := config.ParseFile(fakeFs, "/gitops/pipelines.yaml")
+	fatalIfError(t, err)
+	svc := m.GetEnvironment("tst-dev").Apps[0].Services[0]
+	svc.Pipelines.Integration.Bindings = append(svc.Pipelines.Integration.Bindings, "missing-binding")
+	m.GetEnvironment("tst-stage").Apps = []*config.Application{
+		{Name: "app-other", Services: []*config.Service{{Name: "other", SourceURL: "https://github.com/example/other.git", Pipelines: &config.Pipelines{}}}},
+	}
+	m.Environments = append(m.Environments, &config.Environment{Name: "Invalid_Name"})
+	data, err := yaml.Marshal(m)
+	fatalIfError(t, err)
+	fatalIfError(t, afero.WriteFile(fakeFs, "/gitops/pipelines.yaml", data, 0644))
+
+	err = ValidateManifest(fakeFs, "/gitops")
+	if err == nil {
+		t.Fatal("expected the structural and reference errors to be reported")
+	}
+	for _, want := range []string{
+		`Invalid_Name`,
+		`the binding "missing-binding" of service "http-api" in environment "tst-dev" isn't generated or in config/tst-cicd/base/05-bindings`,
+		`service "other" in environment "tst-stage" has a source_url but no webhook secret`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("got error %q, want it to contain %q", err, want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package pipelines
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mkmik/multierror"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/openapi/kubernetesapi"
+	"sigs.k8s.io/yaml"
+
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+)
+
+// VerifyKustomize runs a kustomize build over each of the bases referenced by
+// the root kustomization of the GitOps tree in path, and returns an error for
+// every overlay that fails to build.
+func VerifyKustomize(appFs afero.Fs, path string) error {
+	root := filepath.Join(path, Kustomize)
+	data, err := afero.ReadFile(appFs, root)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", root, err)
+	}
+	var k res.Kustomization
+	if err := yaml.Unmarshal(data, &k); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", root, err)
+	}
+
+	memFs, err := copyToKustomizeFs(appFs, path)
+	if err != nil {
+		return err
+	}
+	kustomizer := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+	errs := []error{}
+	for _, base := range k.Bases {
+		resetOpenAPISchema()
+		if _, err := kustomizer.Run(memFs, filepath.ToSlash(filepath.Join("/", base))); err != nil {
+			errs = append(errs, fmt.Errorf("failed to kustomize build %s: %w", base, err))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return multierror.Join(errs)
+}
+
+// resetOpenAPISchema resets the OpenAPI schema that kustomize merges patches
+// with to the built-in schema, kustomize keeps the schema of the first
+// kustomization that provides one for the rest of the process.
+func resetOpenAPISchema() {
+	openapi.ResetOpenAPI()
+	// Setting the built-in version clears any custom schema.
+	_ = openapi.SetSchema(map[string]string{"version": kubernetesapi.DefaultOpenAPI}, nil, true)
+}
+
+// copyToKustomizeFs copies the files under path into an in-memory kustomize
+// filesystem rooted at "/".
+func copyToKustomizeFs(appFs afero.Fs, path string) (filesys.FileSystem, error) {
+	memFs := filesys.MakeFsInMemory()
+	err := afero.Walk(appFs, path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(path, p)
+		if err != nil {
+			return err
+		}
+		data, err := afero.ReadFile(appFs, p)
+		if err != nil {
+			return err
+		}
+		return memFs.WriteFile(filepath.ToSlash(filepath.Join("/", rel)), data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the GitOps tree in %s: %w", path, err)
+	}
+	return memFs, nil
+}
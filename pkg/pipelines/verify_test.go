@@ -0,0 +1,51 @@
+package pipelines
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestVerifyKustomizeBootstrapped(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	outputPath := afero.GetTempDir(fakeFs, "gitops")
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           outputPath,
+		VerifyKustomize:      true,
+	}
+	fatalIfError(t, Bootstrap(params, fakeFs))
+}
+
+func TestVerifyKustomizeWithBrokenOverlay(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	outputPath := afero.GetTempDir(fakeFs, "gitops")
+	files := map[string]string{
+		"kustomization.yaml": "bases:\n- environments/dev/env/overlays\n",
+		"environments/dev/env/overlays/kustomization.yaml": "bases:\n- ../base\n",
+		"environments/dev/env/base/kustomization.yaml":     "resources:\n- missing.yaml\n",
+	}
+	for name, data := range files {
+		fatalIfError(t, afero.WriteFile(fakeFs, filepath.Join(outputPath, name), []byte(data), 0644))
+	}
+
+	err := VerifyKustomize(fakeFs, outputPath)
+	test.AssertErrorMatch(t, "failed to kustomize build environments/dev/env/overlays", err)
+}
+
+func TestVerifyKustomizeWithMissingRoot(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+
+	err := VerifyKustomize(fakeFs, "/gitops")
+	test.AssertErrorMatch(t, "failed to read /gitops/kustomization.yaml", err)
+}
@@ -29,10 +29,14 @@ type QualifiedServiceName struct {
 	ServiceName     string
 }
 
-// Create creates a new webhook on the target Git Repository
+// Create creates a new webhook on the target Git Repository that delivers the
+// named events, or the push and pull request events if none are named.
 // It returns the ID of created webhook.
-func Create(accessToken, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool) (string, error) {
-	webhook, err := newWebhookInfo(accessToken, pipelinesFile, serviceName, isCICD)
+//
+// The webhook delivers the events to the listenerURL, if this is empty, the
+// URL of the EventListener's Route in the cluster is used.
+func Create(accessToken, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool, events []string, listenerURL string) (string, error) {
+	webhook, err := newWebhookInfo(accessToken, pipelinesFile, serviceName, isCICD, listenerURL)
 	if err != nil {
 		return "", err
 	}
@@ -46,13 +50,13 @@ func Create(accessToken, pipelinesFile string, serviceName *QualifiedServiceName
 		return "", errors.New("webhook already exists")
 	}
 
-	return webhook.create()
+	return webhook.create(events)
 }
 
 // Delete deletes webhooks on the target Git Repository that match the listener address
 // It returns the IDs of deleted webhooks.
-func Delete(accessToken, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool) ([]string, error) {
-	webhook, err := newWebhookInfo(accessToken, pipelinesFile, serviceName, isCICD)
+func Delete(accessToken, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool, listenerURL string) ([]string, error) {
+	webhook, err := newWebhookInfo(accessToken, pipelinesFile, serviceName, isCICD, listenerURL)
 	if err != nil {
 		return nil, err
 	}
@@ -66,8 +70,8 @@ func Delete(accessToken, pipelinesFile string, serviceName *QualifiedServiceName
 }
 
 // List returns an array of webhook IDs for the target Git repository/listeners
-func List(accessToken, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool) ([]string, error) {
-	webhook, err := newWebhookInfo(accessToken, pipelinesFile, serviceName, isCICD)
+func List(accessToken, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool, listenerURL string) ([]string, error) {
+	webhook, err := newWebhookInfo(accessToken, pipelinesFile, serviceName, isCICD, listenerURL)
 	if err != nil {
 		return nil, err
 	}
@@ -75,7 +79,7 @@ func List(accessToken, pipelinesFile string, serviceName *QualifiedServiceName,
 	return webhook.list()
 }
 
-func newWebhookInfo(accessToken, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool) (*webhookInfo, error) {
+func newWebhookInfo(accessToken, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool, listenerURL string) (*webhookInfo, error) {
 	manifest, err := config.LoadManifest(ioutils.NewFilesystem(), pipelinesFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse pipelines: %v", err)
@@ -97,9 +101,11 @@ func newWebhookInfo(accessToken, pipelinesFile string, serviceName *QualifiedSer
 		return nil, err
 	}
 
-	listenerURL, err := getListenerURL(clusterResources, cicdNamepace)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get event listener URL: %v", err)
+	if listenerURL == "" {
+		listenerURL, err = getListenerURL(clusterResources, cicdNamepace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get event listener URL: %v", err)
+		}
 	}
 	if accessToken == "" {
 		accessToken, err = accesstoken.GetAccessToken(gitRepoURL)
@@ -131,13 +137,13 @@ func (w *webhookInfo) delete(ids []string) ([]string, error) {
 	return w.repository.DeleteWebhooks(ids)
 }
 
-func (w *webhookInfo) create() (string, error) {
+func (w *webhookInfo) create(events []string) (string, error) {
 	secret, err := getWebhookSecret(w.clusterResource, w.cicdNamepace, w.isCICD, w.serviceName)
 	if err != nil {
 		return "", fmt.Errorf("failed to get webhook secret: %v", err)
 	}
 
-	return w.repository.CreateWebhook(w.listenerURL, secret)
+	return w.repository.CreateWebhook(w.listenerURL, secret, events)
 }
 
 // Get Git repository URL whether it is CICD configuration or service source repository
@@ -1,9 +1,11 @@
 package yaml
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"path/filepath"
+	"sort"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/afero"
@@ -11,8 +13,8 @@ import (
 )
 
 // WriteResources takes a prefix path, and a map of paths to values, and will
-// marshal the values to the filenames as YAML resources, joining the prefix to
-// the filenames before writing.
+// marshal the values to the filenames as YAML resources, or JSON for .json
+// files, joining the prefix to the filenames before writing.
 //
 // It returns the list of filenames written out.
 func WriteResources(fs afero.Fs, path string, files map[string]interface{}) ([]string, error) {
@@ -31,6 +33,33 @@ func WriteResources(fs afero.Fs, path string, files map[string]interface{}) ([]s
 	return filenames, nil
 }
 
+// PrintResources takes a prefix path, and a map of paths to values, and will
+// marshal the values to out in the same format as WriteResources, sorted by
+// filename, nothing is written to a filesystem.
+//
+// Each value is written as a separate YAML document, preceded by a comment
+// with the filename that WriteResources would write it to.
+func PrintResources(out io.Writer, path string, files map[string]interface{}) error {
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	for _, filename := range filenames {
+		if _, err := fmt.Fprintf(out, "---\n# %s\n", filepath.Join(path, filename)); err != nil {
+			return fmt.Errorf("failed to write data: %v", err)
+		}
+		marshal := MarshalOutput
+		if filepath.Ext(filename) == ".json" {
+			marshal = marshalJSONOutput
+		}
+		if err := marshal(out, files[filename]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // MarshalItemToFile marshals item to file
 func MarshalItemToFile(fs afero.Fs, filename string, item interface{}) error {
 	err := fs.MkdirAll(filepath.Dir(filename), 0755)
@@ -42,9 +71,24 @@ func MarshalItemToFile(fs afero.Fs, filename string, item interface{}) error {
 		return fmt.Errorf("failed to Create file %s: %v", filename, err)
 	}
 	defer f.Close()
+	if filepath.Ext(filename) == ".json" {
+		return marshalJSONOutput(f, item)
+	}
 	return MarshalOutput(f, item)
 }
 
+func marshalJSONOutput(out io.Writer, output interface{}) error {
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %v", err)
+	}
+	_, err = fmt.Fprintf(out, "%s\n", data)
+	if err != nil {
+		return fmt.Errorf("failed to write data: %v", err)
+	}
+	return nil
+}
+
 // MarshalOutput marshal output to given writer
 func MarshalOutput(out io.Writer, output interface{}) error {
 	data, err := yaml.Marshal(output)
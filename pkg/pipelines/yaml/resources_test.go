@@ -1,6 +1,7 @@
 package yaml
 
 import (
+	"bytes"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -74,3 +75,33 @@ func assertResourceExists(t *testing.T, path string, resource interface{}) {
 		t.Fatalf("files not written to correct location: %s", diff)
 	}
 }
+
+func TestWriteResourcesAsJSON(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	r := res.Resources{"test/schema.json": map[string]interface{}{"swagger": "2.0"}}
+
+	_, err := WriteResources(fs, "/out", r)
+	test.AssertNoError(t, err)
+
+	got, err := afero.ReadFile(fs, "/out/test/schema.json")
+	test.AssertNoError(t, err)
+	if diff := cmp.Diff("{\n  \"swagger\": \"2.0\"\n}\n", string(got)); diff != "" {
+		t.Fatalf("JSON file didn't match:\n%s", diff)
+	}
+}
+
+func TestPrintResources(t *testing.T) {
+	r := res.Resources{
+		"test/myfile.yaml": map[string]string{"key": "value"},
+		"a/schema.json":    map[string]interface{}{"swagger": "2.0"},
+	}
+	var b bytes.Buffer
+
+	test.AssertNoError(t, PrintResources(&b, "/out", r))
+
+	want := "---\n# /out/a/schema.json\n{\n  \"swagger\": \"2.0\"\n}\n" +
+		"---\n# /out/test/myfile.yaml\nkey: value\n"
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Fatalf("printed resources didn't match:\n%s", diff)
+	}
+}